@@ -10,6 +10,7 @@ type Config struct {
 	Port            string
 	UserServiceURL  string
 	OrderServiceURL string
+	AdminAPIKey     string
 }
 
 func Load() Config {
@@ -20,6 +21,7 @@ func Load() Config {
 		Port:            getEnv("API_GATEWAY_PORT", "8080"),
 		UserServiceURL:  getEnv("USER_SERVICE_URL", "localhost:50051"),
 		OrderServiceURL: getEnv("ORDER_SERVICE_URL", "localhost:50052"),
+		AdminAPIKey:     getEnv("ADMIN_API_KEY", ""),
 	}
 }
 
@@ -3,8 +3,12 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	orderpb "online-store-microservice/proto/order"
 )
 
 func grpcToHTTP(err error) (int, string) {
@@ -13,6 +17,12 @@ func grpcToHTTP(err error) (int, string) {
 		return http.StatusInternalServerError, "internal server error"
 	}
 
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == orderpb.ReasonIdempotencyKeyReused {
+			return http.StatusUnprocessableEntity, st.Message()
+		}
+	}
+
 	switch st.Code() {
 	case codes.InvalidArgument:
 		return http.StatusBadRequest, st.Message()
@@ -22,9 +32,31 @@ func grpcToHTTP(err error) (int, string) {
 		return http.StatusConflict, st.Message()
 	case codes.Unauthenticated:
 		return http.StatusUnauthorized, st.Message()
+	case codes.PermissionDenied:
+		return http.StatusForbidden, st.Message()
+	case codes.FailedPrecondition:
+		return http.StatusConflict, st.Message()
+	case codes.Aborted:
+		return http.StatusPreconditionFailed, st.Message()
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable, st.Message()
 	case codes.DeadlineExceeded:
 		return http.StatusGatewayTimeout, "upstream timeout"
 	default:
 		return http.StatusInternalServerError, st.Message()
 	}
 }
+
+// abortResponse drops the connection of a response whose status was already
+// sent, so the client sees a truncated body instead of a complete one.
+func abortResponse(c *gin.Context) {
+	w, ok := c.Writer.(interface{ Unwrap() http.ResponseWriter })
+	if !ok {
+		return
+	}
+	if hj, ok := w.Unwrap().(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			_ = conn.Close()
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type couponRequest struct {
+	Code             string      `json:"code"`
+	Description      string      `json:"description" binding:"max=255"`
+	Kind             string      `json:"kind" binding:"required"`
+	PercentOffBps    int64       `json:"percent_off_bps" binding:"omitempty,gt=0,lte=10000"`
+	AmountOff        *moneyInput `json:"amount_off"`
+	BuyQuantity      int32       `json:"buy_quantity" binding:"omitempty,gt=0"`
+	GetQuantity      int32       `json:"get_quantity" binding:"omitempty,gt=0"`
+	MinSubtotal      *moneyInput `json:"min_subtotal"`
+	StartsAt         string      `json:"starts_at"`
+	EndsAt           string      `json:"ends_at"`
+	MaxRedemptions   int32       `json:"max_redemptions" binding:"omitempty,gte=0"`
+	MaxPerUser       int32       `json:"max_per_user" binding:"omitempty,gte=0"`
+	EligibleProducts []string    `json:"eligible_products" binding:"max=100"`
+	Active           *bool       `json:"active"`
+}
+
+type listCouponsQuery struct {
+	IncludeInactive bool `form:"include_inactive"`
+}
+
+func (r *couponRequest) toPB() *orderpb.CouponData {
+	active := r.Active == nil || *r.Active
+	return &orderpb.CouponData{
+		Code:             r.Code,
+		Description:      r.Description,
+		Kind:             r.Kind,
+		PercentOffBps:    r.PercentOffBps,
+		AmountOff:        r.AmountOff.toPB(),
+		BuyQuantity:      r.BuyQuantity,
+		GetQuantity:      r.GetQuantity,
+		MinSubtotal:      r.MinSubtotal.toPB(),
+		StartsAt:         r.StartsAt,
+		EndsAt:           r.EndsAt,
+		MaxRedemptions:   r.MaxRedemptions,
+		MaxPerUser:       r.MaxPerUser,
+		EligibleProducts: r.EligibleProducts,
+		Active:           active,
+	}
+}
+
+func (h *OrderHandler) CreateCoupon(c *gin.Context) {
+	var req couponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if req.Code == "" {
+		response.Fail(c, http.StatusBadRequest, "code is required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.CreateCoupon(ctx, &orderpb.CreateCouponRequest{Coupon: req.toPB()})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to create coupon", msg)
+		return
+	}
+
+	response.OK(c, http.StatusCreated, "coupon created", resp.Coupon)
+}
+
+func (h *OrderHandler) UpdateCoupon(c *gin.Context) {
+	code := c.Param("code")
+	if code == "" {
+		response.Fail(c, http.StatusBadRequest, "code is required", nil)
+		return
+	}
+
+	var req couponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	req.Code = code
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.UpdateCoupon(ctx, &orderpb.UpdateCouponRequest{Coupon: req.toPB()})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to update coupon", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "coupon updated", resp.Coupon)
+}
+
+func (h *OrderHandler) ListCoupons(c *gin.Context) {
+	var query listCouponsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.ListCoupons(ctx, &orderpb.ListCouponsRequest{IncludeInactive: query.IncludeInactive})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to list coupons", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "coupons fetched", resp.Coupons)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/api-gateway/middleware"
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// invoiceFormats maps the media types an invoice can be served as to the
+// format order-service renders.
+var invoiceFormats = map[string]string{
+	"application/json": "json",
+	"text/html":        "html",
+	"application/pdf":  "pdf",
+	"application/*":    "json",
+	"text/*":           "html",
+	"*/*":              "json",
+}
+
+// negotiateInvoiceFormat picks the format with the highest q value in the
+// Accept header, preferring the earlier one on a tie. A missing header means
+// JSON; ok is false when nothing acceptable is offered.
+func negotiateInvoiceFormat(accept string) (format string, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return "json", true
+	}
+	best := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		f, known := invoiceFormats[mediaType]
+		if !known {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > best {
+			best, format = q, f
+		}
+	}
+	return format, best > 0
+}
+
+// GetInvoice serves the invoice of an order, or one of its credit notes when
+// the route has a number, as JSON, HTML or PDF depending on the Accept header.
+// ?format=json|html|pdf overrides the header for links opened in a browser.
+func (h *OrderHandler) GetInvoice(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	format, ok := c.GetQuery("format")
+	if !ok {
+		c.Header("Vary", "Accept")
+		if format, ok = negotiateInvoiceFormat(c.GetHeader("Accept")); !ok {
+			response.Fail(c, http.StatusNotAcceptable, "invoice is available as application/json, text/html or application/pdf", nil)
+			return
+		}
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.GetInvoice(ctx, &orderpb.GetInvoiceRequest{
+		OrderId:      id,
+		Number:       c.Param("number"),
+		Format:       format,
+		ActorId:      callerID,
+		ActorIsAdmin: isAdmin,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get invoice", msg)
+		return
+	}
+
+	if len(resp.Content) > 0 {
+		if resp.ContentType == "application/pdf" {
+			c.Header("Content-Disposition", `inline; filename="`+resp.Invoice.Number+`.pdf"`)
+		}
+		c.Data(http.StatusOK, resp.ContentType, resp.Content)
+		return
+	}
+	response.OK(c, http.StatusOK, "invoice fetched", gin.H{"invoice": resp.Invoice, "credit_notes": resp.CreditNotes})
+}
@@ -1,11 +1,20 @@
 package handlers
 
 import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
 
 	"online-store-microservice/api-gateway/grpc_clients"
+	"online-store-microservice/api-gateway/middleware"
 	"online-store-microservice/pkg/response"
 	orderpb "online-store-microservice/proto/order"
 )
@@ -18,11 +27,179 @@ func NewOrderHandler(client *grpc_clients.OrderClient) *OrderHandler {
 	return &OrderHandler{client: client}
 }
 
+func (h *OrderHandler) requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := h.client.TimeoutContext()
+	if rid := c.GetString("request_id"); rid != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, orderpb.RequestIDMetadata, rid)
+	}
+	return ctx, cancel
+}
+
+const maxIdempotencyKeyLength = 255
+
+var errInvalidIfMatch = errors.New(`must be a single order ETag such as "3"`)
+
+// setETag exposes the order version so clients can send it back in If-Match.
+func setETag(c *gin.Context, order *orderpb.OrderData) {
+	if order != nil && order.Version > 0 {
+		c.Header("ETag", strconv.Quote(strconv.FormatInt(order.Version, 10)))
+	}
+}
+
+// ifMatchVersion reads the order version from If-Match. A missing header or
+// "*" returns 0, which order-service treats as "any version".
+func ifMatchVersion(c *gin.Context) (int64, error) {
+	tag := strings.TrimSpace(c.GetHeader("If-Match"))
+	if tag == "" || tag == "*" {
+		return 0, nil
+	}
+	tag = strings.TrimPrefix(tag, "W/")
+	unquoted, err := strconv.Unquote(tag)
+	if err != nil {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
+type moneyInput struct {
+	Amount   int64  `json:"amount" binding:"gt=0"`
+	Currency string `json:"currency" binding:"required,len=3"`
+}
+
+func (m *moneyInput) toPB() *orderpb.Money {
+	if m == nil {
+		return nil
+	}
+	return &orderpb.Money{Amount: m.Amount, Currency: m.Currency}
+}
+
+type createOrderItem struct {
+	ProductID          string      `json:"product_id" binding:"required_without=SKU"`
+	SKU                string      `json:"sku" binding:"required_without=ProductID"`
+	Name               string      `json:"name"`
+	UnitPrice          float64     `json:"unit_price" binding:"omitempty,gt=0"`
+	ExpectedUnitAmount *moneyInput `json:"expected_unit_amount"`
+	Quantity           int32       `json:"quantity" binding:"required,gt=0"`
+}
+
+func orderItemsToPB(in []createOrderItem) []*orderpb.OrderItemInput {
+	items := make([]*orderpb.OrderItemInput, 0, len(in))
+	for _, item := range in {
+		items = append(items, &orderpb.OrderItemInput{
+			ProductId:          item.ProductID,
+			Sku:                item.SKU,
+			Name:               item.Name,
+			UnitPrice:          item.UnitPrice,
+			Quantity:           item.Quantity,
+			ExpectedUnitAmount: item.ExpectedUnitAmount.toPB(),
+		})
+	}
+	return items
+}
+
 type createOrderRequest struct {
-	UserID      string  `json:"user_id" binding:"required"`
-	ProductName string  `json:"product_name" binding:"required"`
-	Quantity    int32   `json:"quantity" binding:"required,gt=0"`
-	TotalPrice  float64 `json:"total_price" binding:"required,gt=0"`
+	UserID              string            `json:"user_id" binding:"required"`
+	Items               []createOrderItem `json:"items" binding:"required_without=ProductName,omitempty,dive"`
+	Currency            string            `json:"currency" binding:"omitempty,len=3"`
+	TotalPrice          float64           `json:"total_price" binding:"omitempty,gt=0"`
+	ExpectedTotalAmount *moneyInput       `json:"expected_total_amount"`
+	CouponCode          string            `json:"coupon_code" binding:"max=64"`
+	ShippingAddress     *addressInput     `json:"shipping_address"`
+	ShippingMethod      string            `json:"shipping_method" binding:"max=64"`
+
+	// ProductName belongs to the single-item body, which is no longer
+	// accepted. It is only read to say so.
+	ProductName string `json:"product_name"`
+}
+
+type addressInput struct {
+	Name       string `json:"name" binding:"max=255"`
+	Line1      string `json:"line1" binding:"max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"max=255"`
+	Region     string `json:"region" binding:"max=3"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"required,len=2"`
+}
+
+func (a *addressInput) toPB() *orderpb.Address {
+	if a == nil {
+		return nil
+	}
+	return &orderpb.Address{Name: a.Name, Line1: a.Line1, Line2: a.Line2, City: a.City, Region: a.Region, PostalCode: a.PostalCode, Country: a.Country}
+}
+
+type listOrdersQuery struct {
+	PageSize    int32  `form:"page_size" binding:"omitempty,min=1,max=100"`
+	PageToken   string `form:"page_token"`
+	Status      string `form:"status"`
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+	MinTotal    string `form:"min_total"`
+	MaxTotal    string `form:"max_total"`
+	Sort        string `form:"sort" binding:"omitempty,oneof=created_at -created_at total -total"`
+}
+
+type searchOrdersQuery struct {
+	listOrdersQuery
+	ProductName   string `form:"product_name" binding:"max=255"`
+	CustomerEmail string `form:"customer_email" binding:"omitempty,email"`
+	UserID        string `form:"user_id"`
+	OrderNumber   string `form:"order_number" binding:"max=64"`
+}
+
+// request builds the SearchOrders request; status is a comma separated list.
+func (q searchOrdersQuery) request() *orderpb.SearchOrdersRequest {
+	return &orderpb.SearchOrdersRequest{
+		PageSize:      q.PageSize,
+		PageToken:     q.PageToken,
+		Sort:          q.Sort,
+		Statuses:      splitList(q.Status),
+		CreatedFrom:   q.CreatedFrom,
+		CreatedTo:     q.CreatedTo,
+		MinTotal:      q.MinTotal,
+		MaxTotal:      q.MaxTotal,
+		ProductName:   q.ProductName,
+		CustomerEmail: q.CustomerEmail,
+		UserId:        q.UserID,
+		OrderNumber:   q.OrderNumber,
+	}
+}
+
+type exportOrdersQuery struct {
+	searchOrdersQuery
+	Format  string `form:"format" binding:"omitempty,oneof=csv ndjson"`
+	Columns string `form:"columns"`
+	Gzip    bool   `form:"gzip"`
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+type updateOrderStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type amendOrderRequest struct {
+	Items               []createOrderItem `json:"items" binding:"omitempty,max=100,dive"`
+	ShippingAddress     *addressInput     `json:"shipping_address"`
+	ExpectedTotalAmount *moneyInput       `json:"expected_total_amount"`
+}
+
+type cancelOrderRequest struct {
+	ReasonCode string `json:"reason_code" binding:"required"`
 }
 
 func (h *OrderHandler) Create(c *gin.Context) {
@@ -31,15 +208,32 @@ func (h *OrderHandler) Create(c *gin.Context) {
 		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
 		return
 	}
+	if len(req.Items) == 0 {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", "product_name and quantity are no longer accepted; send items with product_id or sku and quantity")
+		return
+	}
 
-	ctx, cancel := h.client.TimeoutContext()
+	key := c.GetHeader("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLength {
+		response.Fail(c, http.StatusBadRequest, "invalid Idempotency-Key header", "must be at most 255 characters")
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
 	defer cancel()
+	if key != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, orderpb.IdempotencyKeyMetadata, key)
+	}
 
 	resp, err := h.client.Client.CreateOrder(ctx, &orderpb.CreateOrderRequest{
-		UserId:      req.UserID,
-		ProductName: req.ProductName,
-		Quantity:    req.Quantity,
-		TotalPrice:  req.TotalPrice,
+		UserId:              req.UserID,
+		TotalPrice:          req.TotalPrice,
+		Items:               orderItemsToPB(req.Items),
+		Currency:            req.Currency,
+		ExpectedTotalAmount: req.ExpectedTotalAmount.toPB(),
+		CouponCode:          req.CouponCode,
+		ShippingAddress:     req.ShippingAddress.toPB(),
+		ShippingMethod:      req.ShippingMethod,
 	})
 	if err != nil {
 		code, msg := grpcToHTTP(err)
@@ -50,6 +244,9 @@ func (h *OrderHandler) Create(c *gin.Context) {
 	response.OK(c, http.StatusCreated, "order created", resp.Order)
 }
 
+// GetByID accepts either the order id or its order number, such as
+// ORD-2026-000123. Order numbers can be guessed, so only the customer who
+// placed the order or an admin may look one up by number.
 func (h *OrderHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
@@ -57,19 +254,60 @@ func (h *OrderHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := h.client.TimeoutContext()
+	_, parseErr := uuid.Parse(id)
+	byNumber := parseErr != nil
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if byNumber && callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
 	defer cancel()
 
-	resp, err := h.client.Client.GetOrderById(ctx, &orderpb.GetOrderByIdRequest{Id: id})
+	includeHistory := c.Query("include") == "history"
+	var resp *orderpb.GetOrderByIdResponse
+	var err error
+	if byNumber {
+		resp, err = h.client.Client.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{
+			OrderNumber:    id,
+			IncludeHistory: includeHistory,
+			ActorId:        callerID,
+			ActorIsAdmin:   isAdmin,
+		})
+	} else {
+		resp, err = h.client.Client.GetOrderById(ctx, &orderpb.GetOrderByIdRequest{Id: id, IncludeHistory: includeHistory})
+	}
 	if err != nil {
 		code, msg := grpcToHTTP(err)
 		response.Fail(c, code, "failed to get order", msg)
 		return
 	}
 
+	setETag(c, resp.Order)
 	response.OK(c, http.StatusOK, "order fetched", resp.Order)
 }
 
+func (h *OrderHandler) History(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.GetOrderHistory(ctx, &orderpb.GetOrderHistoryRequest{OrderId: id})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get order history", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "order history fetched", resp.History)
+}
+
 func (h *OrderHandler) GetByUserID(c *gin.Context) {
 	userID := c.Param("userId")
 	if userID == "" {
@@ -80,15 +318,332 @@ func (h *OrderHandler) GetByUserID(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := h.client.TimeoutContext()
+	var query listOrdersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
 	defer cancel()
 
-	resp, err := h.client.Client.GetOrdersByUserId(ctx, &orderpb.GetOrdersByUserIdRequest{UserId: userID})
+	resp, err := h.client.Client.GetOrdersByUserId(ctx, &orderpb.GetOrdersByUserIdRequest{
+		UserId:      userID,
+		PageSize:    query.PageSize,
+		PageToken:   query.PageToken,
+		Status:      query.Status,
+		CreatedFrom: query.CreatedFrom,
+		CreatedTo:   query.CreatedTo,
+		MinTotal:    query.MinTotal,
+		MaxTotal:    query.MaxTotal,
+		Sort:        query.Sort,
+	})
 	if err != nil {
 		code, msg := grpcToHTTP(err)
 		response.Fail(c, code, "failed to get user orders", msg)
 		return
 	}
 
-	response.OK(c, http.StatusOK, "orders fetched", resp.Orders)
+	response.Page(c, http.StatusOK, "orders fetched", resp.Orders, response.NewPagination(len(resp.Orders), resp.NextPageToken))
+}
+
+// Search lists the orders of every user for admins. status takes a comma
+// separated list.
+func (h *OrderHandler) Search(c *gin.Context) {
+	var query searchOrdersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.SearchOrders(ctx, query.request())
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to search orders", msg)
+		return
+	}
+
+	response.Page(c, http.StatusOK, "orders fetched", resp.Orders, response.NewPagination(len(resp.Orders), resp.NextPageToken))
+}
+
+// Export streams every order matching the search filters as a CSV or NDJSON
+// download. Errors found before the first row get the usual JSON error
+// response; a failure after that drops the connection, so that a truncated
+// file is not taken for a complete one.
+func (h *OrderHandler) Export(c *gin.Context) {
+	var query exportOrdersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+	if query.Format == "" {
+		query.Format = "csv"
+	}
+
+	// An export takes as long as it takes; it ends when the client goes away.
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	if rid := c.GetString("request_id"); rid != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, orderpb.RequestIDMetadata, rid)
+	}
+
+	stream, err := h.client.Client.ExportOrders(ctx, &orderpb.ExportOrdersRequest{
+		Filter:  query.request(),
+		Format:  query.Format,
+		Columns: splitList(query.Columns),
+	})
+	var chunk *orderpb.ExportOrdersChunk
+	if err == nil {
+		chunk, err = stream.Recv()
+	}
+	if err != nil && err != io.EOF {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to export orders", msg)
+		return
+	}
+
+	filename, contentType := "orders.csv", "text/csv; charset=utf-8"
+	if query.Format == "ndjson" {
+		filename, contentType = "orders.ndjson", "application/x-ndjson"
+	}
+	var out io.Writer = c.Writer
+	var gz *gzip.Writer
+	if query.Gzip {
+		filename, contentType = filename+".gz", "application/gzip"
+		gz = gzip.NewWriter(c.Writer)
+		out = gz
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	for err == nil {
+		if _, err = out.Write(chunk.Data); err != nil {
+			return
+		}
+		c.Writer.Flush()
+		chunk, err = stream.Recv()
+	}
+	if err != io.EOF {
+		_ = c.Error(err)
+		abortResponse(c)
+		return
+	}
+	if gz != nil {
+		_ = gz.Close()
+	}
+}
+
+func (h *OrderHandler) UpdateStatus(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req updateOrderStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.UpdateOrderStatus(ctx, &orderpb.UpdateOrderStatusRequest{
+		Id:              id,
+		Status:          req.Status,
+		ActorId:         "admin",
+		Reason:          req.Reason,
+		ExpectedVersion: version,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to update order status", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "order status updated", resp.Order)
+}
+
+func (h *OrderHandler) Cancel(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	var req cancelOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.CancelOrder(ctx, &orderpb.CancelOrderRequest{
+		Id:              id,
+		ReasonCode:      req.ReasonCode,
+		ActorId:         callerID,
+		ActorIsAdmin:    isAdmin,
+		ExpectedVersion: version,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to cancel order", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "order cancelled", resp.Order)
+}
+
+// Reorder creates a new order with the items of an earlier one at today's
+// prices. With ?preview=true the order is priced but not created.
+func (h *OrderHandler) Reorder(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	preview := false
+	if v := c.Query("preview"); v != "" {
+		var err error
+		if preview, err = strconv.ParseBool(v); err != nil {
+			response.Fail(c, http.StatusBadRequest, "invalid preview", "must be true or false")
+			return
+		}
+	}
+
+	key := c.GetHeader("Idempotency-Key")
+	if len(key) > maxIdempotencyKeyLength {
+		response.Fail(c, http.StatusBadRequest, "invalid Idempotency-Key header", "must be at most 255 characters")
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+	if key != "" && !preview {
+		ctx = metadata.AppendToOutgoingContext(ctx, orderpb.IdempotencyKeyMetadata, key)
+	}
+
+	resp, err := h.client.Client.Reorder(ctx, &orderpb.ReorderRequest{
+		OrderId:      id,
+		ActorId:      callerID,
+		ActorIsAdmin: isAdmin,
+		Preview:      preview,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to reorder", msg)
+		return
+	}
+
+	data := gin.H{"order": resp.Order, "unavailable_items": resp.UnavailableItems, "price_changes": resp.PriceChanges}
+	if preview {
+		response.OK(c, http.StatusOK, "reorder previewed", data)
+		return
+	}
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusCreated, "order created", data)
+}
+
+// Amend changes the items or shipping address of an order that is not yet
+// being fulfilled. With ?preview=true the amendment is priced but not stored.
+func (h *OrderHandler) Amend(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	var req amendOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if len(req.Items) == 0 && req.ShippingAddress == nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", "items or shipping_address is required")
+		return
+	}
+
+	preview := false
+	if v := c.Query("preview"); v != "" {
+		var err error
+		if preview, err = strconv.ParseBool(v); err != nil {
+			response.Fail(c, http.StatusBadRequest, "invalid preview", "must be true or false")
+			return
+		}
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	amend := &orderpb.AmendOrderRequest{
+		Id:                  id,
+		ActorId:             callerID,
+		ActorIsAdmin:        isAdmin,
+		ExpectedVersion:     version,
+		ShippingAddress:     req.ShippingAddress.toPB(),
+		ExpectedTotalAmount: req.ExpectedTotalAmount.toPB(),
+		Preview:             preview,
+	}
+	if len(req.Items) > 0 {
+		amend.Items = orderItemsToPB(req.Items)
+	}
+	resp, err := h.client.Client.AmendOrder(ctx, amend)
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to amend order", msg)
+		return
+	}
+
+	if preview {
+		response.OK(c, http.StatusOK, "amendment previewed", resp)
+		return
+	}
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "order amended", resp)
 }
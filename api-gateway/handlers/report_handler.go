@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type salesReportQuery struct {
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+}
+
+// SalesReport sums the paid orders of a period in the store currency.
+func (h *OrderHandler) SalesReport(c *gin.Context) {
+	var query salesReportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid query parameters", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.GetSalesReport(ctx, &orderpb.SalesReportRequest{CreatedFrom: query.CreatedFrom, CreatedTo: query.CreatedTo})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to build sales report", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "sales report fetched", resp)
+}
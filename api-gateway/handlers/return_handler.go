@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/api-gateway/middleware"
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type returnItem struct {
+	OrderItemID string `json:"order_item_id" binding:"required"`
+	Quantity    int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+type requestReturnRequest struct {
+	ReasonCode string       `json:"reason_code" binding:"required"`
+	Note       string       `json:"note" binding:"max=1000"`
+	Items      []returnItem `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+type updateReturnStatusRequest struct {
+	Status string `json:"status" binding:"required"`
+	Note   string `json:"note" binding:"max=1000"`
+}
+
+type refundReturnRequest struct {
+	Amount *moneyInput `json:"amount"`
+}
+
+func (h *OrderHandler) RequestReturn(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	var req requestReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	items := make([]*orderpb.ReturnItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &orderpb.ReturnItemInput{OrderItemId: item.OrderItemID, Quantity: item.Quantity})
+	}
+	resp, err := h.client.Client.RequestReturn(ctx, &orderpb.RequestReturnRequest{
+		OrderId:      id,
+		ActorId:      callerID,
+		ActorIsAdmin: isAdmin,
+		ReasonCode:   req.ReasonCode,
+		Note:         req.Note,
+		Items:        items,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to request return", msg)
+		return
+	}
+
+	response.OK(c, http.StatusCreated, "return requested", resp.Return)
+}
+
+func (h *OrderHandler) ListReturns(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.ListOrderReturns(ctx, &orderpb.ListOrderReturnsRequest{OrderId: id, ActorId: callerID, ActorIsAdmin: isAdmin})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get returns", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "returns fetched", gin.H{
+		"returns":          resp.Returns,
+		"refunded_amount":  resp.Order.RefundedAmount,
+		"net_total_amount": resp.Order.NetTotalAmount,
+	})
+}
+
+func (h *OrderHandler) UpdateReturnStatus(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req updateReturnStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.UpdateReturnStatus(ctx, &orderpb.UpdateReturnStatusRequest{Id: id, Status: req.Status, ActorId: "admin", Note: req.Note, ExpectedVersion: version})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to update return status", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "return status updated", resp.Return)
+}
+
+func (h *OrderHandler) RefundReturn(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req refundReturnRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id, Amount: req.Amount.toPB(), ActorId: "admin", ExpectedVersion: version})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to refund return", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "return refunded", gin.H{"return": resp.Return, "order": resp.Order})
+}
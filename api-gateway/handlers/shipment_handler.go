@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/api-gateway/middleware"
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type shipmentItem struct {
+	OrderItemID string `json:"order_item_id" binding:"required"`
+	Quantity    int32  `json:"quantity" binding:"required,gt=0"`
+}
+
+type createShipmentRequest struct {
+	Carrier string         `json:"carrier" binding:"max=64"`
+	Items   []shipmentItem `json:"items" binding:"max=100,dive"`
+}
+
+type updateShipmentStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func (h *OrderHandler) CreateShipment(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req createShipmentRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	items := make([]*orderpb.ShipmentItemInput, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, &orderpb.ShipmentItemInput{OrderItemId: item.OrderItemID, Quantity: item.Quantity})
+	}
+	resp, err := h.client.Client.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: id, Carrier: req.Carrier, Items: items, ActorId: "admin", ExpectedVersion: version})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to create shipment", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusCreated, "shipment created", gin.H{"shipment": resp.Shipment, "order": resp.Order})
+}
+
+func (h *OrderHandler) ListShipments(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.ListOrderShipments(ctx, &orderpb.ListOrderShipmentsRequest{OrderId: id, ActorId: callerID, ActorIsAdmin: isAdmin})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get shipments", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "shipments fetched", gin.H{
+		"shipments":       resp.Shipments,
+		"status":          resp.Order.Status,
+		"shipping_method": resp.Order.ShippingMethod,
+	})
+}
+
+// UpdateShipmentStatus records a status reported by the carrier. Without a
+// status in the body, order-service asks the carrier for it.
+func (h *OrderHandler) UpdateShipmentStatus(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req updateShipmentStatusRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+			return
+		}
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid If-Match header", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.UpdateShipmentStatus(ctx, &orderpb.UpdateShipmentStatusRequest{Id: id, Status: req.Status, ActorId: "admin", ExpectedVersion: version})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to update shipment status", msg)
+		return
+	}
+
+	setETag(c, resp.Order)
+	response.OK(c, http.StatusOK, "shipment status updated", gin.H{"shipment": resp.Shipment, "order": resp.Order})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/pkg/response"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type taxExemptionRequest struct {
+	Reason      string `json:"reason" binding:"required,max=255"`
+	Certificate string `json:"certificate" binding:"max=100"`
+}
+
+func (h *OrderHandler) GetTaxExemption(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.GetTaxExemption(ctx, &orderpb.GetTaxExemptionRequest{UserId: id})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get tax exemption", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "tax exemption fetched", resp.Exemption)
+}
+
+func (h *OrderHandler) SetTaxExemption(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	var req taxExemptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	resp, err := h.client.Client.SetTaxExemption(ctx, &orderpb.SetTaxExemptionRequest{UserId: id, Reason: req.Reason, Certificate: req.Certificate, ActorId: "admin"})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to set tax exemption", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "tax exemption set", resp.Exemption)
+}
+
+func (h *OrderHandler) RemoveTaxExemption(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	ctx, cancel := h.requestContext(c)
+	defer cancel()
+
+	if _, err := h.client.Client.RemoveTaxExemption(ctx, &orderpb.RemoveTaxExemptionRequest{UserId: id}); err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to remove tax exemption", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "tax exemption removed", nil)
+}
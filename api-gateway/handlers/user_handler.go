@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"online-store-microservice/api-gateway/grpc_clients"
+	"online-store-microservice/api-gateway/middleware"
 	"online-store-microservice/pkg/response"
 	userpb "online-store-microservice/proto/user"
 )
@@ -19,9 +20,10 @@ func NewUserHandler(client *grpc_clients.UserClient) *UserHandler {
 }
 
 type registerRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
-	Name     string `json:"name" binding:"required,min=2"`
+	Email                string `json:"email" binding:"required,email"`
+	Password             string `json:"password" binding:"required,min=6"`
+	Name                 string `json:"name" binding:"required,min=2"`
+	AcceptedTermsVersion string `json:"accepted_terms_version" binding:"required"`
 }
 
 type loginRequest struct {
@@ -29,6 +31,19 @@ type loginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type recordConsentRequest struct {
+	Purpose       string `json:"purpose" binding:"required"`
+	Granted       *bool  `json:"granted" binding:"required"`
+	PolicyVersion string `json:"policy_version" binding:"required"`
+	Source        string `json:"source"`
+}
+
+type mergeUsersRequest struct {
+	SourceID string `json:"source_id" binding:"required"`
+	TargetID string `json:"target_id" binding:"required"`
+	DryRun   bool   `json:"dry_run"`
+}
+
 func (h *UserHandler) Register(c *gin.Context) {
 	var req registerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,9 +55,12 @@ func (h *UserHandler) Register(c *gin.Context) {
 	defer cancel()
 
 	resp, err := h.client.Client.Register(ctx, &userpb.RegisterRequest{
-		Email:    req.Email,
-		Password: req.Password,
-		Name:     req.Name,
+		Email:                req.Email,
+		Password:             req.Password,
+		Name:                 req.Name,
+		AcceptedTermsVersion: req.AcceptedTermsVersion,
+		IpAddress:            c.ClientIP(),
+		UserAgent:            c.Request.UserAgent(),
 	})
 	if err != nil {
 		code, msg := grpcToHTTP(err)
@@ -92,3 +110,123 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 
 	response.OK(c, http.StatusOK, "user fetched", resp.User)
 }
+
+func (h *UserHandler) RecordConsent(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	var req recordConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	ctx, cancel := h.client.TimeoutContext()
+	defer cancel()
+
+	resp, err := h.client.Client.RecordConsent(ctx, &userpb.RecordConsentRequest{
+		UserId:        id,
+		Purpose:       req.Purpose,
+		Granted:       *req.Granted,
+		PolicyVersion: req.PolicyVersion,
+		Source:        req.Source,
+		IpAddress:     c.ClientIP(),
+		UserAgent:     c.Request.UserAgent(),
+		ActorId:       callerID,
+		ActorIsAdmin:  isAdmin,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to record consent", msg)
+		return
+	}
+
+	response.OK(c, http.StatusCreated, "consent recorded", resp.Consent)
+}
+
+func (h *UserHandler) GetConsents(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+	callerID, isAdmin := middleware.CallerID(c), middleware.IsAdmin(c)
+	if callerID == "" && !isAdmin {
+		response.Fail(c, http.StatusUnauthorized, "authentication required", nil)
+		return
+	}
+
+	ctx, cancel := h.client.TimeoutContext()
+	defer cancel()
+
+	resp, err := h.client.Client.GetConsentState(ctx, &userpb.GetConsentStateRequest{
+		UserId:         id,
+		IncludeHistory: c.Query("include_history") == "true",
+		ActorId:        callerID,
+		ActorIsAdmin:   isAdmin,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to get consents", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "consents fetched", resp)
+}
+
+func (h *UserHandler) Merge(c *gin.Context) {
+	var req mergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Fail(c, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	ctx, cancel := h.client.TimeoutContext()
+	defer cancel()
+
+	resp, err := h.client.Client.MergeUsers(ctx, &userpb.MergeUsersRequest{
+		SourceId: req.SourceID,
+		TargetId: req.TargetID,
+		DryRun:   req.DryRun,
+	})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to merge users", msg)
+		return
+	}
+
+	if resp.DryRun {
+		response.OK(c, http.StatusOK, "merge dry run", resp)
+		return
+	}
+	response.OK(c, http.StatusOK, "users merged", resp)
+}
+
+func (h *UserHandler) RevertMerge(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		response.Fail(c, http.StatusBadRequest, "id is required", nil)
+		return
+	}
+
+	ctx, cancel := h.client.TimeoutContext()
+	defer cancel()
+
+	resp, err := h.client.Client.RevertUserMerge(ctx, &userpb.RevertUserMergeRequest{MergeId: id})
+	if err != nil {
+		code, msg := grpcToHTTP(err)
+		response.Fail(c, code, "failed to revert merge", msg)
+		return
+	}
+
+	response.OK(c, http.StatusOK, "merge reverted", resp.Merge)
+}
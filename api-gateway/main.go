@@ -60,13 +60,42 @@ func main() {
 		response.OK(c, http.StatusOK, "ok", gin.H{"service": "api-gateway"})
 	})
 
-	api := r.Group("/api")
+	api := r.Group("/api", middleware.Identity(cfg.AdminAPIKey))
 	api.POST("/register", userHandler.Register)
 	api.POST("/login", userHandler.Login)
 	api.GET("/users/:id", userHandler.GetByID)
 	api.POST("/orders", orderHandler.Create)
 	api.GET("/orders/:id", orderHandler.GetByID)
+	api.PATCH("/orders/:id", orderHandler.Amend)
+	api.POST("/orders/:id/cancel", orderHandler.Cancel)
+	api.POST("/orders/:id/reorder", orderHandler.Reorder)
+	api.GET("/orders/:id/history", orderHandler.History)
+	api.POST("/orders/:id/returns", orderHandler.RequestReturn)
+	api.GET("/orders/:id/returns", orderHandler.ListReturns)
+	api.GET("/orders/:id/shipments", orderHandler.ListShipments)
+	api.GET("/orders/:id/invoice", orderHandler.GetInvoice)
+	api.GET("/orders/:id/credit-notes/:number", orderHandler.GetInvoice)
 	api.GET("/users/:id/orders", orderHandler.GetByUserID)
+	api.POST("/users/:id/consents", userHandler.RecordConsent)
+	api.GET("/users/:id/consents", userHandler.GetConsents)
+
+	admin := api.Group("/admin", middleware.AdminAuth(cfg.AdminAPIKey))
+	admin.POST("/users/merge", userHandler.Merge)
+	admin.POST("/users/merges/:id/revert", userHandler.RevertMerge)
+	admin.GET("/orders", orderHandler.Search)
+	admin.GET("/orders/export", orderHandler.Export)
+	admin.GET("/reports/sales", orderHandler.SalesReport)
+	admin.PATCH("/orders/:id/status", orderHandler.UpdateStatus)
+	admin.PATCH("/returns/:id/status", orderHandler.UpdateReturnStatus)
+	admin.POST("/returns/:id/refund", orderHandler.RefundReturn)
+	admin.GET("/coupons", orderHandler.ListCoupons)
+	admin.POST("/coupons", orderHandler.CreateCoupon)
+	admin.PATCH("/coupons/:code", orderHandler.UpdateCoupon)
+	admin.GET("/users/:id/tax-exemption", orderHandler.GetTaxExemption)
+	admin.PUT("/users/:id/tax-exemption", orderHandler.SetTaxExemption)
+	admin.DELETE("/users/:id/tax-exemption", orderHandler.RemoveTaxExemption)
+	admin.POST("/orders/:id/shipments", orderHandler.CreateShipment)
+	admin.PATCH("/shipments/:id/status", orderHandler.UpdateShipmentStatus)
 
 	srv := &http.Server{
 		Addr:              ":" + cfg.Port,
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"online-store-microservice/pkg/response"
+)
+
+func AdminAuth(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-Admin-Key")
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) != 1 {
+			response.Fail(c, http.StatusForbidden, "admin access required", nil)
+			c.Abort()
+			return
+		}
+		c.Set("is_admin", true)
+		c.Next()
+	}
+}
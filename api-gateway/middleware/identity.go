@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const tokenPrefix = "dummy-token-"
+
+func Identity(adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader("X-Admin-Key"); adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1 {
+			c.Set("is_admin", true)
+		}
+
+		token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if strings.HasPrefix(token, tokenPrefix) {
+			if id, err := uuid.Parse(strings.TrimPrefix(token, tokenPrefix)); err == nil {
+				c.Set("user_id", id.String())
+			}
+		}
+
+		c.Next()
+	}
+}
+
+func CallerID(c *gin.Context) string {
+	return c.GetString("user_id")
+}
+
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool("is_admin")
+}
@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCreateCouponEndpoint(t *testing.T) {
+	body := map[string]any{"code": "SUMMER15", "kind": "percent", "percent_off_bps": 1500, "max_per_user": 1}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/coupons", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+}
+
+func TestCreateCouponEndpointRejectsDuplicateCode(t *testing.T) {
+	body := map[string]any{"code": "SPRING10", "kind": "percent", "percent_off_bps": 1000}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/coupons", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+}
+
+func TestCreateCouponEndpointRequiresAdmin(t *testing.T) {
+	body := map[string]any{"code": "SUMMER15", "kind": "percent", "percent_off_bps": 1500}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/admin/coupons", body)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
@@ -2,13 +2,133 @@ package tests
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 )
 
-func TestCreateOrderEndpoint(t *testing.T) {
+func TestCreateOrderEndpointRejectsSingleItemBody(t *testing.T) {
 	body := map[string]any{"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "product_name": "Laptop", "quantity": 1, "total_price": 15000000}
 	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "no longer accepted") {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointWithItems(t *testing.T) {
+	body := map[string]any{
+		"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		"items": []map[string]any{
+			{"product_id": "laptop-14", "quantity": 1},
+			{"sku": "MS-01-BLK", "unit_price": 199000, "quantity": 2},
+		},
+	}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointRequiresItems(t *testing.T) {
+	body := map[string]any{"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506"}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointRejectsPriceMismatch(t *testing.T) {
+	body := map[string]any{"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "items": []map[string]any{{"product_id": "laptop-14", "quantity": 1}}, "total_price": 1}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointAcceptsMoneyAmounts(t *testing.T) {
+	body := map[string]any{
+		"user_id":               "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		"currency":              "IDR",
+		"items":                 []map[string]any{{"product_id": "laptop-14", "quantity": 1, "expected_unit_amount": map[string]any{"amount": 1500000000, "currency": "IDR"}}},
+		"expected_total_amount": map[string]any{"amount": 1500000000, "currency": "IDR"},
+	}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
 	}
+
+	body["expected_total_amount"] = map[string]any{"amount": 100, "currency": "IDR"}
+	w = doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+
+	body["expected_total_amount"] = map[string]any{"amount": 100}
+	w = doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointIdempotencyKey(t *testing.T) {
+	body := map[string]any{"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "items": []map[string]any{{"product_id": "laptop-14", "quantity": 1}}}
+
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/orders", body, map[string]string{"Idempotency-Key": "retry-1"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+
+	w = doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/orders", body, map[string]string{"Idempotency-Key": "used-with-other-body"})
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusUnprocessableEntity, w.Body.String())
+	}
+
+	w = doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/orders", body, map[string]string{"Idempotency-Key": strings.Repeat("k", 256)})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointRejectsInactiveCoupon(t *testing.T) {
+	body := map[string]any{"user_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "items": []map[string]any{{"product_id": "laptop-14", "quantity": 1}}, "coupon_code": "EXPIRED"}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointWithShippingAddress(t *testing.T) {
+	body := map[string]any{
+		"user_id":          "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		"items":            []map[string]any{{"product_id": "laptop-14", "quantity": 1}},
+		"shipping_address": map[string]any{"name": "Budi", "line1": "Jl. Sudirman 1", "city": "Jakarta", "region": "JK", "country": "ID"},
+	}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointRejectsUntaxableDestination(t *testing.T) {
+	body := map[string]any{
+		"user_id":          "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		"items":            []map[string]any{{"product_id": "laptop-14", "quantity": 1}},
+		"shipping_address": map[string]any{"country": "FR"},
+	}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestCreateOrderEndpointRejectsUnknownShippingMethod(t *testing.T) {
+	body := map[string]any{
+		"user_id":          "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		"items":            []map[string]any{{"product_id": "laptop-14", "quantity": 1}},
+		"shipping_address": map[string]any{"line1": "Jl. Sudirman 1", "city": "Jakarta", "country": "ID"},
+		"shipping_method":  "teleport",
+	}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/orders", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
 }
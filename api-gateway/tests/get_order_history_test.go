@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestGetOrderHistoryEndpoint(t *testing.T) {
+	w := doRequest(setupRouter(), http.MethodGet, "/api/orders/8f328abb-4ae4-493b-a460-a63f1206b2f3/history", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var body struct {
+		Data []struct {
+			ToStatus string `json:"to_status"`
+			Actor    string `json:"actor"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Data) != 2 || body.Data[1].ToStatus != "cancelled" || body.Data[1].Actor != "admin" {
+		t.Fatalf("unexpected history: %+v", body.Data)
+	}
+}
+
+func TestGetOrderHistoryEndpointNotFound(t *testing.T) {
+	w := doRequest(setupRouter(), http.MethodGet, "/api/orders/00000000-0000-0000-0000-000000000000/history", nil)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusNotFound, w.Body.String())
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 )
@@ -11,3 +12,33 @@ func TestGetOrdersByUserIDEndpoint(t *testing.T) {
 		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
 	}
 }
+
+func TestGetOrdersByUserIDEndpointPaginates(t *testing.T) {
+	w := doRequest(setupRouter(), http.MethodGet, "/api/users/4e427d78-58c5-4f78-bfc1-e2c196e0b506/orders?page_size=1&status=pending&sort=-total", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var body struct {
+		Pagination struct {
+			Count         int    `json:"count"`
+			NextPageToken string `json:"next_page_token"`
+			HasMore       bool   `json:"has_more"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Pagination.Count != 1 || body.Pagination.NextPageToken != "next-token" || !body.Pagination.HasMore {
+		t.Fatalf("unexpected pagination: %+v", body.Pagination)
+	}
+}
+
+func TestGetOrdersByUserIDEndpointRejectsInvalidQuery(t *testing.T) {
+	for _, query := range []string{"page_size=-1", "page_size=101", "sort=name", "page_token=bad"} {
+		w := doRequest(setupRouter(), http.MethodGet, "/api/users/4e427d78-58c5-4f78-bfc1-e2c196e0b506/orders?"+query, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d, body=%s", query, w.Code, http.StatusBadRequest, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestListCouponsEndpoint(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodGet, "/api/admin/coupons?include_inactive=true", nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var body struct {
+		Data []map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Data) != 2 {
+		t.Fatalf("coupons = %d, want 2", len(body.Data))
+	}
+}
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMergeUsersEndpoint(t *testing.T) {
+	body := map[string]any{"source_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "target_id": "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f", "dry_run": true}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/users/merge", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestMergeUsersEndpointRequiresAdminKey(t *testing.T) {
+	body := map[string]any{"source_id": "4e427d78-58c5-4f78-bfc1-e2c196e0b506", "target_id": "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f"}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/admin/users/merge", body)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
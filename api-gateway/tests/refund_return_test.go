@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRefundReturnEndpoint(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/refund", nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestRefundReturnEndpointPartialAmount(t *testing.T) {
+	body := map[string]any{"amount": map[string]any{"amount": 500000000, "currency": "IDR"}}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/refund", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"refunded_amount":{"amount":500000000,"currency":"IDR"}`) {
+		t.Fatalf("partial refund not forwarded: %s", w.Body.String())
+	}
+}
+
+func TestRefundReturnEndpointChecksIfMatch(t *testing.T) {
+	path := "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/refund"
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, path, nil, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"3"`})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != `"4"` {
+		t.Fatalf("ETag = %q, want %q", got, `"4"`)
+	}
+
+	w = doRequestWithHeaders(setupRouter(), http.MethodPost, path, nil, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"2"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale: status = %d, want %d, body=%s", w.Code, http.StatusPreconditionFailed, w.Body.String())
+	}
+}
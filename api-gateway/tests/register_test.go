@@ -6,9 +6,17 @@ import (
 )
 
 func TestRegisterEndpoint(t *testing.T) {
-	body := map[string]any{"email": "user@example.com", "password": "secret123", "name": "John Doe"}
+	body := map[string]any{"email": "user@example.com", "password": "secret123", "name": "John Doe", "accepted_terms_version": "2026-01"}
 	w := doRequest(setupRouter(), http.MethodPost, "/api/register", body)
 	if w.Code != http.StatusCreated {
 		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusCreated, w.Body.String())
 	}
 }
+
+func TestRegisterEndpointRequiresTermsAcceptance(t *testing.T) {
+	body := map[string]any{"email": "user@example.com", "password": "secret123", "name": "John Doe"}
+	w := doRequest(setupRouter(), http.MethodPost, "/api/register", body)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
@@ -0,0 +1,13 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRevertUserMergeEndpoint(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodPost, "/api/admin/users/merges/0b7f3c55-1d52-4b0e-9a4c-6a2f3f1e9d11/revert", nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
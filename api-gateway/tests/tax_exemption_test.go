@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+const taxExemptionPath = "/api/admin/users/4e427d78-58c5-4f78-bfc1-e2c196e0b506/tax-exemption"
+
+func TestSetTaxExemptionEndpoint(t *testing.T) {
+	body := map[string]any{"reason": "registered charity", "certificate": "EX-2026-0042"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPut, taxExemptionPath, body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestSetTaxExemptionEndpointRequiresReason(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodPut, taxExemptionPath, map[string]any{}, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestSetTaxExemptionEndpointRequiresAdmin(t *testing.T) {
+	w := doRequest(setupRouter(), http.MethodPut, taxExemptionPath, map[string]any{"reason": "registered charity"})
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
+
+func TestGetTaxExemptionEndpoint(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodGet, taxExemptionPath, nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestRemoveTaxExemptionEndpointNotFound(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodDelete, "/api/admin/users/0b7f3c55-1d52-4b0e-9a4c-6a2f3f1e9d11/tax-exemption", nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusNotFound, w.Body.String())
+	}
+}
@@ -4,24 +4,36 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"online-store-microservice/api-gateway/grpc_clients"
 	"online-store-microservice/api-gateway/handlers"
+	"online-store-microservice/api-gateway/middleware"
 	"online-store-microservice/pkg/response"
 	orderpb "online-store-microservice/proto/order"
 	userpb "online-store-microservice/proto/user"
 )
 
 type fakeUserServiceClient struct {
-	registerFn    func(context.Context, *userpb.RegisterRequest, ...grpc.CallOption) (*userpb.RegisterResponse, error)
-	loginFn       func(context.Context, *userpb.LoginRequest, ...grpc.CallOption) (*userpb.LoginResponse, error)
-	getUserByIDFn func(context.Context, *userpb.GetUserByIdRequest, ...grpc.CallOption) (*userpb.GetUserByIdResponse, error)
+	registerFn      func(context.Context, *userpb.RegisterRequest, ...grpc.CallOption) (*userpb.RegisterResponse, error)
+	loginFn         func(context.Context, *userpb.LoginRequest, ...grpc.CallOption) (*userpb.LoginResponse, error)
+	getUserByIDFn   func(context.Context, *userpb.GetUserByIdRequest, ...grpc.CallOption) (*userpb.GetUserByIdResponse, error)
+	getUserByEmail  func(context.Context, *userpb.GetUserByEmailRequest, ...grpc.CallOption) (*userpb.GetUserByIdResponse, error)
+	mergeUsersFn    func(context.Context, *userpb.MergeUsersRequest, ...grpc.CallOption) (*userpb.MergeUsersResponse, error)
+	revertMergeFn   func(context.Context, *userpb.RevertUserMergeRequest, ...grpc.CallOption) (*userpb.RevertUserMergeResponse, error)
+	recordConsentFn func(context.Context, *userpb.RecordConsentRequest, ...grpc.CallOption) (*userpb.RecordConsentResponse, error)
+	consentStateFn  func(context.Context, *userpb.GetConsentStateRequest, ...grpc.CallOption) (*userpb.GetConsentStateResponse, error)
 }
 
 func (f *fakeUserServiceClient) Register(ctx context.Context, req *userpb.RegisterRequest, opts ...grpc.CallOption) (*userpb.RegisterResponse, error) {
@@ -36,10 +48,54 @@ func (f *fakeUserServiceClient) GetUserById(ctx context.Context, req *userpb.Get
 	return f.getUserByIDFn(ctx, req, opts...)
 }
 
+func (f *fakeUserServiceClient) GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest, opts ...grpc.CallOption) (*userpb.GetUserByIdResponse, error) {
+	return f.getUserByEmail(ctx, req, opts...)
+}
+
+func (f *fakeUserServiceClient) MergeUsers(ctx context.Context, req *userpb.MergeUsersRequest, opts ...grpc.CallOption) (*userpb.MergeUsersResponse, error) {
+	return f.mergeUsersFn(ctx, req, opts...)
+}
+
+func (f *fakeUserServiceClient) RevertUserMerge(ctx context.Context, req *userpb.RevertUserMergeRequest, opts ...grpc.CallOption) (*userpb.RevertUserMergeResponse, error) {
+	return f.revertMergeFn(ctx, req, opts...)
+}
+
+func (f *fakeUserServiceClient) RecordConsent(ctx context.Context, req *userpb.RecordConsentRequest, opts ...grpc.CallOption) (*userpb.RecordConsentResponse, error) {
+	return f.recordConsentFn(ctx, req, opts...)
+}
+
+func (f *fakeUserServiceClient) GetConsentState(ctx context.Context, req *userpb.GetConsentStateRequest, opts ...grpc.CallOption) (*userpb.GetConsentStateResponse, error) {
+	return f.consentStateFn(ctx, req, opts...)
+}
+
 type fakeOrderServiceClient struct {
 	createOrderFn       func(context.Context, *orderpb.CreateOrderRequest, ...grpc.CallOption) (*orderpb.CreateOrderResponse, error)
 	getOrderByIDFn      func(context.Context, *orderpb.GetOrderByIdRequest, ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error)
+	getOrderByNumberFn  func(context.Context, *orderpb.GetOrderByNumberRequest, ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error)
 	getOrdersByUserIDFn func(context.Context, *orderpb.GetOrdersByUserIdRequest, ...grpc.CallOption) (*orderpb.GetOrdersByUserIdResponse, error)
+	reassignOrdersFn    func(context.Context, *orderpb.ReassignOrdersRequest, ...grpc.CallOption) (*orderpb.ReassignOrdersResponse, error)
+	updateStatusFn      func(context.Context, *orderpb.UpdateOrderStatusRequest, ...grpc.CallOption) (*orderpb.UpdateOrderStatusResponse, error)
+	cancelOrderFn       func(context.Context, *orderpb.CancelOrderRequest, ...grpc.CallOption) (*orderpb.CancelOrderResponse, error)
+	orderHistoryFn      func(context.Context, *orderpb.GetOrderHistoryRequest, ...grpc.CallOption) (*orderpb.GetOrderHistoryResponse, error)
+	requestReturnFn     func(context.Context, *orderpb.RequestReturnRequest, ...grpc.CallOption) (*orderpb.ReturnResponse, error)
+	listReturnsFn       func(context.Context, *orderpb.ListOrderReturnsRequest, ...grpc.CallOption) (*orderpb.ListOrderReturnsResponse, error)
+	updateReturnFn      func(context.Context, *orderpb.UpdateReturnStatusRequest, ...grpc.CallOption) (*orderpb.ReturnResponse, error)
+	refundReturnFn      func(context.Context, *orderpb.RefundReturnRequest, ...grpc.CallOption) (*orderpb.ReturnResponse, error)
+	createCouponFn      func(context.Context, *orderpb.CreateCouponRequest, ...grpc.CallOption) (*orderpb.CouponResponse, error)
+	updateCouponFn      func(context.Context, *orderpb.UpdateCouponRequest, ...grpc.CallOption) (*orderpb.CouponResponse, error)
+	listCouponsFn       func(context.Context, *orderpb.ListCouponsRequest, ...grpc.CallOption) (*orderpb.ListCouponsResponse, error)
+	getExemptionFn      func(context.Context, *orderpb.GetTaxExemptionRequest, ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error)
+	setExemptionFn      func(context.Context, *orderpb.SetTaxExemptionRequest, ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error)
+	removeExemptionFn   func(context.Context, *orderpb.RemoveTaxExemptionRequest, ...grpc.CallOption) (*orderpb.RemoveTaxExemptionResponse, error)
+	createShipmentFn    func(context.Context, *orderpb.CreateShipmentRequest, ...grpc.CallOption) (*orderpb.ShipmentResponse, error)
+	listShipmentsFn     func(context.Context, *orderpb.ListOrderShipmentsRequest, ...grpc.CallOption) (*orderpb.ListOrderShipmentsResponse, error)
+	updateShipmentFn    func(context.Context, *orderpb.UpdateShipmentStatusRequest, ...grpc.CallOption) (*orderpb.ShipmentResponse, error)
+	getInvoiceFn        func(context.Context, *orderpb.GetInvoiceRequest, ...grpc.CallOption) (*orderpb.InvoiceResponse, error)
+	reorderFn           func(context.Context, *orderpb.ReorderRequest, ...grpc.CallOption) (*orderpb.ReorderResponse, error)
+	searchOrdersFn      func(context.Context, *orderpb.SearchOrdersRequest, ...grpc.CallOption) (*orderpb.SearchOrdersResponse, error)
+	exportOrdersFn      func(context.Context, *orderpb.ExportOrdersRequest, ...grpc.CallOption) (orderpb.OrderService_ExportOrdersClient, error)
+	salesReportFn       func(context.Context, *orderpb.SalesReportRequest, ...grpc.CallOption) (*orderpb.SalesReportResponse, error)
+	amendOrderFn        func(context.Context, *orderpb.AmendOrderRequest, ...grpc.CallOption) (*orderpb.AmendOrderResponse, error)
 }
 
 func (f *fakeOrderServiceClient) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest, opts ...grpc.CallOption) (*orderpb.CreateOrderResponse, error) {
@@ -50,10 +106,131 @@ func (f *fakeOrderServiceClient) GetOrderById(ctx context.Context, req *orderpb.
 	return f.getOrderByIDFn(ctx, req, opts...)
 }
 
+func (f *fakeOrderServiceClient) GetOrderByNumber(ctx context.Context, req *orderpb.GetOrderByNumberRequest, opts ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error) {
+	return f.getOrderByNumberFn(ctx, req, opts...)
+}
+
 func (f *fakeOrderServiceClient) GetOrdersByUserId(ctx context.Context, req *orderpb.GetOrdersByUserIdRequest, opts ...grpc.CallOption) (*orderpb.GetOrdersByUserIdResponse, error) {
 	return f.getOrdersByUserIDFn(ctx, req, opts...)
 }
 
+func (f *fakeOrderServiceClient) ReassignOrders(ctx context.Context, req *orderpb.ReassignOrdersRequest, opts ...grpc.CallOption) (*orderpb.ReassignOrdersResponse, error) {
+	return f.reassignOrdersFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest, opts ...grpc.CallOption) (*orderpb.UpdateOrderStatusResponse, error) {
+	return f.updateStatusFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest, opts ...grpc.CallOption) (*orderpb.CancelOrderResponse, error) {
+	return f.cancelOrderFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) GetOrderHistory(ctx context.Context, req *orderpb.GetOrderHistoryRequest, opts ...grpc.CallOption) (*orderpb.GetOrderHistoryResponse, error) {
+	return f.orderHistoryFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) RequestReturn(ctx context.Context, req *orderpb.RequestReturnRequest, opts ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+	return f.requestReturnFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) ListOrderReturns(ctx context.Context, req *orderpb.ListOrderReturnsRequest, opts ...grpc.CallOption) (*orderpb.ListOrderReturnsResponse, error) {
+	return f.listReturnsFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) UpdateReturnStatus(ctx context.Context, req *orderpb.UpdateReturnStatusRequest, opts ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+	return f.updateReturnFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) RefundReturn(ctx context.Context, req *orderpb.RefundReturnRequest, opts ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+	return f.refundReturnFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) CreateCoupon(ctx context.Context, req *orderpb.CreateCouponRequest, opts ...grpc.CallOption) (*orderpb.CouponResponse, error) {
+	return f.createCouponFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) UpdateCoupon(ctx context.Context, req *orderpb.UpdateCouponRequest, opts ...grpc.CallOption) (*orderpb.CouponResponse, error) {
+	return f.updateCouponFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) ListCoupons(ctx context.Context, req *orderpb.ListCouponsRequest, opts ...grpc.CallOption) (*orderpb.ListCouponsResponse, error) {
+	return f.listCouponsFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) GetTaxExemption(ctx context.Context, req *orderpb.GetTaxExemptionRequest, opts ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error) {
+	return f.getExemptionFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) SetTaxExemption(ctx context.Context, req *orderpb.SetTaxExemptionRequest, opts ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error) {
+	return f.setExemptionFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) RemoveTaxExemption(ctx context.Context, req *orderpb.RemoveTaxExemptionRequest, opts ...grpc.CallOption) (*orderpb.RemoveTaxExemptionResponse, error) {
+	return f.removeExemptionFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) CreateShipment(ctx context.Context, req *orderpb.CreateShipmentRequest, opts ...grpc.CallOption) (*orderpb.ShipmentResponse, error) {
+	return f.createShipmentFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) ListOrderShipments(ctx context.Context, req *orderpb.ListOrderShipmentsRequest, opts ...grpc.CallOption) (*orderpb.ListOrderShipmentsResponse, error) {
+	return f.listShipmentsFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) UpdateShipmentStatus(ctx context.Context, req *orderpb.UpdateShipmentStatusRequest, opts ...grpc.CallOption) (*orderpb.ShipmentResponse, error) {
+	return f.updateShipmentFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) GetInvoice(ctx context.Context, req *orderpb.GetInvoiceRequest, opts ...grpc.CallOption) (*orderpb.InvoiceResponse, error) {
+	return f.getInvoiceFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) Reorder(ctx context.Context, req *orderpb.ReorderRequest, opts ...grpc.CallOption) (*orderpb.ReorderResponse, error) {
+	return f.reorderFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) SearchOrders(ctx context.Context, req *orderpb.SearchOrdersRequest, opts ...grpc.CallOption) (*orderpb.SearchOrdersResponse, error) {
+	return f.searchOrdersFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) ExportOrders(ctx context.Context, req *orderpb.ExportOrdersRequest, opts ...grpc.CallOption) (orderpb.OrderService_ExportOrdersClient, error) {
+	return f.exportOrdersFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) GetSalesReport(ctx context.Context, req *orderpb.SalesReportRequest, opts ...grpc.CallOption) (*orderpb.SalesReportResponse, error) {
+	return f.salesReportFn(ctx, req, opts...)
+}
+
+func (f *fakeOrderServiceClient) AmendOrder(ctx context.Context, req *orderpb.AmendOrderRequest, opts ...grpc.CallOption) (*orderpb.AmendOrderResponse, error) {
+	return f.amendOrderFn(ctx, req, opts...)
+}
+
+// fakeExportStream returns chunks, then err, or io.EOF when err is nil.
+type fakeExportStream struct {
+	grpc.ClientStream
+	chunks []string
+	err    error
+}
+
+func (s *fakeExportStream) Recv() (*orderpb.ExportOrdersChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return &orderpb.ExportOrdersChunk{Data: []byte(chunk)}, nil
+}
+
+func (f *fakeOrderServiceClient) ImportOrders(context.Context, ...grpc.CallOption) (orderpb.OrderService_ImportOrdersClient, error) {
+	return nil, status.Error(codes.Unimplemented, "imports are not served through the gateway")
+}
+
+const testAdminKey = "test-admin-key"
+
 func setupRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -68,17 +245,349 @@ func setupRouter() *gin.Engine {
 		getUserByIDFn: func(context.Context, *userpb.GetUserByIdRequest, ...grpc.CallOption) (*userpb.GetUserByIdResponse, error) {
 			return &userpb.GetUserByIdResponse{User: &userpb.UserData{Id: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Email: "user@example.com", Name: "John Doe", CreatedAt: now, UpdatedAt: now}}, nil
 		},
+		mergeUsersFn: func(_ context.Context, req *userpb.MergeUsersRequest, _ ...grpc.CallOption) (*userpb.MergeUsersResponse, error) {
+			return &userpb.MergeUsersResponse{DryRun: req.DryRun, Merge: &userpb.UserMergeData{Id: "0b7f3c55-1d52-4b0e-9a4c-6a2f3f1e9d11", SourceUserId: req.SourceId, TargetUserId: req.TargetId, MovedOrderIds: []string{"8f328abb-4ae4-493b-a460-a63f1206b2f3"}, Status: "completed", CreatedAt: now}, Changes: []string{"move 1 order(s)"}}, nil
+		},
+		revertMergeFn: func(_ context.Context, req *userpb.RevertUserMergeRequest, _ ...grpc.CallOption) (*userpb.RevertUserMergeResponse, error) {
+			return &userpb.RevertUserMergeResponse{Merge: &userpb.UserMergeData{Id: req.MergeId, Status: "reverted", CreatedAt: now, RevertedAt: now}}, nil
+		},
+		recordConsentFn: func(_ context.Context, req *userpb.RecordConsentRequest, _ ...grpc.CallOption) (*userpb.RecordConsentResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != req.UserId {
+				return nil, status.Error(codes.PermissionDenied, "only the user or an admin may do this")
+			}
+			return &userpb.RecordConsentResponse{Consent: &userpb.ConsentRecordData{Id: "5a0c1f2e-3b4d-4e5f-8a6b-7c8d9e0f1a2b", UserId: req.UserId, Purpose: req.Purpose, Granted: req.Granted, PolicyVersion: req.PolicyVersion, Source: "api", RecordedAt: now}}, nil
+		},
+		consentStateFn: func(_ context.Context, req *userpb.GetConsentStateRequest, _ ...grpc.CallOption) (*userpb.GetConsentStateResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != req.UserId {
+				return nil, status.Error(codes.PermissionDenied, "only the user or an admin may do this")
+			}
+			return &userpb.GetConsentStateResponse{CurrentTermsVersion: "2026-01", Current: []*userpb.ConsentRecordData{{Id: "5a0c1f2e-3b4d-4e5f-8a6b-7c8d9e0f1a2b", UserId: req.UserId, Purpose: "terms_of_service", Granted: true, PolicyVersion: "2026-01", Source: "register", RecordedAt: now}}}, nil
+		},
 	}
 
 	fakeOrder := &fakeOrderServiceClient{
-		createOrderFn: func(context.Context, *orderpb.CreateOrderRequest, ...grpc.CallOption) (*orderpb.CreateOrderResponse, error) {
+		createOrderFn: func(ctx context.Context, req *orderpb.CreateOrderRequest, _ ...grpc.CallOption) (*orderpb.CreateOrderResponse, error) {
+			if req.TotalPrice > 0 && req.TotalPrice != 15000000 {
+				return nil, status.Error(codes.FailedPrecondition, "price mismatch: order total is 15000000.00, client expected 1.00")
+			}
+			if md, _ := metadata.FromOutgoingContext(ctx); len(md.Get(orderpb.IdempotencyKeyMetadata)) > 0 && md.Get(orderpb.IdempotencyKeyMetadata)[0] == "used-with-other-body" {
+				st, _ := status.New(codes.InvalidArgument, "idempotency key was already used with a different request").WithDetails(&errdetails.ErrorInfo{Reason: orderpb.ReasonIdempotencyKeyReused})
+				return nil, st.Err()
+			}
+			if req.ShippingAddress != nil && req.ShippingAddress.Country == "FR" {
+				return nil, status.Error(codes.InvalidArgument, "no tax rate for destination: FR")
+			}
+			if req.ShippingMethod != "" && req.ShippingMethod != "regular" {
+				return nil, status.Error(codes.InvalidArgument, "unknown shipping_method: "+req.ShippingMethod)
+			}
+			if req.CouponCode == "EXPIRED" {
+				return nil, status.Error(codes.FailedPrecondition, "coupon is not active: EXPIRED ended at 2026-01-01T00:00:00Z")
+			}
+			if req.ExpectedTotalAmount != nil && req.ExpectedTotalAmount.Amount != 1500000000 {
+				return nil, status.Error(codes.FailedPrecondition, "price mismatch: order total is 15000000.00 IDR, client expected 1.00 IDR")
+			}
 			return &orderpb.CreateOrderResponse{Order: &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now}}, nil
 		},
-		getOrderByIDFn: func(context.Context, *orderpb.GetOrderByIdRequest, ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error) {
-			return &orderpb.GetOrderByIdResponse{Order: &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now}}, nil
+		getOrderByIDFn: func(_ context.Context, req *orderpb.GetOrderByIdRequest, _ ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error) {
+			order := &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", OrderNumber: "ORD-2026-000123", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now, Version: 3}
+			if req.IncludeHistory {
+				order.History = []*orderpb.OrderStatusChangeData{{Id: "e2b7c7de-5f0e-4d8a-9b57-3d1f0c6a2b10", ToStatus: "pending", Actor: order.UserId, CreatedAt: now}}
+			}
+			return &orderpb.GetOrderByIdResponse{Order: order}, nil
+		},
+		getOrderByNumberFn: func(_ context.Context, req *orderpb.GetOrderByNumberRequest, _ ...grpc.CallOption) (*orderpb.GetOrderByIdResponse, error) {
+			if !strings.EqualFold(req.OrderNumber, "ORD-2026-000123") {
+				return nil, status.Error(codes.NotFound, "order not found")
+			}
+			order := &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", OrderNumber: "ORD-2026-000123", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now, Version: 3}
+			if !req.ActorIsAdmin && req.ActorId != order.UserId {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			return &orderpb.GetOrderByIdResponse{Order: order}, nil
+		},
+		orderHistoryFn: func(_ context.Context, req *orderpb.GetOrderHistoryRequest, _ ...grpc.CallOption) (*orderpb.GetOrderHistoryResponse, error) {
+			if req.OrderId != "8f328abb-4ae4-493b-a460-a63f1206b2f3" {
+				return nil, status.Error(codes.NotFound, "order not found")
+			}
+			return &orderpb.GetOrderHistoryResponse{History: []*orderpb.OrderStatusChangeData{
+				{Id: "e2b7c7de-5f0e-4d8a-9b57-3d1f0c6a2b10", ToStatus: "pending", Actor: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", CreatedAt: now},
+				{Id: "7c0f3a9e-1d2b-4c5e-8f6a-9b0c1d2e3f4a", FromStatus: "pending", ToStatus: "cancelled", Actor: "admin", Reason: "fraud_suspected", RequestId: "req-1", CreatedAt: now},
+			}}, nil
+		},
+		getOrdersByUserIDFn: func(_ context.Context, req *orderpb.GetOrdersByUserIdRequest, _ ...grpc.CallOption) (*orderpb.GetOrdersByUserIdResponse, error) {
+			if req.PageToken == "bad" {
+				return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+			}
+			resp := &orderpb.GetOrdersByUserIdResponse{Orders: []*orderpb.OrderData{{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now}}}
+			if req.PageSize == 1 {
+				resp.NextPageToken = "next-token"
+			}
+			return resp, nil
 		},
-		getOrdersByUserIDFn: func(context.Context, *orderpb.GetOrdersByUserIdRequest, ...grpc.CallOption) (*orderpb.GetOrdersByUserIdResponse, error) {
-			return &orderpb.GetOrdersByUserIdResponse{Orders: []*orderpb.OrderData{{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "pending", CreatedAt: now, UpdatedAt: now}}}, nil
+		updateStatusFn: func(_ context.Context, req *orderpb.UpdateOrderStatusRequest, _ ...grpc.CallOption) (*orderpb.UpdateOrderStatusResponse, error) {
+			if req.Status == "delivered" {
+				return nil, status.Error(codes.FailedPrecondition, "illegal order status transition: pending -> delivered")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			return &orderpb.UpdateOrderStatusResponse{Order: &orderpb.OrderData{Id: req.Id, UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: req.Status, CreatedAt: now, UpdatedAt: now, Version: 4}}, nil
+		},
+		cancelOrderFn: func(_ context.Context, req *orderpb.CancelOrderRequest, _ ...grpc.CallOption) (*orderpb.CancelOrderResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			return &orderpb.CancelOrderResponse{Order: &orderpb.OrderData{Id: req.Id, UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Quantity: 1, TotalPrice: 15000000, Status: "cancelled", CancellationReason: req.ReasonCode, CancelledBy: req.ActorId, CreatedAt: now, UpdatedAt: now, CancelledAt: now, Version: 4}}, nil
+		},
+		requestReturnFn: func(_ context.Context, req *orderpb.RequestReturnRequest, _ ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			ret := &orderpb.ReturnData{Id: "3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33", OrderId: req.OrderId, UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Status: "requested", ReasonCode: req.ReasonCode, RequestedAmount: &orderpb.Money{Amount: 1500000000, Currency: "IDR"}, RefundedAmount: &orderpb.Money{Currency: "IDR"}, RequestedBy: req.ActorId, CreatedAt: now, UpdatedAt: now}
+			return &orderpb.ReturnResponse{Return: ret}, nil
+		},
+		listReturnsFn: func(_ context.Context, req *orderpb.ListOrderReturnsRequest, _ ...grpc.CallOption) (*orderpb.ListOrderReturnsResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			return &orderpb.ListOrderReturnsResponse{
+				Returns: []*orderpb.ReturnData{{Id: "3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33", OrderId: req.OrderId, Status: "refunded", RefundedAmount: &orderpb.Money{Amount: 500000000, Currency: "IDR"}, CreatedAt: now, UpdatedAt: now}},
+				Order:   &orderpb.OrderData{Id: req.OrderId, RefundedAmount: &orderpb.Money{Amount: 500000000, Currency: "IDR"}, NetTotalAmount: &orderpb.Money{Amount: 1000000000, Currency: "IDR"}},
+			}, nil
+		},
+		updateReturnFn: func(_ context.Context, req *orderpb.UpdateReturnStatusRequest, _ ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+			if req.Status == "refunded" {
+				return nil, status.Error(codes.InvalidArgument, "invalid return status")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			return &orderpb.ReturnResponse{
+				Return: &orderpb.ReturnData{Id: req.Id, Status: req.Status, DecidedBy: req.ActorId, DecisionNote: req.Note, CreatedAt: now, UpdatedAt: now},
+				Order:  &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", Version: 3},
+			}, nil
+		},
+		refundReturnFn: func(_ context.Context, req *orderpb.RefundReturnRequest, _ ...grpc.CallOption) (*orderpb.ReturnResponse, error) {
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			refunded := &orderpb.Money{Amount: 1500000000, Currency: "IDR"}
+			if req.Amount != nil {
+				refunded = req.Amount
+			}
+			return &orderpb.ReturnResponse{
+				Return: &orderpb.ReturnData{Id: req.Id, Status: "refunded", RefundedAmount: refunded, RefundReference: "fake-1", CreatedAt: now, UpdatedAt: now},
+				Order:  &orderpb.OrderData{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", RefundedAmount: refunded, Version: 4},
+			}, nil
+		},
+		createCouponFn: func(_ context.Context, req *orderpb.CreateCouponRequest, _ ...grpc.CallOption) (*orderpb.CouponResponse, error) {
+			if req.Coupon.Code == "SPRING10" {
+				return nil, status.Error(codes.AlreadyExists, "coupon code already exists")
+			}
+			coupon := *req.Coupon
+			coupon.CreatedAt, coupon.UpdatedAt = now, now
+			return &orderpb.CouponResponse{Coupon: &coupon}, nil
+		},
+		updateCouponFn: func(_ context.Context, req *orderpb.UpdateCouponRequest, _ ...grpc.CallOption) (*orderpb.CouponResponse, error) {
+			if req.Coupon.Code != "SPRING10" {
+				return nil, status.Error(codes.NotFound, "coupon not found")
+			}
+			coupon := *req.Coupon
+			coupon.Redemptions, coupon.CreatedAt, coupon.UpdatedAt = 4, now, now
+			return &orderpb.CouponResponse{Coupon: &coupon}, nil
+		},
+		listCouponsFn: func(_ context.Context, req *orderpb.ListCouponsRequest, _ ...grpc.CallOption) (*orderpb.ListCouponsResponse, error) {
+			coupons := []*orderpb.CouponData{{Code: "SPRING10", Kind: "percent", PercentOffBps: 1000, Active: true, CreatedAt: now, UpdatedAt: now}}
+			if req.IncludeInactive {
+				coupons = append(coupons, &orderpb.CouponData{Code: "WINTER5", Kind: "fixed", AmountOff: &orderpb.Money{Amount: 500000, Currency: "IDR"}, CreatedAt: now, UpdatedAt: now})
+			}
+			return &orderpb.ListCouponsResponse{Coupons: coupons}, nil
+		},
+		getExemptionFn: func(_ context.Context, req *orderpb.GetTaxExemptionRequest, _ ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error) {
+			if req.UserId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.NotFound, "tax exemption not found")
+			}
+			return &orderpb.TaxExemptionResponse{Exemption: &orderpb.TaxExemptionData{UserId: req.UserId, Reason: "registered charity", CreatedBy: "admin", CreatedAt: now, UpdatedAt: now}}, nil
+		},
+		setExemptionFn: func(_ context.Context, req *orderpb.SetTaxExemptionRequest, _ ...grpc.CallOption) (*orderpb.TaxExemptionResponse, error) {
+			return &orderpb.TaxExemptionResponse{Exemption: &orderpb.TaxExemptionData{UserId: req.UserId, Reason: req.Reason, Certificate: req.Certificate, CreatedBy: req.ActorId, CreatedAt: now, UpdatedAt: now}}, nil
+		},
+		removeExemptionFn: func(_ context.Context, req *orderpb.RemoveTaxExemptionRequest, _ ...grpc.CallOption) (*orderpb.RemoveTaxExemptionResponse, error) {
+			if req.UserId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.NotFound, "tax exemption not found")
+			}
+			return &orderpb.RemoveTaxExemptionResponse{}, nil
+		},
+		createShipmentFn: func(_ context.Context, req *orderpb.CreateShipmentRequest, _ ...grpc.CallOption) (*orderpb.ShipmentResponse, error) {
+			if req.OrderId != "8f328abb-4ae4-493b-a460-a63f1206b2f3" {
+				return nil, status.Error(codes.FailedPrecondition, "only paid orders can be shipped: order is pending")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			shipment := &orderpb.ShipmentData{Id: "b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01", OrderId: req.OrderId, Carrier: "fake", TrackingNumber: "FAKE0000000042", Status: "pending", CreatedBy: req.ActorId, CreatedAt: now, UpdatedAt: now}
+			for _, item := range req.Items {
+				shipment.Items = append(shipment.Items, &orderpb.ShipmentItemData{OrderItemId: item.OrderItemId, Quantity: item.Quantity})
+			}
+			orderStatus := "shipped"
+			if len(req.Items) > 0 {
+				orderStatus = "fulfilling"
+			}
+			return &orderpb.ShipmentResponse{Shipment: shipment, Order: &orderpb.OrderData{Id: req.OrderId, Status: orderStatus, Version: 4}}, nil
+		},
+		listShipmentsFn: func(_ context.Context, req *orderpb.ListOrderShipmentsRequest, _ ...grpc.CallOption) (*orderpb.ListOrderShipmentsResponse, error) {
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			return &orderpb.ListOrderShipmentsResponse{
+				Shipments: []*orderpb.ShipmentData{{Id: "b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01", OrderId: req.OrderId, Carrier: "fake", TrackingNumber: "FAKE0000000042", Status: "in_transit", CreatedAt: now, UpdatedAt: now}},
+				Order:     &orderpb.OrderData{Id: req.OrderId, Status: "shipped", ShippingMethod: "regular"},
+			}, nil
+		},
+		updateShipmentFn: func(_ context.Context, req *orderpb.UpdateShipmentStatusRequest, _ ...grpc.CallOption) (*orderpb.ShipmentResponse, error) {
+			to := req.Status
+			if to == "" {
+				to = "in_transit"
+			}
+			if to == "pending" {
+				return nil, status.Error(codes.InvalidArgument, "invalid shipment status")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 3 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version: order is at version 3")
+			}
+			return &orderpb.ShipmentResponse{Shipment: &orderpb.ShipmentData{Id: req.Id, Status: to, CreatedAt: now, UpdatedAt: now}, Order: &orderpb.OrderData{Status: "shipped", Version: 4}}, nil
+		},
+		getInvoiceFn: func(_ context.Context, req *orderpb.GetInvoiceRequest, _ ...grpc.CallOption) (*orderpb.InvoiceResponse, error) {
+			if req.OrderId != "8f328abb-4ae4-493b-a460-a63f1206b2f3" {
+				return nil, status.Error(codes.FailedPrecondition, "order has not been paid, so it has no invoice")
+			}
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			invoice := &orderpb.InvoiceData{Id: "5c0d9e2a-7b41-4f3e-8d6a-2e9b1c4f7a30", Kind: "invoice", Number: "INV-2026-000042", OrderId: req.OrderId, IssuedAt: now, TotalAmount: &orderpb.Money{Amount: 1650000000, Currency: "IDR"}}
+			creditNote := &orderpb.InvoiceData{Id: "9a3f6b1c-2d4e-4f5a-8b7c-0e1d2f3a4b5c", Kind: "credit_note", Number: "CN-2026-000007", OrderId: req.OrderId, InvoiceNumber: invoice.Number, IssuedAt: now}
+			resp := &orderpb.InvoiceResponse{Invoice: invoice, CreditNotes: []*orderpb.InvoiceData{creditNote}}
+			switch req.Number {
+			case "":
+			case creditNote.Number:
+				resp.Invoice = creditNote
+			default:
+				return nil, status.Error(codes.NotFound, "invoice not found")
+			}
+			switch req.Format {
+			case "html":
+				resp.ContentType, resp.Content = "text/html; charset=utf-8", []byte("<h1>Invoice "+resp.Invoice.Number+"</h1>")
+			case "pdf":
+				resp.ContentType, resp.Content = "application/pdf", []byte("%PDF-1.4\n%%EOF\n")
+			case "json":
+			default:
+				return nil, status.Error(codes.InvalidArgument, "invalid invoice format")
+			}
+			return resp, nil
+		},
+		reorderFn: func(_ context.Context, req *orderpb.ReorderRequest, _ ...grpc.CallOption) (*orderpb.ReorderResponse, error) {
+			if req.OrderId != "8f328abb-4ae4-493b-a460-a63f1206b2f3" {
+				return nil, status.Error(codes.NotFound, "order not found")
+			}
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			order := &orderpb.OrderData{Id: "0b7d4c1e-5a2f-4e8b-9c3d-6f1a2b3c4d5e", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Status: "pending", Version: 1, CreatedAt: now, UpdatedAt: now}
+			if req.Preview {
+				order.Id, order.Version = "", 0
+			}
+			return &orderpb.ReorderResponse{
+				Order:   order,
+				Preview: req.Preview,
+				UnavailableItems: []*orderpb.ReorderItemData{
+					{OrderItemId: "e5f6a7b8-c9d0-4e1f-a2b3-c4d5e6f7a8b9", ProductId: "cable", Name: "Cable", Quantity: 3, PreviousUnitAmount: &orderpb.Money{Amount: 5000000, Currency: "IDR"}},
+				},
+				PriceChanges: []*orderpb.ReorderItemData{
+					{OrderItemId: "a1b2c3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", ProductId: "laptop-14", Name: "Laptop", Quantity: 1, PreviousUnitAmount: &orderpb.Money{Amount: 1500000000, Currency: "IDR"}, UnitAmount: &orderpb.Money{Amount: 1400000000, Currency: "IDR"}},
+				},
+			}, nil
+		},
+		searchOrdersFn: func(_ context.Context, req *orderpb.SearchOrdersRequest, _ ...grpc.CallOption) (*orderpb.SearchOrdersResponse, error) {
+			for _, s := range req.Statuses {
+				if s != "paid" && s != "shipped" {
+					return nil, status.Error(codes.InvalidArgument, "invalid order status")
+				}
+			}
+			if req.CustomerEmail != "" && req.CustomerEmail != "user@example.com" {
+				return &orderpb.SearchOrdersResponse{Orders: []*orderpb.OrderData{}}, nil
+			}
+			orders := []*orderpb.OrderData{
+				{Id: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", ProductName: "Laptop", Status: "paid", CreatedAt: now, UpdatedAt: now},
+				{Id: "2d7c9e41-0b3a-4f6e-8a5d-1c2b3a4d5e6f", UserId: "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f", ProductName: "Laptop sleeve", Status: "shipped", CreatedAt: now, UpdatedAt: now},
+			}
+			if req.PageSize == 1 {
+				return &orderpb.SearchOrdersResponse{Orders: orders[:1], NextPageToken: "next-page"}, nil
+			}
+			return &orderpb.SearchOrdersResponse{Orders: orders}, nil
+		},
+		exportOrdersFn: func(_ context.Context, req *orderpb.ExportOrdersRequest, _ ...grpc.CallOption) (orderpb.OrderService_ExportOrdersClient, error) {
+			for _, col := range req.Columns {
+				if col != "id" && col != "status" {
+					return &fakeExportStream{err: status.Error(codes.InvalidArgument, "invalid export: unknown column")}, nil
+				}
+			}
+			if req.Filter.ProductName == "broken" {
+				return &fakeExportStream{chunks: []string{"id,status\n"}, err: status.Error(codes.Internal, "internal server error")}, nil
+			}
+			if req.Format == "ndjson" {
+				return &fakeExportStream{chunks: []string{`{"id":"8f328abb-4ae4-493b-a460-a63f1206b2f3","status":"paid"}` + "\n"}}, nil
+			}
+			return &fakeExportStream{chunks: []string{"id,status\n", "8f328abb-4ae4-493b-a460-a63f1206b2f3,paid\n"}}, nil
+		},
+		salesReportFn: func(_ context.Context, req *orderpb.SalesReportRequest, _ ...grpc.CallOption) (*orderpb.SalesReportResponse, error) {
+			if req.CreatedFrom != "" && req.CreatedFrom != "2026-10-01T00:00:00Z" {
+				return nil, status.Error(codes.InvalidArgument, "invalid filter")
+			}
+			return &orderpb.SalesReportResponse{
+				BaseCurrency:   "IDR",
+				Orders:         2,
+				TotalAmount:    &orderpb.Money{Amount: 3625000000, Currency: "IDR"},
+				RefundedAmount: &orderpb.Money{Amount: 0, Currency: "IDR"},
+				NetAmount:      &orderpb.Money{Amount: 3625000000, Currency: "IDR"},
+				Currencies: []*orderpb.CurrencySales{
+					{Currency: "IDR", Orders: 1, TotalAmount: &orderpb.Money{Amount: 2000000000, Currency: "IDR"}, RefundedAmount: &orderpb.Money{Currency: "IDR"}, BaseTotalAmount: &orderpb.Money{Amount: 2000000000, Currency: "IDR"}, BaseRefundedAmount: &orderpb.Money{Currency: "IDR"}},
+					{Currency: "USD", Orders: 1, TotalAmount: &orderpb.Money{Amount: 100000, Currency: "USD"}, RefundedAmount: &orderpb.Money{Currency: "USD"}, BaseTotalAmount: &orderpb.Money{Amount: 1625000000, Currency: "IDR"}, BaseRefundedAmount: &orderpb.Money{Currency: "IDR"}},
+				},
+			}, nil
+		},
+		amendOrderFn: func(_ context.Context, req *orderpb.AmendOrderRequest, _ ...grpc.CallOption) (*orderpb.AmendOrderResponse, error) {
+			switch req.Id {
+			case "8f328abb-4ae4-493b-a460-a63f1206b2f3":
+			case "2d7c9e41-0b3a-4f6e-8a5d-1c2b3a4d5e6f":
+				return nil, status.Error(codes.FailedPrecondition, "order can no longer be amended: order is fulfilling")
+			default:
+				return nil, status.Error(codes.NotFound, "order not found")
+			}
+			if !req.ActorIsAdmin && req.ActorId != "4e427d78-58c5-4f78-bfc1-e2c196e0b506" {
+				return nil, status.Error(codes.PermissionDenied, "not allowed to modify this order")
+			}
+			if req.ExpectedVersion != 0 && req.ExpectedVersion != 2 {
+				return nil, status.Error(codes.Aborted, "order version does not match expected_version")
+			}
+			version := int64(3)
+			if req.Preview {
+				version = 2
+			}
+			return &orderpb.AmendOrderResponse{
+				Order:   &orderpb.OrderData{Id: req.Id, UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Status: "paid", Version: version, CreatedAt: now, UpdatedAt: now},
+				Preview: req.Preview,
+				Changes: []*orderpb.OrderChangeData{
+					{Field: "item:laptop-14", From: "1", To: "2"},
+					{Field: "total_amount", From: "15000000.00 IDR", To: "30000000.00 IDR"},
+				},
+				PreviousTotalAmount: &orderpb.Money{Amount: 1500000000, Currency: "IDR"},
+				DeltaAmount:         &orderpb.Money{Amount: 1500000000, Currency: "IDR"},
+				ChargeAmount:        &orderpb.Money{Amount: 1500000000, Currency: "IDR"},
+				RefundAmount:        &orderpb.Money{Currency: "IDR"},
+			}, nil
 		},
 	}
 
@@ -88,18 +597,51 @@ func setupRouter() *gin.Engine {
 	r := gin.New()
 	r.GET("/health", func(c *gin.Context) { response.OK(c, http.StatusOK, "ok", gin.H{"service": "api-gateway"}) })
 
-	api := r.Group("/api")
+	api := r.Group("/api", middleware.Identity(testAdminKey))
 	api.POST("/register", userHandler.Register)
 	api.POST("/login", userHandler.Login)
 	api.GET("/users/:id", userHandler.GetByID)
 	api.POST("/orders", orderHandler.Create)
 	api.GET("/orders/:id", orderHandler.GetByID)
+	api.PATCH("/orders/:id", orderHandler.Amend)
+	api.POST("/orders/:id/cancel", orderHandler.Cancel)
+	api.POST("/orders/:id/reorder", orderHandler.Reorder)
+	api.GET("/orders/:id/history", orderHandler.History)
+	api.POST("/orders/:id/returns", orderHandler.RequestReturn)
+	api.GET("/orders/:id/returns", orderHandler.ListReturns)
+	api.GET("/orders/:id/shipments", orderHandler.ListShipments)
+	api.GET("/orders/:id/invoice", orderHandler.GetInvoice)
+	api.GET("/orders/:id/credit-notes/:number", orderHandler.GetInvoice)
 	api.GET("/users/:id/orders", orderHandler.GetByUserID)
+	api.POST("/users/:id/consents", userHandler.RecordConsent)
+	api.GET("/users/:id/consents", userHandler.GetConsents)
+
+	admin := api.Group("/admin", middleware.AdminAuth(testAdminKey))
+	admin.POST("/users/merge", userHandler.Merge)
+	admin.POST("/users/merges/:id/revert", userHandler.RevertMerge)
+	admin.GET("/orders", orderHandler.Search)
+	admin.GET("/orders/export", orderHandler.Export)
+	admin.GET("/reports/sales", orderHandler.SalesReport)
+	admin.PATCH("/orders/:id/status", orderHandler.UpdateStatus)
+	admin.PATCH("/returns/:id/status", orderHandler.UpdateReturnStatus)
+	admin.POST("/returns/:id/refund", orderHandler.RefundReturn)
+	admin.GET("/coupons", orderHandler.ListCoupons)
+	admin.POST("/coupons", orderHandler.CreateCoupon)
+	admin.PATCH("/coupons/:code", orderHandler.UpdateCoupon)
+	admin.GET("/users/:id/tax-exemption", orderHandler.GetTaxExemption)
+	admin.PUT("/users/:id/tax-exemption", orderHandler.SetTaxExemption)
+	admin.DELETE("/users/:id/tax-exemption", orderHandler.RemoveTaxExemption)
+	admin.POST("/orders/:id/shipments", orderHandler.CreateShipment)
+	admin.PATCH("/shipments/:id/status", orderHandler.UpdateShipmentStatus)
 
 	return r
 }
 
 func doRequest(r *gin.Engine, method, path string, body any) *httptest.ResponseRecorder {
+	return doRequestWithHeaders(r, method, path, body, nil)
+}
+
+func doRequestWithHeaders(r *gin.Engine, method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
 	var payload []byte
 	if body != nil {
 		payload, _ = json.Marshal(body)
@@ -108,6 +650,9 @@ func doRequest(r *gin.Engine, method, path string, body any) *httptest.ResponseR
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 	return w
@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpdateCouponEndpoint(t *testing.T) {
+	body := map[string]any{"kind": "percent", "percent_off_bps": 1000, "active": false}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/coupons/SPRING10", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestUpdateCouponEndpointNotFound(t *testing.T) {
+	body := map[string]any{"kind": "percent", "percent_off_bps": 1000}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/coupons/NOPE", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusNotFound, w.Body.String())
+	}
+}
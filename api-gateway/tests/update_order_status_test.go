@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpdateOrderStatusEndpoint(t *testing.T) {
+	body := map[string]any{"status": "awaiting_payment"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/orders/8f328abb-4ae4-493b-a460-a63f1206b2f3/status", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestUpdateOrderStatusEndpointRejectsIllegalTransition(t *testing.T) {
+	body := map[string]any{"status": "delivered"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/orders/8f328abb-4ae4-493b-a460-a63f1206b2f3/status", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusConflict, w.Body.String())
+	}
+}
+
+func TestUpdateOrderStatusEndpointHonorsIfMatch(t *testing.T) {
+	body := map[string]any{"status": "awaiting_payment"}
+	path := "/api/admin/orders/8f328abb-4ae4-493b-a460-a63f1206b2f3/status"
+
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, path, body, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"3"`})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get("ETag"); got != `"4"` {
+		t.Fatalf("ETag = %q, want %q", got, `"4"`)
+	}
+
+	w = doRequestWithHeaders(setupRouter(), http.MethodPatch, path, body, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"2"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusPreconditionFailed, w.Body.String())
+	}
+
+	w = doRequestWithHeaders(setupRouter(), http.MethodPatch, path, body, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": "three"})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
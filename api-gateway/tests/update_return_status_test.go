@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpdateReturnStatusEndpoint(t *testing.T) {
+	body := map[string]any{"status": "approved", "note": "photos confirm damage"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/status", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestUpdateReturnStatusEndpointRequiresAdmin(t *testing.T) {
+	body := map[string]any{"status": "approved"}
+	w := doRequest(setupRouter(), http.MethodPatch, "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/status", body)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusForbidden, w.Body.String())
+	}
+}
+
+func TestUpdateReturnStatusEndpointChecksIfMatch(t *testing.T) {
+	body := map[string]any{"status": "approved"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/returns/3d6f1c2a-8b4e-4f0a-9c7d-2e5b1a0f9c33/status", body, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"2"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusPreconditionFailed, w.Body.String())
+	}
+}
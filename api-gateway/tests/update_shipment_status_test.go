@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUpdateShipmentStatusEndpoint(t *testing.T) {
+	body := map[string]any{"status": "delivered"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/shipments/b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01/status", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"status":"delivered"`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestUpdateShipmentStatusEndpointTracksWithCarrier(t *testing.T) {
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/shipments/b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01/status", nil, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"status":"in_transit"`) {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+}
+
+func TestUpdateShipmentStatusEndpointRejectsInvalidStatus(t *testing.T) {
+	body := map[string]any{"status": "pending"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/shipments/b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01/status", body, map[string]string{"X-Admin-Key": testAdminKey})
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusBadRequest, w.Body.String())
+	}
+}
+
+func TestUpdateShipmentStatusEndpointChecksIfMatch(t *testing.T) {
+	body := map[string]any{"status": "delivered"}
+	w := doRequestWithHeaders(setupRouter(), http.MethodPatch, "/api/admin/shipments/b1e7c6d2-5f3a-4c8e-9a1b-7d2e4f6a8c01/status", body, map[string]string{"X-Admin-Key": testAdminKey, "If-Match": `"2"`})
+	if w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("status = %d, want %d, body=%s", w.Code, http.StatusPreconditionFailed, w.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+// Command order-import streams a CSV or NDJSON file of orders to the order
+// service's ImportOrders RPC and prints the report.
+//
+//	order-import [-addr host:port] [-format csv|ndjson] [-dry-run] [-resume id] file
+//
+// It exits with 1 when the import failed and can be resumed with -resume, and
+// with 2 when it completed but some rows were rejected.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"online-store-microservice/pkg/grpcjson"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const chunkSize = 64 << 10
+
+func main() {
+	addr := flag.String("addr", envOr("ORDER_SERVICE_URL", "localhost:50052"), "order service gRPC address")
+	format := flag.String("format", "", "csv or ndjson; guessed from the file extension when empty")
+	dryRun := flag.Bool("dry-run", false, "validate every row without storing anything")
+	resume := flag.String("resume", "", "id of an earlier import to resume")
+	actor := flag.String("actor", envOr("USER", "admin"), "recorded as the actor in the orders' history")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] file\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(64)
+	}
+
+	path := flag.Arg(0)
+	if *format == "" {
+		*format = formatOf(path)
+	}
+	resp, err := run(*addr, path, &orderpb.ImportOrdersRequest{
+		ImportId: *resume,
+		Format:   *format,
+		DryRun:   *dryRun,
+		Source:   filepath.Base(path),
+		ActorId:  *actor,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "order-import: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	_ = out.Encode(resp)
+	switch {
+	case resp.Status != "completed":
+		fmt.Fprintf(os.Stderr, "order-import: import stopped, resume with -resume %s\n", resp.ImportId)
+		os.Exit(1)
+	case resp.RowsFailed > 0:
+		os.Exit(2)
+	}
+}
+
+func run(addr, path string, opts *orderpb.ImportOrdersRequest) (*orderpb.ImportOrdersResponse, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conn, err := grpc.NewClient(addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcjson.Codec{})),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stream, err := orderpb.NewOrderServiceClient(conn).ImportOrders(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := opts
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 || msg == opts {
+			msg.Chunk = buf[:n]
+			if err := stream.Send(msg); err != nil {
+				// The server ended the stream; CloseAndRecv returns why.
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, err
+			}
+			msg = &orderpb.ImportOrdersRequest{}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+	return "csv"
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -3,17 +3,49 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	GRPCPort   string
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
+	GRPCPort          string
+	DBHost            string
+	DBPort            string
+	DBUser            string
+	DBPassword        string
+	DBName            string
+	PriceSource       string
+	PriceFile         string
+	TaxRate           float64
+	TaxSource         string
+	TaxRulesFile      string
+	Currency          string
+	IdempotencyKeyTTL time.Duration
+	UserServiceURL    string
+	UserCheckTimeout  time.Duration
+	UserCacheTTL      time.Duration
+	UserCheckMode     string
+	UserVerifyEvery   time.Duration
+	PaymentTTL        time.Duration
+	ExpiryEvery       time.Duration
+	ExpiryBatch       int
+	MetricsAddr       string
+	ImportBatch       int
+	ReturnWindow      time.Duration
+	RefundGateway     string
+	ShippingSource    string
+	ShippingFile      string
+	ShippingCarrier   string
+	RateSource        string
+	RateFile          string
+	OrderNumberFormat string
+	OrderNumberStore  string
+	SellerName        string
+	SellerAddress     string
+	SellerTaxID       string
+	SellerEmail       string
 }
 
 func Load() Config {
@@ -21,12 +53,42 @@ func Load() Config {
 	_ = godotenv.Load("../.env")
 
 	return Config{
-		GRPCPort:   getEnv("ORDER_SERVICE_GRPC_PORT", "50052"),
-		DBHost:     getEnv("ORDER_DB_HOST", "localhost"),
-		DBPort:     getEnv("ORDER_DB_PORT", "5433"),
-		DBUser:     getEnv("ORDER_DB_USER", "postgres"),
-		DBPassword: getEnv("ORDER_DB_PASSWORD", "postgres"),
-		DBName:     getEnv("ORDER_DB_NAME", "order_db"),
+		GRPCPort:          getEnv("ORDER_SERVICE_GRPC_PORT", "50052"),
+		DBHost:            getEnv("ORDER_DB_HOST", "localhost"),
+		DBPort:            getEnv("ORDER_DB_PORT", "5433"),
+		DBUser:            getEnv("ORDER_DB_USER", "postgres"),
+		DBPassword:        getEnv("ORDER_DB_PASSWORD", "postgres"),
+		DBName:            getEnv("ORDER_DB_NAME", "order_db"),
+		PriceSource:       getEnv("PRICE_SOURCE", "postgres"),
+		PriceFile:         getEnv("PRICE_FILE", "scripts/dev/prices.json"),
+		TaxRate:           getEnvFloat("ORDER_TAX_RATE", 0),
+		TaxSource:         getEnv("TAX_SOURCE", "flat"),
+		TaxRulesFile:      getEnv("TAX_RULES_FILE", "scripts/dev/tax_rules.json"),
+		Currency:          getEnv("STORE_CURRENCY", "IDR"),
+		IdempotencyKeyTTL: getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+		UserServiceURL:    getEnv("USER_SERVICE_URL", "localhost:50051"),
+		UserCheckTimeout:  getEnvDuration("USER_CHECK_TIMEOUT", 2*time.Second),
+		UserCacheTTL:      getEnvDuration("USER_CACHE_TTL", 30*time.Second),
+		UserCheckMode:     getEnv("USER_CHECK_MODE", "fail_closed"),
+		UserVerifyEvery:   getEnvDuration("USER_VERIFY_INTERVAL", time.Minute),
+		PaymentTTL:        getEnvDuration("ORDER_PAYMENT_TTL", 30*time.Minute),
+		ExpiryEvery:       getEnvDuration("ORDER_EXPIRY_INTERVAL", time.Minute),
+		ExpiryBatch:       getEnvInt("ORDER_EXPIRY_BATCH", 100),
+		MetricsAddr:       getEnv("ORDER_METRICS_ADDR", ":9102"),
+		ImportBatch:       getEnvInt("ORDER_IMPORT_BATCH", 500),
+		ReturnWindow:      getEnvDuration("RETURN_WINDOW", 30*24*time.Hour),
+		RefundGateway:     getEnv("REFUND_GATEWAY", "fake"),
+		ShippingSource:    getEnv("SHIPPING_SOURCE", "postgres"),
+		ShippingFile:      getEnv("SHIPPING_METHODS_FILE", "scripts/dev/shipping_methods.json"),
+		ShippingCarrier:   getEnv("SHIPPING_CARRIER", "fake"),
+		RateSource:        getEnv("EXCHANGE_RATE_SOURCE", "postgres"),
+		RateFile:          getEnv("EXCHANGE_RATES_FILE", "scripts/dev/exchange_rates.json"),
+		OrderNumberFormat: getEnv("ORDER_NUMBER_FORMAT", "ORD-{year}-{seq:6}"),
+		OrderNumberStore:  getEnv("ORDER_NUMBER_STORE", "main"),
+		SellerName:        getEnv("INVOICE_SELLER_NAME", "Online Store"),
+		SellerAddress:     getEnv("INVOICE_SELLER_ADDRESS", ""),
+		SellerTaxID:       getEnv("INVOICE_SELLER_TAX_ID", ""),
+		SellerEmail:       getEnv("INVOICE_SELLER_EMAIL", ""),
 	}
 }
 
@@ -47,3 +109,39 @@ func getEnv(key string, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvFloat(key string, fallback float64) float64 {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+func getEnvInt(key string, fallback int) int {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
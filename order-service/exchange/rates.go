@@ -0,0 +1,90 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"online-store-microservice/pkg/money"
+)
+
+// RateDecimals is the precision rates are stored with.
+const RateDecimals = 10
+
+var (
+	ErrNoRate      = errors.New("no exchange rate for currency")
+	ErrInvalidRate = errors.New("invalid exchange rate")
+)
+
+// Rate is what one unit of Currency is worth in Base from EffectiveFrom on.
+// A new rate is published with a later EffectiveFrom instead of editing an
+// old one, so orders keep pointing at the rate they were converted with.
+type Rate struct {
+	Base          string
+	Currency      string
+	Value         *big.Rat
+	EffectiveFrom time.Time
+}
+
+// Identity is the rate of base to itself.
+func Identity(base string) Rate {
+	return Rate{Base: base, Currency: base, Value: big.NewRat(1, 1)}
+}
+
+// NewRate validates a rate such as "16250.5" of currency in base.
+func NewRate(base, currency, value string, effectiveFrom time.Time) (Rate, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	for _, c := range []string{base, currency} {
+		if !money.IsKnownCurrency(c) {
+			return Rate{}, fmt.Errorf("%w: unknown currency %q", ErrInvalidRate, c)
+		}
+	}
+	if base == currency {
+		return Rate{}, fmt.Errorf("%w: %s is the base currency", ErrInvalidRate, currency)
+	}
+	v, err := ParseRate(value)
+	if err != nil {
+		return Rate{}, err
+	}
+	return Rate{Base: base, Currency: currency, Value: v, EffectiveFrom: effectiveFrom.UTC()}, nil
+}
+
+// ParseRate parses a positive decimal rate with at most RateDecimals
+// decimals.
+func ParseRate(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q must be a positive number", ErrInvalidRate, s)
+	}
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(RateDecimals), nil)))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidRate, s, RateDecimals)
+	}
+	return r, nil
+}
+
+// String formats the rate the way ParseRate reads it, without trailing zeros.
+func (r Rate) String() string {
+	s := r.Value.FloatString(RateDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// ToBase converts an amount in Currency to Base.
+func (r Rate) ToBase(m money.Money) (money.Money, error) {
+	if !strings.EqualFold(m.Currency, r.Currency) {
+		return money.Money{}, fmt.Errorf("%w: %s and %s", money.ErrCurrencyMismatch, m.Currency, r.Currency)
+	}
+	return m.Convert(r.Base, r.Value)
+}
+
+// FromBase converts an amount in Base to Currency.
+func (r Rate) FromBase(m money.Money) (money.Money, error) {
+	if !strings.EqualFold(m.Currency, r.Base) {
+		return money.Money{}, fmt.Errorf("%w: %s and %s", money.ErrCurrencyMismatch, m.Currency, r.Base)
+	}
+	return m.Convert(r.Currency, new(big.Rat).Inv(r.Value))
+}
@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"online-store-microservice/pkg/money"
+)
+
+func TestFileSourcePicksRateInEffect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	data := `[
+  {"currency": "usd", "rate": "16400", "effective_from": "2026-07-01T00:00:00Z"},
+  {"currency": "USD", "rate": 16250.5, "effective_from": "2026-01-01T00:00:00Z"},
+  {"currency": "JPY", "rate": "108.25", "effective_from": "2026-01-01T00:00:00Z"}
+]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := LoadFileSource(path, "IDR")
+	if err != nil {
+		t.Fatalf("LoadFileSource: %v", err)
+	}
+
+	rate, err := src.Rate(context.Background(), "USD", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || rate.String() != "16250.5" || rate.Base != "IDR" {
+		t.Fatalf("rate = %+v, err = %v", rate, err)
+	}
+	rate, err = src.Rate(context.Background(), "usd", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || rate.String() != "16400" {
+		t.Fatalf("rate from July = %v, err = %v", rate, err)
+	}
+	if _, err := src.Rate(context.Background(), "USD", time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoRate) {
+		t.Fatalf("before the first rate: err = %v, want ErrNoRate", err)
+	}
+	if _, err := src.Rate(context.Background(), "EUR", time.Now()); !errors.Is(err, ErrNoRate) {
+		t.Fatalf("unlisted currency: err = %v, want ErrNoRate", err)
+	}
+}
+
+func TestLoadFileSourceRejectsBadRates(t *testing.T) {
+	for name, data := range map[string]string{
+		"zero":      `[{"currency": "USD", "rate": "0", "effective_from": "2026-01-01T00:00:00Z"}]`,
+		"precision": `[{"currency": "USD", "rate": "0.00000000001", "effective_from": "2026-01-01T00:00:00Z"}]`,
+		"unknown":   `[{"currency": "XYZ", "rate": "1", "effective_from": "2026-01-01T00:00:00Z"}]`,
+		"base":      `[{"currency": "IDR", "rate": "1", "effective_from": "2026-01-01T00:00:00Z"}]`,
+		"duplicate": `[{"currency": "USD", "rate": "1", "effective_from": "2026-01-01T00:00:00Z"}, {"currency": "USD", "rate": "2", "effective_from": "2026-01-01T00:00:00Z"}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rates.json")
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadFileSource(path, "IDR"); !errors.Is(err, ErrInvalidRate) {
+				t.Fatalf("err = %v, want ErrInvalidRate", err)
+			}
+		})
+	}
+}
+
+func TestRateConvertsBothWays(t *testing.T) {
+	rate, err := NewRate("IDR", "USD", "16250", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	usd, err := rate.FromBase(money.New(2000000, "IDR"))
+	if err != nil || !usd.Equal(money.New(123, "USD")) {
+		t.Fatalf("20000 IDR = %v, %v, want 1.23 USD", usd, err)
+	}
+	idr, err := rate.ToBase(usd)
+	if err != nil || !idr.Equal(money.New(1998750, "IDR")) {
+		t.Fatalf("1.23 USD = %v, %v, want 19987.50 IDR", idr, err)
+	}
+	if _, err := rate.ToBase(money.New(1, "EUR")); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("err = %v, want ErrCurrencyMismatch", err)
+	}
+}
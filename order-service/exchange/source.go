@@ -0,0 +1,118 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+)
+
+// Source provides the rates of currencies against the store currency. A
+// source other than the file and the exchange_rates table, such as a rate
+// feed, implements it too.
+type Source interface {
+	// Rate returns the rate of currency that was in effect at at.
+	Rate(ctx context.Context, currency string, at time.Time) (Rate, error)
+}
+
+type rateFileRow struct {
+	Currency      string      `json:"currency"`
+	Rate          json.Number `json:"rate"`
+	EffectiveFrom time.Time   `json:"effective_from"`
+}
+
+type historySource struct {
+	// rates holds the history of each currency, oldest first.
+	rates map[string][]Rate
+}
+
+// NewStaticSource serves rates from memory.
+func NewStaticSource(rates []Rate) Source {
+	s := &historySource{rates: make(map[string][]Rate)}
+	for _, r := range rates {
+		s.rates[r.Currency] = append(s.rates[r.Currency], r)
+	}
+	for _, history := range s.rates {
+		sort.Slice(history, func(i, j int) bool { return history[i].EffectiveFrom.Before(history[j].EffectiveFrom) })
+	}
+	return s
+}
+
+// LoadFileSource reads a JSON list of rates against base. A currency can be
+// listed several times with different effective_from dates; the latest rate
+// that has taken effect applies.
+func LoadFileSource(path, base string) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read exchange rates: %w", err)
+	}
+	var rows []rateFileRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("parse exchange rates: %w", err)
+	}
+	rates := make([]Rate, 0, len(rows))
+	seen := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		rate, err := NewRate(base, row.Currency, row.Rate.String(), row.EffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("parse exchange rates: %w", err)
+		}
+		key := rate.Currency + "@" + rate.EffectiveFrom.Format(time.RFC3339)
+		if seen[key] {
+			return nil, fmt.Errorf("parse exchange rates: %w: %s is listed twice for %s", ErrInvalidRate, rate.Currency, rate.EffectiveFrom.Format(time.RFC3339))
+		}
+		seen[key] = true
+		rates = append(rates, rate)
+	}
+	return NewStaticSource(rates), nil
+}
+
+func (s *historySource) Rate(_ context.Context, currency string, at time.Time) (Rate, error) {
+	history := s.rates[strings.ToUpper(currency)]
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].EffectiveFrom.After(at) {
+			return history[i], nil
+		}
+	}
+	return Rate{}, fmt.Errorf("%w: %s", ErrNoRate, currency)
+}
+
+type postgresSource struct {
+	db   *gorm.DB
+	base string
+}
+
+// NewPostgresSource reads rates against base from the exchange_rates table,
+// for the currencies enabled in the currencies table.
+func NewPostgresSource(db *gorm.DB, base string) Source {
+	return &postgresSource{db: db, base: strings.ToUpper(base)}
+}
+
+func (s *postgresSource) Rate(ctx context.Context, currency string, at time.Time) (Rate, error) {
+	currency = strings.ToUpper(currency)
+	var row models.ExchangeRate
+	err := s.db.WithContext(ctx).
+		Joins("JOIN currencies ON currencies.code = exchange_rates.currency AND currencies.enabled").
+		Where("exchange_rates.base_currency = ? AND exchange_rates.currency = ? AND exchange_rates.effective_from <= ?", s.base, currency, at).
+		Order("exchange_rates.effective_from DESC").
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Rate{}, fmt.Errorf("%w: %s", ErrNoRate, currency)
+	}
+	if err != nil {
+		return Rate{}, err
+	}
+	rate, err := NewRate(row.BaseCurrency, row.Currency, row.Rate, row.EffectiveFrom)
+	if err != nil {
+		return Rate{}, fmt.Errorf("exchange rate %s at %s: %w", row.Currency, row.EffectiveFrom.Format(time.RFC3339), err)
+	}
+	return rate, nil
+}
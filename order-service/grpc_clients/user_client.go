@@ -0,0 +1,59 @@
+package grpc_clients
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"online-store-microservice/pkg/grpcjson"
+	userpb "online-store-microservice/proto/user"
+)
+
+type UserClient struct {
+	conn    *grpc.ClientConn
+	Client  userpb.UserServiceClient
+	timeout time.Duration
+}
+
+func NewUserClient(addr string, timeout time.Duration) (*UserClient, error) {
+	conn, err := grpc.NewClient(
+		addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcjson.Codec{})),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserClient{conn: conn, Client: userpb.NewUserServiceClient(conn), timeout: timeout}, nil
+}
+
+func (c *UserClient) Close() error {
+	return c.conn.Close()
+}
+
+// GetUser derives its deadline from ctx, so an incoming request's remaining
+// deadline is propagated and only shortened to the client timeout.
+func (c *UserClient) GetUser(ctx context.Context, id string) (*userpb.UserData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.Client.GetUserById(ctx, &userpb.GetUserByIdRequest{Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
+
+func (c *UserClient) FindUserByEmail(ctx context.Context, email string) (*userpb.UserData, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.Client.GetUserByEmail(ctx, &userpb.GetUserByEmailRequest{Email: email})
+	if err != nil {
+		return nil, err
+	}
+	return resp.User, nil
+}
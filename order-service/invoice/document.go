@@ -0,0 +1,112 @@
+package invoice
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"online-store-microservice/pkg/money"
+)
+
+const (
+	KindInvoice    = "invoice"
+	KindCreditNote = "credit_note"
+)
+
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+var ErrUnknownFormat = errors.New("unknown invoice format")
+
+// Party is the seller or the buyer as printed on a document.
+type Party struct {
+	Name         string   `json:"name"`
+	Email        string   `json:"email,omitempty"`
+	TaxID        string   `json:"tax_id,omitempty"`
+	AddressLines []string `json:"address_lines,omitempty"`
+}
+
+type Line struct {
+	Description string `json:"description"`
+	SKU         string `json:"sku,omitempty"`
+	Quantity    int32  `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Discount    int64  `json:"discount"`
+	Amount      int64  `json:"amount"`
+}
+
+// TaxLine is the tax of one jurisdiction and class. Rate is in millionths,
+// as on the order.
+type TaxLine struct {
+	Name          string `json:"name"`
+	Rate          int64  `json:"rate"`
+	TaxableAmount int64  `json:"taxable_amount"`
+	Amount        int64  `json:"amount"`
+	Exempt        bool   `json:"exempt,omitempty"`
+}
+
+// Document is everything printed on an invoice or credit note, captured when
+// it is issued so that later changes to the order, the customer or the
+// seller never alter it. Amounts are in minor units of Currency.
+type Document struct {
+	Kind             string    `json:"kind"`
+	Number           string    `json:"number"`
+	IssuedAt         time.Time `json:"issued_at"`
+	OrderID          string    `json:"order_id"`
+	InvoiceNumber    string    `json:"invoice_number,omitempty"`
+	Seller           Party     `json:"seller"`
+	Buyer            Party     `json:"buyer"`
+	Currency         string    `json:"currency"`
+	Lines            []Line    `json:"lines"`
+	TaxLines         []TaxLine `json:"tax_lines,omitempty"`
+	SubtotalAmount   int64     `json:"subtotal_amount"`
+	DiscountAmount   int64     `json:"discount_amount"`
+	ShippingAmount   int64     `json:"shipping_amount"`
+	TaxAmount        int64     `json:"tax_amount"`
+	TotalAmount      int64     `json:"total_amount"`
+	PricesIncludeTax bool      `json:"prices_include_tax,omitempty"`
+	TaxExempt        bool      `json:"tax_exempt,omitempty"`
+	Note             string    `json:"note,omitempty"`
+}
+
+// Number formats the n-th document of a series in a year, for example
+// INV-2026-000123.
+func Number(prefix string, year int, n int64) string {
+	return fmt.Sprintf("%s-%d-%06d", prefix, year, n)
+}
+
+func (d *Document) Title() string {
+	if d.Kind == KindCreditNote {
+		return "Credit note"
+	}
+	return "Invoice"
+}
+
+// Format prints an amount in minor units with the document's currency.
+func (d *Document) Format(amount int64) string {
+	return money.New(amount, d.Currency).String()
+}
+
+func formatRate(rate int64) string {
+	s := fmt.Sprintf("%d.%04d", rate/10000, rate%10000)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	return s + "%"
+}
+
+// Render produces the document as an HTML page or a PDF file and returns it
+// with its content type.
+func Render(doc *Document, format string) ([]byte, string, error) {
+	switch format {
+	case FormatHTML:
+		body, err := RenderHTML(doc)
+		return body, "text/html; charset=utf-8", err
+	case FormatPDF:
+		body, err := RenderPDF(doc)
+		return body, "application/pdf", err
+	default:
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
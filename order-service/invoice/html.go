@@ -0,0 +1,85 @@
+package invoice
+
+import (
+	"bytes"
+	"html/template"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"rate": formatRate,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; color: #222; margin: 40px; }
+h1 { font-size: 22px; margin: 0 0 4px; }
+.meta, .parties { margin-bottom: 24px; }
+.parties td { vertical-align: top; width: 50%; padding: 0; }
+table { border-collapse: collapse; width: 100%; }
+.lines th, .lines td { border-bottom: 1px solid #ddd; padding: 6px 4px; text-align: left; }
+.lines .num { text-align: right; white-space: nowrap; }
+.totals { margin-top: 16px; width: 45%; margin-left: auto; }
+.totals td { padding: 3px 4px; }
+.totals .num { text-align: right; white-space: nowrap; }
+.totals .grand td { font-weight: bold; border-top: 1px solid #222; }
+.note { margin-top: 24px; color: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}} {{.Number}}</h1>
+<div class="meta">
+Issued {{.IssuedAt.Format "2006-01-02"}} &middot; Order {{.OrderID}}{{if .InvoiceNumber}} &middot; Corrects invoice {{.InvoiceNumber}}{{end}}
+</div>
+<table class="parties"><tr>
+<td><strong>Seller</strong><br>{{template "party" .Seller}}</td>
+<td><strong>Bill to</strong><br>{{template "party" .Buyer}}</td>
+</tr></table>
+<table class="lines">
+<thead><tr><th>Description</th><th class="num">Qty</th><th class="num">Unit price</th><th class="num">Discount</th><th class="num">Amount</th></tr></thead>
+<tbody>
+{{- range .Lines}}
+<tr><td>{{.Description}}{{if .SKU}} <small>({{.SKU}})</small>{{end}}</td><td class="num">{{.Quantity}}</td><td class="num">{{$.Format .UnitAmount}}</td><td class="num">{{$.Format .Discount}}</td><td class="num">{{$.Format .Amount}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- if .TaxLines}}
+<table class="lines" style="margin-top:16px">
+<thead><tr><th>Tax</th><th class="num">Rate</th><th class="num">Taxable</th><th class="num">Tax</th></tr></thead>
+<tbody>
+{{- range .TaxLines}}
+<tr><td>{{.Name}}{{if .Exempt}} (exempt){{end}}</td><td class="num">{{rate .Rate}}</td><td class="num">{{$.Format .TaxableAmount}}</td><td class="num">{{$.Format .Amount}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}
+<table class="totals">
+<tr><td>Subtotal</td><td class="num">{{.Format .SubtotalAmount}}</td></tr>
+{{- if .DiscountAmount}}
+<tr><td>Discount</td><td class="num">-{{.Format .DiscountAmount}}</td></tr>
+{{- end}}
+{{- if .ShippingAmount}}
+<tr><td>Shipping</td><td class="num">{{.Format .ShippingAmount}}</td></tr>
+{{- end}}
+<tr><td>{{if .PricesIncludeTax}}Included tax{{else}}Tax{{end}}</td><td class="num">{{.Format .TaxAmount}}</td></tr>
+<tr class="grand"><td>Total</td><td class="num">{{.Format .TotalAmount}}</td></tr>
+</table>
+{{- if .TaxExempt}}
+<p class="note">The customer is exempt from tax.</p>
+{{- end}}
+{{- if .Note}}
+<p class="note">{{.Note}}</p>
+{{- end}}
+</body>
+</html>
+{{define "party"}}{{.Name}}{{range .AddressLines}}<br>{{.}}{{end}}{{if .Email}}<br>{{.Email}}{{end}}{{if .TaxID}}<br>Tax ID {{.TaxID}}{{end}}{{end}}
+`))
+
+func RenderHTML(doc *Document) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,303 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The PDF is written by hand with the two standard Helvetica fonts every
+// reader ships, so no font has to be embedded and no dependency is needed.
+const (
+	pageWidth    = 595
+	pageHeight   = 842
+	marginLeft   = 50
+	marginRight  = pageWidth - 50
+	marginTop    = pageHeight - 50
+	marginBottom = 60
+)
+
+// helveticaWidths are the glyph widths of Helvetica for the printable ASCII
+// characters, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsi maps the characters outside Latin-1 that WinAnsiEncoding can show.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+func encodeText(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x20:
+			out = append(out, ' ')
+		case r < 0x7f:
+			out = append(out, byte(r))
+		case r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsi[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+func textWidth(s string, size float64) float64 {
+	units := 0
+	for _, b := range encodeText(s) {
+		if b >= 0x20 && b < 0x7f {
+			units += helveticaWidths[b-0x20]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// fit shortens s with an ellipsis until it is at most width points wide.
+func fit(s string, size, width float64) string {
+	if textWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+type pdfLayout struct {
+	pages []*pdfPage
+	page  *pdfPage
+	y     float64
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &pdfPage{}
+	l.pages = append(l.pages, l.page)
+	l.y = marginTop
+}
+
+// need starts a new page when fewer than height points are left.
+func (l *pdfLayout) need(height float64) {
+	if l.y-height < marginBottom {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&l.page.content, "BT /%s %s Tf %s %s Td (", font, num(size), num(x), num(y))
+	for _, b := range encodeText(s) {
+		if b == '(' || b == ')' || b == '\\' {
+			l.page.content.WriteByte('\\')
+		}
+		l.page.content.WriteByte(b)
+	}
+	l.page.content.WriteString(") Tj ET\n")
+}
+
+func (l *pdfLayout) textRight(right, y, size float64, bold bool, s string) {
+	l.text(right-textWidth(s, size), y, size, bold, s)
+}
+
+func (l *pdfLayout) rule(y float64) {
+	fmt.Fprintf(&l.page.content, "0.5 w %d %s m %d %s l S\n", marginLeft, num(y), marginRight, num(y))
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// Right edges of the numeric columns of the line table.
+const (
+	colDescription = 235
+	colQuantity    = 270
+	colUnit        = 355
+	colDiscount    = 450
+	colAmount      = marginRight
+)
+
+func RenderPDF(doc *Document) ([]byte, error) {
+	l := &pdfLayout{}
+	l.newPage()
+
+	l.text(marginLeft, l.y, 20, true, doc.Title()+" "+doc.Number)
+	l.y -= 18
+	meta := "Issued " + doc.IssuedAt.Format("2006-01-02") + "  Order " + doc.OrderID
+	l.text(marginLeft, l.y, 9, false, meta)
+	if doc.InvoiceNumber != "" {
+		l.y -= 12
+		l.text(marginLeft, l.y, 9, false, "Corrects invoice "+doc.InvoiceNumber)
+	}
+	l.y -= 30
+
+	seller, buyer := partyLines(doc.Seller), partyLines(doc.Buyer)
+	l.text(marginLeft, l.y, 10, true, "Seller")
+	l.text(pageWidth/2, l.y, 10, true, "Bill to")
+	for i := 0; i < len(seller) || i < len(buyer); i++ {
+		l.y -= 13
+		if i < len(seller) {
+			l.text(marginLeft, l.y, 10, false, fit(seller[i], 10, pageWidth/2-marginLeft-10))
+		}
+		if i < len(buyer) {
+			l.text(pageWidth/2, l.y, 10, false, fit(buyer[i], 10, marginRight-pageWidth/2))
+		}
+	}
+	l.y -= 30
+
+	header := func() {
+		l.text(marginLeft, l.y, 9, true, "Description")
+		l.textRight(colQuantity, l.y, 9, true, "Qty")
+		l.textRight(colUnit, l.y, 9, true, "Unit price")
+		l.textRight(colDiscount, l.y, 9, true, "Discount")
+		l.textRight(colAmount, l.y, 9, true, "Amount")
+		l.rule(l.y - 4)
+		l.y -= 16
+	}
+	header()
+	for _, line := range doc.Lines {
+		if l.y-14 < marginBottom {
+			l.newPage()
+			header()
+		}
+		description := line.Description
+		if line.SKU != "" {
+			description += " (" + line.SKU + ")"
+		}
+		l.text(marginLeft, l.y, 9, false, fit(description, 9, colDescription-marginLeft))
+		l.textRight(colQuantity, l.y, 9, false, strconv.Itoa(int(line.Quantity)))
+		l.textRight(colUnit, l.y, 9, false, doc.Format(line.UnitAmount))
+		l.textRight(colDiscount, l.y, 9, false, doc.Format(line.Discount))
+		l.textRight(colAmount, l.y, 9, false, doc.Format(line.Amount))
+		l.y -= 14
+	}
+
+	if len(doc.TaxLines) > 0 {
+		l.need(40)
+		l.y -= 10
+		l.text(marginLeft, l.y, 9, true, "Tax")
+		l.textRight(colUnit, l.y, 9, true, "Rate")
+		l.textRight(colDiscount, l.y, 9, true, "Taxable")
+		l.textRight(colAmount, l.y, 9, true, "Tax")
+		l.rule(l.y - 4)
+		l.y -= 16
+		for _, line := range doc.TaxLines {
+			l.need(14)
+			name := line.Name
+			if line.Exempt {
+				name += " (exempt)"
+			}
+			l.text(marginLeft, l.y, 9, false, fit(name, 9, colQuantity-marginLeft))
+			l.textRight(colUnit, l.y, 9, false, formatRate(line.Rate))
+			l.textRight(colDiscount, l.y, 9, false, doc.Format(line.TaxableAmount))
+			l.textRight(colAmount, l.y, 9, false, doc.Format(line.Amount))
+			l.y -= 14
+		}
+	}
+
+	totals := [][2]string{{"Subtotal", doc.Format(doc.SubtotalAmount)}}
+	if doc.DiscountAmount != 0 {
+		totals = append(totals, [2]string{"Discount", "-" + doc.Format(doc.DiscountAmount)})
+	}
+	if doc.ShippingAmount != 0 {
+		totals = append(totals, [2]string{"Shipping", doc.Format(doc.ShippingAmount)})
+	}
+	taxLabel := "Tax"
+	if doc.PricesIncludeTax {
+		taxLabel = "Included tax"
+	}
+	totals = append(totals, [2]string{taxLabel, doc.Format(doc.TaxAmount)})
+	l.need(float64(len(totals)+1)*14 + 20)
+	l.y -= 16
+	for _, row := range totals {
+		l.text(colUnit, l.y, 10, false, row[0])
+		l.textRight(colAmount, l.y, 10, false, row[1])
+		l.y -= 14
+	}
+	fmt.Fprintf(&l.page.content, "0.5 w %d %s m %d %s l S\n", colUnit, num(l.y+10), colAmount, num(l.y+10))
+	l.text(colUnit, l.y-2, 11, true, "Total")
+	l.textRight(colAmount, l.y-2, 11, true, doc.Format(doc.TotalAmount))
+	l.y -= 30
+
+	var notes []string
+	if doc.TaxExempt {
+		notes = append(notes, "The customer is exempt from tax.")
+	}
+	if doc.Note != "" {
+		notes = append(notes, doc.Note)
+	}
+	for _, note := range notes {
+		l.need(14)
+		l.text(marginLeft, l.y, 9, false, fit(note, 9, marginRight-marginLeft))
+		l.y -= 14
+	}
+
+	return l.bytes(), nil
+}
+
+func partyLines(p Party) []string {
+	lines := []string{p.Name}
+	lines = append(lines, p.AddressLines...)
+	if p.Email != "" {
+		lines = append(lines, p.Email)
+	}
+	if p.TaxID != "" {
+		lines = append(lines, "Tax ID "+p.TaxID)
+	}
+	return lines
+}
+
+// bytes assembles the file: catalog, page tree, the two fonts, then a page
+// object and a content stream for every page, followed by the xref table.
+func (l *pdfLayout) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range l.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
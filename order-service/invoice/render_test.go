@@ -0,0 +1,103 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testDocument() *Document {
+	return &Document{
+		Kind:     KindInvoice,
+		Number:   Number("INV", 2026, 42),
+		IssuedAt: time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC),
+		OrderID:  "8f328abb-4ae4-493b-a460-a63f1206b2f3",
+		Seller:   Party{Name: "Online Store", TaxID: "01.234.567.8-901.000", AddressLines: []string{"Jl. Sudirman 1", "Jakarta"}},
+		Buyer:    Party{Name: "Budi <script>", Email: "budi@example.com", AddressLines: []string{"Jl. Thamrin (Blok C)"}},
+		Currency: "IDR",
+		Lines: []Line{
+			{Description: "Laptop 14", SKU: "LAP-14", Quantity: 1, UnitAmount: 1500000000, Amount: 1500000000},
+			{Description: "Mouse", Quantity: 2, UnitAmount: 15000000, Discount: 3000000, Amount: 27000000},
+		},
+		TaxLines:       []TaxLine{{Name: "ID standard", Rate: 110000, TaxableAmount: 1527000000, Amount: 167970000}},
+		SubtotalAmount: 1530000000,
+		DiscountAmount: 3000000,
+		ShippingAmount: 1500000,
+		TaxAmount:      167970000,
+		TotalAmount:    1696470000,
+	}
+}
+
+func TestNumberIsZeroPadded(t *testing.T) {
+	if got := Number("CN", 2026, 123); got != "CN-2026-000123" {
+		t.Fatalf("Number = %q", got)
+	}
+}
+
+func TestRenderHTMLEscapesAndPrintsTotals(t *testing.T) {
+	body, contentType, err := Render(testDocument(), FormatHTML)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.HasPrefix(contentType, "text/html") {
+		t.Fatalf("content type = %q", contentType)
+	}
+	html := string(body)
+	for _, want := range []string{"Invoice INV-2026-000042", "Budi &lt;script&gt;", "16964700.00 IDR", "11%", "Shipping"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML does not contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Fatal("buyer name was not escaped")
+	}
+}
+
+func TestRenderPDFHasValidCrossReferences(t *testing.T) {
+	doc := testDocument()
+	for i := 0; i < 80; i++ {
+		doc.Lines = append(doc.Lines, Line{Description: fmt.Sprintf("Cable %d", i), Quantity: 1, UnitAmount: 100, Amount: 100})
+	}
+	body, contentType, err := Render(doc, FormatPDF)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if contentType != "application/pdf" {
+		t.Fatalf("content type = %q", contentType)
+	}
+	if !bytes.HasPrefix(body, []byte("%PDF-1.4")) || !bytes.HasSuffix(body, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	if !bytes.Contains(body, []byte(`(Jl. Thamrin \(Blok C\)) Tj`)) {
+		t.Fatal("parentheses in text were not escaped")
+	}
+	if !bytes.Contains(body, []byte("/Count 2")) {
+		t.Fatal("long invoice did not continue on a second page")
+	}
+
+	start := bytes.LastIndex(body, []byte("startxref\n"))
+	xref, err := strconv.Atoi(strings.Fields(string(body[start+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(body[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table: %v", err)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(body[xref:], -1)
+	if len(entries) != 8 {
+		t.Fatalf("xref has %d objects, want 8", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(body[offset:], []byte(want)) {
+			t.Fatalf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+}
+
+func TestRenderRejectsUnknownFormat(t *testing.T) {
+	if _, _, err := Render(testDocument(), "docx"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
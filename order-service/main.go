@@ -2,24 +2,38 @@ package main
 
 import (
 	"context"
+	"errors"
+	"expvar"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
 	"online-store-microservice/order-service/config"
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/grpc_clients"
+	"online-store-microservice/order-service/invoice"
+	"online-store-microservice/order-service/ordernumber"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/refund"
 	"online-store-microservice/order-service/repository"
 	"online-store-microservice/order-service/server"
 	"online-store-microservice/order-service/service"
+	"online-store-microservice/order-service/shipping"
+	"online-store-microservice/order-service/tax"
 	"online-store-microservice/pkg/grpcjson"
 	pkglog "online-store-microservice/pkg/logger"
+	"online-store-microservice/pkg/money"
 	orderpb "online-store-microservice/proto/order"
 )
 
@@ -27,22 +41,147 @@ func main() {
 	grpcjson.Register()
 	log := pkglog.New("[order-service]")
 	cfg := config.Load()
+	if !money.IsKnownCurrency(cfg.Currency) {
+		log.Fatalf("unknown STORE_CURRENCY %q", cfg.Currency)
+	}
 
 	db, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
 	if err != nil {
 		log.Fatalf("connect db: %v", err)
 	}
 
+	var prices pricing.PriceProvider
+	switch cfg.PriceSource {
+	case "file":
+		prices, err = pricing.LoadStaticProvider(cfg.PriceFile, cfg.Currency)
+		if err != nil {
+			log.Fatalf("load prices: %v", err)
+		}
+	case "postgres":
+		prices = pricing.NewPostgresProvider(db)
+	default:
+		log.Fatalf("unknown PRICE_SOURCE %q", cfg.PriceSource)
+	}
+
+	var taxes tax.Source
+	switch cfg.TaxSource {
+	case "flat":
+		taxes = tax.NewFlatSource(cfg.TaxRate)
+	case "file":
+		taxes, err = tax.LoadFileSource(cfg.TaxRulesFile)
+		if err != nil {
+			log.Fatalf("load tax rules: %v", err)
+		}
+	case "postgres":
+		taxes = tax.NewPostgresSource(db)
+	default:
+		log.Fatalf("unknown TAX_SOURCE %q", cfg.TaxSource)
+	}
+
+	var methods shipping.Catalog
+	switch cfg.ShippingSource {
+	case "file":
+		methods, err = shipping.LoadFileCatalog(cfg.ShippingFile, cfg.Currency)
+		if err != nil {
+			log.Fatalf("load shipping methods: %v", err)
+		}
+	case "postgres":
+		methods = shipping.NewPostgresCatalog(db)
+	default:
+		log.Fatalf("unknown SHIPPING_SOURCE %q", cfg.ShippingSource)
+	}
+
+	var rates exchange.Source
+	switch cfg.RateSource {
+	case "file":
+		rates, err = exchange.LoadFileSource(cfg.RateFile, cfg.Currency)
+		if err != nil {
+			log.Fatalf("load exchange rates: %v", err)
+		}
+	case "postgres":
+		rates = exchange.NewPostgresSource(db, cfg.Currency)
+	default:
+		log.Fatalf("unknown EXCHANGE_RATE_SOURCE %q", cfg.RateSource)
+	}
+
+	userClient, err := grpc_clients.NewUserClient(cfg.UserServiceURL, cfg.UserCheckTimeout)
+	if err != nil {
+		log.Fatalf("connect user-service: %v", err)
+	}
+	defer userClient.Close()
+	users, err := service.NewUserChecker(userClient, cfg.UserCheckMode, cfg.UserCacheTTL)
+	if err != nil {
+		log.Fatalf("USER_CHECK_MODE: %v", err)
+	}
+
+	var refunds refund.Gateway
+	switch cfg.RefundGateway {
+	case "fake":
+		refunds = refund.NewFakeGateway(log)
+	default:
+		log.Fatalf("unknown REFUND_GATEWAY %q", cfg.RefundGateway)
+	}
+
+	carriers := shipping.Carriers{}
+	switch cfg.ShippingCarrier {
+	case "fake":
+		carriers["fake"] = shipping.NewFakeCarrier(log)
+	default:
+		log.Fatalf("unknown SHIPPING_CARRIER %q", cfg.ShippingCarrier)
+	}
+
+	numberFormat, err := ordernumber.ParseFormat(cfg.OrderNumberFormat)
+	if err != nil {
+		log.Fatalf("ORDER_NUMBER_FORMAT: %v", err)
+	}
+	numbers, err := ordernumber.NewGenerator(numberFormat, cfg.OrderNumberStore, ordernumber.NewPostgresSequence(db))
+	if err != nil {
+		log.Fatalf("ORDER_NUMBER_STORE: %v", err)
+	}
+	stores, err := ordernumber.PostgresStores(context.Background(), db)
+	if err != nil {
+		log.Fatalf("list order number stores: %v", err)
+	}
+	if err := numberFormat.CheckStores(cfg.OrderNumberStore, stores); err != nil {
+		log.Fatalf("ORDER_NUMBER_FORMAT: %v", err)
+	}
+
 	repo := repository.NewOrderRepository(db)
-	svc := service.NewOrderService(repo)
-	grpcSrv := server.NewGRPCServer(svc)
+	pricer := pricing.NewPricer(prices, cfg.Currency, taxes, methods, rates)
+	keys := repository.NewIdempotencyRepository(db, cfg.IdempotencyKeyTTL)
+	coupons := repository.NewCouponRepository(db)
+	exemptions := repository.NewTaxExemptionRepository(db)
+	returnRepo := repository.NewReturnRepository(db)
+	invoices := service.NewInvoiceService(repo, returnRepo, repository.NewInvoiceRepository(db), userClient, sellerParty(cfg), log)
+	svc := service.NewOrderService(service.OrderServiceDeps{
+		Repo:         repo,
+		Keys:         keys,
+		Coupons:      coupons,
+		Exemptions:   exemptions,
+		Pricer:       pricer,
+		Users:        users,
+		Compensation: service.CompensationHooks{service.NewLoggingCompensationHook(log)},
+		Invoices:     invoices,
+		Numbers:      numbers,
+		Logger:       log,
+	})
+	returns := service.NewReturnService(repo, returnRepo, refunds, invoices, cfg.ReturnWindow, log)
+	shipments := service.NewShipmentService(repo, repository.NewShipmentRepository(db), carriers, log)
+	if cfg.ImportBatch < 1 {
+		log.Fatalf("ORDER_IMPORT_BATCH must be at least 1")
+	}
+	imports := service.NewOrderImportService(svc, repository.NewOrderImportRepository(db), cfg.Currency, cfg.ImportBatch, log)
+	grpcSrv := server.NewGRPCServer(svc, returns, service.NewCouponService(coupons, cfg.Currency), service.NewTaxService(exemptions), shipments, invoices, service.NewOrderSearchService(repo, userClient, cfg.Currency), imports)
 
 	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
 	if err != nil {
 		log.Fatalf("listen: %v", err)
 	}
 
-	s := grpc.NewServer(grpc.UnaryInterceptor(loggingInterceptor(log)))
+	s := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestIDInterceptor, loggingInterceptor(log)),
+		grpc.ChainStreamInterceptor(requestIDStreamInterceptor, streamLoggingInterceptor(log)),
+	)
 	orderpb.RegisterOrderServiceServer(s, grpcSrv)
 
 	go func() {
@@ -52,7 +191,74 @@ func main() {
 		}
 	}()
 
+	workers, stopWorkers := context.WithCancel(context.Background())
+	go service.NewUserVerificationWorker(repo, users, log, cfg.UserVerifyEvery, 100).Run(workers)
+	if cfg.PaymentTTL > 0 {
+		if cfg.ExpiryBatch < 1 {
+			log.Fatalf("ORDER_EXPIRY_BATCH must be at least 1")
+		}
+		expiry := service.NewOrderExpiryWorker(repo, service.ExpiryHooks{service.NewLoggingExpiryHook(log)}, log, cfg.PaymentTTL, cfg.ExpiryEvery, cfg.ExpiryBatch, expvar.NewMap("order_expiry"))
+		go expiry.Run(workers)
+	}
+
+	var metrics *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		metrics = &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			log.Printf("metrics listening on %s", cfg.MetricsAddr)
+			if err := metrics.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("metrics server: %v", err)
+			}
+		}()
+	}
+
 	shutdown(log, s)
+	stopWorkers()
+	if metrics != nil {
+		_ = metrics.Close()
+	}
+}
+
+// sellerParty is the seller printed on invoices. INVOICE_SELLER_ADDRESS
+// separates address lines with semicolons.
+func sellerParty(cfg config.Config) invoice.Party {
+	seller := invoice.Party{Name: cfg.SellerName, Email: cfg.SellerEmail, TaxID: cfg.SellerTaxID}
+	for _, line := range strings.Split(cfg.SellerAddress, ";") {
+		if line = strings.TrimSpace(line); line != "" {
+			seller.AddressLines = append(seller.AddressLines, line)
+		}
+	}
+	return seller
+}
+
+func requestIDInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(orderpb.RequestIDMetadata); len(ids) > 0 {
+			ctx = service.WithRequestID(ctx, ids[0])
+		}
+	}
+	return handler(ctx, req)
+}
+
+func requestIDStreamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if md, ok := metadata.FromIncomingContext(ss.Context()); ok {
+		if ids := md.Get(orderpb.RequestIDMetadata); len(ids) > 0 {
+			ss = &contextStream{ServerStream: ss, ctx: service.WithRequestID(ss.Context(), ids[0])}
+		}
+	}
+	return handler(srv, ss)
+}
+
+// contextStream replaces the context of a server stream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
 }
 
 func loggingInterceptor(log interface{ Printf(string, ...interface{}) }) grpc.UnaryServerInterceptor {
@@ -64,6 +270,15 @@ func loggingInterceptor(log interface{ Printf(string, ...interface{}) }) grpc.Un
 	}
 }
 
+func streamLoggingInterceptor(log interface{ Printf(string, ...interface{}) }) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		log.Printf("method=%s duration=%s err=%v", info.FullMethod, time.Since(start), err)
+		return err
+	}
+}
+
 func shutdown(log interface{ Printf(string, ...interface{}) }, s *grpc.Server) {
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
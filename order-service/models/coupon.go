@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+type Coupon struct {
+	Code             string     `gorm:"type:varchar(64);primaryKey"`
+	Description      string     `gorm:"type:varchar(255)"`
+	Kind             string     `gorm:"type:varchar(20);not null"`
+	PercentOffBps    int64      `gorm:"not null;default:0"`
+	Currency         string     `gorm:"type:char(3);not null"`
+	AmountOff        int64      `gorm:"not null;default:0"`
+	BuyQuantity      int32      `gorm:"not null;default:0"`
+	GetQuantity      int32      `gorm:"not null;default:0"`
+	MinSubtotal      int64      `gorm:"not null;default:0"`
+	StartsAt         *time.Time `gorm:"type:timestamp"`
+	EndsAt           *time.Time `gorm:"type:timestamp"`
+	MaxRedemptions   int32      `gorm:"not null;default:0"`
+	MaxPerUser       int32      `gorm:"not null;default:0"`
+	Redemptions      int32      `gorm:"not null;default:0"`
+	EligibleProducts []string   `gorm:"type:jsonb;serializer:json;not null"`
+	Active           bool       `gorm:"not null"`
+	CreatedAt        time.Time  `gorm:"not null"`
+	UpdatedAt        time.Time  `gorm:"not null"`
+}
+
+func (Coupon) TableName() string {
+	return "coupons"
+}
+
+// CouponTerms are the pricing terms of the coupon an order was placed with,
+// kept on the order so that later changes to the coupon do not reprice it.
+// Amounts are in minor units of Currency.
+type CouponTerms struct {
+	Kind             string   `json:"kind"`
+	PercentOffBps    int64    `json:"percent_off_bps,omitempty"`
+	Currency         string   `json:"currency"`
+	AmountOff        int64    `json:"amount_off,omitempty"`
+	BuyQuantity      int32    `json:"buy_quantity,omitempty"`
+	GetQuantity      int32    `json:"get_quantity,omitempty"`
+	MinSubtotal      int64    `json:"min_subtotal,omitempty"`
+	EligibleProducts []string `json:"eligible_products,omitempty"`
+}
+
+type CouponRedemption struct {
+	ID         string    `gorm:"type:uuid;primaryKey"`
+	CouponCode string    `gorm:"type:varchar(64);not null"`
+	UserID     string    `gorm:"type:uuid;not null"`
+	OrderID    string    `gorm:"type:uuid;not null"`
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+func (CouponRedemption) TableName() string {
+	return "coupon_redemptions"
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Currency is a currency customers may order in. Disabling it stops new
+// orders in it without touching its rate history.
+type Currency struct {
+	Code      string    `gorm:"type:char(3);primaryKey"`
+	Name      string    `gorm:"type:varchar(100);not null"`
+	Enabled   bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+func (Currency) TableName() string {
+	return "currencies"
+}
+
+// ExchangeRate is what one unit of Currency was worth in BaseCurrency from
+// EffectiveFrom on. Rates are never edited in place.
+type ExchangeRate struct {
+	BaseCurrency  string    `gorm:"type:char(3);primaryKey"`
+	Currency      string    `gorm:"type:char(3);primaryKey"`
+	EffectiveFrom time.Time `gorm:"type:timestamp;primaryKey"`
+	Rate          string    `gorm:"type:numeric(20,10);not null"`
+	Source        string    `gorm:"type:varchar(64);not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+}
+
+func (ExchangeRate) TableName() string {
+	return "exchange_rates"
+}
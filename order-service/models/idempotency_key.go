@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	Scope       string    `gorm:"type:varchar(100);primaryKey"`
+	Key         string    `gorm:"type:varchar(255);primaryKey"`
+	Fingerprint string    `gorm:"type:char(64);not null"`
+	Response    []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"not null"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Invoice is an issued invoice or credit note. Document holds everything
+// printed on it, so it never changes after issue.
+type Invoice struct {
+	ID          string          `gorm:"type:uuid;primaryKey"`
+	Kind        string          `gorm:"type:varchar(20);not null"`
+	Number      string          `gorm:"type:varchar(32);not null;uniqueIndex"`
+	Year        int             `gorm:"not null"`
+	Sequence    int64           `gorm:"not null"`
+	OrderID     string          `gorm:"type:uuid;not null;index"`
+	ReturnID    *string         `gorm:"type:uuid"`
+	InvoiceID   *string         `gorm:"type:uuid"`
+	Currency    string          `gorm:"type:char(3);not null"`
+	TotalAmount int64           `gorm:"not null"`
+	TaxAmount   int64           `gorm:"not null"`
+	Document    json.RawMessage `gorm:"type:jsonb;not null"`
+	IssuedAt    time.Time       `gorm:"not null"`
+}
+
+func (Invoice) TableName() string {
+	return "invoices"
+}
+
+// InvoiceSequence is the last number handed out in a series for a year.
+type InvoiceSequence struct {
+	Series     string `gorm:"type:varchar(10);primaryKey"`
+	Year       int    `gorm:"primaryKey"`
+	LastNumber int64  `gorm:"not null"`
+}
+
+func (InvoiceSequence) TableName() string {
+	return "invoice_sequences"
+}
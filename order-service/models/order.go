@@ -3,14 +3,51 @@ package models
 import "time"
 
 type Order struct {
-	ID          string    `gorm:"type:uuid;primaryKey"`
-	UserID      string    `gorm:"type:uuid;not null;index"`
-	ProductName string    `gorm:"type:varchar(255);not null"`
-	Quantity    int32     `gorm:"not null"`
-	TotalPrice  float64   `gorm:"type:numeric(12,2);not null"`
-	Status      string    `gorm:"type:varchar(50);not null;default:pending"`
-	CreatedAt   time.Time `gorm:"not null"`
-	UpdatedAt   time.Time `gorm:"not null"`
+	ID             string `gorm:"type:uuid;primaryKey"`
+	Number         string `gorm:"type:varchar(64);default:null"`
+	UserID         string `gorm:"type:uuid;not null;index"`
+	ProductName    string `gorm:"type:varchar(255);not null"`
+	Quantity       int32  `gorm:"not null"`
+	Currency       string `gorm:"type:char(3);not null"`
+	SubtotalAmount int64  `gorm:"not null;default:0"`
+	DiscountAmount int64  `gorm:"not null;default:0"`
+	TaxAmount      int64  `gorm:"not null;default:0"`
+	TotalAmount    int64  `gorm:"not null"`
+	RefundedAmount int64  `gorm:"not null;default:0"`
+	// BaseCurrency is the store currency when the order was priced, and
+	// ExchangeRate what one unit of Currency was worth in it. Reports convert
+	// with the stored rate, not the current one.
+	BaseCurrency       string          `gorm:"type:char(3);not null"`
+	ExchangeRate       string          `gorm:"type:numeric(20,10);not null;default:1"`
+	ExchangeRateFrom   *time.Time      `gorm:"type:timestamp"`
+	BaseTotalAmount    int64           `gorm:"not null;default:0"`
+	CouponCode         string          `gorm:"type:varchar(64)"`
+	CouponTerms        *CouponTerms    `gorm:"type:jsonb;serializer:json"`
+	FreeShipping       bool            `gorm:"not null;default:false"`
+	ShippingAddress    *Address        `gorm:"type:jsonb;serializer:json"`
+	ShippingMethod     string          `gorm:"type:varchar(64)"`
+	ShippingCarrier    string          `gorm:"type:varchar(64)"`
+	ShippingZone       string          `gorm:"type:varchar(100)"`
+	ShippingAmount     int64           `gorm:"not null;default:0"`
+	TaxRulesVersion    string          `gorm:"type:varchar(64)"`
+	PricesIncludeTax   bool            `gorm:"not null;default:false"`
+	TaxExempt          bool            `gorm:"not null;default:false"`
+	TaxExemptAmount    int64           `gorm:"not null;default:0"`
+	Subtotal           float64         `gorm:"type:numeric(12,2);not null;default:0"`
+	DiscountTotal      float64         `gorm:"type:numeric(12,2);not null;default:0"`
+	TaxTotal           float64         `gorm:"type:numeric(12,2);not null;default:0"`
+	TotalPrice         float64         `gorm:"type:numeric(12,2);not null"`
+	Status             string          `gorm:"type:varchar(50);not null;default:pending"`
+	CancellationReason string          `gorm:"type:varchar(50)"`
+	CancelledBy        string          `gorm:"type:varchar(64)"`
+	CancelledAt        *time.Time      `gorm:"type:timestamp"`
+	UserVerifiedAt     *time.Time      `gorm:"type:timestamp"`
+	Version            int64           `gorm:"not null;default:1"`
+	CreatedAt          time.Time       `gorm:"not null"`
+	UpdatedAt          time.Time       `gorm:"not null"`
+	Items              []OrderItem     `gorm:"foreignKey:OrderID"`
+	Discounts          []OrderDiscount `gorm:"foreignKey:OrderID"`
+	TaxLines           []OrderTaxLine  `gorm:"foreignKey:OrderID"`
 }
 
 func (Order) TableName() string {
@@ -0,0 +1,16 @@
+package models
+
+type OrderDiscount struct {
+	ID          string  `gorm:"type:uuid;primaryKey"`
+	OrderID     string  `gorm:"type:uuid;not null;index"`
+	OrderItemID *string `gorm:"type:uuid"`
+	Position    int32   `gorm:"not null"`
+	Source      string  `gorm:"type:varchar(20);not null"`
+	Code        string  `gorm:"type:varchar(64)"`
+	Kind        string  `gorm:"type:varchar(20)"`
+	Amount      int64   `gorm:"not null"`
+}
+
+func (OrderDiscount) TableName() string {
+	return "order_discounts"
+}
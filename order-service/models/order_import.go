@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+const (
+	OrderImportRunning   = "running"
+	OrderImportCompleted = "completed"
+	OrderImportFailed    = "failed"
+)
+
+// OrderImport tracks a bulk import. Rows up to and including CommittedRow
+// have been handled, successfully or not, so a resumed import skips them.
+type OrderImport struct {
+	ID           string    `gorm:"type:uuid;primaryKey"`
+	Format       string    `gorm:"type:varchar(10);not null"`
+	Source       string    `gorm:"type:varchar(255);not null;default:''"`
+	ActorID      string    `gorm:"type:varchar(64);not null;default:''"`
+	Status       string    `gorm:"type:varchar(20);not null"`
+	CommittedRow int64     `gorm:"not null;default:0"`
+	RowsImported int64     `gorm:"not null;default:0"`
+	RowsFailed   int64     `gorm:"not null;default:0"`
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (OrderImport) TableName() string {
+	return "order_imports"
+}
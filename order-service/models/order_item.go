@@ -0,0 +1,24 @@
+package models
+
+type OrderItem struct {
+	ID             string  `gorm:"type:uuid;primaryKey"`
+	OrderID        string  `gorm:"type:uuid;not null;index"`
+	Position       int32   `gorm:"not null"`
+	ProductID      string  `gorm:"type:varchar(64)"`
+	SKU            string  `gorm:"column:sku;type:varchar(64)"`
+	Name           string  `gorm:"type:varchar(255);not null"`
+	TaxClass       string  `gorm:"type:varchar(50)"`
+	WeightGrams    int64   `gorm:"not null;default:0"`
+	Currency       string  `gorm:"type:char(3);not null"`
+	UnitAmount     int64   `gorm:"not null"`
+	DiscountAmount int64   `gorm:"not null;default:0"`
+	LineAmount     int64   `gorm:"not null"`
+	UnitPrice      float64 `gorm:"type:numeric(12,2);not null"`
+	Quantity       int32   `gorm:"not null"`
+	Discount       float64 `gorm:"type:numeric(12,2);not null;default:0"`
+	LineTotal      float64 `gorm:"type:numeric(12,2);not null"`
+}
+
+func (OrderItem) TableName() string {
+	return "order_items"
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+const (
+	ReturnStatusRequested = "requested"
+	ReturnStatusApproved  = "approved"
+	ReturnStatusRejected  = "rejected"
+	ReturnStatusReceived  = "received"
+	ReturnStatusRefunded  = "refunded"
+)
+
+type OrderReturn struct {
+	ID              string            `gorm:"type:uuid;primaryKey"`
+	OrderID         string            `gorm:"type:uuid;not null;index"`
+	UserID          string            `gorm:"type:uuid;not null"`
+	Status          string            `gorm:"type:varchar(50);not null"`
+	Reason          string            `gorm:"type:varchar(50);not null"`
+	Note            string            `gorm:"type:text"`
+	Currency        string            `gorm:"type:char(3);not null"`
+	RequestedAmount int64             `gorm:"not null"`
+	RefundedAmount  int64             `gorm:"not null;default:0"`
+	RefundReference string            `gorm:"type:varchar(100)"`
+	RequestedBy     string            `gorm:"type:varchar(64);not null"`
+	DecidedBy       string            `gorm:"type:varchar(64)"`
+	DecisionNote    string            `gorm:"type:text"`
+	CreatedAt       time.Time         `gorm:"not null"`
+	UpdatedAt       time.Time         `gorm:"not null"`
+	ReceivedAt      *time.Time        `gorm:"type:timestamp"`
+	RefundedAt      *time.Time        `gorm:"type:timestamp"`
+	Items           []OrderReturnItem `gorm:"foreignKey:ReturnID"`
+}
+
+func (OrderReturn) TableName() string {
+	return "order_returns"
+}
+
+type OrderReturnItem struct {
+	ID          string `gorm:"type:uuid;primaryKey"`
+	ReturnID    string `gorm:"type:uuid;not null;index"`
+	OrderItemID string `gorm:"type:uuid;not null;index"`
+	Name        string `gorm:"type:varchar(255);not null"`
+	Quantity    int32  `gorm:"not null"`
+	Amount      int64  `gorm:"not null"`
+}
+
+func (OrderReturnItem) TableName() string {
+	return "order_return_items"
+}
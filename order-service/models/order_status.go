@@ -0,0 +1,13 @@
+package models
+
+const (
+	OrderStatusPending         = "pending"
+	OrderStatusAwaitingPayment = "awaiting_payment"
+	OrderStatusPaid            = "paid"
+	OrderStatusFulfilling      = "fulfilling"
+	OrderStatusShipped         = "shipped"
+	OrderStatusDelivered       = "delivered"
+	OrderStatusCancelled       = "cancelled"
+	OrderStatusRefunded        = "refunded"
+	OrderStatusExpired         = "expired"
+)
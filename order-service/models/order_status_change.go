@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type OrderStatusChange struct {
+	ID         string        `gorm:"type:uuid;primaryKey"`
+	OrderID    string        `gorm:"type:uuid;not null;index"`
+	FromStatus string        `gorm:"type:varchar(50)"`
+	ToStatus   string        `gorm:"type:varchar(50);not null"`
+	Actor      string        `gorm:"type:varchar(64);not null"`
+	Reason     string        `gorm:"type:varchar(255)"`
+	RequestID  string        `gorm:"type:varchar(64)"`
+	Changes    []OrderChange `gorm:"type:jsonb;serializer:json"`
+	CreatedAt  time.Time     `gorm:"not null"`
+}
+
+func (OrderStatusChange) TableName() string {
+	return "order_status_history"
+}
+
+// OrderChange is one field an amendment changed, with its values formatted
+// for display.
+type OrderChange struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
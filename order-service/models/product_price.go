@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type ProductPrice struct {
+	ProductID string  `gorm:"type:varchar(64);primaryKey"`
+	SKU       string  `gorm:"column:sku;type:varchar(64);uniqueIndex;not null"`
+	Name      string  `gorm:"type:varchar(255);not null"`
+	Currency  string  `gorm:"type:char(3);not null"`
+	UnitPrice string  `gorm:"type:numeric(12,2);not null"`
+	SalePrice *string `gorm:"type:numeric(12,2)"`
+	TaxClass  string  `gorm:"type:varchar(50);not null;default:standard"`
+	// WeightGrams is the shipping weight of one unit.
+	WeightGrams int64     `gorm:"not null;default:0"`
+	Active      bool      `gorm:"not null;default:true"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (ProductPrice) TableName() string {
+	return "product_prices"
+}
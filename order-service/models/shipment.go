@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ShipmentStatusPending   = "pending"
+	ShipmentStatusInTransit = "in_transit"
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusFailed    = "failed"
+)
+
+// ShippingMethod is a method customers can pick at checkout. Zones holds the
+// rate rules in the same format as SHIPPING_METHODS_FILE.
+type ShippingMethod struct {
+	Code      string          `gorm:"type:varchar(64);primaryKey"`
+	Name      string          `gorm:"type:varchar(255);not null"`
+	Carrier   string          `gorm:"type:varchar(64);not null"`
+	Currency  string          `gorm:"type:char(3);not null"`
+	Zones     json.RawMessage `gorm:"type:jsonb;not null"`
+	Active    bool            `gorm:"not null"`
+	CreatedAt time.Time       `gorm:"not null"`
+	UpdatedAt time.Time       `gorm:"not null"`
+}
+
+func (ShippingMethod) TableName() string {
+	return "shipping_methods"
+}
+
+type Shipment struct {
+	ID             string         `gorm:"type:uuid;primaryKey"`
+	OrderID        string         `gorm:"type:uuid;not null;index"`
+	Carrier        string         `gorm:"type:varchar(64);not null"`
+	TrackingNumber string         `gorm:"type:varchar(100);not null"`
+	TrackingURL    string         `gorm:"type:varchar(500)"`
+	Status         string         `gorm:"type:varchar(50);not null"`
+	WeightGrams    int64          `gorm:"not null;default:0"`
+	CreatedBy      string         `gorm:"type:varchar(64);not null"`
+	CreatedAt      time.Time      `gorm:"not null"`
+	UpdatedAt      time.Time      `gorm:"not null"`
+	DeliveredAt    *time.Time     `gorm:"type:timestamp"`
+	Items          []ShipmentItem `gorm:"foreignKey:ShipmentID"`
+}
+
+func (Shipment) TableName() string {
+	return "shipments"
+}
+
+type ShipmentItem struct {
+	ID          string `gorm:"type:uuid;primaryKey"`
+	ShipmentID  string `gorm:"type:uuid;not null;index"`
+	OrderItemID string `gorm:"type:uuid;not null;index"`
+	Name        string `gorm:"type:varchar(255);not null"`
+	Quantity    int32  `gorm:"not null"`
+}
+
+func (ShipmentItem) TableName() string {
+	return "shipment_items"
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaxRuleSet is one published version of the tax rules. Rules holds the
+// rates, rounding and pricing mode in the same format as TAX_RULES_FILE.
+type TaxRuleSet struct {
+	Version       string          `gorm:"type:varchar(64);primaryKey"`
+	EffectiveFrom time.Time       `gorm:"type:timestamp;not null"`
+	Rules         json.RawMessage `gorm:"type:jsonb;not null"`
+	CreatedAt     time.Time       `gorm:"not null"`
+}
+
+func (TaxRuleSet) TableName() string {
+	return "tax_rule_sets"
+}
+
+type TaxExemption struct {
+	UserID      string    `gorm:"type:uuid;primaryKey"`
+	Reason      string    `gorm:"type:varchar(255);not null"`
+	Certificate string    `gorm:"type:varchar(100)"`
+	CreatedBy   string    `gorm:"type:varchar(64);not null"`
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+func (TaxExemption) TableName() string {
+	return "tax_exemptions"
+}
+
+// OrderTaxLine is the tax as calculated when the order was placed. Rate is in
+// millionths (110000 is 11%).
+type OrderTaxLine struct {
+	ID            string  `gorm:"type:uuid;primaryKey"`
+	OrderID       string  `gorm:"type:uuid;not null;index"`
+	OrderItemID   *string `gorm:"type:uuid"`
+	Position      int32   `gorm:"not null"`
+	Country       string  `gorm:"type:varchar(2);not null"`
+	Region        string  `gorm:"type:varchar(10)"`
+	TaxClass      string  `gorm:"type:varchar(50);not null"`
+	Name          string  `gorm:"type:varchar(100)"`
+	Rate          int64   `gorm:"not null"`
+	TaxableAmount int64   `gorm:"not null"`
+	Amount        int64   `gorm:"not null"`
+	Exempt        bool    `gorm:"not null;default:false"`
+}
+
+func (OrderTaxLine) TableName() string {
+	return "order_tax_lines"
+}
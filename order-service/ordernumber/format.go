@@ -0,0 +1,114 @@
+package ordernumber
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormat gives numbers like ORD-2026-000123.
+const DefaultFormat = "ORD-{year}-{seq:6}"
+
+// MaxLength is the longest order number a format may produce.
+const MaxLength = 64
+
+var ErrInvalidFormat = errors.New("invalid order number format")
+
+var (
+	placeholder = regexp.MustCompile(`\{[^{}]*\}`)
+	literal     = regexp.MustCompile(`^[A-Za-z0-9._-]*$`)
+	// Pattern matches every number a valid format can produce, after
+	// Normalize.
+	Pattern = regexp.MustCompile(`^[A-Z0-9._-]{1,64}$`)
+)
+
+// Format turns a sequence value into an order number. Its pattern is text
+// with the placeholders {store}, {year} and {seq}, where {seq:N} pads the
+// sequence to N digits. When it has {year} the sequence restarts every year.
+type Format struct {
+	pattern  string
+	digits   int
+	yearly   bool
+	perStore bool
+}
+
+func ParseFormat(pattern string) (Format, error) {
+	f := Format{pattern: pattern}
+	seqs := 0
+	for _, token := range placeholder.FindAllString(pattern, -1) {
+		name, width, _ := strings.Cut(strings.Trim(token, "{}"), ":")
+		switch {
+		case name == "store" && width == "", name == "year" && width == "":
+			f.yearly = f.yearly || name == "year"
+			f.perStore = f.perStore || name == "store"
+		case name == "seq":
+			seqs++
+			if width == "" {
+				continue
+			}
+			n, err := strconv.Atoi(width)
+			if err != nil || n < 1 || n > 18 {
+				return Format{}, fmt.Errorf("%w: %s needs a width from 1 to 18", ErrInvalidFormat, token)
+			}
+			f.digits = n
+		default:
+			return Format{}, fmt.Errorf("%w: unknown placeholder %s", ErrInvalidFormat, token)
+		}
+	}
+	if seqs != 1 {
+		return Format{}, fmt.Errorf("%w: needs exactly one {seq}", ErrInvalidFormat)
+	}
+	if !literal.MatchString(placeholder.ReplaceAllString(pattern, "")) {
+		return Format{}, fmt.Errorf("%w: only letters, digits, '.', '_' and '-' may appear outside placeholders", ErrInvalidFormat)
+	}
+	return f, nil
+}
+
+// CheckStores fails when the format leaves {store} out while stores other
+// than store number orders too, since their numbers would collide.
+func (f Format) CheckStores(store string, stores []string) error {
+	if f.perStore {
+		return nil
+	}
+	store = Normalize(store)
+	for _, other := range stores {
+		if Normalize(other) != store {
+			return fmt.Errorf("%w: needs {store} since store %s numbers orders too", ErrInvalidFormat, other)
+		}
+	}
+	return nil
+}
+
+// Period is the sequence the number of an order placed at t is taken from:
+// its year for a yearly format, and empty otherwise.
+func (f Format) Period(t time.Time) string {
+	if !f.yearly {
+		return ""
+	}
+	return strconv.Itoa(t.UTC().Year())
+}
+
+// Number formats seq for an order of store placed at t.
+func (f Format) Number(store string, t time.Time, seq int64) string {
+	number := placeholder.ReplaceAllStringFunc(f.pattern, func(token string) string {
+		name, _, _ := strings.Cut(strings.Trim(token, "{}"), ":")
+		switch name {
+		case "store":
+			return store
+		case "year":
+			return strconv.Itoa(t.UTC().Year())
+		default:
+			return fmt.Sprintf("%0*d", f.digits, seq)
+		}
+	})
+	return Normalize(number)
+}
+
+// Normalize is how order numbers are stored and looked up, so that one read
+// out in lower case still matches.
+func Normalize(number string) string {
+	return strings.ToUpper(strings.TrimSpace(number))
+}
@@ -0,0 +1,91 @@
+package ordernumber
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type memorySequence map[string]int64
+
+func (m memorySequence) Next(_ context.Context, store, period string) (int64, error) {
+	m[store+"/"+period]++
+	return m[store+"/"+period], nil
+}
+
+func TestGeneratorRestartsYearlySequences(t *testing.T) {
+	format, err := ParseFormat(DefaultFormat)
+	if err != nil {
+		t.Fatalf("ParseFormat: %v", err)
+	}
+	gen, err := NewGenerator(format, "main", memorySequence{})
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	ctx := context.Background()
+	dec := time.Date(2026, 12, 31, 23, 0, 0, 0, time.UTC)
+	jan := time.Date(2027, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	var got []string
+	for _, at := range []time.Time{dec, dec, jan} {
+		number, err := gen.Next(ctx, at)
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		got = append(got, number)
+	}
+	want := []string{"ORD-2026-000001", "ORD-2026-000002", "ORD-2027-000001"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("number %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFormatWithStoreAndUnpaddedSequence(t *testing.T) {
+	format, err := ParseFormat("{store}-{seq}")
+	if err != nil {
+		t.Fatalf("ParseFormat: %v", err)
+	}
+	at := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	if got := format.Number("JKT", at, 123); got != "JKT-123" {
+		t.Fatalf("Number = %s, want JKT-123", got)
+	}
+	if format.Period(at) != "" {
+		t.Fatalf("Period = %q, want a single sequence", format.Period(at))
+	}
+	if !Pattern.MatchString(Normalize(" jkt-123 ")) {
+		t.Fatal("normalized number does not match Pattern")
+	}
+}
+
+func TestParseFormatRejects(t *testing.T) {
+	for _, pattern := range []string{"ORD-{year}", "{seq}-{seq}", "ORD-{month}-{seq}", "ORD/{seq}", "{seq:0}", "{seq:x}", "{year:4}-{seq}"} {
+		if _, err := ParseFormat(pattern); !errors.Is(err, ErrInvalidFormat) {
+			t.Errorf("%q: err = %v, want ErrInvalidFormat", pattern, err)
+		}
+	}
+	format, _ := ParseFormat(DefaultFormat)
+	if _, err := NewGenerator(format, "main st", memorySequence{}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("store with a space: err = %v, want ErrInvalidFormat", err)
+	}
+}
+
+func TestFormatWithoutStoreNeedsASingleStore(t *testing.T) {
+	shared, _ := ParseFormat(DefaultFormat)
+	if err := shared.CheckStores("main", nil); err != nil {
+		t.Errorf("first store: %v", err)
+	}
+	if err := shared.CheckStores("main", []string{"MAIN"}); err != nil {
+		t.Errorf("same store: %v", err)
+	}
+	if err := shared.CheckStores("jkt", []string{"MAIN"}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("second store: err = %v, want ErrInvalidFormat", err)
+	}
+
+	perStore, _ := ParseFormat("{store}-{year}-{seq:6}")
+	if err := perStore.CheckStores("jkt", []string{"MAIN", "SBY"}); err != nil {
+		t.Errorf("format with {store}: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package ordernumber
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sequence hands out increasing values per store and period, starting at 1.
+type Sequence interface {
+	Next(ctx context.Context, store, period string) (int64, error)
+}
+
+type postgresSequence struct {
+	db *gorm.DB
+}
+
+// NewPostgresSequence counts in the order_number_sequences table. Each value
+// is committed on its own, so an order that then fails to be stored leaves a
+// gap rather than holding the counter's row lock until it commits.
+func NewPostgresSequence(db *gorm.DB) Sequence {
+	return &postgresSequence{db: db}
+}
+
+func (s *postgresSequence) Next(ctx context.Context, store, period string) (int64, error) {
+	var value int64
+	err := s.db.WithContext(ctx).Raw(
+		`INSERT INTO order_number_sequences (store, period, last_value) VALUES (?, ?, 1)
+ON CONFLICT (store, period) DO UPDATE SET last_value = order_number_sequences.last_value + 1
+RETURNING last_value`, store, period).Scan(&value).Error
+	return value, err
+}
+
+// PostgresStores lists the stores that have numbered orders.
+func PostgresStores(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var stores []string
+	err := db.WithContext(ctx).Raw(`SELECT DISTINCT store FROM order_number_sequences`).Scan(&stores).Error
+	return stores, err
+}
+
+// Generator gives the orders of one store their numbers.
+type Generator struct {
+	format Format
+	store  string
+	seq    Sequence
+}
+
+func NewGenerator(format Format, store string, seq Sequence) (*Generator, error) {
+	store = Normalize(store)
+	if store == "" || !literal.MatchString(store) {
+		return nil, fmt.Errorf("%w: store %q may only contain letters, digits, '.', '_' and '-'", ErrInvalidFormat, store)
+	}
+	return &Generator{format: format, store: store, seq: seq}, nil
+}
+
+// Next returns the number of an order placed at t.
+func (g *Generator) Next(ctx context.Context, t time.Time) (string, error) {
+	value, err := g.seq.Next(ctx, g.store, g.format.Period(t))
+	if err != nil {
+		return "", fmt.Errorf("next order number: %w", err)
+	}
+	number := g.format.Number(g.store, t, value)
+	if len(number) > MaxLength {
+		return "", fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidFormat, number, MaxLength)
+	}
+	return number, nil
+}
@@ -0,0 +1,309 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/shipping"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
+)
+
+var (
+	ErrUnknownProduct   = errors.New("unknown product")
+	ErrPriceMismatch    = errors.New("price mismatch")
+	ErrCurrencyMismatch = errors.New("product is not priced in the store currency")
+)
+
+type LineRequest struct {
+	Ref               string
+	Quantity          int32
+	ExpectedUnitPrice money.Money
+}
+
+type Line struct {
+	Price
+	Quantity  int32
+	Discount  money.Money
+	LineTotal money.Money
+}
+
+// QuoteRequest is everything that affects the price of an order.
+type QuoteRequest struct {
+	Lines       []LineRequest
+	Promotion   *Promotion
+	Destination tax.Address
+	TaxExempt   bool
+	// ShippingMethod is the code of the method picked at checkout; empty
+	// means the order is not shipped.
+	ShippingMethod string
+	// Currency is the currency the customer pays in; empty means the store
+	// currency.
+	Currency string
+}
+
+type Quote struct {
+	Currency      string
+	Lines         []Line
+	Subtotal      money.Money
+	DiscountTotal money.Money
+	TaxTotal      money.Money
+	Total         money.Money
+	// Discounts breaks DiscountTotal down into sale prices and coupons.
+	Discounts    []Discount
+	FreeShipping bool
+	Tax          *tax.Result
+	// Shipping is nil when no shipping method was picked. Its amount is what
+	// the method costs; ShippingTotal is what the customer pays for it.
+	Shipping      *shipping.Charge
+	ShippingTotal money.Money
+	WeightGrams   int64
+	// Rate converted the store prices to Currency, and BaseTotal is Total
+	// back in the store currency at that rate.
+	Rate      exchange.Rate
+	BaseTotal money.Money
+}
+
+type Pricer struct {
+	provider PriceProvider
+	currency string
+	taxes    tax.Source
+	methods  shipping.Catalog
+	rates    exchange.Source
+}
+
+// NewPricer prices orders from store prices in currency. rates converts them
+// for orders in other currencies; with nil rates only currency is accepted.
+func NewPricer(provider PriceProvider, currency string, taxes tax.Source, methods shipping.Catalog, rates exchange.Source) *Pricer {
+	return &Pricer{provider: provider, currency: currency, taxes: taxes, methods: methods, rates: rates}
+}
+
+func (p *Pricer) Currency() string {
+	return p.currency
+}
+
+// Prices returns the current prices of refs in currency, converted at
+// today's rate. Refs that are not sold, or not in the store currency, are
+// missing from the result.
+func (p *Pricer) Prices(ctx context.Context, refs []string, currency string) (map[string]Price, error) {
+	rate, err := p.Rate(ctx, currency, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	prices, err := p.provider.Lookup(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("lookup prices: %w", err)
+	}
+	for ref, price := range prices {
+		if price.UnitPrice.Currency != p.currency {
+			delete(prices, ref)
+			continue
+		}
+		if prices[ref], err = convertPrice(price, rate); err != nil {
+			return nil, err
+		}
+	}
+	return prices, nil
+}
+
+// Rate returns the rate that converts store prices to currency at at. Orders
+// in the store currency need no rate.
+func (p *Pricer) Rate(ctx context.Context, currency string, at time.Time) (exchange.Rate, error) {
+	if currency == "" || strings.EqualFold(currency, p.currency) {
+		return exchange.Identity(p.currency), nil
+	}
+	if p.rates == nil {
+		return exchange.Rate{}, fmt.Errorf("%w: %s", exchange.ErrNoRate, currency)
+	}
+	return p.rates.Rate(ctx, currency, at)
+}
+
+func convertPrice(price Price, rate exchange.Rate) (Price, error) {
+	if rate.Currency == rate.Base {
+		return price, nil
+	}
+	var err error
+	if price.UnitPrice, err = rate.FromBase(price.UnitPrice); err != nil {
+		return Price{}, err
+	}
+	if !price.SalePrice.IsZero() {
+		if price.SalePrice, err = rate.FromBase(price.SalePrice); err != nil {
+			return Price{}, err
+		}
+	}
+	return price, nil
+}
+
+func (p *Pricer) Quote(ctx context.Context, reqs []LineRequest) (*Quote, error) {
+	return p.QuoteOrder(ctx, QuoteRequest{Lines: reqs})
+}
+
+// QuoteOrder prices the lines, applies the promotion, if any, taxes the
+// discounted amounts with the rules in effect now and adds shipping. Orders
+// in another currency than the store's are priced in it at the rate in effect
+// now: unit prices, coupon amounts and shipping charges are converted, and
+// tax is calculated on the converted amounts.
+func (p *Pricer) QuoteOrder(ctx context.Context, qr QuoteRequest) (*Quote, error) {
+	now := time.Now().UTC()
+	rate, err := p.Rate(ctx, qr.Currency, now)
+	if err != nil {
+		return nil, err
+	}
+	currency := rate.Currency
+
+	reqs := qr.Lines
+	refs := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		refs = append(refs, req.Ref)
+	}
+
+	prices, err := p.provider.Lookup(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("lookup prices: %w", err)
+	}
+
+	zero := money.New(0, currency)
+	quote := &Quote{Currency: currency, Rate: rate, Lines: make([]Line, 0, len(reqs)), Subtotal: zero, DiscountTotal: zero, ShippingTotal: zero}
+	for _, req := range reqs {
+		price, ok := prices[req.Ref]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProduct, req.Ref)
+		}
+		if price.UnitPrice.Currency != p.currency {
+			return nil, fmt.Errorf("%w: %s is priced in %s", ErrCurrencyMismatch, req.Ref, price.UnitPrice.Currency)
+		}
+		if price, err = convertPrice(price, rate); err != nil {
+			return nil, err
+		}
+
+		unit := price.EffectiveUnitPrice()
+		if !req.ExpectedUnitPrice.IsZero() && !req.ExpectedUnitPrice.Equal(unit) {
+			return nil, fmt.Errorf("%w: %s unit price is %s, client expected %s", ErrPriceMismatch, req.Ref, unit, req.ExpectedUnitPrice)
+		}
+
+		gross, err := price.UnitPrice.Mul(int64(req.Quantity))
+		if err != nil {
+			return nil, fmt.Errorf("price %s: %w", req.Ref, err)
+		}
+		lineTotal, err := unit.Mul(int64(req.Quantity))
+		if err != nil {
+			return nil, fmt.Errorf("price %s: %w", req.Ref, err)
+		}
+		discount, _ := gross.Sub(lineTotal)
+		quote.Lines = append(quote.Lines, Line{Price: price, Quantity: req.Quantity, Discount: discount, LineTotal: lineTotal})
+		quote.Subtotal, _ = quote.Subtotal.Add(gross)
+		quote.WeightGrams += price.WeightGrams * int64(req.Quantity)
+		quote.DiscountTotal, _ = quote.DiscountTotal.Add(discount)
+		if !discount.IsZero() {
+			quote.Discounts = append(quote.Discounts, Discount{Source: DiscountSourceSale, Line: len(quote.Lines) - 1, Amount: discount})
+		}
+	}
+
+	if qr.Promotion != nil {
+		promo, err := convertPromotion(*qr.Promotion, rate)
+		if err != nil {
+			return nil, err
+		}
+		if err := promo.apply(quote); err != nil {
+			return nil, err
+		}
+	}
+
+	rules, err := p.taxes.Rules(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("load tax rules: %w", err)
+	}
+	items := make([]tax.Item, 0, len(quote.Lines))
+	for _, line := range quote.Lines {
+		items = append(items, tax.Item{Class: line.TaxClass, Amount: line.LineTotal})
+	}
+	if quote.Tax, err = rules.Calculate(items, qr.Destination, qr.TaxExempt, currency); err != nil {
+		return nil, err
+	}
+
+	// With tax-inclusive prices the tax is already in the net amount; an
+	// exempt customer pays the net amount without it.
+	net, _ := quote.Subtotal.Sub(quote.DiscountTotal)
+	quote.TaxTotal = quote.Tax.Total
+	if quote.Tax.Inclusive {
+		quote.Total, _ = net.Sub(quote.Tax.Waived)
+	} else {
+		quote.Total, _ = net.Add(quote.TaxTotal)
+	}
+
+	if qr.ShippingMethod != "" {
+		if err := p.ship(ctx, quote, qr, net); err != nil {
+			return nil, err
+		}
+		quote.Total, _ = quote.Total.Add(quote.ShippingTotal)
+	}
+	if quote.BaseTotal, err = rate.ToBase(quote.Total); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// convertPromotion converts the amounts of a coupon, which are set in the
+// store currency, to the currency of rate.
+func convertPromotion(promo Promotion, rate exchange.Rate) (Promotion, error) {
+	if rate.Currency == rate.Base {
+		return promo, nil
+	}
+	var err error
+	if promo.AmountOff.Currency == rate.Base {
+		if promo.AmountOff, err = rate.FromBase(promo.AmountOff); err != nil {
+			return Promotion{}, err
+		}
+	}
+	if promo.MinSubtotal.Currency == rate.Base {
+		if promo.MinSubtotal, err = rate.FromBase(promo.MinSubtotal); err != nil {
+			return Promotion{}, err
+		}
+	}
+	return promo, nil
+}
+
+// ship prices the shipping method for the quote. Shipping is not taxed, and
+// a free shipping coupon waives it whatever the method costs. Methods are
+// priced in the store currency, so merchandise is compared with their
+// thresholds in it and the charge is converted to the quote currency.
+func (p *Pricer) ship(ctx context.Context, quote *Quote, qr QuoteRequest, merchandise money.Money) error {
+	if p.methods == nil {
+		return fmt.Errorf("%w: %s", shipping.ErrUnknownMethod, qr.ShippingMethod)
+	}
+	method, err := p.methods.Method(ctx, qr.ShippingMethod)
+	if err != nil {
+		return err
+	}
+	if method.Currency != p.currency {
+		return fmt.Errorf("%w: shipping method %s is priced in %s", ErrCurrencyMismatch, method.Code, method.Currency)
+	}
+	if merchandise, err = quote.Rate.ToBase(merchandise); err != nil {
+		return err
+	}
+	charge, err := method.Rate(qr.Destination.Country, quote.WeightGrams, merchandise)
+	if err != nil {
+		return err
+	}
+	if quote.Rate.Currency != quote.Rate.Base {
+		if charge.Amount, err = quote.Rate.FromBase(charge.Amount); err != nil {
+			return err
+		}
+	}
+	quote.Shipping = &charge
+	if !quote.FreeShipping {
+		quote.ShippingTotal = charge.Amount
+	}
+	return nil
+}
+
+func (q *Quote) CheckExpectedTotal(expected money.Money) error {
+	if !expected.IsZero() && !expected.Equal(q.Total) {
+		return fmt.Errorf("%w: order total is %s, client expected %s", ErrPriceMismatch, q.Total, expected)
+	}
+	return nil
+}
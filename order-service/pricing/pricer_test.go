@@ -0,0 +1,213 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/shipping"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
+)
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+func testPricer(taxRate float64) *Pricer {
+	return testPricerWithRules(tax.NewFlatSource(taxRate))
+}
+
+func testPricerWithRules(taxes tax.Source) *Pricer {
+	return NewPricer(NewStaticProvider([]Price{
+		{ProductID: "laptop-14", SKU: "LP-14-SLV", Name: "Laptop", UnitPrice: usd(100000), WeightGrams: 1600},
+		{ProductID: "mouse-01", SKU: "MS-01-BLK", Name: "Mouse", UnitPrice: usd(2000), SalePrice: usd(1500), WeightGrams: 90},
+		{ProductID: "cable-01", SKU: "CB-01", Name: "Cable", UnitPrice: usd(333)},
+		{ProductID: "yen-01", SKU: "YN-01", Name: "Imported", UnitPrice: money.New(500, "JPY")},
+	}), "USD", taxes, shipping.NewStaticCatalog([]*shipping.Method{testShippingMethod()}), testRates())
+}
+
+// testRates values a euro at 1.25 USD now and at 2 USD from 2999 on.
+func testRates() exchange.Source {
+	now, _ := exchange.NewRate("USD", "EUR", "1.25", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	later, _ := exchange.NewRate("USD", "EUR", "2", time.Date(2999, 1, 1, 0, 0, 0, 0, time.UTC))
+	return exchange.NewStaticSource([]exchange.Rate{later, now})
+}
+
+func testShippingMethod() *shipping.Method {
+	return &shipping.Method{Code: "standard", Name: "Standard", Carrier: "fake", Currency: "USD", Zones: []shipping.Zone{
+		{Name: "domestic", Countries: []string{"US"}, Kind: shipping.RateFlat, Amount: usd(500), FreeAbove: usd(50000)},
+		{Name: "international", Countries: []string{"*"}, Kind: shipping.RateWeight, Amount: usd(1000), PerKg: usd(800)},
+	}}
+}
+
+func TestQuoteComputesTotals(t *testing.T) {
+	quote, err := testPricer(0.11).Quote(context.Background(), []LineRequest{
+		{Ref: "laptop-14", Quantity: 1},
+		{Ref: "MS-01-BLK", Quantity: 3},
+	})
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+
+	if !quote.Subtotal.Equal(usd(106000)) {
+		t.Errorf("subtotal = %v, want 1060.00 USD", quote.Subtotal)
+	}
+	if !quote.DiscountTotal.Equal(usd(1500)) {
+		t.Errorf("discount = %v, want 15.00 USD", quote.DiscountTotal)
+	}
+	if !quote.TaxTotal.Equal(usd(11495)) {
+		t.Errorf("tax = %v, want 114.95 USD", quote.TaxTotal)
+	}
+	if !quote.Total.Equal(usd(115995)) {
+		t.Errorf("total = %v, want 1159.95 USD", quote.Total)
+	}
+	if !quote.Lines[1].LineTotal.Equal(usd(4500)) {
+		t.Errorf("mouse line total = %v, want 45.00 USD", quote.Lines[1].LineTotal)
+	}
+}
+
+func TestQuoteRoundsTaxHalfAwayFromZero(t *testing.T) {
+	// 3 x 3.33 = 9.99; 9.99 * 5% = 0.4995 -> 0.50
+	quote, err := testPricer(0.05).Quote(context.Background(), []LineRequest{{Ref: "cable-01", Quantity: 3}})
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if !quote.TaxTotal.Equal(usd(50)) || !quote.Total.Equal(usd(1049)) {
+		t.Fatalf("tax = %v total = %v, want 0.50 and 10.49", quote.TaxTotal, quote.Total)
+	}
+}
+
+func TestQuoteIgnoresClientPricesUnlessTheyDisagree(t *testing.T) {
+	p := testPricer(0)
+
+	if _, err := p.Quote(context.Background(), []LineRequest{{Ref: "mouse-01", Quantity: 1, ExpectedUnitPrice: usd(1500)}}); err != nil {
+		t.Fatalf("matching expected price: %v", err)
+	}
+
+	_, err := p.Quote(context.Background(), []LineRequest{{Ref: "laptop-14", Quantity: 1, ExpectedUnitPrice: usd(100)}})
+	if !errors.Is(err, ErrPriceMismatch) {
+		t.Fatalf("err = %v, want ErrPriceMismatch", err)
+	}
+
+	quote, err := p.Quote(context.Background(), []LineRequest{{Ref: "laptop-14", Quantity: 1}})
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if err := quote.CheckExpectedTotal(usd(100)); !errors.Is(err, ErrPriceMismatch) {
+		t.Fatalf("CheckExpectedTotal = %v, want ErrPriceMismatch", err)
+	}
+	if err := quote.CheckExpectedTotal(money.Money{}); err != nil {
+		t.Fatalf("CheckExpectedTotal without expectation = %v", err)
+	}
+}
+
+func TestQuoteRejectsUnknownProduct(t *testing.T) {
+	_, err := testPricer(0).Quote(context.Background(), []LineRequest{{Ref: "nope", Quantity: 1}})
+	if !errors.Is(err, ErrUnknownProduct) {
+		t.Fatalf("err = %v, want ErrUnknownProduct", err)
+	}
+}
+
+func TestQuoteRejectsLineTotalOutOfRange(t *testing.T) {
+	pricer := NewPricer(NewStaticProvider([]Price{{ProductID: "gold", Name: "Gold", UnitPrice: usd(math.MaxInt64 / 2)}}), "USD", tax.NewFlatSource(0), nil, testRates())
+	_, err := pricer.Quote(context.Background(), []LineRequest{{Ref: "gold", Quantity: 3}})
+	if !errors.Is(err, money.ErrInvalidAmount) {
+		t.Fatalf("err = %v, want money.ErrInvalidAmount", err)
+	}
+}
+
+func TestQuoteRejectsForeignCurrencyPrice(t *testing.T) {
+	_, err := testPricer(0).Quote(context.Background(), []LineRequest{{Ref: "yen-01", Quantity: 1}})
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("err = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestQuoteOrderAddsShipping(t *testing.T) {
+	tests := []struct {
+		name    string
+		country string
+		lines   []LineRequest
+		promo   *Promotion
+		zone    string
+		want    money.Money
+	}{
+		{"flat domestic", "US", []LineRequest{{Ref: "mouse-01", Quantity: 2}}, nil, "domestic", usd(500)},
+		{"free above threshold", "US", []LineRequest{{Ref: "laptop-14", Quantity: 1}}, nil, "domestic", usd(0)},
+		// 1600g + 3 x 90g = 1870g, two started kilograms.
+		{"weight international", "DE", []LineRequest{{Ref: "laptop-14", Quantity: 1}, {Ref: "mouse-01", Quantity: 3}}, nil, "international", usd(2600)},
+		{"free shipping coupon", "DE", []LineRequest{{Ref: "mouse-01", Quantity: 1}}, &Promotion{Code: "SHIPFREE", Kind: PromotionFreeShipping}, "international", usd(0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{
+				Lines:          tt.lines,
+				Promotion:      tt.promo,
+				Destination:    tax.Address{Country: tt.country},
+				ShippingMethod: "standard",
+			})
+			if err != nil {
+				t.Fatalf("QuoteOrder: %v", err)
+			}
+			if quote.Shipping == nil || quote.Shipping.Zone != tt.zone {
+				t.Fatalf("shipping = %+v, want zone %s", quote.Shipping, tt.zone)
+			}
+			if !quote.ShippingTotal.Equal(tt.want) {
+				t.Errorf("shipping total = %v, want %v", quote.ShippingTotal, tt.want)
+			}
+			net, _ := quote.Subtotal.Sub(quote.DiscountTotal)
+			if want, _ := net.Add(tt.want); !quote.Total.Equal(want) {
+				t.Errorf("total = %v, want %v", quote.Total, want)
+			}
+		})
+	}
+}
+
+func TestQuoteOrderRejectsUnknownShippingMethod(t *testing.T) {
+	_, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{
+		Lines:          []LineRequest{{Ref: "mouse-01", Quantity: 1}},
+		Destination:    tax.Address{Country: "US"},
+		ShippingMethod: "overnight",
+	})
+	if !errors.Is(err, shipping.ErrUnknownMethod) {
+		t.Fatalf("err = %v, want ErrUnknownMethod", err)
+	}
+}
+
+func TestQuoteOrderConvertsToOrderCurrency(t *testing.T) {
+	eur := func(amount int64) money.Money { return money.New(amount, "EUR") }
+	quote, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{
+		Lines:          []LineRequest{{Ref: "mouse-01", Quantity: 2, ExpectedUnitPrice: eur(1200)}},
+		Promotion:      &Promotion{Code: "FIVE", Kind: PromotionFixed, AmountOff: usd(500), MinSubtotal: usd(3000)},
+		Destination:    tax.Address{Country: "US"},
+		ShippingMethod: "standard",
+		Currency:       "EUR",
+	})
+	if err != nil {
+		t.Fatalf("QuoteOrder: %v", err)
+	}
+	if quote.Currency != "EUR" || quote.Rate.String() != "1.25" {
+		t.Fatalf("quote in %s at %s, want EUR at 1.25", quote.Currency, quote.Rate)
+	}
+	// 2 x 16.00 less 2 x 4.00 sale and a 4.00 coupon, plus 4.00 shipping.
+	if !quote.Subtotal.Equal(eur(3200)) || !quote.DiscountTotal.Equal(eur(1200)) || !quote.ShippingTotal.Equal(eur(400)) {
+		t.Fatalf("subtotal %v, discount %v, shipping %v", quote.Subtotal, quote.DiscountTotal, quote.ShippingTotal)
+	}
+	if !quote.Total.Equal(eur(2400)) || !quote.BaseTotal.Equal(usd(3000)) {
+		t.Fatalf("total %v, base total %v, want 24.00 EUR and 30.00 USD", quote.Total, quote.BaseTotal)
+	}
+}
+
+func TestQuoteOrderRejectsCurrencyWithoutRate(t *testing.T) {
+	_, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{
+		Lines:    []LineRequest{{Ref: "mouse-01", Quantity: 1}},
+		Currency: "GBP",
+	})
+	if !errors.Is(err, exchange.ErrNoRate) {
+		t.Fatalf("err = %v, want ErrNoRate", err)
+	}
+}
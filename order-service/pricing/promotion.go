@@ -0,0 +1,134 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+
+	"online-store-microservice/pkg/money"
+)
+
+const (
+	PromotionPercent      = "percent"
+	PromotionFixed        = "fixed"
+	PromotionFreeShipping = "free_shipping"
+	PromotionBuyXGetY     = "buy_x_get_y"
+)
+
+const (
+	DiscountSourceSale   = "sale"
+	DiscountSourceCoupon = "coupon"
+)
+
+// bpsScale is the denominator of Promotion.PercentOffBps: 1000 is 10%.
+const bpsScale = 10_000
+
+var ErrPromotionNotApplicable = errors.New("coupon does not apply to this order")
+
+// Promotion is the pricing part of a coupon. Validity windows and usage
+// limits are checked by the caller before quoting.
+type Promotion struct {
+	Code          string
+	Kind          string
+	PercentOffBps int64
+	AmountOff     money.Money
+	BuyQuantity   int32
+	GetQuantity   int32
+	MinSubtotal   money.Money
+	// EligibleProducts lists product ids or SKUs; empty means every product.
+	EligibleProducts []string
+}
+
+// Discount is one entry of a quote's discount breakdown. Line is the index
+// of the quote line it belongs to.
+type Discount struct {
+	Source string
+	Code   string
+	Kind   string
+	Line   int
+	Amount money.Money
+}
+
+func (p *Promotion) eligible(line Line) bool {
+	if len(p.EligibleProducts) == 0 {
+		return true
+	}
+	for _, ref := range p.EligibleProducts {
+		if ref == line.ProductID || ref == line.SKU {
+			return true
+		}
+	}
+	return false
+}
+
+// apply adds the promotion's discount to the eligible lines of q. Order-wide
+// discounts are spread over the lines in proportion to their totals so that
+// every line keeps an exact net amount, which returns are refunded from.
+func (p *Promotion) apply(q *Quote) error {
+	net, _ := q.Subtotal.Sub(q.DiscountTotal)
+	if net.Amount < p.MinSubtotal.Amount {
+		return fmt.Errorf("%w: subtotal must be at least %s", ErrPromotionNotApplicable, p.MinSubtotal)
+	}
+
+	var lines []int
+	var eligible int64
+	for i, line := range q.Lines {
+		if p.eligible(line) {
+			lines = append(lines, i)
+			eligible += line.LineTotal.Amount
+		}
+	}
+	if len(lines) == 0 {
+		return fmt.Errorf("%w: no eligible products", ErrPromotionNotApplicable)
+	}
+
+	switch p.Kind {
+	case PromotionPercent:
+		p.spread(q, lines, eligible, money.New(eligible, q.Currency).MulRatio(p.PercentOffBps, bpsScale).Amount)
+	case PromotionFixed:
+		if p.AmountOff.Currency != q.Currency {
+			return fmt.Errorf("%w: coupon is in %s", ErrPromotionNotApplicable, p.AmountOff.Currency)
+		}
+		p.spread(q, lines, eligible, min(p.AmountOff.Amount, eligible))
+	case PromotionBuyXGetY:
+		group := p.BuyQuantity + p.GetQuantity
+		for _, i := range lines {
+			free := q.Lines[i].Quantity / group * p.GetQuantity
+			if free > 0 {
+				p.discountLine(q, i, q.Lines[i].LineTotal.MulRatio(int64(free), int64(q.Lines[i].Quantity)).Amount)
+			}
+		}
+	case PromotionFreeShipping:
+		q.FreeShipping = true
+		q.Discounts = append(q.Discounts, Discount{Source: DiscountSourceCoupon, Code: p.Code, Kind: p.Kind, Line: -1, Amount: money.New(0, q.Currency)})
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrPromotionNotApplicable, p.Kind)
+	}
+	return nil
+}
+
+// spread splits amount over lines by their share of total, giving rounding
+// leftovers to the last line.
+func (p *Promotion) spread(q *Quote, lines []int, total, amount int64) {
+	left := amount
+	for n, i := range lines {
+		share := left
+		if n < len(lines)-1 {
+			share = money.New(amount, q.Currency).MulRatio(q.Lines[i].LineTotal.Amount, total).Amount
+		}
+		share = min(share, q.Lines[i].LineTotal.Amount, left)
+		left -= share
+		p.discountLine(q, i, share)
+	}
+}
+
+func (p *Promotion) discountLine(q *Quote, i int, amount int64) {
+	if amount <= 0 {
+		return
+	}
+	d := money.New(amount, q.Currency)
+	line := &q.Lines[i]
+	line.Discount, _ = line.Discount.Add(d)
+	line.LineTotal, _ = line.LineTotal.Sub(d)
+	q.DiscountTotal, _ = q.DiscountTotal.Add(d)
+	q.Discounts = append(q.Discounts, Discount{Source: DiscountSourceCoupon, Code: p.Code, Kind: p.Kind, Line: i, Amount: d})
+}
@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestQuoteOrderWithPromotion(t *testing.T) {
+	lines := []LineRequest{{Ref: "laptop-14", Quantity: 1}, {Ref: "MS-01-BLK", Quantity: 3}}
+	cases := []struct {
+		name      string
+		promo     Promotion
+		discounts []int64
+		total     int64
+	}{
+		{
+			name:      "percent spread over lines",
+			promo:     Promotion{Code: "TEN", Kind: PromotionPercent, PercentOffBps: 1000},
+			discounts: []int64{10000, 450},
+			total:     94050,
+		},
+		{
+			name:      "fixed capped at eligible lines",
+			promo:     Promotion{Code: "MOUSE50", Kind: PromotionFixed, AmountOff: usd(5000), EligibleProducts: []string{"mouse-01"}},
+			discounts: []int64{0, 4500},
+			total:     100000,
+		},
+		{
+			name:      "buy two get one",
+			promo:     Promotion{Code: "B2G1", Kind: PromotionBuyXGetY, BuyQuantity: 2, GetQuantity: 1, EligibleProducts: []string{"MS-01-BLK"}},
+			discounts: []int64{0, 1500},
+			total:     103000,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{Lines: lines, Promotion: &tc.promo})
+			if err != nil {
+				t.Fatalf("QuoteOrder: %v", err)
+			}
+			got := make([]int64, len(quote.Lines))
+			for _, d := range quote.Discounts {
+				if d.Source == DiscountSourceCoupon {
+					got[d.Line] += d.Amount.Amount
+				}
+			}
+			for i := range tc.discounts {
+				if got[i] != tc.discounts[i] {
+					t.Errorf("line %d coupon discount = %d, want %d", i, got[i], tc.discounts[i])
+				}
+			}
+			if !quote.Total.Equal(usd(tc.total)) {
+				t.Errorf("total = %v, want %v", quote.Total, usd(tc.total))
+			}
+		})
+	}
+}
+
+func TestQuoteOrderWithPromotionChecksMinimumSubtotal(t *testing.T) {
+	promo := &Promotion{Code: "BIG", Kind: PromotionPercent, PercentOffBps: 500, MinSubtotal: usd(200000)}
+	_, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{Lines: []LineRequest{{Ref: "laptop-14", Quantity: 1}}, Promotion: promo})
+	if !errors.Is(err, ErrPromotionNotApplicable) {
+		t.Fatalf("err = %v, want ErrPromotionNotApplicable", err)
+	}
+}
+
+func TestQuoteWithFreeShipping(t *testing.T) {
+	promo := &Promotion{Code: "SHIPFREE", Kind: PromotionFreeShipping}
+	quote, err := testPricer(0).QuoteOrder(context.Background(), QuoteRequest{Lines: []LineRequest{{Ref: "laptop-14", Quantity: 1}}, Promotion: promo})
+	if err != nil {
+		t.Fatalf("QuoteOrder: %v", err)
+	}
+	if !quote.FreeShipping || !quote.Total.Equal(usd(100000)) {
+		t.Fatalf("free shipping = %v, total = %v", quote.FreeShipping, quote.Total)
+	}
+}
@@ -0,0 +1,151 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/pkg/money"
+)
+
+type Price struct {
+	ProductID string
+	SKU       string
+	Name      string
+	UnitPrice money.Money
+	SalePrice money.Money
+	// TaxClass selects the tax rate; empty means tax.StandardClass.
+	TaxClass string
+	// WeightGrams is the shipping weight of one unit.
+	WeightGrams int64
+}
+
+func (p Price) EffectiveUnitPrice() money.Money {
+	if p.SalePrice.Amount > 0 && p.SalePrice.Amount < p.UnitPrice.Amount {
+		return p.SalePrice
+	}
+	return p.UnitPrice
+}
+
+func newPrice(productID, sku, name, taxClass, currency, unitPrice string, salePrice *string) (Price, error) {
+	price := Price{ProductID: productID, SKU: sku, Name: name, TaxClass: taxClass}
+	var err error
+	if price.UnitPrice, err = money.Parse(unitPrice, currency); err != nil {
+		return Price{}, fmt.Errorf("price of %s: %w", productID, err)
+	}
+	if salePrice != nil {
+		if price.SalePrice, err = money.Parse(*salePrice, currency); err != nil {
+			return Price{}, fmt.Errorf("sale price of %s: %w", productID, err)
+		}
+	}
+	return price, nil
+}
+
+type PriceProvider interface {
+	Lookup(ctx context.Context, refs []string) (map[string]Price, error)
+}
+
+type postgresProvider struct {
+	db *gorm.DB
+}
+
+func NewPostgresProvider(db *gorm.DB) PriceProvider {
+	return &postgresProvider{db: db}
+}
+
+func (p *postgresProvider) Lookup(ctx context.Context, refs []string) (map[string]Price, error) {
+	var rows []models.ProductPrice
+	err := p.db.WithContext(ctx).
+		Where("active = ? AND (product_id IN ? OR sku IN ?)", true, refs, refs).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]Price, len(rows)*2)
+	for _, row := range rows {
+		price, err := newPrice(row.ProductID, row.SKU, row.Name, row.TaxClass, row.Currency, row.UnitPrice, row.SalePrice)
+		if err != nil {
+			return nil, err
+		}
+		price.WeightGrams = row.WeightGrams
+		prices[row.ProductID] = price
+		prices[row.SKU] = price
+	}
+	return prices, nil
+}
+
+type staticProvider struct {
+	prices map[string]Price
+}
+
+func NewStaticProvider(prices []Price) PriceProvider {
+	index := make(map[string]Price, len(prices)*2)
+	for _, price := range prices {
+		if price.ProductID != "" {
+			index[price.ProductID] = price
+		}
+		if price.SKU != "" {
+			index[price.SKU] = price
+		}
+	}
+	return &staticProvider{prices: index}
+}
+
+type priceFileEntry struct {
+	ProductID   string       `json:"product_id"`
+	SKU         string       `json:"sku"`
+	Name        string       `json:"name"`
+	Currency    string       `json:"currency"`
+	UnitPrice   json.Number  `json:"unit_price"`
+	SalePrice   *json.Number `json:"sale_price,omitempty"`
+	TaxClass    string       `json:"tax_class,omitempty"`
+	WeightGrams int64        `json:"weight_grams,omitempty"`
+}
+
+// LoadStaticProvider reads a JSON price list. Prices are decimal numbers in
+// major units; entries without a currency use defaultCurrency.
+func LoadStaticProvider(path, defaultCurrency string) (PriceProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read price file: %w", err)
+	}
+	var entries []priceFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse price file: %w", err)
+	}
+
+	prices := make([]Price, 0, len(entries))
+	for _, e := range entries {
+		currency := e.Currency
+		if currency == "" {
+			currency = defaultCurrency
+		}
+		var sale *string
+		if e.SalePrice != nil {
+			v := e.SalePrice.String()
+			sale = &v
+		}
+		price, err := newPrice(e.ProductID, e.SKU, e.Name, e.TaxClass, currency, e.UnitPrice.String(), sale)
+		if err != nil {
+			return nil, fmt.Errorf("parse price file: %w", err)
+		}
+		price.WeightGrams = e.WeightGrams
+		prices = append(prices, price)
+	}
+	return NewStaticProvider(prices), nil
+}
+
+func (p *staticProvider) Lookup(_ context.Context, refs []string) (map[string]Price, error) {
+	prices := make(map[string]Price, len(refs))
+	for _, ref := range refs {
+		if price, ok := p.prices[ref]; ok {
+			prices[ref] = price
+		}
+	}
+	return prices, nil
+}
@@ -0,0 +1,61 @@
+package refund
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/pkg/money"
+)
+
+var ErrRefundFailed = errors.New("refund gateway rejected the refund")
+
+type Request struct {
+	// Key identifies the refund; gateways must return the original result
+	// when the same key is sent again so that retries never pay out twice.
+	Key     string
+	OrderID string
+	Amount  money.Money
+	Reason  string
+}
+
+type Result struct {
+	Reference string
+	Amount    money.Money
+}
+
+type Gateway interface {
+	Refund(ctx context.Context, req Request) (Result, error)
+}
+
+// FakeGateway settles every refund immediately and only logs it. It is meant
+// for local development and tests.
+type FakeGateway struct {
+	logger *log.Logger
+
+	mu      sync.Mutex
+	refunds map[string]Result
+}
+
+func NewFakeGateway(logger *log.Logger) *FakeGateway {
+	return &FakeGateway{logger: logger, refunds: make(map[string]Result)}
+}
+
+func (g *FakeGateway) Refund(_ context.Context, req Request) (Result, error) {
+	if req.Amount.Amount <= 0 {
+		return Result{}, ErrRefundFailed
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if res, ok := g.refunds[req.Key]; ok {
+		return res, nil
+	}
+	res := Result{Reference: "fake-" + uuid.NewString(), Amount: req.Amount}
+	g.refunds[req.Key] = res
+	g.logger.Printf("fake refund order_id=%s key=%s amount=%s reference=%s", req.OrderID, req.Key, req.Amount, res.Reference)
+	return res, nil
+}
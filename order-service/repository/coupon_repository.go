@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/models"
+)
+
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *models.Coupon) error
+	Update(ctx context.Context, coupon *models.Coupon) error
+	GetByCode(ctx context.Context, code string) (*models.Coupon, error)
+	List(ctx context.Context, includeInactive bool) ([]models.Coupon, error)
+}
+
+var (
+	ErrCouponNotFound  = errors.New("coupon not found")
+	ErrCouponExists    = errors.New("coupon code already exists")
+	ErrCouponExhausted = errors.New("coupon has no redemptions left")
+	ErrCouponUserLimit = errors.New("coupon was already used the maximum number of times by this user")
+)
+
+// couponRuleColumns are the columns an admin may change after creation;
+// the redemption counter is only ever moved by redeemCoupon.
+var couponRuleColumns = []string{
+	"description", "kind", "percent_off_bps", "currency", "amount_off", "buy_quantity", "get_quantity",
+	"min_subtotal", "starts_at", "ends_at", "max_redemptions", "max_per_user", "eligible_products", "active", "updated_at",
+}
+
+type couponRepository struct {
+	db *gorm.DB
+}
+
+func NewCouponRepository(db *gorm.DB) CouponRepository {
+	return &couponRepository{db: db}
+}
+
+func (r *couponRepository) Create(ctx context.Context, coupon *models.Coupon) error {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(coupon)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCouponExists
+	}
+	return nil
+}
+
+func (r *couponRepository) Update(ctx context.Context, coupon *models.Coupon) error {
+	res := r.db.WithContext(ctx).Model(&models.Coupon{}).
+		Where("code = ?", coupon.Code).
+		Select(couponRuleColumns).
+		Updates(coupon)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCouponNotFound
+	}
+	return r.db.WithContext(ctx).Where("code = ?", coupon.Code).First(coupon).Error
+}
+
+func (r *couponRepository) GetByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	err := r.db.WithContext(ctx).Where("code = ?", code).First(&coupon).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCouponNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *couponRepository) List(ctx context.Context, includeInactive bool) ([]models.Coupon, error) {
+	q := r.db.WithContext(ctx).Order("code ASC")
+	if !includeInactive {
+		q = q.Where("active = ?", true)
+	}
+	var coupons []models.Coupon
+	if err := q.Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// redeemCoupon counts a redemption of the order's coupon inside the order's
+// transaction. Incrementing the counter locks the coupon row until commit, so
+// concurrent checkouts with the same code see each other's redemptions and
+// neither the total nor the per-user limit can be overrun.
+func redeemCoupon(tx *gorm.DB, order *models.Order) error {
+	now := time.Now().UTC()
+	res := tx.Model(&models.Coupon{}).
+		Where("code = ? AND active AND (max_redemptions = 0 OR redemptions < max_redemptions)", order.CouponCode).
+		Where("(starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?)", now, now).
+		Update("redemptions", gorm.Expr("redemptions + 1"))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCouponExhausted
+	}
+
+	var coupon models.Coupon
+	if err := tx.Select("max_per_user").Where("code = ?", order.CouponCode).First(&coupon).Error; err != nil {
+		return err
+	}
+	if coupon.MaxPerUser > 0 {
+		var used int64
+		err := tx.Model(&models.CouponRedemption{}).
+			Where("coupon_code = ? AND user_id = ?", order.CouponCode, order.UserID).
+			Count(&used).Error
+		if err != nil {
+			return err
+		}
+		if used >= int64(coupon.MaxPerUser) {
+			return ErrCouponUserLimit
+		}
+	}
+
+	return tx.Create(&models.CouponRedemption{
+		ID:         uuid.NewString(),
+		CouponCode: order.CouponCode,
+		UserID:     order.UserID,
+		OrderID:    order.ID,
+		CreatedAt:  now,
+	}).Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/models"
+)
+
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+
+type IdempotencyRepository interface {
+	// Run claims record's key for the configured TTL and calls fn in the same transaction, storing
+	// the response it returns. If the key was already used with the same
+	// fingerprint the stored response is returned and fn is not called. A
+	// concurrent request with the same key blocks on the key row until the
+	// first one commits or rolls back.
+	Run(ctx context.Context, record *models.IdempotencyKey, fn func(orders OrderRepository) ([]byte, error)) ([]byte, error)
+}
+
+type idempotencyRepository struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+func NewIdempotencyRepository(db *gorm.DB, ttl time.Duration) IdempotencyRepository {
+	return &idempotencyRepository{db: db, ttl: ttl}
+}
+
+func (r *idempotencyRepository) Run(ctx context.Context, record *models.IdempotencyKey, fn func(orders OrderRepository) ([]byte, error)) ([]byte, error) {
+	now := time.Now().UTC()
+	record.CreatedAt = now
+	record.ExpiresAt = now.Add(r.ttl)
+
+	var response []byte
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("scope = ? AND key = ? AND expires_at < ?", record.Scope, record.Key, now).
+			Delete(&models.IdempotencyKey{}).Error
+		if err != nil {
+			return err
+		}
+
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			var existing models.IdempotencyKey
+			if err := tx.Where("scope = ? AND key = ?", record.Scope, record.Key).First(&existing).Error; err != nil {
+				return err
+			}
+			if existing.Fingerprint != record.Fingerprint {
+				return ErrIdempotencyKeyReused
+			}
+			response = existing.Response
+			return nil
+		}
+
+		response, err = fn(&orderRepository{db: tx})
+		if err != nil {
+			return err
+		}
+		return tx.Model(record).Update("response", response).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
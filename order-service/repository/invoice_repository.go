@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/invoice"
+	"online-store-microservice/order-service/models"
+)
+
+type InvoiceRepository interface {
+	Issue(ctx context.Context, inv *models.Invoice, series string) (*models.Invoice, error)
+	Reissue(ctx context.Context, replaced, credit, inv *models.Invoice, creditSeries, series string) (*models.Invoice, error)
+	ListByOrder(ctx context.Context, orderID string) ([]models.Invoice, error)
+}
+
+var ErrInvoiceNotFound = errors.New("invoice not found")
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+// Issue numbers inv in series for the year it is issued and stores it. The
+// counter is bumped in the same transaction as the insert, so its row lock
+// makes concurrent issuers wait for each other and a rolled back issue gives
+// its number back: the numbers of a year have no gaps.
+//
+// The order row is locked first, which makes issuing idempotent: when the
+// order already has its invoice, or the return its credit note, that one is
+// returned instead.
+func (r *invoiceRepository) Issue(ctx context.Context, inv *models.Invoice, series string) (*models.Invoice, error) {
+	issued := inv
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOrder(tx, inv.OrderID); err != nil {
+			return err
+		}
+
+		var found []models.Invoice
+		existing := tx.Where("order_id = ? AND kind = ?", inv.OrderID, inv.Kind)
+		if inv.ReturnID != nil {
+			existing = existing.Where("return_id = ?", *inv.ReturnID)
+		}
+		if inv.Kind == invoice.KindInvoice {
+			existing = current(existing)
+		}
+		if err := existing.Limit(1).Find(&found).Error; err != nil {
+			return err
+		}
+		if len(found) > 0 {
+			issued = &found[0]
+			return nil
+		}
+		return create(tx, inv, series)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+// Reissue numbers and stores credit, the credit note cancelling replaced, and
+// inv, the invoice taking its place, in one transaction. When replaced is no longer
+// the order's current invoice, the one that is is returned and nothing is
+// stored.
+func (r *invoiceRepository) Reissue(ctx context.Context, replaced, credit, inv *models.Invoice, creditSeries, series string) (*models.Invoice, error) {
+	issued := inv
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockOrder(tx, replaced.OrderID); err != nil {
+			return err
+		}
+
+		var found []models.Invoice
+		err := current(tx.Where("order_id = ? AND kind = ?", replaced.OrderID, invoice.KindInvoice)).Limit(1).Find(&found).Error
+		if err != nil {
+			return err
+		}
+		if len(found) > 0 && found[0].ID != replaced.ID {
+			issued = &found[0]
+			return nil
+		}
+
+		credit.InvoiceID, inv.InvoiceID = &replaced.ID, &replaced.ID
+		if err := create(tx, credit, creditSeries); err != nil {
+			return err
+		}
+		return create(tx, inv, series)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return issued, nil
+}
+
+func lockOrder(tx *gorm.DB, orderID string) error {
+	var order models.Order
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", orderID).First(&order).Error
+}
+
+// current narrows invoices down to those no later invoice replaces.
+func current(invoices *gorm.DB) *gorm.DB {
+	return invoices.Where("NOT EXISTS (SELECT 1 FROM invoices r WHERE r.invoice_id = invoices.id AND r.kind = ?)", invoice.KindInvoice)
+}
+
+// create numbers inv in series for the year it is issued and stores it.
+func create(tx *gorm.DB, inv *models.Invoice, series string) error {
+	inv.Year = inv.IssuedAt.UTC().Year()
+	err := tx.Raw(`INSERT INTO invoice_sequences (series, year, last_number) VALUES (?, ?, 1)
+		ON CONFLICT (series, year) DO UPDATE SET last_number = invoice_sequences.last_number + 1
+		RETURNING last_number`, series, inv.Year).Scan(&inv.Sequence).Error
+	if err != nil {
+		return err
+	}
+	inv.Number = invoice.Number(series, inv.Year, inv.Sequence)
+	return tx.Create(inv).Error
+}
+
+func (r *invoiceRepository) ListByOrder(ctx context.Context, orderID string) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("issued_at ASC, number ASC").Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper makes user input match literally in a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	// SortByTotal sorts by the total in the store currency, so orders in
+	// different currencies compare by what they are worth.
+	SortByTotal SortField = "base_total_amount"
+)
+
+type OrderSort struct {
+	Field SortField
+	Desc  bool
+}
+
+// Cursor is the sort key of the last row of the previous page. Rows are
+// ordered by (sort field, id) so the key is unique even when the sort field
+// ties.
+type Cursor struct {
+	CreatedAt       time.Time
+	BaseTotalAmount int64
+	ID              string
+}
+
+type OrderFilter struct {
+	UserID      string
+	ID          string
+	Statuses    []string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// MinTotal and MaxTotal bound the total in the store currency.
+	MinTotal *int64
+	MaxTotal *int64
+	Sort     OrderSort
+	After    *Cursor
+	Limit    int
+	// Number matches the order with this order number.
+	Number string
+	// ProductName matches a substring of the order's product name or of any
+	// of its item names, ignoring case.
+	ProductName string
+}
+
+func (f OrderFilter) apply(q *gorm.DB) *gorm.DB {
+	if f.UserID != "" {
+		q = q.Where("user_id = ?", f.UserID)
+	}
+	if f.ID != "" {
+		q = q.Where("id = ?", f.ID)
+	}
+	if f.Number != "" {
+		q = q.Where("number = ?", f.Number)
+	}
+	if f.ProductName != "" {
+		pattern := "%" + likeEscaper.Replace(f.ProductName) + "%"
+		q = q.Where("(product_name ILIKE ? OR EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = orders.id AND i.name ILIKE ?))", pattern, pattern)
+	}
+	if len(f.Statuses) > 0 {
+		q = q.Where("status IN ?", f.Statuses)
+	}
+	if f.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		q = q.Where("created_at < ?", *f.CreatedTo)
+	}
+	if f.MinTotal != nil {
+		q = q.Where("base_total_amount >= ?", *f.MinTotal)
+	}
+	if f.MaxTotal != nil {
+		q = q.Where("base_total_amount <= ?", *f.MaxTotal)
+	}
+
+	field := f.Sort.Field
+	if field == "" {
+		field = SortByCreatedAt
+	}
+	op, dir := ">", "ASC"
+	if f.Sort.Desc {
+		op, dir = "<", "DESC"
+	}
+	if f.After != nil {
+		var key any = f.After.CreatedAt
+		if field == SortByTotal {
+			key = f.After.BaseTotalAmount
+		}
+		q = q.Where(fmt.Sprintf("(%s, id) %s (?, ?)", field, op), key, f.After.ID)
+	}
+	q = q.Order(fmt.Sprintf("%s %s, id %s", field, dir, dir))
+	if f.Limit > 0 {
+		q = q.Limit(f.Limit)
+	}
+	return q
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/models"
+)
+
+var (
+	ErrImportNotFound = errors.New("import not found")
+	ErrImportConflict = errors.New("import was advanced by another run")
+)
+
+// ImportRow is one row of an import batch. A row that already has an Err,
+// or has no Order, is only counted as failed.
+type ImportRow struct {
+	Line  int64
+	Order *models.Order
+	Err   error
+}
+
+type OrderImportRepository interface {
+	// Start stores imp, or returns the stored import with the same id marked
+	// as running again so it can be resumed.
+	Start(ctx context.Context, imp *models.OrderImport) (*models.OrderImport, error)
+	Get(ctx context.Context, id string) (*models.OrderImport, error)
+	// CommitBatch creates the orders of rows and moves the import's
+	// committed row to the last of them, in one transaction. It fails with
+	// ErrImportConflict unless the import was at after, which stops two runs
+	// of the same import from inserting a batch twice. Every order is created
+	// under a savepoint: when its coupon cannot be redeemed only that row
+	// fails, with its Err set.
+	CommitBatch(ctx context.Context, importID string, after int64, rows []ImportRow, meta ChangeMeta) error
+	Finish(ctx context.Context, id, status string) error
+}
+
+type orderImportRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderImportRepository(db *gorm.DB) OrderImportRepository {
+	return &orderImportRepository{db: db}
+}
+
+func (r *orderImportRepository) Start(ctx context.Context, imp *models.OrderImport) (*models.OrderImport, error) {
+	now := time.Now().UTC()
+	imp.Status = models.OrderImportRunning
+	imp.CreatedAt = now
+	imp.UpdatedAt = now
+
+	stored := imp
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(imp)
+		if res.Error != nil || res.RowsAffected == 1 {
+			return res.Error
+		}
+
+		var existing models.OrderImport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", imp.ID).First(&existing).Error; err != nil {
+			return err
+		}
+		existing.Status = models.OrderImportRunning
+		existing.UpdatedAt = now
+		stored = &existing
+		return tx.Model(&existing).Updates(map[string]interface{}{"status": existing.Status, "updated_at": now}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (r *orderImportRepository) Get(ctx context.Context, id string) (*models.OrderImport, error) {
+	var imp models.OrderImport
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&imp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrImportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (r *orderImportRepository) CommitBatch(ctx context.Context, importID string, after int64, rows []ImportRow, meta ChangeMeta) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var imp models.OrderImport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", importID).First(&imp).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrImportNotFound
+			}
+			return err
+		}
+		if imp.CommittedRow != after {
+			return ErrImportConflict
+		}
+
+		orders := &orderRepository{db: tx}
+		var imported, failed int64
+		for i := range rows {
+			row := &rows[i]
+			if row.Err == nil && row.Order != nil {
+				err := orders.Create(ctx, row.Order, meta)
+				if errors.Is(err, ErrCouponExhausted) || errors.Is(err, ErrCouponUserLimit) {
+					row.Err = err
+				} else if err != nil {
+					return err
+				}
+			}
+			if row.Err != nil || row.Order == nil {
+				failed++
+			} else {
+				imported++
+			}
+		}
+
+		return tx.Model(&imp).Updates(map[string]interface{}{
+			"committed_row": rows[len(rows)-1].Line,
+			"rows_imported": gorm.Expr("rows_imported + ?", imported),
+			"rows_failed":   gorm.Expr("rows_failed + ?", failed),
+			"updated_at":    time.Now().UTC(),
+		}).Error
+	})
+}
+
+func (r *orderImportRepository) Finish(ctx context.Context, id, status string) error {
+	return r.db.WithContext(ctx).Model(&models.OrderImport{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": status, "updated_at": time.Now().UTC()}).Error
+}
@@ -2,44 +2,412 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"online-store-microservice/order-service/models"
 )
 
 type OrderRepository interface {
-	Create(ctx context.Context, order *models.Order) error
+	Create(ctx context.Context, order *models.Order, meta ChangeMeta) error
 	GetByID(ctx context.Context, id string) (*models.Order, error)
-	GetByUserID(ctx context.Context, userID string) ([]models.Order, error)
+	GetByNumber(ctx context.Context, number string) (*models.Order, error)
+	List(ctx context.Context, filter OrderFilter) ([]models.Order, error)
+	ReassignUser(ctx context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error)
+	UpdateStatus(ctx context.Context, order *models.Order, to string, meta ChangeMeta) error
+	Cancel(ctx context.Context, order *models.Order, meta ChangeMeta) error
+	Amend(ctx context.Context, order *models.Order, changes []models.OrderChange, meta ChangeMeta) error
+	History(ctx context.Context, orderID string) ([]models.OrderStatusChange, error)
+	ListUnverifiedUsers(ctx context.Context, limit int) ([]models.Order, error)
+	MarkUserVerified(ctx context.Context, orderID string) error
+	ExpireUnpaid(ctx context.Context, createdBefore time.Time, limit int, meta ChangeMeta) ([]models.Order, error)
+	Export(ctx context.Context, filter OrderFilter, batch int, fn func([]models.Order) error) error
+	Sales(ctx context.Context, filter SalesFilter) ([]SalesRow, error)
+}
+
+type SalesFilter struct {
+	BaseCurrency string
+	Statuses     []string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+}
+
+// SalesRow sums the orders in one currency that were priced at the same
+// exchange rate.
+type SalesRow struct {
+	Currency        string
+	ExchangeRate    string
+	Orders          int64
+	TotalAmount     int64
+	RefundedAmount  int64
+	BaseTotalAmount int64
+}
+
+var ErrStaleOrder = errors.New("order was modified concurrently")
+
+// ChangeMeta describes who changed an order and why. It is written to the
+// order's status history together with the change.
+type ChangeMeta struct {
+	Actor     string
+	Reason    string
+	RequestID string
+}
+
+func statusChange(orderID, from, to string, meta ChangeMeta, at time.Time) *models.OrderStatusChange {
+	return &models.OrderStatusChange{
+		ID:         uuid.NewString(),
+		OrderID:    orderID,
+		FromStatus: from,
+		ToStatus:   to,
+		Actor:      meta.Actor,
+		Reason:     meta.Reason,
+		RequestID:  meta.RequestID,
+		CreatedAt:  at,
+	}
 }
 
 type orderRepository struct {
 	db *gorm.DB
 }
 
+func orderItemsByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+func withLines(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", orderItemsByPosition).Preload("Discounts", orderItemsByPosition).Preload("TaxLines", orderItemsByPosition)
+}
+
 func NewOrderRepository(db *gorm.DB) OrderRepository {
 	return &orderRepository{db: db}
 }
 
-func (r *orderRepository) Create(ctx context.Context, order *models.Order) error {
-	return r.db.WithContext(ctx).Create(order).Error
+func (r *orderRepository) Create(ctx context.Context, order *models.Order, meta ChangeMeta) error {
+	if order.Version == 0 {
+		order.Version = 1
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(order).Error; err != nil {
+			return err
+		}
+		if order.CouponCode != "" {
+			if err := redeemCoupon(tx, order); err != nil {
+				return err
+			}
+		}
+		return tx.Create(statusChange(order.ID, "", order.Status, meta, order.CreatedAt)).Error
+	})
 }
 
 func (r *orderRepository) GetByID(ctx context.Context, id string) (*models.Order, error) {
 	var order models.Order
-	err := r.db.WithContext(ctx).Where("id = ?", id).First(&order).Error
+	err := withLines(r.db.WithContext(ctx)).Where("id = ?", id).First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *orderRepository) GetByNumber(ctx context.Context, number string) (*models.Order, error) {
+	var order models.Order
+	err := withLines(r.db.WithContext(ctx)).Where("number = ?", number).First(&order).Error
 	if err != nil {
 		return nil, err
 	}
 	return &order, nil
 }
 
-func (r *orderRepository) GetByUserID(ctx context.Context, userID string) ([]models.Order, error) {
+func (r *orderRepository) List(ctx context.Context, filter OrderFilter) ([]models.Order, error) {
+	var orders []models.Order
+	err := filter.apply(withLines(r.db.WithContext(ctx))).Find(&orders).Error
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// Export calls fn with every order matching filter, in its sort order, batch
+// orders at a time with their items. Its Limit and After are ignored. The
+// orders are read through a server-side cursor in a read-only transaction,
+// so memory use does not grow with the number of orders exported.
+func (r *orderRepository) Export(ctx context.Context, filter OrderFilter, batch int, fn func([]models.Order) error) error {
+	filter.Limit, filter.After = 0, nil
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stmt := filter.apply(tx.Session(&gorm.Session{DryRun: true}).Model(&models.Order{})).Find(&[]models.Order{}).Statement
+		_, err := tx.Statement.ConnPool.ExecContext(ctx, "DECLARE order_export NO SCROLL CURSOR FOR "+stmt.SQL.String(), stmt.Vars...)
+		if err != nil {
+			return err
+		}
+
+		fetch := fmt.Sprintf("FETCH FORWARD %d FROM order_export", batch)
+		for {
+			var orders []models.Order
+			if err := tx.Raw(fetch).Scan(&orders).Error; err != nil {
+				return err
+			}
+			if len(orders) == 0 {
+				return nil
+			}
+			if err := loadItems(tx, orders); err != nil {
+				return err
+			}
+			if err := fn(orders); err != nil {
+				return err
+			}
+			if len(orders) < batch {
+				return nil
+			}
+		}
+	}, &sql.TxOptions{ReadOnly: true})
+}
+
+// Sales groups the orders matching filter by currency and stored exchange
+// rate, so each group converts with the rate its orders were priced at.
+func (r *orderRepository) Sales(ctx context.Context, filter SalesFilter) ([]SalesRow, error) {
+	q := r.db.WithContext(ctx).Model(&models.Order{}).
+		Select("currency, exchange_rate, COUNT(*) AS orders, "+
+			"SUM(total_amount) AS total_amount, SUM(refunded_amount) AS refunded_amount, SUM(base_total_amount) AS base_total_amount").
+		Where("base_currency = ? AND status IN ?", filter.BaseCurrency, filter.Statuses)
+	if filter.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		q = q.Where("created_at < ?", *filter.CreatedTo)
+	}
+	var rows []SalesRow
+	err := q.Group("currency, exchange_rate").Order("currency, exchange_rate").Scan(&rows).Error
+	return rows, err
+}
+
+func loadItems(tx *gorm.DB, orders []models.Order) error {
+	ids := make([]string, len(orders))
+	byID := make(map[string]*models.Order, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].ID
+		byID[orders[i].ID] = &orders[i]
+	}
+	var items []models.OrderItem
+	if err := tx.Where("order_id IN ?", ids).Order("order_id, position").Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		order := byID[item.OrderID]
+		order.Items = append(order.Items, item)
+	}
+	return nil
+}
+
+func (r *orderRepository) ReassignUser(ctx context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		q := tx.Model(&models.Order{}).Where("user_id = ?", fromUserID)
+		if len(orderIDs) > 0 {
+			q = q.Where("id IN ?", orderIDs)
+		}
+		if !dryRun {
+			q = q.Clauses(clause.Locking{Strength: "UPDATE"})
+		}
+		if err := q.Order("created_at ASC").Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if dryRun || len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Order{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"user_id": toUserID, "version": gorm.Expr("version + 1"), "updated_at": time.Now().UTC()}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *orderRepository) UpdateStatus(ctx context.Context, order *models.Order, to string, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND version = ?", order.ID, order.Version).
+			Updates(map[string]interface{}{"status": to, "version": gorm.Expr("version + 1"), "updated_at": now})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleOrder
+		}
+		return tx.Create(statusChange(order.ID, order.Status, to, meta, now)).Error
+	})
+	if err != nil {
+		return err
+	}
+	order.Status = to
+	order.Version++
+	order.UpdatedAt = now
+	return nil
+}
+
+func (r *orderRepository) Cancel(ctx context.Context, order *models.Order, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Order{}).
+			Where("id = ? AND version = ?", order.ID, order.Version).
+			Updates(map[string]interface{}{
+				"status":              models.OrderStatusCancelled,
+				"version":             gorm.Expr("version + 1"),
+				"cancellation_reason": meta.Reason,
+				"cancelled_by":        meta.Actor,
+				"cancelled_at":        now,
+				"updated_at":          now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleOrder
+		}
+		return tx.Create(statusChange(order.ID, order.Status, models.OrderStatusCancelled, meta, now)).Error
+	})
+	if err != nil {
+		return err
+	}
+	order.Status = models.OrderStatusCancelled
+	order.CancellationReason = meta.Reason
+	order.CancelledBy = meta.Actor
+	order.CancelledAt = &now
+	order.Version++
+	order.UpdatedAt = now
+	return nil
+}
+
+// amendedColumns are the order columns an amendment may change.
+var amendedColumns = []string{
+	"product_name", "quantity", "subtotal_amount", "discount_amount", "tax_amount", "total_amount",
+	"base_currency", "exchange_rate", "exchange_rate_from", "base_total_amount", "free_shipping",
+	"shipping_address", "shipping_method", "shipping_carrier", "shipping_zone", "shipping_amount",
+	"tax_rules_version", "prices_include_tax", "tax_exempt", "tax_exempt_amount",
+	"subtotal", "discount_total", "tax_total", "total_price", "version", "updated_at",
+}
+
+// Amend stores a re-priced order in place of the one at order.Version. Its
+// items, discounts and tax lines replace the stored ones, and changes is
+// written to the history with the order's status unchanged.
+func (r *orderRepository) Amend(ctx context.Context, order *models.Order, changes []models.OrderChange, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	next := *order
+	next.Version++
+	next.UpdatedAt = now
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&next).Where("version = ?", order.Version).Select(amendedColumns).Updates(&next)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleOrder
+		}
+		for _, model := range []interface{}{&models.OrderDiscount{}, &models.OrderTaxLine{}, &models.OrderItem{}} {
+			if err := tx.Where("order_id = ?", order.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&next.Items).Error; err != nil {
+			return err
+		}
+		if len(next.Discounts) > 0 {
+			if err := tx.Create(&next.Discounts).Error; err != nil {
+				return err
+			}
+		}
+		if len(next.TaxLines) > 0 {
+			if err := tx.Create(&next.TaxLines).Error; err != nil {
+				return err
+			}
+		}
+		change := statusChange(order.ID, order.Status, order.Status, meta, now)
+		change.Changes = changes
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return err
+	}
+	order.Version = next.Version
+	order.UpdatedAt = now
+	return nil
+}
+
+func (r *orderRepository) ListUnverifiedUsers(ctx context.Context, limit int) ([]models.Order, error) {
 	var orders []models.Order
-	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&orders).Error
+	err := r.db.WithContext(ctx).
+		Where("user_verified_at IS NULL AND status <> ?", models.OrderStatusCancelled).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&orders).Error
 	if err != nil {
 		return nil, err
 	}
 	return orders, nil
 }
+
+func (r *orderRepository) MarkUserVerified(ctx context.Context, orderID string) error {
+	return r.db.WithContext(ctx).Model(&models.Order{}).
+		Where("id = ? AND user_verified_at IS NULL", orderID).
+		Updates(map[string]interface{}{"user_verified_at": time.Now().UTC(), "version": gorm.Expr("version + 1")}).Error
+}
+
+// ExpireUnpaid moves up to limit pending or awaiting_payment orders created
+// before createdBefore to expired, oldest first, and returns them with their
+// lines. The rows are claimed with FOR UPDATE SKIP LOCKED, so replicas running
+// it at the same time each take a different batch instead of waiting on one
+// another, and rows locked by a concurrent payment or cancel are left alone.
+func (r *orderRepository) ExpireUnpaid(ctx context.Context, createdBefore time.Time, limit int, meta ChangeMeta) ([]models.Order, error) {
+	now := time.Now().UTC()
+	var orders []models.Order
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := withLines(tx).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND created_at < ?", []string{models.OrderStatusPending, models.OrderStatusAwaitingPayment}, createdBefore).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&orders).Error
+		if err != nil || len(orders) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(orders))
+		changes := make([]*models.OrderStatusChange, len(orders))
+		for i, order := range orders {
+			ids[i] = order.ID
+			changes[i] = statusChange(order.ID, order.Status, models.OrderStatusExpired, meta, now)
+		}
+		err = tx.Model(&models.Order{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.OrderStatusExpired, "version": gorm.Expr("version + 1"), "updated_at": now}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&changes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		orders[i].Status = models.OrderStatusExpired
+		orders[i].Version++
+		orders[i].UpdatedAt = now
+	}
+	return orders, nil
+}
+
+func (r *orderRepository) History(ctx context.Context, orderID string) ([]models.OrderStatusChange, error) {
+	var changes []models.OrderStatusChange
+	err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
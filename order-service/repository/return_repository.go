@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/models"
+)
+
+type ReturnRepository interface {
+	Create(ctx context.Context, ret *models.OrderReturn) error
+	GetByID(ctx context.Context, id string) (*models.OrderReturn, error)
+	ListByOrder(ctx context.Context, orderID string) ([]models.OrderReturn, error)
+	UpdateStatus(ctx context.Context, ret *models.OrderReturn, to, actor, note string) error
+	Refund(ctx context.Context, ret *models.OrderReturn, order *models.Order, refund Refund, meta ChangeMeta) error
+}
+
+var (
+	ErrReturnNotFound         = errors.New("return not found")
+	ErrStaleReturn            = errors.New("return was modified concurrently")
+	ErrReturnQuantityExceeded = errors.New("return quantity exceeds the quantity still returnable")
+)
+
+// Refund is a payout that the refund gateway has already made for a return.
+// OrderStatus is the status the order ends up in; it differs from the current
+// one only when the refund covers what is left of the order total.
+type Refund struct {
+	Amount      int64
+	Reference   string
+	OrderStatus string
+}
+
+type returnRepository struct {
+	db *gorm.DB
+}
+
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+// Create stores a return after checking, with the order row locked, that no
+// item is returned more often than it was bought across all open returns.
+func (r *returnRepository) Create(ctx context.Context, ret *models.OrderReturn) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", ret.OrderID).First(&order).Error
+		if err != nil {
+			return err
+		}
+
+		var bought []models.OrderItem
+		if err := tx.Select("id", "quantity").Where("order_id = ?", ret.OrderID).Find(&bought).Error; err != nil {
+			return err
+		}
+		var returned []struct {
+			OrderItemID string
+			Quantity    int32
+		}
+		err = tx.Model(&models.OrderReturnItem{}).
+			Select("order_return_items.order_item_id, SUM(order_return_items.quantity) AS quantity").
+			Joins("JOIN order_returns ON order_returns.id = order_return_items.return_id").
+			Where("order_returns.order_id = ? AND order_returns.status <> ?", ret.OrderID, models.ReturnStatusRejected).
+			Group("order_return_items.order_item_id").
+			Scan(&returned).Error
+		if err != nil {
+			return err
+		}
+
+		left := make(map[string]int32, len(bought))
+		for _, item := range bought {
+			left[item.ID] = item.Quantity
+		}
+		for _, item := range returned {
+			left[item.OrderItemID] -= item.Quantity
+		}
+		for _, item := range ret.Items {
+			if item.Quantity > left[item.OrderItemID] {
+				return ErrReturnQuantityExceeded
+			}
+		}
+		return tx.Create(ret).Error
+	})
+}
+
+func (r *returnRepository) GetByID(ctx context.Context, id string) (*models.OrderReturn, error) {
+	var ret models.OrderReturn
+	err := r.db.WithContext(ctx).Preload("Items").Where("id = ?", id).First(&ret).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReturnNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ret, nil
+}
+
+func (r *returnRepository) ListByOrder(ctx context.Context, orderID string) ([]models.OrderReturn, error) {
+	var returns []models.OrderReturn
+	err := r.db.WithContext(ctx).Preload("Items").Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&returns).Error
+	if err != nil {
+		return nil, err
+	}
+	return returns, nil
+}
+
+func (r *returnRepository) UpdateStatus(ctx context.Context, ret *models.OrderReturn, to, actor, note string) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{"status": to, "updated_at": now}
+	switch to {
+	case models.ReturnStatusApproved, models.ReturnStatusRejected:
+		updates["decided_by"] = actor
+		updates["decision_note"] = note
+	case models.ReturnStatusReceived:
+		updates["received_at"] = now
+	}
+
+	res := r.db.WithContext(ctx).Model(&models.OrderReturn{}).
+		Where("id = ? AND status = ?", ret.ID, ret.Status).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStaleReturn
+	}
+
+	ret.Status = to
+	ret.UpdatedAt = now
+	switch to {
+	case models.ReturnStatusApproved, models.ReturnStatusRejected:
+		ret.DecidedBy = actor
+		ret.DecisionNote = note
+	case models.ReturnStatusReceived:
+		ret.ReceivedAt = &now
+	}
+	return nil
+}
+
+// Refund records a refund on the return and adds it to the order's refunded
+// amount in one transaction. The order update is conditioned on the version
+// the caller read, so two refunds for the same order cannot both count
+// against the same remaining total.
+func (r *returnRepository) Refund(ctx context.Context, ret *models.OrderReturn, order *models.Order, refund Refund, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.OrderReturn{}).
+			Where("id = ? AND status = ?", ret.ID, models.ReturnStatusReceived).
+			Updates(map[string]interface{}{
+				"status":           models.ReturnStatusRefunded,
+				"refunded_amount":  refund.Amount,
+				"refund_reference": refund.Reference,
+				"refunded_at":      now,
+				"updated_at":       now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleReturn
+		}
+
+		res = tx.Model(&models.Order{}).
+			Where("id = ? AND version = ?", order.ID, order.Version).
+			Updates(map[string]interface{}{
+				"refunded_amount": gorm.Expr("refunded_amount + ?", refund.Amount),
+				"status":          refund.OrderStatus,
+				"version":         gorm.Expr("version + 1"),
+				"updated_at":      now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleOrder
+		}
+		if refund.OrderStatus == order.Status {
+			return nil
+		}
+		return tx.Create(statusChange(order.ID, order.Status, refund.OrderStatus, meta, now)).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	ret.Status = models.ReturnStatusRefunded
+	ret.RefundedAmount = refund.Amount
+	ret.RefundReference = refund.Reference
+	ret.RefundedAt = &now
+	ret.UpdatedAt = now
+	order.RefundedAmount += refund.Amount
+	order.Status = refund.OrderStatus
+	order.Version++
+	order.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+)
+
+type ShipmentRepository interface {
+	Create(ctx context.Context, shipment *models.Shipment, order *models.Order, path []string, meta ChangeMeta) error
+	GetByID(ctx context.Context, id string) (*models.Shipment, error)
+	ListByOrder(ctx context.Context, orderID string) ([]models.Shipment, error)
+	UpdateStatus(ctx context.Context, shipment *models.Shipment, to string, order *models.Order, path []string, meta ChangeMeta) error
+}
+
+var (
+	ErrShipmentNotFound         = errors.New("shipment not found")
+	ErrStaleShipment            = errors.New("shipment was modified concurrently")
+	ErrShipmentQuantityExceeded = errors.New("shipment quantity exceeds the quantity still to ship")
+)
+
+type shipmentRepository struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepository(db *gorm.DB) ShipmentRepository {
+	return &shipmentRepository{db: db}
+}
+
+// Create stores a shipment and moves the order along path, one status at a
+// time. Every shipment bumps the order version, so two shipments prepared
+// from the same view of the order cannot both be stored and the caller's
+// decision to mark the order shipped always sees every earlier shipment.
+func (r *shipmentRepository) Create(ctx context.Context, shipment *models.Shipment, order *models.Order, path []string, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := advanceOrder(tx, order, path, meta, now); err != nil {
+			return err
+		}
+
+		var bought []models.OrderItem
+		if err := tx.Select("id", "quantity").Where("order_id = ?", order.ID).Find(&bought).Error; err != nil {
+			return err
+		}
+		var shipped []struct {
+			OrderItemID string
+			Quantity    int32
+		}
+		err := tx.Model(&models.ShipmentItem{}).
+			Select("shipment_items.order_item_id, SUM(shipment_items.quantity) AS quantity").
+			Joins("JOIN shipments ON shipments.id = shipment_items.shipment_id").
+			Where("shipments.order_id = ? AND shipments.status <> ?", order.ID, models.ShipmentStatusFailed).
+			Group("shipment_items.order_item_id").
+			Scan(&shipped).Error
+		if err != nil {
+			return err
+		}
+
+		left := make(map[string]int32, len(bought))
+		for _, item := range bought {
+			left[item.ID] = item.Quantity
+		}
+		for _, item := range shipped {
+			left[item.OrderItemID] -= item.Quantity
+		}
+		for _, item := range shipment.Items {
+			if item.Quantity > left[item.OrderItemID] {
+				return ErrShipmentQuantityExceeded
+			}
+		}
+		return tx.Create(shipment).Error
+	})
+	if err != nil {
+		return err
+	}
+	applyPath(order, path, now)
+	return nil
+}
+
+func (r *shipmentRepository) GetByID(ctx context.Context, id string) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.db.WithContext(ctx).Preload("Items").Where("id = ?", id).First(&shipment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShipmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *shipmentRepository) ListByOrder(ctx context.Context, orderID string) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.db.WithContext(ctx).Preload("Items").Where("order_id = ?", orderID).Order("created_at ASC, id ASC").Find(&shipments).Error
+	if err != nil {
+		return nil, err
+	}
+	return shipments, nil
+}
+
+// UpdateStatus records a carrier status on the shipment and moves the order
+// along path in the same transaction.
+func (r *shipmentRepository) UpdateStatus(ctx context.Context, shipment *models.Shipment, to string, order *models.Order, path []string, meta ChangeMeta) error {
+	now := time.Now().UTC()
+	updates := map[string]interface{}{"status": to, "updated_at": now}
+	if to == models.ShipmentStatusDelivered {
+		updates["delivered_at"] = now
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.Shipment{}).
+			Where("id = ? AND status = ?", shipment.ID, shipment.Status).
+			Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrStaleShipment
+		}
+		return advanceOrder(tx, order, path, meta, now)
+	})
+	if err != nil {
+		return err
+	}
+
+	shipment.Status = to
+	shipment.UpdatedAt = now
+	if to == models.ShipmentStatusDelivered {
+		shipment.DeliveredAt = &now
+	}
+	applyPath(order, path, now)
+	return nil
+}
+
+// advanceOrder bumps the order version, conditioned on the version the caller
+// read, and records one status change for every step of path.
+func advanceOrder(tx *gorm.DB, order *models.Order, path []string, meta ChangeMeta, now time.Time) error {
+	updates := map[string]interface{}{"version": gorm.Expr("version + 1"), "updated_at": now}
+	if len(path) > 0 {
+		updates["status"] = path[len(path)-1]
+	}
+	res := tx.Model(&models.Order{}).Where("id = ? AND version = ?", order.ID, order.Version).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrStaleOrder
+	}
+
+	// The steps are a microsecond apart so that the history, ordered by
+	// time, lists them in the order they were taken.
+	from := order.Status
+	for i, to := range path {
+		if err := tx.Create(statusChange(order.ID, from, to, meta, now.Add(time.Duration(i)*time.Microsecond))).Error; err != nil {
+			return err
+		}
+		from = to
+	}
+	return nil
+}
+
+func applyPath(order *models.Order, path []string, now time.Time) {
+	if len(path) > 0 {
+		order.Status = path[len(path)-1]
+	}
+	order.Version++
+	order.UpdatedAt = now
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"online-store-microservice/order-service/models"
+)
+
+type TaxExemptionRepository interface {
+	Get(ctx context.Context, userID string) (*models.TaxExemption, error)
+	Upsert(ctx context.Context, exemption *models.TaxExemption) error
+	Delete(ctx context.Context, userID string) error
+}
+
+var ErrTaxExemptionNotFound = errors.New("tax exemption not found")
+
+type taxExemptionRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxExemptionRepository(db *gorm.DB) TaxExemptionRepository {
+	return &taxExemptionRepository{db: db}
+}
+
+func (r *taxExemptionRepository) Get(ctx context.Context, userID string) (*models.TaxExemption, error) {
+	var exemption models.TaxExemption
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&exemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTaxExemptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &exemption, nil
+}
+
+// Upsert keeps the original created_at when an exemption is updated.
+func (r *taxExemptionRepository) Upsert(ctx context.Context, exemption *models.TaxExemption) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "certificate", "created_by", "updated_at"}),
+	}).Create(exemption).Error
+}
+
+func (r *taxExemptionRepository) Delete(ctx context.Context, userID string) error {
+	res := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.TaxExemption{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrTaxExemptionNotFound
+	}
+	return nil
+}
@@ -3,25 +3,62 @@ package server
 import (
 	"context"
 	"errors"
+	"io"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"gorm.io/gorm"
 
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
 	"online-store-microservice/order-service/service"
+	"online-store-microservice/order-service/shipping"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
 	orderpb "online-store-microservice/proto/order"
 )
 
+const maxIdempotencyKeyLength = 255
+
 type GRPCServer struct {
 	orderpb.UnimplementedOrderServiceServer
-	service service.OrderService
+	service   service.OrderService
+	returns   service.ReturnService
+	coupons   service.CouponService
+	taxes     service.TaxService
+	shipments service.ShipmentService
+	invoices  service.InvoiceService
+	search    service.OrderSearchService
+	imports   service.OrderImportService
+}
+
+func NewGRPCServer(svc service.OrderService, returns service.ReturnService, coupons service.CouponService, taxes service.TaxService, shipments service.ShipmentService, invoices service.InvoiceService, search service.OrderSearchService, imports service.OrderImportService) *GRPCServer {
+	return &GRPCServer{service: svc, returns: returns, coupons: coupons, taxes: taxes, shipments: shipments, invoices: invoices, search: search, imports: imports}
 }
 
-func NewGRPCServer(svc service.OrderService) *GRPCServer {
-	return &GRPCServer{service: svc}
+// withIdempotencyKey moves the idempotency key from the request metadata to
+// the context, where the order service looks for it.
+func withIdempotencyKey(ctx context.Context) (context.Context, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if keys := md.Get(orderpb.IdempotencyKeyMetadata); len(keys) > 0 && keys[0] != "" {
+			if len(keys[0]) > maxIdempotencyKeyLength {
+				return nil, status.Error(codes.InvalidArgument, "idempotency key is too long")
+			}
+			ctx = service.WithIdempotencyKey(ctx, keys[0])
+		}
+	}
+	return ctx, nil
 }
 
 func (s *GRPCServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	ctx, err := withIdempotencyKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := s.service.CreateOrder(ctx, req)
 	if err != nil {
 		return nil, mapError(err)
@@ -29,6 +66,27 @@ func (s *GRPCServer) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRe
 	return resp, nil
 }
 
+func (s *GRPCServer) Reorder(ctx context.Context, req *orderpb.ReorderRequest) (*orderpb.ReorderResponse, error) {
+	ctx, err := withIdempotencyKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.service.Reorder(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) AmendOrder(ctx context.Context, req *orderpb.AmendOrderRequest) (*orderpb.AmendOrderResponse, error) {
+	resp, err := s.service.AmendOrder(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
 func (s *GRPCServer) GetOrderById(ctx context.Context, req *orderpb.GetOrderByIdRequest) (*orderpb.GetOrderByIdResponse, error) {
 	resp, err := s.service.GetOrderByID(ctx, req)
 	if err != nil {
@@ -37,6 +95,14 @@ func (s *GRPCServer) GetOrderById(ctx context.Context, req *orderpb.GetOrderById
 	return resp, nil
 }
 
+func (s *GRPCServer) GetOrderByNumber(ctx context.Context, req *orderpb.GetOrderByNumberRequest) (*orderpb.GetOrderByIdResponse, error) {
+	resp, err := s.service.GetOrderByNumber(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
 func (s *GRPCServer) GetOrdersByUserId(ctx context.Context, req *orderpb.GetOrdersByUserIdRequest) (*orderpb.GetOrdersByUserIdResponse, error) {
 	resp, err := s.service.GetOrdersByUserID(ctx, req)
 	if err != nil {
@@ -45,18 +111,315 @@ func (s *GRPCServer) GetOrdersByUserId(ctx context.Context, req *orderpb.GetOrde
 	return resp, nil
 }
 
+func (s *GRPCServer) ReassignOrders(ctx context.Context, req *orderpb.ReassignOrdersRequest) (*orderpb.ReassignOrdersResponse, error) {
+	resp, err := s.service.ReassignOrders(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error) {
+	resp, err := s.service.UpdateOrderStatus(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest) (*orderpb.CancelOrderResponse, error) {
+	resp, err := s.service.CancelOrder(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
 func mapError(err error) error {
 	switch {
+	case errors.Is(err, repository.ErrIdempotencyKeyReused):
+		st, _ := status.New(codes.InvalidArgument, err.Error()).WithDetails(&errdetails.ErrorInfo{Reason: orderpb.ReasonIdempotencyKeyReused})
+		return st.Err()
 	case errors.Is(err, service.ErrInvalidUserID),
 		errors.Is(err, service.ErrInvalidProduct),
+		errors.Is(err, service.ErrLegacyItem),
 		errors.Is(err, service.ErrInvalidQuantity),
 		errors.Is(err, service.ErrInvalidPrice),
+		errors.Is(err, service.ErrInvalidCurrency),
+		errors.Is(err, service.ErrNoItems),
+		errors.Is(err, service.ErrNothingToAmend),
+		errors.Is(err, service.ErrTooManyItems),
 		errors.Is(err, service.ErrInvalidOrderID),
-		errors.Is(err, service.ErrInvalidUserParam):
+		errors.Is(err, service.ErrInvalidNumber),
+		errors.Is(err, service.ErrInvalidUserParam),
+		errors.Is(err, service.ErrSameUser),
+		errors.Is(err, service.ErrInvalidStatus),
+		errors.Is(err, service.ErrInvalidReason),
+		errors.Is(err, service.ErrInvalidPageSize),
+		errors.Is(err, service.ErrInvalidPageToken),
+		errors.Is(err, service.ErrInvalidSort),
+		errors.Is(err, service.ErrInvalidFilter),
+		errors.Is(err, service.ErrInvalidVersion),
+		errors.Is(err, service.ErrInvalidReturnID),
+		errors.Is(err, service.ErrInvalidReturnReason),
+		errors.Is(err, service.ErrInvalidReturnItems),
+		errors.Is(err, service.ErrInvalidReturnStatus),
+		errors.Is(err, service.ErrInvalidRefundAmount),
+		errors.Is(err, service.ErrInvalidCoupon),
+		errors.Is(err, service.ErrUnknownCoupon),
+		errors.Is(err, service.ErrInvalidAddress),
+		errors.Is(err, service.ErrInvalidTaxExemption),
+		errors.Is(err, tax.ErrNoRate),
+		errors.Is(err, exchange.ErrNoRate),
+		errors.Is(err, shipping.ErrUnknownMethod),
+		errors.Is(err, shipping.ErrNotShippable),
+		errors.Is(err, shipping.ErrUnknownCarrier),
+		errors.Is(err, service.ErrInvalidShipmentID),
+		errors.Is(err, service.ErrInvalidShipmentItems),
+		errors.Is(err, service.ErrInvalidShipmentStatus),
+		errors.Is(err, service.ErrNoCarrier),
+		errors.Is(err, service.ErrInvalidInvoiceFormat),
+		errors.Is(err, service.ErrInvalidImport),
+		errors.Is(err, service.ErrInvalidImportID),
+		errors.Is(err, service.ErrInvalidExport),
+		errors.Is(err, pricing.ErrUnknownProduct),
+		errors.Is(err, money.ErrInvalidAmount),
+		errors.Is(err, service.ErrUnknownUser):
 		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidTransition),
+		errors.Is(err, service.ErrNotCancellable),
+		errors.Is(err, service.ErrUseCancelOrder),
+		errors.Is(err, service.ErrNotAmendable),
+		errors.Is(err, service.ErrUserInactive),
+		errors.Is(err, pricing.ErrPriceMismatch),
+		errors.Is(err, pricing.ErrCurrencyMismatch),
+		errors.Is(err, service.ErrNotReturnable),
+		errors.Is(err, service.ErrReturnWindowClosed),
+		errors.Is(err, service.ErrReturnTransition),
+		errors.Is(err, service.ErrRefundExceedsOrder),
+		errors.Is(err, repository.ErrReturnQuantityExceeded),
+		errors.Is(err, repository.ErrStaleReturn),
+		errors.Is(err, service.ErrCouponInactive),
+		errors.Is(err, pricing.ErrPromotionNotApplicable),
+		errors.Is(err, repository.ErrCouponExhausted),
+		errors.Is(err, repository.ErrCouponUserLimit),
+		errors.Is(err, service.ErrOrderNotShippable),
+		errors.Is(err, service.ErrNothingToShip),
+		errors.Is(err, service.ErrShipmentTransition),
+		errors.Is(err, repository.ErrShipmentQuantityExceeded),
+		errors.Is(err, repository.ErrStaleShipment),
+		errors.Is(err, service.ErrNotInvoiced),
+		errors.Is(err, service.ErrNothingToReorder),
+		errors.Is(err, repository.ErrStaleOrder):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrUserServiceUnavailable):
+		return status.Error(codes.Unavailable, "user-service is unavailable")
+	case errors.Is(err, service.ErrRefundGateway):
+		return status.Error(codes.Unavailable, "refund gateway is unavailable, retry the refund")
+	case errors.Is(err, service.ErrCarrier):
+		return status.Error(codes.Unavailable, "carrier is unavailable, retry later")
+	case errors.Is(err, service.ErrCompensation):
+		return status.Error(codes.Unavailable, "charge or refund failed, the order was not amended; retry later")
+	case errors.Is(err, repository.ErrCouponExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrTaxExemptionNotFound):
+		return status.Error(codes.NotFound, "tax exemption not found")
+	case errors.Is(err, repository.ErrCouponNotFound):
+		return status.Error(codes.NotFound, "coupon not found")
+	case errors.Is(err, repository.ErrReturnNotFound):
+		return status.Error(codes.NotFound, "return not found")
+	case errors.Is(err, repository.ErrShipmentNotFound):
+		return status.Error(codes.NotFound, "shipment not found")
+	case errors.Is(err, repository.ErrInvoiceNotFound):
+		return status.Error(codes.NotFound, "invoice not found")
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		return status.Error(codes.NotFound, "order not found")
 	default:
 		return status.Error(codes.Internal, "internal server error")
 	}
 }
+
+func (s *GRPCServer) GetOrderHistory(ctx context.Context, req *orderpb.GetOrderHistoryRequest) (*orderpb.GetOrderHistoryResponse, error) {
+	resp, err := s.service.GetOrderHistory(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) RequestReturn(ctx context.Context, req *orderpb.RequestReturnRequest) (*orderpb.ReturnResponse, error) {
+	resp, err := s.returns.RequestReturn(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) ListOrderReturns(ctx context.Context, req *orderpb.ListOrderReturnsRequest) (*orderpb.ListOrderReturnsResponse, error) {
+	resp, err := s.returns.ListOrderReturns(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) UpdateReturnStatus(ctx context.Context, req *orderpb.UpdateReturnStatusRequest) (*orderpb.ReturnResponse, error) {
+	resp, err := s.returns.UpdateReturnStatus(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) RefundReturn(ctx context.Context, req *orderpb.RefundReturnRequest) (*orderpb.ReturnResponse, error) {
+	resp, err := s.returns.RefundReturn(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) CreateCoupon(ctx context.Context, req *orderpb.CreateCouponRequest) (*orderpb.CouponResponse, error) {
+	resp, err := s.coupons.CreateCoupon(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) UpdateCoupon(ctx context.Context, req *orderpb.UpdateCouponRequest) (*orderpb.CouponResponse, error) {
+	resp, err := s.coupons.UpdateCoupon(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) ListCoupons(ctx context.Context, req *orderpb.ListCouponsRequest) (*orderpb.ListCouponsResponse, error) {
+	resp, err := s.coupons.ListCoupons(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetTaxExemption(ctx context.Context, req *orderpb.GetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error) {
+	resp, err := s.taxes.GetTaxExemption(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) SetTaxExemption(ctx context.Context, req *orderpb.SetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error) {
+	resp, err := s.taxes.SetTaxExemption(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) RemoveTaxExemption(ctx context.Context, req *orderpb.RemoveTaxExemptionRequest) (*orderpb.RemoveTaxExemptionResponse, error) {
+	resp, err := s.taxes.RemoveTaxExemption(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) CreateShipment(ctx context.Context, req *orderpb.CreateShipmentRequest) (*orderpb.ShipmentResponse, error) {
+	resp, err := s.shipments.CreateShipment(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) ListOrderShipments(ctx context.Context, req *orderpb.ListOrderShipmentsRequest) (*orderpb.ListOrderShipmentsResponse, error) {
+	resp, err := s.shipments.ListOrderShipments(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) UpdateShipmentStatus(ctx context.Context, req *orderpb.UpdateShipmentStatusRequest) (*orderpb.ShipmentResponse, error) {
+	resp, err := s.shipments.UpdateShipmentStatus(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetInvoice(ctx context.Context, req *orderpb.GetInvoiceRequest) (*orderpb.InvoiceResponse, error) {
+	resp, err := s.invoices.GetInvoice(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) SearchOrders(ctx context.Context, req *orderpb.SearchOrdersRequest) (*orderpb.SearchOrdersResponse, error) {
+	resp, err := s.search.SearchOrders(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+// ImportOrders takes the import options from the first message and reads the
+// file from the chunks of every message.
+func (s *GRPCServer) ImportOrders(stream orderpb.OrderService_ImportOrdersServer) error {
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "import options are required")
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := s.imports.ImportOrders(stream.Context(), first, &importStreamReader{stream: stream, buf: first.Chunk})
+	if err != nil {
+		return mapError(err)
+	}
+	return stream.SendAndClose(resp)
+}
+
+func (s *GRPCServer) ExportOrders(req *orderpb.ExportOrdersRequest, stream orderpb.OrderService_ExportOrdersServer) error {
+	err := s.search.ExportOrders(stream.Context(), req, func(data []byte) error {
+		return stream.Send(&orderpb.ExportOrdersChunk{Data: data})
+	})
+	if err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+func (s *GRPCServer) GetSalesReport(ctx context.Context, req *orderpb.SalesReportRequest) (*orderpb.SalesReportResponse, error) {
+	resp, err := s.search.GetSalesReport(ctx, req)
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+type importStreamReader struct {
+	stream orderpb.OrderService_ImportOrdersServer
+	buf    []byte
+}
+
+func (r *importStreamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		msg, err := r.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		r.buf = msg.Chunk
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var (
+	ErrInvalidCoupon  = errors.New("invalid coupon")
+	ErrUnknownCoupon  = errors.New("unknown coupon code")
+	ErrCouponInactive = errors.New("coupon is not active")
+)
+
+const maxEligibleProducts = 100
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,64}$`)
+
+type CouponService interface {
+	CreateCoupon(ctx context.Context, req *orderpb.CreateCouponRequest) (*orderpb.CouponResponse, error)
+	UpdateCoupon(ctx context.Context, req *orderpb.UpdateCouponRequest) (*orderpb.CouponResponse, error)
+	ListCoupons(ctx context.Context, req *orderpb.ListCouponsRequest) (*orderpb.ListCouponsResponse, error)
+}
+
+type couponService struct {
+	repo     repository.CouponRepository
+	currency string
+}
+
+func NewCouponService(repo repository.CouponRepository, currency string) CouponService {
+	return &couponService{repo: repo, currency: currency}
+}
+
+func (s *couponService) CreateCoupon(ctx context.Context, req *orderpb.CreateCouponRequest) (*orderpb.CouponResponse, error) {
+	coupon, err := couponFromPB(req.Coupon, s.currency)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	coupon.CreatedAt = now
+	coupon.UpdatedAt = now
+	if err := s.repo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return &orderpb.CouponResponse{Coupon: toPBCoupon(coupon)}, nil
+}
+
+func (s *couponService) UpdateCoupon(ctx context.Context, req *orderpb.UpdateCouponRequest) (*orderpb.CouponResponse, error) {
+	coupon, err := couponFromPB(req.Coupon, s.currency)
+	if err != nil {
+		return nil, err
+	}
+	coupon.UpdatedAt = time.Now().UTC()
+	if err := s.repo.Update(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return &orderpb.CouponResponse{Coupon: toPBCoupon(coupon)}, nil
+}
+
+func (s *couponService) ListCoupons(ctx context.Context, req *orderpb.ListCouponsRequest) (*orderpb.ListCouponsResponse, error) {
+	coupons, err := s.repo.List(ctx, req.IncludeInactive)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]*orderpb.CouponData, 0, len(coupons))
+	for i := range coupons {
+		data = append(data, toPBCoupon(&coupons[i]))
+	}
+	return &orderpb.ListCouponsResponse{Coupons: data}, nil
+}
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// promotion loads the coupon for an order and checks that it can be used
+// now. Usage limits are enforced when the order is stored.
+func (s *orderService) promotion(ctx context.Context, code string, now time.Time) (*pricing.Promotion, error) {
+	if s.coupons == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCoupon, code)
+	}
+	coupon, err := s.coupons.GetByCode(ctx, code)
+	if errors.Is(err, repository.ErrCouponNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCoupon, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !coupon.Active {
+		return nil, fmt.Errorf("%w: %s is disabled", ErrCouponInactive, code)
+	}
+	if coupon.StartsAt != nil && now.Before(*coupon.StartsAt) {
+		return nil, fmt.Errorf("%w: %s starts at %s", ErrCouponInactive, code, coupon.StartsAt.Format(time.RFC3339))
+	}
+	if coupon.EndsAt != nil && !now.Before(*coupon.EndsAt) {
+		return nil, fmt.Errorf("%w: %s ended at %s", ErrCouponInactive, code, coupon.EndsAt.Format(time.RFC3339))
+	}
+	return promotionOf(coupon.Code, &models.CouponTerms{
+		Kind:             coupon.Kind,
+		PercentOffBps:    coupon.PercentOffBps,
+		Currency:         coupon.Currency,
+		AmountOff:        coupon.AmountOff,
+		BuyQuantity:      coupon.BuyQuantity,
+		GetQuantity:      coupon.GetQuantity,
+		MinSubtotal:      coupon.MinSubtotal,
+		EligibleProducts: coupon.EligibleProducts,
+	}), nil
+}
+
+func promotionOf(code string, terms *models.CouponTerms) *pricing.Promotion {
+	return &pricing.Promotion{
+		Code:             code,
+		Kind:             terms.Kind,
+		PercentOffBps:    terms.PercentOffBps,
+		AmountOff:        money.New(terms.AmountOff, terms.Currency),
+		BuyQuantity:      terms.BuyQuantity,
+		GetQuantity:      terms.GetQuantity,
+		MinSubtotal:      money.New(terms.MinSubtotal, terms.Currency),
+		EligibleProducts: terms.EligibleProducts,
+	}
+}
+
+func couponTerms(p *pricing.Promotion) *models.CouponTerms {
+	if p == nil {
+		return nil
+	}
+	return &models.CouponTerms{
+		Kind:             p.Kind,
+		PercentOffBps:    p.PercentOffBps,
+		Currency:         p.AmountOff.Currency,
+		AmountOff:        p.AmountOff.Amount,
+		BuyQuantity:      p.BuyQuantity,
+		GetQuantity:      p.GetQuantity,
+		MinSubtotal:      p.MinSubtotal.Amount,
+		EligibleProducts: p.EligibleProducts,
+	}
+}
+
+func buildOrderDiscounts(order *models.Order, quote *pricing.Quote) []models.OrderDiscount {
+	discounts := make([]models.OrderDiscount, 0, len(quote.Discounts))
+	for i, d := range quote.Discounts {
+		discount := models.OrderDiscount{
+			ID:       uuid.NewString(),
+			OrderID:  order.ID,
+			Position: int32(i),
+			Source:   d.Source,
+			Code:     d.Code,
+			Kind:     d.Kind,
+			Amount:   d.Amount.Amount,
+		}
+		if d.Line >= 0 {
+			discount.OrderItemID = &order.Items[d.Line].ID
+		}
+		discounts = append(discounts, discount)
+	}
+	return discounts
+}
+
+func toPBDiscounts(order *models.Order) []*orderpb.OrderDiscountData {
+	discounts := make([]*orderpb.OrderDiscountData, 0, len(order.Discounts))
+	for _, d := range order.Discounts {
+		data := &orderpb.OrderDiscountData{
+			Source:     d.Source,
+			CouponCode: d.Code,
+			Kind:       d.Kind,
+			Amount:     amount(d.Amount, order.Currency),
+		}
+		if d.OrderItemID != nil {
+			data.OrderItemId = *d.OrderItemID
+		}
+		discounts = append(discounts, data)
+	}
+	return discounts
+}
+
+func couponFromPB(data *orderpb.CouponData, currency string) (*models.Coupon, error) {
+	if data == nil {
+		return nil, fmt.Errorf("%w: coupon is required", ErrInvalidCoupon)
+	}
+	coupon := &models.Coupon{
+		Code:             normalizeCouponCode(data.Code),
+		Description:      strings.TrimSpace(data.Description),
+		Kind:             strings.TrimSpace(strings.ToLower(data.Kind)),
+		Currency:         currency,
+		MaxRedemptions:   data.MaxRedemptions,
+		MaxPerUser:       data.MaxPerUser,
+		EligibleProducts: []string{},
+		Active:           data.Active,
+	}
+	if !couponCodePattern.MatchString(coupon.Code) {
+		return nil, fmt.Errorf("%w: code must be 3-64 letters, digits, '-' or '_'", ErrInvalidCoupon)
+	}
+	if len([]rune(coupon.Description)) > 255 {
+		return nil, fmt.Errorf("%w: description is too long", ErrInvalidCoupon)
+	}
+
+	switch coupon.Kind {
+	case pricing.PromotionPercent:
+		if data.PercentOffBps <= 0 || data.PercentOffBps > 10000 {
+			return nil, fmt.Errorf("%w: percent_off_bps must be between 1 and 10000", ErrInvalidCoupon)
+		}
+		coupon.PercentOffBps = data.PercentOffBps
+	case pricing.PromotionFixed:
+		off, err := couponAmount(data.AmountOff, currency)
+		if err != nil || off <= 0 {
+			return nil, fmt.Errorf("%w: amount_off must be a positive %s amount", ErrInvalidCoupon, currency)
+		}
+		coupon.AmountOff = off
+	case pricing.PromotionBuyXGetY:
+		if data.BuyQuantity <= 0 || data.GetQuantity <= 0 {
+			return nil, fmt.Errorf("%w: buy_quantity and get_quantity must be greater than 0", ErrInvalidCoupon)
+		}
+		coupon.BuyQuantity = data.BuyQuantity
+		coupon.GetQuantity = data.GetQuantity
+	case pricing.PromotionFreeShipping:
+	default:
+		return nil, fmt.Errorf("%w: kind must be percent, fixed, free_shipping or buy_x_get_y", ErrInvalidCoupon)
+	}
+
+	minSubtotal, err := couponAmount(data.MinSubtotal, currency)
+	if err != nil || minSubtotal < 0 {
+		return nil, fmt.Errorf("%w: min_subtotal must be a %s amount", ErrInvalidCoupon, currency)
+	}
+	coupon.MinSubtotal = minSubtotal
+
+	if coupon.StartsAt, err = optionalTime(data.StartsAt); err != nil {
+		return nil, fmt.Errorf("%w: starts_at must be RFC 3339", ErrInvalidCoupon)
+	}
+	if coupon.EndsAt, err = optionalTime(data.EndsAt); err != nil {
+		return nil, fmt.Errorf("%w: ends_at must be RFC 3339", ErrInvalidCoupon)
+	}
+	if coupon.StartsAt != nil && coupon.EndsAt != nil && !coupon.EndsAt.After(*coupon.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidCoupon)
+	}
+	if coupon.MaxRedemptions < 0 || coupon.MaxPerUser < 0 {
+		return nil, fmt.Errorf("%w: usage limits must not be negative", ErrInvalidCoupon)
+	}
+
+	if len(data.EligibleProducts) > maxEligibleProducts {
+		return nil, fmt.Errorf("%w: at most %d eligible products", ErrInvalidCoupon, maxEligibleProducts)
+	}
+	for _, ref := range data.EligibleProducts {
+		ref = strings.TrimSpace(ref)
+		if ref == "" {
+			return nil, fmt.Errorf("%w: eligible product must not be empty", ErrInvalidCoupon)
+		}
+		coupon.EligibleProducts = append(coupon.EligibleProducts, ref)
+	}
+	return coupon, nil
+}
+
+func couponAmount(m *orderpb.Money, currency string) (int64, error) {
+	if m == nil {
+		return 0, nil
+	}
+	if !strings.EqualFold(m.Currency, currency) {
+		return 0, ErrInvalidCurrency
+	}
+	return m.Amount, nil
+}
+
+func optionalTime(s string) (*time.Time, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil, err
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func toPBCoupon(coupon *models.Coupon) *orderpb.CouponData {
+	return &orderpb.CouponData{
+		Code:             coupon.Code,
+		Description:      coupon.Description,
+		Kind:             coupon.Kind,
+		PercentOffBps:    coupon.PercentOffBps,
+		AmountOff:        amount(coupon.AmountOff, coupon.Currency),
+		BuyQuantity:      coupon.BuyQuantity,
+		GetQuantity:      coupon.GetQuantity,
+		MinSubtotal:      amount(coupon.MinSubtotal, coupon.Currency),
+		StartsAt:         formatOptionalTime(coupon.StartsAt),
+		EndsAt:           formatOptionalTime(coupon.EndsAt),
+		MaxRedemptions:   coupon.MaxRedemptions,
+		MaxPerUser:       coupon.MaxPerUser,
+		EligibleProducts: coupon.EligibleProducts,
+		Active:           coupon.Active,
+		Redemptions:      coupon.Redemptions,
+		CreatedAt:        coupon.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:        coupon.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type memoryCoupons struct {
+	repository.CouponRepository
+	coupons map[string]*models.Coupon
+}
+
+func (m *memoryCoupons) GetByCode(_ context.Context, code string) (*models.Coupon, error) {
+	coupon, ok := m.coupons[code]
+	if !ok {
+		return nil, repository.ErrCouponNotFound
+	}
+	return coupon, nil
+}
+
+func newCouponOrderService(coupons ...*models.Coupon) (OrderService, *memoryOrders) {
+	repo := &memoryCoupons{coupons: map[string]*models.Coupon{}}
+	for _, c := range coupons {
+		repo.coupons[c.Code] = c
+	}
+	return newTestOrderService(OrderServiceDeps{Coupons: repo})
+}
+
+func TestCreateOrderAppliesCoupon(t *testing.T) {
+	svc, _ := newCouponOrderService(&models.Coupon{Code: "MOUSE50", Kind: pricing.PromotionPercent, PercentOffBps: 5000, Currency: "USD", EligibleProducts: []string{"mouse-01"}, Active: true})
+
+	resp, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+		UserId:     "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		Items:      []*orderpb.OrderItemInput{{ProductId: "laptop-14", Quantity: 1}, {ProductId: "mouse-01", Quantity: 2}},
+		CouponCode: " mouse50 ",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order := resp.Order
+	if order.CouponCode != "MOUSE50" || order.TotalAmount.Amount != 102000 {
+		t.Fatalf("coupon = %q, total = %d", order.CouponCode, order.TotalAmount.Amount)
+	}
+	if len(order.Discounts) != 1 || order.Discounts[0].Amount.Amount != 2000 || order.Discounts[0].OrderItemId != order.Items[1].Id {
+		t.Fatalf("unexpected discounts %+v", order.Discounts)
+	}
+}
+
+func TestCreateOrderRejectsUnusableCoupon(t *testing.T) {
+	ended := time.Now().Add(-time.Hour)
+	svc, orders := newCouponOrderService(
+		&models.Coupon{Code: "OLD", Kind: pricing.PromotionFreeShipping, Currency: "USD", Active: true, EndsAt: &ended},
+		&models.Coupon{Code: "OFF", Kind: pricing.PromotionFreeShipping, Currency: "USD"},
+	)
+	cases := map[string]error{"OLD": ErrCouponInactive, "OFF": ErrCouponInactive, "MISSING": ErrUnknownCoupon}
+	for code, want := range cases {
+		_, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+			UserId:     "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+			Items:      []*orderpb.OrderItemInput{{ProductId: "laptop-14", Quantity: 1}},
+			CouponCode: code,
+		})
+		if !errors.Is(err, want) {
+			t.Errorf("%s: err = %v, want %v", code, err, want)
+		}
+	}
+	if len(orders.created) != 0 {
+		t.Fatalf("created %d orders with unusable coupons", len(orders.created))
+	}
+}
+
+func TestCouponFromPBValidatesRules(t *testing.T) {
+	cases := []*orderpb.CouponData{
+		{Code: "x", Kind: "percent", PercentOffBps: 1000},
+		{Code: "TOO-MUCH", Kind: "percent", PercentOffBps: 20000},
+		{Code: "FIXED", Kind: "fixed", AmountOff: &orderpb.Money{Amount: 500, Currency: "EUR"}},
+		{Code: "B2G1", Kind: "buy_x_get_y", BuyQuantity: 2},
+		{Code: "WINDOW", Kind: "free_shipping", StartsAt: "2026-02-01T00:00:00Z", EndsAt: "2026-01-01T00:00:00Z"},
+	}
+	for _, data := range cases {
+		if _, err := couponFromPB(data, "USD"); !errors.Is(err, ErrInvalidCoupon) {
+			t.Errorf("%s: err = %v, want ErrInvalidCoupon", data.Code, err)
+		}
+	}
+	coupon, err := couponFromPB(&orderpb.CouponData{Code: "spring-10", Kind: "Percent", PercentOffBps: 1000, Active: true}, "USD")
+	if err != nil || coupon.Code != "SPRING-10" || coupon.Kind != pricing.PromotionPercent {
+		t.Fatalf("coupon = %+v, err = %v", coupon, err)
+	}
+}
+
+func TestCreateOrderWithFullDiscountCoupon(t *testing.T) {
+	svc, orders := newCouponOrderService(&models.Coupon{Code: "FREEMOUSE", Kind: pricing.PromotionPercent, PercentOffBps: 10000, Currency: "USD", EligibleProducts: []string{"mouse-01"}, Active: true})
+
+	resp, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+		UserId:     "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		Items:      []*orderpb.OrderItemInput{{ProductId: "mouse-01", Quantity: 2}},
+		CouponCode: "FREEMOUSE",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if resp.Order.TotalAmount.Amount != 0 || resp.Order.DiscountAmount.Amount != 4000 {
+		t.Fatalf("total = %d, discount = %d", resp.Order.TotalAmount.Amount, resp.Order.DiscountAmount.Amount)
+	}
+	order := orders.created[0]
+	if order.TotalPrice != 0 || order.Items[0].LineTotal != 0 || order.Items[0].UnitPrice <= 0 {
+		t.Fatalf("stored total_price %v, line_total %v, unit_price %v", order.TotalPrice, order.Items[0].LineTotal, order.Items[0].UnitPrice)
+	}
+}
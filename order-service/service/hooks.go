@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/pkg/money"
+)
+
+type CompensationHook interface {
+	OnPaidOrderCancelled(ctx context.Context, order *models.Order) error
+	// OnPaidOrderAmended is called when an amendment changes the total of a
+	// paid order, before it is stored; an error keeps the order as it was.
+	// A positive delta is owed by the customer, a negative one is owed to
+	// them. If storing fails it is called again with the delta reversed.
+	OnPaidOrderAmended(ctx context.Context, order *models.Order, delta money.Money) error
+}
+
+type CompensationHooks []CompensationHook
+
+func (h CompensationHooks) OnPaidOrderCancelled(ctx context.Context, order *models.Order) error {
+	for _, hook := range h {
+		if err := hook.OnPaidOrderCancelled(ctx, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h CompensationHooks) OnPaidOrderAmended(ctx context.Context, order *models.Order, delta money.Money) error {
+	for _, hook := range h {
+		if err := hook.OnPaidOrderAmended(ctx, order, delta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type loggingCompensationHook struct {
+	logger *log.Logger
+}
+
+func NewLoggingCompensationHook(logger *log.Logger) CompensationHook {
+	return &loggingCompensationHook{logger: logger}
+}
+
+func (h *loggingCompensationHook) OnPaidOrderCancelled(_ context.Context, order *models.Order) error {
+	h.logger.Printf("compensation required order_id=%s refund=%s", order.ID, money.New(order.TotalAmount, order.Currency))
+	for _, item := range orderLines(order) {
+		h.logger.Printf("compensation required order_id=%s release=%dx%q sku=%s", order.ID, item.Quantity, item.Name, item.SKU)
+	}
+	return nil
+}
+
+func (h *loggingCompensationHook) OnPaidOrderAmended(_ context.Context, order *models.Order, delta money.Money) error {
+	if delta.Amount < 0 {
+		h.logger.Printf("compensation required order_id=%s refund=%s", order.ID, money.New(-delta.Amount, delta.Currency))
+		return nil
+	}
+	h.logger.Printf("charge required order_id=%s charge=%s", order.ID, delta)
+	return nil
+}
+
+// ExpiryHook is called for every order the expiry worker expires, after the
+// change is committed, to release what the order was holding.
+type ExpiryHook interface {
+	OnOrderExpired(ctx context.Context, order *models.Order) error
+}
+
+type ExpiryHooks []ExpiryHook
+
+func (h ExpiryHooks) OnOrderExpired(ctx context.Context, order *models.Order) error {
+	for _, hook := range h {
+		if err := hook.OnOrderExpired(ctx, order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type loggingExpiryHook struct {
+	logger *log.Logger
+}
+
+func NewLoggingExpiryHook(logger *log.Logger) ExpiryHook {
+	return &loggingExpiryHook{logger: logger}
+}
+
+func (h *loggingExpiryHook) OnOrderExpired(_ context.Context, order *models.Order) error {
+	for _, item := range orderLines(order) {
+		h.logger.Printf("reservation released order_id=%s release=%dx%q sku=%s", order.ID, item.Quantity, item.Name, item.SKU)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type memoryKeys struct {
+	orders  repository.OrderRepository
+	records map[string]*models.IdempotencyKey
+}
+
+func (m *memoryKeys) Run(_ context.Context, record *models.IdempotencyKey, fn func(repository.OrderRepository) ([]byte, error)) ([]byte, error) {
+	if existing, ok := m.records[record.Scope+"/"+record.Key]; ok {
+		if existing.Fingerprint != record.Fingerprint {
+			return nil, repository.ErrIdempotencyKeyReused
+		}
+		return existing.Response, nil
+	}
+	resp, err := fn(m.orders)
+	if err != nil {
+		return nil, err
+	}
+	record.Response = resp
+	m.records[record.Scope+"/"+record.Key] = record
+	return resp, nil
+}
+
+func TestCreateOrderReplaysIdempotentRequest(t *testing.T) {
+	orders := newMemoryOrders()
+	svc, _ := newTestOrderService(OrderServiceDeps{Repo: orders, Keys: &memoryKeys{orders: orders, records: map[string]*models.IdempotencyKey{}}})
+
+	req := &orderpb.CreateOrderRequest{UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Items: []*orderpb.OrderItemInput{{ProductId: "laptop-14", Quantity: 1}}}
+	ctx := WithIdempotencyKey(context.Background(), "retry-1")
+
+	first, err := svc.CreateOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("first CreateOrder: %v", err)
+	}
+	second, err := svc.CreateOrder(ctx, req)
+	if err != nil {
+		t.Fatalf("replayed CreateOrder: %v", err)
+	}
+	if second.Order.Id != first.Order.Id || len(orders.created) != 1 {
+		t.Fatalf("replay created a new order: %s vs %s, created=%d", first.Order.Id, second.Order.Id, len(orders.created))
+	}
+
+	other := &orderpb.CreateOrderRequest{UserId: req.UserId, Items: []*orderpb.OrderItemInput{{ProductId: "laptop-14", Quantity: 2}}}
+	if _, err := svc.CreateOrder(ctx, other); !errors.Is(err, repository.ErrIdempotencyKeyReused) {
+		t.Fatalf("reused key err = %v, want ErrIdempotencyKeyReused", err)
+	}
+
+	if _, err := svc.CreateOrder(context.Background(), req); err != nil || len(orders.created) != 2 {
+		t.Fatalf("request without key: err=%v created=%d", err, len(orders.created))
+	}
+}
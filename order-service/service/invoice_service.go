@@ -0,0 +1,565 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"online-store-microservice/order-service/invoice"
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const (
+	invoiceSeries    = "INV"
+	creditNoteSeries = "CN"
+)
+
+var (
+	ErrInvalidInvoiceFormat = errors.New("invalid invoice format")
+	ErrNotInvoiced          = errors.New("order has not been paid, so it has no invoice")
+)
+
+// Invoicer issues the invoice of a paid order, the credit note of a refunded
+// return and the replacement invoice of an amended one. Issuing is
+// idempotent.
+type Invoicer interface {
+	IssueInvoice(ctx context.Context, order *models.Order) (*models.Invoice, error)
+	IssueCreditNote(ctx context.Context, order *models.Order, ret *models.OrderReturn) (*models.Invoice, error)
+	ReissueInvoice(ctx context.Context, order *models.Order) (*models.Invoice, error)
+}
+
+type InvoiceService interface {
+	Invoicer
+	GetInvoice(ctx context.Context, req *orderpb.GetInvoiceRequest) (*orderpb.InvoiceResponse, error)
+}
+
+type invoiceService struct {
+	orders   repository.OrderRepository
+	returns  repository.ReturnRepository
+	invoices repository.InvoiceRepository
+	users    UserLookup
+	seller   invoice.Party
+	logger   *log.Logger
+	now      func() time.Time
+}
+
+// NewInvoiceService prints seller on every document. Buyers are looked up in
+// users; without it the name on the shipping address is used.
+func NewInvoiceService(orders repository.OrderRepository, returns repository.ReturnRepository, invoices repository.InvoiceRepository, users UserLookup, seller invoice.Party, logger *log.Logger) InvoiceService {
+	return &invoiceService{orders: orders, returns: returns, invoices: invoices, users: users, seller: seller, logger: logger, now: time.Now}
+}
+
+// GetInvoice returns the current invoice of an order, or one of its earlier
+// invoices or credit notes, and renders it when a format is asked for.
+// Documents that should exist but were not issued when the order was paid,
+// amended or refunded are issued now.
+func (s *invoiceService) GetInvoice(ctx context.Context, req *orderpb.GetInvoiceRequest) (*orderpb.InvoiceResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	format := strings.TrimSpace(strings.ToLower(req.Format))
+	switch format {
+	case "", "json", invoice.FormatHTML, invoice.FormatPDF:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInvoiceFormat, req.Format)
+	}
+
+	order, err := s.orders.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orderActor(order, req.ActorId, req.ActorIsAdmin); err != nil {
+		return nil, err
+	}
+
+	issued, err := s.invoices.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !hasInvoice(issued) {
+		paid, err := s.wasPaid(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		if !paid {
+			return nil, fmt.Errorf("%w: order is %s", ErrNotInvoiced, order.Status)
+		}
+	}
+	if issued, err = s.issueMissing(ctx, order, issued); err != nil {
+		return nil, err
+	}
+
+	resp := &orderpb.InvoiceResponse{}
+	requested := currentInvoice(issued)
+	if req.Number != "" {
+		requested = nil
+	}
+	for i := range issued {
+		inv := &issued[i]
+		if req.Number != "" && inv.Number == strings.TrimSpace(req.Number) {
+			requested = inv
+		}
+		if inv.Kind == invoice.KindCreditNote {
+			data, err := toPBInvoice(inv)
+			if err != nil {
+				return nil, err
+			}
+			resp.CreditNotes = append(resp.CreditNotes, data)
+		}
+	}
+	if requested == nil {
+		return nil, repository.ErrInvoiceNotFound
+	}
+	if resp.Invoice, err = toPBInvoice(requested); err != nil {
+		return nil, err
+	}
+
+	if format == invoice.FormatHTML || format == invoice.FormatPDF {
+		doc, err := documentOf(requested)
+		if err != nil {
+			return nil, err
+		}
+		if resp.Content, resp.ContentType, err = invoice.Render(doc, format); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// issueMissing issues the invoice, the replacement of an invoice the order
+// was amended after and the credit notes of refunded returns that an earlier
+// attempt failed to issue, and returns all documents.
+func (s *invoiceService) issueMissing(ctx context.Context, order *models.Order, issued []models.Invoice) ([]models.Invoice, error) {
+	missing := !hasInvoice(issued)
+	if current := currentInvoice(issued); current != nil {
+		amended, err := s.amendedSince(ctx, order.ID, current.IssuedAt)
+		if err != nil {
+			return nil, err
+		}
+		if amended {
+			if _, err := s.ReissueInvoice(ctx, order); err != nil {
+				return nil, err
+			}
+			missing = true
+		}
+	}
+	if order.RefundedAmount > 0 && s.returns != nil {
+		returns, err := s.returns.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		credited := make(map[string]bool, len(issued))
+		for _, inv := range issued {
+			if inv.ReturnID != nil {
+				credited[*inv.ReturnID] = true
+			}
+		}
+		for i := range returns {
+			if returns[i].Status != models.ReturnStatusRefunded || credited[returns[i].ID] {
+				continue
+			}
+			if _, err := s.IssueCreditNote(ctx, order, &returns[i]); err != nil {
+				return nil, err
+			}
+			missing = true
+		}
+	}
+	if !missing {
+		return issued, nil
+	}
+	if _, err := s.IssueInvoice(ctx, order); err != nil {
+		return nil, err
+	}
+	return s.invoices.ListByOrder(ctx, order.ID)
+}
+
+// wasPaid reports whether the order has been paid at some point, including
+// orders that were cancelled or refunded after payment.
+func (s *invoiceService) wasPaid(ctx context.Context, order *models.Order) (bool, error) {
+	switch order.Status {
+	case models.OrderStatusPaid, models.OrderStatusFulfilling, models.OrderStatusShipped, models.OrderStatusDelivered:
+		return true, nil
+	}
+	changes, err := s.orders.History(ctx, order.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range changes {
+		if change.ToStatus == models.OrderStatusPaid {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// amendedSince reports whether the order was amended after t.
+func (s *invoiceService) amendedSince(ctx context.Context, orderID string, t time.Time) (bool, error) {
+	changes, err := s.orders.History(ctx, orderID)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range changes {
+		if len(change.Changes) > 0 && change.CreatedAt.After(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *invoiceService) IssueInvoice(ctx context.Context, order *models.Order) (*models.Invoice, error) {
+	doc, err := s.invoiceDocument(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return s.issue(ctx, doc, &models.Invoice{OrderID: order.ID, Kind: invoice.KindInvoice}, invoiceSeries)
+}
+
+// ReissueInvoice replaces the invoice of an amended order: a credit note
+// cancels the current invoice in full and a new one is issued for the order
+// as it stands now. An order without an invoice gets its first one.
+func (s *invoiceService) ReissueInvoice(ctx context.Context, order *models.Order) (*models.Invoice, error) {
+	issued, err := s.invoices.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	replaced := currentInvoice(issued)
+	if replaced == nil {
+		return s.IssueInvoice(ctx, order)
+	}
+	old, err := documentOf(replaced)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := s.invoiceDocument(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	doc.InvoiceNumber = replaced.Number
+
+	credit := *old
+	credit.Kind = invoice.KindCreditNote
+	credit.Number, credit.IssuedAt = "", time.Time{}
+	credit.InvoiceNumber = replaced.Number
+	credit.Note = "Cancels invoice " + replaced.Number + ", replaced after the order was amended"
+
+	creditNote := &models.Invoice{OrderID: order.ID, Kind: invoice.KindCreditNote}
+	if err := s.prepare(&credit, creditNote); err != nil {
+		return nil, err
+	}
+	inv := &models.Invoice{OrderID: order.ID, Kind: invoice.KindInvoice}
+	if err := s.prepare(doc, inv); err != nil {
+		return nil, err
+	}
+	reissued, err := s.invoices.Reissue(ctx, replaced, creditNote, inv, creditNoteSeries, invoiceSeries)
+	if err != nil {
+		return nil, err
+	}
+	if reissued.ID == inv.ID {
+		s.logIssued(creditNote)
+		s.logIssued(inv)
+	}
+	return reissued, nil
+}
+
+// invoiceDocument is the invoice of the order as it stands now.
+func (s *invoiceService) invoiceDocument(ctx context.Context, order *models.Order) (*invoice.Document, error) {
+	buyer, err := s.buyer(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &invoice.Document{
+		Kind:             invoice.KindInvoice,
+		OrderID:          order.ID,
+		Seller:           s.seller,
+		Buyer:            buyer,
+		Currency:         order.Currency,
+		SubtotalAmount:   order.SubtotalAmount,
+		DiscountAmount:   order.DiscountAmount,
+		ShippingAmount:   order.ShippingAmount,
+		TaxAmount:        order.TaxAmount,
+		TotalAmount:      order.TotalAmount,
+		PricesIncludeTax: order.PricesIncludeTax,
+		TaxExempt:        order.TaxExempt,
+	}
+	for _, item := range orderLines(order) {
+		doc.Lines = append(doc.Lines, invoice.Line{
+			Description: item.Name,
+			SKU:         item.SKU,
+			Quantity:    item.Quantity,
+			UnitAmount:  item.UnitAmount,
+			Discount:    item.DiscountAmount,
+			Amount:      item.LineAmount,
+		})
+	}
+	for _, line := range order.TaxLines {
+		doc.TaxLines = append(doc.TaxLines, invoiceTaxLine(line, line.TaxableAmount, line.Amount))
+	}
+	if order.ShippingAmount > 0 && order.ShippingMethod != "" {
+		doc.Note = "Shipping: " + order.ShippingMethod
+	}
+	return doc, nil
+}
+
+// IssueCreditNote credits what was refunded for a return. The refund is split
+// over the returned lines, and its tax is the order's tax in proportion to
+// what the customer paid for the goods.
+func (s *invoiceService) IssueCreditNote(ctx context.Context, order *models.Order, ret *models.OrderReturn) (*models.Invoice, error) {
+	original, err := s.IssueInvoice(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	buyer, err := s.buyer(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	refunded := ret.RefundedAmount
+	paid := order.TotalAmount - order.ShippingAmount
+	doc := &invoice.Document{
+		Kind:             invoice.KindCreditNote,
+		OrderID:          order.ID,
+		InvoiceNumber:    original.Number,
+		Seller:           s.seller,
+		Buyer:            buyer,
+		Currency:         order.Currency,
+		PricesIncludeTax: order.PricesIncludeTax,
+		TaxExempt:        order.TaxExempt,
+		TotalAmount:      refunded,
+		Note:             "Refund for return " + ret.ID + " (" + ret.Reason + ")",
+	}
+
+	taxable := make([]int64, len(order.TaxLines))
+	taxes := make([]int64, len(order.TaxLines))
+	for i, line := range order.TaxLines {
+		taxable[i], taxes[i] = line.TaxableAmount, line.Amount
+	}
+	if paid > 0 {
+		taxable = scaleAmounts(taxable, refunded, paid, order.Currency)
+		taxes = scaleAmounts(taxes, refunded, paid, order.Currency)
+	}
+	for i, line := range order.TaxLines {
+		doc.TaxLines = append(doc.TaxLines, invoiceTaxLine(line, taxable[i], taxes[i]))
+		if !line.Exempt {
+			doc.TaxAmount += taxes[i]
+		}
+	}
+	doc.SubtotalAmount = refunded
+	if !order.PricesIncludeTax {
+		doc.SubtotalAmount -= doc.TaxAmount
+	}
+
+	// Lines add up to the subtotal: what each returned line was worth, scaled
+	// to the part of the refund that is not tax.
+	worth := make([]int64, len(ret.Items))
+	var requested int64
+	for i, item := range ret.Items {
+		worth[i] = item.Amount
+		requested += item.Amount
+	}
+	if requested > 0 {
+		worth = scaleAmounts(worth, doc.SubtotalAmount, requested, order.Currency)
+	}
+	for i, item := range ret.Items {
+		doc.Lines = append(doc.Lines, invoice.Line{
+			Description: item.Name,
+			Quantity:    item.Quantity,
+			UnitAmount:  money.New(worth[i], order.Currency).MulRatio(1, int64(max(item.Quantity, 1))).Amount,
+			Amount:      worth[i],
+		})
+	}
+
+	return s.issue(ctx, doc, &models.Invoice{OrderID: order.ID, Kind: invoice.KindCreditNote, ReturnID: &ret.ID, InvoiceID: &original.ID}, creditNoteSeries)
+}
+
+func (s *invoiceService) issue(ctx context.Context, doc *invoice.Document, inv *models.Invoice, series string) (*models.Invoice, error) {
+	if err := s.prepare(doc, inv); err != nil {
+		return nil, err
+	}
+	issued, err := s.invoices.Issue(ctx, inv, series)
+	if err != nil {
+		return nil, err
+	}
+	if issued.ID == inv.ID {
+		s.logIssued(issued)
+	}
+	return issued, nil
+}
+
+// prepare fills in inv from doc, short of the number it is given when stored.
+func (s *invoiceService) prepare(doc *invoice.Document, inv *models.Invoice) error {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	inv.ID = uuid.NewString()
+	inv.Currency = doc.Currency
+	inv.TotalAmount = doc.TotalAmount
+	inv.TaxAmount = doc.TaxAmount
+	inv.Document = body
+	inv.IssuedAt = s.now().UTC()
+	return nil
+}
+
+func (s *invoiceService) logIssued(inv *models.Invoice) {
+	s.logger.Printf("%s issued number=%s order_id=%s total=%s", inv.Kind, inv.Number, inv.OrderID, money.New(inv.TotalAmount, inv.Currency))
+}
+
+// buyer is the customer as recorded in user-service, billed at the shipping
+// address. Customers that no longer exist are billed by the address name.
+func (s *invoiceService) buyer(ctx context.Context, order *models.Order) (invoice.Party, error) {
+	party := invoice.Party{}
+	if a := order.ShippingAddress; a != nil {
+		party.Name = a.Name
+		for _, line := range []string{a.Line1, a.Line2, strings.TrimSpace(a.PostalCode + " " + a.City), a.Region, a.Country} {
+			if line != "" {
+				party.AddressLines = append(party.AddressLines, line)
+			}
+		}
+	}
+	if s.users != nil {
+		user, err := s.users.GetUser(ctx, order.UserID)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return invoice.Party{}, fmt.Errorf("%w: %v", ErrUserServiceUnavailable, err)
+		default:
+			party.Name = user.Name
+			party.Email = user.Email
+		}
+	}
+	if party.Name == "" {
+		party.Name = "Customer " + order.UserID
+	}
+	return party, nil
+}
+
+func hasInvoice(issued []models.Invoice) bool {
+	for _, inv := range issued {
+		if inv.Kind == invoice.KindInvoice {
+			return true
+		}
+	}
+	return false
+}
+
+// currentInvoice is the invoice no later invoice replaces, or nil.
+func currentInvoice(issued []models.Invoice) *models.Invoice {
+	replaced := make(map[string]bool, len(issued))
+	for _, inv := range issued {
+		if inv.Kind == invoice.KindInvoice && inv.InvoiceID != nil {
+			replaced[*inv.InvoiceID] = true
+		}
+	}
+	for i := range issued {
+		if issued[i].Kind == invoice.KindInvoice && !replaced[issued[i].ID] {
+			return &issued[i]
+		}
+	}
+	return nil
+}
+
+func invoiceTaxLine(line models.OrderTaxLine, taxable, amount int64) invoice.TaxLine {
+	name := line.Name
+	if name == "" {
+		name = strings.TrimSpace(line.Country + " " + line.Region + " " + line.TaxClass)
+	}
+	return invoice.TaxLine{Name: name, Rate: line.Rate, TaxableAmount: taxable, Amount: amount, Exempt: line.Exempt}
+}
+
+// scaleAmounts multiplies every amount by num/den and gives the rounding
+// leftover to the last one, so the results add up to the scaled sum.
+func scaleAmounts(amounts []int64, num, den int64, currency string) []int64 {
+	var sum int64
+	for _, a := range amounts {
+		sum += a
+	}
+	left := money.New(sum, currency).MulRatio(num, den).Amount
+	scaled := make([]int64, len(amounts))
+	for i, a := range amounts {
+		if i == len(amounts)-1 {
+			scaled[i] = left
+			break
+		}
+		scaled[i] = money.New(a, currency).MulRatio(num, den).Amount
+		left -= scaled[i]
+	}
+	return scaled
+}
+
+func documentOf(inv *models.Invoice) (*invoice.Document, error) {
+	var doc invoice.Document
+	if err := json.Unmarshal(inv.Document, &doc); err != nil {
+		return nil, fmt.Errorf("decode invoice %s: %w", inv.Number, err)
+	}
+	doc.Number = inv.Number
+	doc.IssuedAt = inv.IssuedAt
+	return &doc, nil
+}
+
+func toPBParty(p invoice.Party) *orderpb.InvoicePartyData {
+	return &orderpb.InvoicePartyData{Name: p.Name, Email: p.Email, TaxId: p.TaxID, AddressLines: p.AddressLines}
+}
+
+func toPBInvoice(inv *models.Invoice) (*orderpb.InvoiceData, error) {
+	doc, err := documentOf(inv)
+	if err != nil {
+		return nil, err
+	}
+	data := &orderpb.InvoiceData{
+		Id:               inv.ID,
+		Kind:             inv.Kind,
+		Number:           inv.Number,
+		OrderId:          inv.OrderID,
+		InvoiceNumber:    doc.InvoiceNumber,
+		IssuedAt:         inv.IssuedAt.Format(time.RFC3339),
+		Seller:           toPBParty(doc.Seller),
+		Buyer:            toPBParty(doc.Buyer),
+		Currency:         doc.Currency,
+		Lines:            make([]*orderpb.InvoiceLineData, 0, len(doc.Lines)),
+		TaxLines:         make([]*orderpb.InvoiceTaxLineData, 0, len(doc.TaxLines)),
+		SubtotalAmount:   amount(doc.SubtotalAmount, doc.Currency),
+		DiscountAmount:   amount(doc.DiscountAmount, doc.Currency),
+		ShippingAmount:   amount(doc.ShippingAmount, doc.Currency),
+		TaxAmount:        amount(doc.TaxAmount, doc.Currency),
+		TotalAmount:      amount(doc.TotalAmount, doc.Currency),
+		PricesIncludeTax: doc.PricesIncludeTax,
+		TaxExempt:        doc.TaxExempt,
+		Note:             doc.Note,
+	}
+	if inv.ReturnID != nil {
+		data.ReturnId = *inv.ReturnID
+	}
+	for _, line := range doc.Lines {
+		data.Lines = append(data.Lines, &orderpb.InvoiceLineData{
+			Description:    line.Description,
+			Sku:            line.SKU,
+			Quantity:       line.Quantity,
+			UnitAmount:     amount(line.UnitAmount, doc.Currency),
+			DiscountAmount: amount(line.Discount, doc.Currency),
+			Amount:         amount(line.Amount, doc.Currency),
+		})
+	}
+	for _, line := range doc.TaxLines {
+		data.TaxLines = append(data.TaxLines, &orderpb.InvoiceTaxLineData{
+			Name:          line.Name,
+			Rate:          tax.FormatRate(line.Rate),
+			TaxableAmount: amount(line.TaxableAmount, doc.Currency),
+			Amount:        amount(line.Amount, doc.Currency),
+			Exempt:        line.Exempt,
+		})
+	}
+	return data, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/invoice"
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+	userpb "online-store-microservice/proto/user"
+)
+
+// memoryInvoices numbers documents per series like the postgres repository,
+// without years since the tests never cross one.
+type memoryInvoices struct {
+	last     map[string]int64
+	invoices []models.Invoice
+}
+
+func (r *memoryInvoices) Issue(ctx context.Context, inv *models.Invoice, series string) (*models.Invoice, error) {
+	issued, _ := r.ListByOrder(ctx, inv.OrderID)
+	if current := currentInvoice(issued); inv.Kind == invoice.KindInvoice && current != nil {
+		return current, nil
+	}
+	for _, existing := range issued {
+		if existing.Kind == inv.Kind && inv.ReturnID != nil && existing.ReturnID != nil && *existing.ReturnID == *inv.ReturnID {
+			return &existing, nil
+		}
+	}
+	r.create(inv, series)
+	return inv, nil
+}
+
+func (r *memoryInvoices) Reissue(ctx context.Context, replaced, credit, inv *models.Invoice, creditSeries, series string) (*models.Invoice, error) {
+	issued, _ := r.ListByOrder(ctx, replaced.OrderID)
+	if current := currentInvoice(issued); current != nil && current.ID != replaced.ID {
+		return current, nil
+	}
+	credit.InvoiceID, inv.InvoiceID = &replaced.ID, &replaced.ID
+	r.create(credit, creditSeries)
+	r.create(inv, series)
+	return inv, nil
+}
+
+func (r *memoryInvoices) create(inv *models.Invoice, series string) {
+	r.last[series]++
+	inv.Year = inv.IssuedAt.Year()
+	inv.Sequence = r.last[series]
+	inv.Number = invoice.Number(series, inv.Year, inv.Sequence)
+	r.invoices = append(r.invoices, *inv)
+}
+
+func (r *memoryInvoices) ListByOrder(_ context.Context, orderID string) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	for _, inv := range r.invoices {
+		if inv.OrderID == orderID {
+			invoices = append(invoices, inv)
+		}
+	}
+	return invoices, nil
+}
+
+func newInvoiceService(order *models.Order) (*invoiceService, *memoryInvoices) {
+	orders := newMemoryOrders(order)
+	invoices := &memoryInvoices{last: map[string]int64{}}
+	users := &fakeUserLookup{users: map[string]*userpb.UserData{order.UserID: {Id: order.UserID, Name: "Budi", Email: "budi@example.com"}}}
+	seller := invoice.Party{Name: "Online Store", TaxID: "01.234.567.8-901.000"}
+	svc := NewInvoiceService(orders, nil, invoices, users, seller, log.New(io.Discard, "", 0)).(*invoiceService)
+	return svc, invoices
+}
+
+// taxedOrder is deliveredOrder with its 10% tax split in one tax line per item.
+func taxedOrder() *models.Order {
+	order := deliveredOrder()
+	order.ShippingAddress = &models.Address{Name: "B. Santoso", Line1: "1 Market St", City: "San Francisco", Country: "US"}
+	for i, item := range order.Items {
+		id := item.ID
+		order.TaxLines = append(order.TaxLines, models.OrderTaxLine{
+			OrderItemID: &id, Position: int32(i), Country: "US", TaxClass: "standard",
+			Rate: 100000, TaxableAmount: item.LineAmount, Amount: item.LineAmount / 10,
+		})
+	}
+	return order
+}
+
+func TestGetInvoiceIssuesOnceAndRenders(t *testing.T) {
+	order := taxedOrder()
+	svc, invoices := newInvoiceService(order)
+	ctx := context.Background()
+
+	first, err := svc.GetInvoice(ctx, &orderpb.GetInvoiceRequest{OrderId: order.ID, ActorId: order.UserID})
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	inv := first.Invoice
+	wantNumber := fmt.Sprintf("INV-%d-000001", time.Now().UTC().Year())
+	if inv.Number != wantNumber || inv.Kind != invoice.KindInvoice || len(first.Content) != 0 {
+		t.Fatalf("invoice = %s %s, content %d bytes", inv.Kind, inv.Number, len(first.Content))
+	}
+	if inv.Buyer.Name != "Budi" || inv.Buyer.Email != "budi@example.com" || len(inv.Buyer.AddressLines) != 3 || inv.Seller.TaxId == "" {
+		t.Fatalf("parties = %+v / %+v", inv.Seller, inv.Buyer)
+	}
+	if len(inv.Lines) != 2 || inv.TotalAmount.Amount != 27500 || inv.TaxAmount.Amount != 2500 || inv.TaxLines[0].Rate != "0.1" {
+		t.Fatalf("invoice lines = %+v, total %d", inv.Lines, inv.TotalAmount.Amount)
+	}
+
+	// The snapshot does not follow later changes to the order.
+	order.TotalAmount = 1
+	pdf, err := svc.GetInvoice(ctx, &orderpb.GetInvoiceRequest{OrderId: order.ID, ActorIsAdmin: true, Format: "pdf"})
+	if err != nil {
+		t.Fatalf("GetInvoice pdf: %v", err)
+	}
+	if pdf.Invoice.Number != wantNumber || pdf.Invoice.TotalAmount.Amount != 27500 || len(invoices.invoices) != 1 {
+		t.Fatalf("second call issued %d invoices, number %s", len(invoices.invoices), pdf.Invoice.Number)
+	}
+	if pdf.ContentType != "application/pdf" || !bytes.HasPrefix(pdf.Content, []byte("%PDF-")) {
+		t.Fatalf("content type %q", pdf.ContentType)
+	}
+}
+
+func TestGetInvoiceRejectsInvalidRequests(t *testing.T) {
+	cases := []struct {
+		name   string
+		status string
+		req    func(*models.Order) *orderpb.GetInvoiceRequest
+		want   error
+	}{
+		{"other user", models.OrderStatusPaid, func(o *models.Order) *orderpb.GetInvoiceRequest {
+			return &orderpb.GetInvoiceRequest{OrderId: o.ID, ActorId: uuid.NewString()}
+		}, ErrForbidden},
+		{"unpaid order", models.OrderStatusAwaitingPayment, func(o *models.Order) *orderpb.GetInvoiceRequest {
+			return &orderpb.GetInvoiceRequest{OrderId: o.ID, ActorIsAdmin: true}
+		}, ErrNotInvoiced},
+		{"unknown format", models.OrderStatusPaid, func(o *models.Order) *orderpb.GetInvoiceRequest {
+			return &orderpb.GetInvoiceRequest{OrderId: o.ID, ActorIsAdmin: true, Format: "docx"}
+		}, ErrInvalidInvoiceFormat},
+		{"unknown credit note", models.OrderStatusPaid, func(o *models.Order) *orderpb.GetInvoiceRequest {
+			return &orderpb.GetInvoiceRequest{OrderId: o.ID, ActorIsAdmin: true, Number: "CN-2026-000009"}
+		}, repository.ErrInvoiceNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := taxedOrder()
+			order.Status = tc.status
+			svc, _ := newInvoiceService(order)
+			if _, err := svc.GetInvoice(context.Background(), tc.req(order)); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreditNoteSplitsRefundIntoNetAndTax(t *testing.T) {
+	order := taxedOrder()
+	svc, _ := newInvoiceService(order)
+	ctx := context.Background()
+
+	ret := &models.OrderReturn{
+		ID:             uuid.NewString(),
+		OrderID:        order.ID,
+		Status:         models.ReturnStatusRefunded,
+		Reason:         "damaged",
+		Currency:       "USD",
+		RefundedAmount: 11000,
+		Items:          []models.OrderReturnItem{{OrderItemID: order.Items[0].ID, Name: "Mouse", Quantity: 1, Amount: 11000}},
+	}
+	note, err := svc.IssueCreditNote(ctx, order, ret)
+	if err != nil {
+		t.Fatalf("IssueCreditNote: %v", err)
+	}
+	if again, _ := svc.IssueCreditNote(ctx, order, ret); again.ID != note.ID {
+		t.Fatal("credit note was issued twice for one return")
+	}
+
+	resp, err := svc.GetInvoice(ctx, &orderpb.GetInvoiceRequest{OrderId: order.ID, ActorIsAdmin: true, Number: note.Number})
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	cn := resp.Invoice
+	if cn.Kind != invoice.KindCreditNote || cn.ReturnId != ret.ID || len(resp.CreditNotes) != 1 {
+		t.Fatalf("credit note = %+v", cn)
+	}
+	if cn.InvoiceNumber != fmt.Sprintf("INV-%d-000001", time.Now().UTC().Year()) {
+		t.Fatalf("credit note corrects %q", cn.InvoiceNumber)
+	}
+	if cn.TotalAmount.Amount != 11000 || cn.TaxAmount.Amount != 1000 || cn.SubtotalAmount.Amount != 10000 {
+		t.Fatalf("total %d, tax %d, subtotal %d", cn.TotalAmount.Amount, cn.TaxAmount.Amount, cn.SubtotalAmount.Amount)
+	}
+	if cn.Lines[0].Amount.Amount != 10000 || cn.TaxLines[0].Amount.Amount+cn.TaxLines[1].Amount.Amount != 1000 {
+		t.Fatalf("lines = %+v, tax lines = %+v", cn.Lines, cn.TaxLines)
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var (
+	ErrNothingToAmend = errors.New("items or shipping_address is required")
+	ErrNotAmendable   = errors.New("order can no longer be amended")
+	ErrCompensation   = errors.New("compensation failed")
+)
+
+// amendableStatuses are those before fulfillment in which the amount paid, if
+// any, is settled. awaiting_payment is left out since a payment for the old
+// total may be under way.
+var amendableStatuses = map[string]bool{
+	models.OrderStatusPending: true,
+	models.OrderStatusPaid:    true,
+}
+
+// AmendOrder replaces the items or the shipping address of an order that is
+// not yet being fulfilled and prices it again the way CreateOrder would,
+// today's prices and exchange rate included. The coupon is kept and applied
+// with the terms it had when the order was placed; orders placed before those
+// were recorded use the coupon as it is now, which fails if it has been
+// disabled or no longer applies. What changed is recorded in the
+// history. For a paid order the difference in total is the amount to charge
+// or refund, which is handed to the compensation hook before the amendment is
+// stored and handed back reversed if storing it fails. Its invoice is then
+// replaced by one for the amended order, with a credit note cancelling it.
+func (s *orderService) AmendOrder(ctx context.Context, req *orderpb.AmendOrderRequest) (*orderpb.AmendOrderResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+	if len(req.Items) == 0 && req.ShippingAddress == nil {
+		return nil, ErrNothingToAmend
+	}
+
+	order, err := s.repo.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := orderActor(order, req.ActorId, req.ActorIsAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if !amendableStatuses[order.Status] {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotAmendable, order.Status)
+	}
+	paid := order.Status == models.OrderStatusPaid
+
+	create := &orderpb.CreateOrderRequest{
+		UserId:          order.UserID,
+		Items:           req.Items,
+		Currency:        order.Currency,
+		CouponCode:      order.CouponCode,
+		ShippingAddress: toPBAddress(order.ShippingAddress),
+		ShippingMethod:  order.ShippingMethod,
+	}
+	if len(create.Items) == 0 {
+		for _, item := range orderLines(order) {
+			create.Items = append(create.Items, &orderpb.OrderItemInput{ProductId: reorderRef(item), Quantity: item.Quantity})
+		}
+	}
+	if req.ShippingAddress != nil {
+		if create.ShippingAddress, err = normalizeAddress(req.ShippingAddress); err != nil {
+			return nil, err
+		}
+	}
+	expectedTotal, err := expectedAmount(req.ExpectedTotalAmount, 0, order.Currency)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := lineRequests(create, order.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	promo, err := s.orderPromotion(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	quote, err := s.quote(ctx, create, lines, promo)
+	if err != nil {
+		return nil, err
+	}
+	if err := quote.CheckExpectedTotal(expectedTotal); err != nil {
+		return nil, err
+	}
+	amended := *order
+	amended.ShippingAddress = addressFromPB(create.ShippingAddress)
+	applyQuote(&amended, quote)
+
+	changes := orderChanges(order, &amended)
+	delta := money.New(amended.TotalAmount-order.TotalAmount, order.Currency)
+	resp := &orderpb.AmendOrderResponse{
+		Preview:             req.Preview,
+		Changes:             toPBChanges(changes),
+		PreviousTotalAmount: amount(order.TotalAmount, order.Currency),
+		DeltaAmount:         &delta,
+		ChargeAmount:        amount(0, order.Currency),
+		RefundAmount:        amount(0, order.Currency),
+	}
+	if paid && delta.Amount > 0 {
+		resp.ChargeAmount = amount(delta.Amount, order.Currency)
+	}
+	if paid && delta.Amount < 0 {
+		resp.RefundAmount = amount(-delta.Amount, order.Currency)
+	}
+	if req.Preview || len(changes) == 0 {
+		resp.Order = toPBOrder(&amended)
+		return resp, nil
+	}
+
+	compensate := paid && delta.Amount != 0
+	if compensate {
+		if err := s.compensation.OnPaidOrderAmended(ctx, &amended, delta); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrCompensation, err)
+		}
+	}
+	if err := s.repo.Amend(ctx, &amended, changes, changeMeta(ctx, actor, "amended")); err != nil {
+		err = versionError(err, req.ExpectedVersion)
+		if compensate {
+			if cerr := s.compensation.OnPaidOrderAmended(ctx, order, money.New(-delta.Amount, delta.Currency)); cerr != nil {
+				s.logger.Printf("compensation rollback failed order_id=%s delta=%s err=%v", order.ID, delta, cerr)
+				return nil, fmt.Errorf("%w (compensation rollback failed: %v)", err, cerr)
+			}
+		}
+		return nil, err
+	}
+
+	// A failed invoice is issued on the next GetInvoice instead.
+	if paid && s.invoices != nil {
+		if _, err := s.invoices.ReissueInvoice(ctx, &amended); err != nil {
+			s.logger.Printf("reissue invoice failed order_id=%s err=%v", amended.ID, err)
+		}
+	}
+	resp.Order = toPBOrder(&amended)
+	return resp, nil
+}
+
+// orderPromotion is the coupon of an order with the terms recorded when it
+// was placed, or, for orders placed before terms were recorded, with those of
+// the coupon now if it is still usable at the order's creation time.
+func (s *orderService) orderPromotion(ctx context.Context, order *models.Order) (*pricing.Promotion, error) {
+	switch {
+	case order.CouponCode == "":
+		return nil, nil
+	case order.CouponTerms != nil:
+		return promotionOf(order.CouponCode, order.CouponTerms), nil
+	}
+	promo, err := s.promotion(ctx, order.CouponCode, order.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: the order's coupon no longer applies: %v", ErrNotAmendable, err)
+	}
+	return promo, nil
+}
+
+// orderChanges lists what differs between an order and its amended version:
+// item quantities by product, the shipping address, the exchange rate and
+// the amounts.
+func orderChanges(before, after *models.Order) []models.OrderChange {
+	var changes []models.OrderChange
+
+	was, is := itemQuantities(before), itemQuantities(after)
+	seen := map[string]bool{}
+	for _, items := range [][]models.OrderItem{orderLines(before), orderLines(after)} {
+		for _, item := range items {
+			ref := reorderRef(item)
+			if seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			if was[ref] != is[ref] {
+				changes = append(changes, models.OrderChange{Field: "item:" + ref, From: quantityText(was[ref]), To: quantityText(is[ref])})
+			}
+		}
+	}
+
+	if from, to := addressText(before.ShippingAddress), addressText(after.ShippingAddress); from != to {
+		changes = append(changes, models.OrderChange{Field: "shipping_address", From: from, To: to})
+	}
+	if from, to := exchangeRate(before), exchangeRate(after); from != to {
+		changes = append(changes, models.OrderChange{Field: "exchange_rate", From: from, To: to})
+	}
+
+	amounts := []struct {
+		field         string
+		before, after int64
+	}{
+		{"subtotal_amount", before.SubtotalAmount, after.SubtotalAmount},
+		{"discount_amount", before.DiscountAmount, after.DiscountAmount},
+		{"shipping_amount", before.ShippingAmount, after.ShippingAmount},
+		{"tax_amount", before.TaxAmount, after.TaxAmount},
+		{"total_amount", before.TotalAmount, after.TotalAmount},
+	}
+	for _, a := range amounts {
+		if a.before != a.after {
+			changes = append(changes, models.OrderChange{
+				Field: a.field,
+				From:  money.New(a.before, before.Currency).String(),
+				To:    money.New(a.after, after.Currency).String(),
+			})
+		}
+	}
+	return changes
+}
+
+func itemQuantities(order *models.Order) map[string]int32 {
+	quantities := map[string]int32{}
+	for _, item := range orderLines(order) {
+		quantities[reorderRef(item)] += item.Quantity
+	}
+	return quantities
+}
+
+func quantityText(qty int32) string {
+	if qty == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(qty))
+}
+
+func addressText(a *models.Address) string {
+	if a == nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{a.Name, a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func toPBChanges(changes []models.OrderChange) []*orderpb.OrderChangeData {
+	if len(changes) == 0 {
+		return nil
+	}
+	out := make([]*orderpb.OrderChangeData, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, &orderpb.OrderChangeData{Field: c.Field, From: c.From, To: c.To})
+	}
+	return out
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/invoice"
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// newAmendService places an order for two mice on sale at 90.00 and leaves it
+// in status. It returns the order as placed.
+func newAmendService(t *testing.T, status string) (OrderService, *memoryOrders, *models.Order, *recordingCompensation) {
+	t.Helper()
+	hook := &recordingCompensation{}
+	svc, repo := newTestOrderService(OrderServiceDeps{Compensation: hook})
+
+	_, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+		UserId: uuid.NewString(),
+		Items:  []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order := repo.created[0]
+	order.Status = status
+	return svc, repo, order, hook
+}
+
+func TestAmendPaidOrderChargesDifference(t *testing.T) {
+	svc, repo, order, hook := newAmendService(t, models.OrderStatusPaid)
+
+	resp, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{
+		Id:      order.ID,
+		ActorId: order.UserID,
+		Items:   []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}, {ProductId: "pad", Quantity: 2}},
+	})
+	if err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	if len(repo.amended) != 1 || repo.amended[0].ID != order.ID || len(repo.amended[0].Items) != 2 || repo.amended[0].TotalAmount != 19000 {
+		t.Fatalf("amended = %+v", repo.amended)
+	}
+	if order.TotalAmount != 18000 || len(order.Items) != 1 {
+		t.Fatalf("stored order was modified in place: total %d, %d items", order.TotalAmount, len(order.Items))
+	}
+	if resp.DeltaAmount.Amount != 1000 || resp.ChargeAmount.Amount != 1000 || resp.RefundAmount.Amount != 0 || resp.PreviousTotalAmount.Amount != 18000 {
+		t.Fatalf("delta %d, charge %d, refund %d", resp.DeltaAmount.Amount, resp.ChargeAmount.Amount, resp.RefundAmount.Amount)
+	}
+	if len(hook.deltas) != 1 || hook.deltas[0].Amount != 1000 {
+		t.Fatalf("compensation deltas = %+v", hook.deltas)
+	}
+
+	want := []models.OrderChange{
+		{Field: "item:mouse", From: "2", To: "1"},
+		{Field: "item:pad", To: "2"},
+		{Field: "discount_amount", From: "20.00 USD", To: "10.00 USD"},
+		{Field: "total_amount", From: "180.00 USD", To: "190.00 USD"},
+	}
+	if len(repo.changes) != len(want) {
+		t.Fatalf("changes = %+v", repo.changes)
+	}
+	for i := range want {
+		if repo.changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, repo.changes[i], want[i])
+		}
+	}
+}
+
+func TestAmendPendingOrderAddress(t *testing.T) {
+	svc, repo, order, hook := newAmendService(t, models.OrderStatusPending)
+
+	resp, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{
+		Id:              order.ID,
+		ActorIsAdmin:    true,
+		ShippingAddress: &orderpb.Address{Line1: " 1 Main St ", Country: "us"},
+		Items:           []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	if resp.DeltaAmount.Amount != -9000 || resp.ChargeAmount.Amount != 0 || resp.RefundAmount.Amount != 0 {
+		t.Fatalf("delta %d, charge %d, refund %d", resp.DeltaAmount.Amount, resp.ChargeAmount.Amount, resp.RefundAmount.Amount)
+	}
+	if len(hook.deltas) != 0 {
+		t.Fatalf("unpaid order reached compensation: %+v", hook.deltas)
+	}
+	if a := repo.amended[0].ShippingAddress; a == nil || a.Country != "US" || a.Line1 != "1 Main St" {
+		t.Fatalf("address = %+v", a)
+	}
+}
+
+// newCouponAmendService places a paid order for two mice on sale at 90.00
+// with 10.00 off from coupon TENOFF, and returns the coupon to change.
+func newCouponAmendService(t *testing.T) (OrderService, *memoryOrders, *models.Order, *models.Coupon) {
+	t.Helper()
+	coupon := &models.Coupon{Code: "TENOFF", Kind: pricing.PromotionFixed, Currency: "USD", AmountOff: 1000, Active: true}
+	svc, repo := newCouponOrderService(coupon)
+	_, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+		UserId:     uuid.NewString(),
+		Items:      []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 2}},
+		CouponCode: "TENOFF",
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order := repo.created[0]
+	if order.TotalAmount != 17000 || order.CouponTerms == nil || order.CouponTerms.AmountOff != 1000 {
+		t.Fatalf("placed order total %d, coupon terms %+v", order.TotalAmount, order.CouponTerms)
+	}
+	order.Status = models.OrderStatusPaid
+	return svc, repo, order, coupon
+}
+
+func TestAmendOrderKeepsCouponTerms(t *testing.T) {
+	svc, _, order, coupon := newCouponAmendService(t)
+	coupon.AmountOff = 5000
+	coupon.Active = false
+
+	resp, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{
+		Id:      order.ID,
+		ActorId: order.UserID,
+		Items:   []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	if resp.Order.TotalAmount.Amount != 8000 || resp.Order.CouponCode != "TENOFF" || resp.DeltaAmount.Amount != -9000 {
+		t.Fatalf("amended total %d with coupon %q, delta %d; want 80.00 with TENOFF as placed", resp.Order.TotalAmount.Amount, resp.Order.CouponCode, resp.DeltaAmount.Amount)
+	}
+}
+
+func TestAmendOrderWithoutCouponTermsChecksCoupon(t *testing.T) {
+	svc, repo, order, coupon := newCouponAmendService(t)
+	order.CouponTerms = nil
+	amend := &orderpb.AmendOrderRequest{
+		Id:      order.ID,
+		ActorId: order.UserID,
+		Items:   []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}},
+		Preview: true,
+	}
+
+	// Placed before terms were recorded: the coupon as it is now is used.
+	coupon.AmountOff = 2000
+	resp, err := svc.AmendOrder(context.Background(), amend)
+	if err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	if resp.Order.TotalAmount.Amount != 7000 {
+		t.Fatalf("amended total = %d, want 70.00 with the current coupon", resp.Order.TotalAmount.Amount)
+	}
+
+	coupon.Active = false
+	_, err = svc.AmendOrder(context.Background(), amend)
+	if !errors.Is(err, ErrNotAmendable) || !strings.Contains(err.Error(), "TENOFF is disabled") {
+		t.Fatalf("err = %v, want ErrNotAmendable naming the disabled coupon", err)
+	}
+	if len(repo.amended) != 0 {
+		t.Fatalf("amended = %d", len(repo.amended))
+	}
+}
+
+func TestAmendOrderPreviewDoesNotStore(t *testing.T) {
+	svc, repo, order, _ := newAmendService(t, models.OrderStatusPaid)
+
+	resp, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{
+		Id:      order.ID,
+		ActorId: order.UserID,
+		Items:   []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}},
+		Preview: true,
+	})
+	if err != nil {
+		t.Fatalf("AmendOrder preview: %v", err)
+	}
+	if !resp.Preview || resp.Order.Id != order.ID || resp.Order.TotalAmount.Amount != 9000 || resp.RefundAmount.Amount != 9000 || len(repo.amended) != 0 {
+		t.Fatalf("preview total %d, refund %d, stored %v", resp.Order.TotalAmount.Amount, resp.RefundAmount.Amount, len(repo.amended) != 0)
+	}
+}
+
+func TestAmendOrderRejects(t *testing.T) {
+	items := []*orderpb.OrderItemInput{{ProductId: "pad", Quantity: 1}}
+	for _, status := range []string{models.OrderStatusAwaitingPayment, models.OrderStatusFulfilling, models.OrderStatusShipped, models.OrderStatusCancelled} {
+		svc, _, order, _ := newAmendService(t, status)
+		_, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{Id: order.ID, ActorIsAdmin: true, Items: items})
+		if !errors.Is(err, ErrNotAmendable) {
+			t.Errorf("%s: err = %v, want ErrNotAmendable", status, err)
+		}
+	}
+
+	svc, repo, order, _ := newAmendService(t, models.OrderStatusPending)
+	ctx := context.Background()
+	if _, err := svc.AmendOrder(ctx, &orderpb.AmendOrderRequest{Id: order.ID, ActorIsAdmin: true}); !errors.Is(err, ErrNothingToAmend) {
+		t.Errorf("no changes: err = %v, want ErrNothingToAmend", err)
+	}
+	if _, err := svc.AmendOrder(ctx, &orderpb.AmendOrderRequest{Id: order.ID, ActorId: uuid.NewString(), Items: items}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("other user: err = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.AmendOrder(ctx, &orderpb.AmendOrderRequest{Id: order.ID, ActorIsAdmin: true, ExpectedVersion: 7, Items: items}); !errors.Is(err, ErrVersionConflict) {
+		t.Errorf("stale version: err = %v, want ErrVersionConflict", err)
+	}
+	if len(repo.amended) != 0 {
+		t.Fatal("a rejected amendment was stored")
+	}
+}
+
+func TestAmendPaidOrderCompensatesBeforeStoring(t *testing.T) {
+	items := []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}}
+
+	svc, repo, order, hook := newAmendService(t, models.OrderStatusPaid)
+	hook.fail = errors.New("payment provider down")
+	_, err := svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{Id: order.ID, ActorId: order.UserID, Items: items})
+	if !errors.Is(err, ErrCompensation) {
+		t.Fatalf("err = %v, want ErrCompensation", err)
+	}
+	if len(repo.amended) != 0 {
+		t.Fatal("amendment stored although compensation failed")
+	}
+
+	svc, repo, order, hook = newAmendService(t, models.OrderStatusPaid)
+	order.Version = 1
+	repo.stale = true
+	_, err = svc.AmendOrder(context.Background(), &orderpb.AmendOrderRequest{Id: order.ID, ActorId: order.UserID, Items: items, ExpectedVersion: order.Version})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+	if len(hook.deltas) != 2 || hook.deltas[0].Amount != -9000 || hook.deltas[1].Amount != 9000 {
+		t.Fatalf("compensation deltas = %+v, want the refund and its reversal", hook.deltas)
+	}
+}
+
+func TestAmendInvoicedOrderReissuesInvoice(t *testing.T) {
+	repo := newMemoryOrders()
+	logger := log.New(io.Discard, "", 0)
+	invoices := &memoryInvoices{last: map[string]int64{}}
+	invoicer := NewInvoiceService(repo, nil, invoices, nil, invoice.Party{Name: "Shop"}, logger)
+	hook := &recordingCompensation{}
+	svc, _ := newTestOrderService(OrderServiceDeps{Repo: repo, Invoices: invoicer, Compensation: hook})
+	ctx := context.Background()
+
+	created, err := svc.CreateOrder(ctx, &orderpb.CreateOrderRequest{UserId: uuid.NewString(), Items: []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 2}}})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	id := created.Order.Id
+	for _, status := range []string{models.OrderStatusAwaitingPayment, models.OrderStatusPaid} {
+		if _, err := svc.UpdateOrderStatus(ctx, &orderpb.UpdateOrderStatusRequest{Id: id, Status: status}); err != nil {
+			t.Fatalf("UpdateOrderStatus(%s): %v", status, err)
+		}
+	}
+
+	resp, err := svc.AmendOrder(ctx, &orderpb.AmendOrderRequest{Id: id, ActorIsAdmin: true, Items: []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}}})
+	if err != nil {
+		t.Fatalf("AmendOrder: %v", err)
+	}
+	if resp.DeltaAmount.Amount != -9000 || resp.RefundAmount.Amount != 9000 || len(hook.deltas) != 1 || hook.deltas[0].Amount != -9000 {
+		t.Fatalf("delta %d, refund %d, compensation deltas %+v", resp.DeltaAmount.Amount, resp.RefundAmount.Amount, hook.deltas)
+	}
+
+	if len(invoices.invoices) != 3 {
+		t.Fatalf("issued %d documents, want the invoice, its credit note and the new invoice", len(invoices.invoices))
+	}
+	old, credit, next := invoices.invoices[0], invoices.invoices[1], invoices.invoices[2]
+	if credit.Kind != invoice.KindCreditNote || credit.TotalAmount != 18000 || credit.ReturnID != nil || *credit.InvoiceID != old.ID {
+		t.Fatalf("credit note = %+v, want 180.00 against %s", credit, old.Number)
+	}
+	if next.Kind != invoice.KindInvoice || next.TotalAmount != 9000 || *next.InvoiceID != old.ID {
+		t.Fatalf("new invoice = %+v, want 90.00 replacing %s", next, old.Number)
+	}
+
+	got, err := invoicer.GetInvoice(ctx, &orderpb.GetInvoiceRequest{OrderId: id, ActorIsAdmin: true})
+	if err != nil {
+		t.Fatalf("GetInvoice: %v", err)
+	}
+	if got.Invoice.Number != next.Number || got.Invoice.InvoiceNumber != old.Number || len(got.CreditNotes) != 1 || got.CreditNotes[0].Number != credit.Number {
+		t.Fatalf("GetInvoice = %s correcting %s, %d credit notes", got.Invoice.Number, got.Invoice.InvoiceNumber, len(got.CreditNotes))
+	}
+	if len(invoices.invoices) != 3 {
+		t.Fatalf("GetInvoice issued again: %d documents", len(invoices.invoices))
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func newCancelService(status string) (OrderService, *memoryOrders, *models.Order, *recordingCompensation) {
+	order := &models.Order{ID: uuid.NewString(), UserID: uuid.NewString(), Status: status, Version: 1}
+	hook := &recordingCompensation{}
+	svc, repo := newTestOrderService(OrderServiceDeps{Repo: newMemoryOrders(order), Compensation: hook})
+	return svc, repo, order, hook
+}
+
+func TestOwnerCancelsPendingOrder(t *testing.T) {
+	svc, repo, order, hook := newCancelService(models.OrderStatusPending)
+
+	resp, err := svc.CancelOrder(context.Background(), &orderpb.CancelOrderRequest{
+		Id: order.ID, ReasonCode: "changed_mind", ActorId: order.UserID,
+	})
+	if err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if resp.Order.Status != models.OrderStatusCancelled {
+		t.Fatalf("status = %s, want cancelled", resp.Order.Status)
+	}
+	if len(repo.cancelled) != 1 || repo.cancelled[0] != order.ID+":changed_mind" {
+		t.Fatalf("cancelled = %v", repo.cancelled)
+	}
+	if got := repo.metas[0].Actor; got != order.UserID {
+		t.Fatalf("actor = %q, want the owner", got)
+	}
+	if len(hook.cancelled) != 0 {
+		t.Fatalf("compensation ran for a pending order: %v", hook.cancelled)
+	}
+}
+
+func TestCancelOrderRefusesOtherUsers(t *testing.T) {
+	svc, repo, order, hook := newCancelService(models.OrderStatusPending)
+
+	_, err := svc.CancelOrder(context.Background(), &orderpb.CancelOrderRequest{
+		Id: order.ID, ReasonCode: "changed_mind", ActorId: uuid.NewString(),
+	})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("err = %v, want ErrForbidden", err)
+	}
+	if stored := repo.stored(order.ID); stored.Status != models.OrderStatusPending || len(repo.cancelled) != 0 {
+		t.Fatalf("order = %s, cancelled = %v, want it untouched", stored.Status, repo.cancelled)
+	}
+	if len(hook.cancelled) != 0 {
+		t.Fatalf("compensation ran: %v", hook.cancelled)
+	}
+}
+
+func TestAdminCancelsPaidOrderWithCompensation(t *testing.T) {
+	svc, repo, order, hook := newCancelService(models.OrderStatusPaid)
+
+	_, err := svc.CancelOrder(context.Background(), &orderpb.CancelOrderRequest{
+		Id: order.ID, ReasonCode: "fraud_suspected", ActorIsAdmin: true,
+	})
+	if err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if stored := repo.stored(order.ID); stored.Status != models.OrderStatusCancelled {
+		t.Fatalf("status = %s, want cancelled", stored.Status)
+	}
+	if got := repo.metas[0].Actor; got != "admin" {
+		t.Fatalf("actor = %q, want admin", got)
+	}
+	if len(hook.cancelled) != 1 || hook.cancelled[0] != order.ID {
+		t.Fatalf("compensated = %v, want [%s]", hook.cancelled, order.ID)
+	}
+}
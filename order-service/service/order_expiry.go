@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"expvar"
+	"log"
+	"time"
+
+	"online-store-microservice/order-service/repository"
+)
+
+// expiryReasonPaymentTimeout is recorded in the status history of orders the
+// expiry worker expires.
+const expiryReasonPaymentTimeout = "payment_timeout"
+
+// OrderExpiryWorker expires orders that are still unpaid paymentTTL after they
+// were created. Its counters are kept in metrics:
+//
+//	runs         sweeps started
+//	expired      orders moved to expired
+//	errors       sweeps that failed
+//	hook_errors  expired orders whose release hook failed
+type OrderExpiryWorker struct {
+	repo       repository.OrderRepository
+	hook       ExpiryHook
+	logger     *log.Logger
+	paymentTTL time.Duration
+	interval   time.Duration
+	batch      int
+	metrics    *expvar.Map
+}
+
+func NewOrderExpiryWorker(repo repository.OrderRepository, hook ExpiryHook, logger *log.Logger, paymentTTL, interval time.Duration, batch int, metrics *expvar.Map) *OrderExpiryWorker {
+	return &OrderExpiryWorker{repo: repo, hook: hook, logger: logger, paymentTTL: paymentTTL, interval: interval, batch: batch, metrics: metrics}
+}
+
+func (w *OrderExpiryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			expired, err := w.ExpireDue(ctx)
+			if err != nil {
+				w.logger.Printf("order expiry failed after %d orders: %v", expired, err)
+			} else if expired > 0 {
+				w.logger.Printf("expired %d orders unpaid for %s", expired, w.paymentTTL)
+			}
+		}
+	}
+}
+
+// ExpireDue expires overdue orders batch by batch until none are left, so a
+// backlog clears in one sweep. Each batch is its own transaction. The release
+// hook runs after the batch is committed; a failing hook is logged and counted
+// but does not undo the expiry.
+func (w *OrderExpiryWorker) ExpireDue(ctx context.Context) (int, error) {
+	w.metrics.Add("runs", 1)
+	createdBefore := time.Now().UTC().Add(-w.paymentTTL)
+	meta := repository.ChangeMeta{Actor: "system", Reason: expiryReasonPaymentTimeout}
+
+	total := 0
+	for ctx.Err() == nil {
+		orders, err := w.repo.ExpireUnpaid(ctx, createdBefore, w.batch, meta)
+		if err != nil {
+			w.metrics.Add("errors", 1)
+			return total, err
+		}
+		total += len(orders)
+		w.metrics.Add("expired", int64(len(orders)))
+
+		for i := range orders {
+			if err := w.hook.OnOrderExpired(ctx, &orders[i]); err != nil {
+				w.metrics.Add("hook_errors", 1)
+				w.logger.Printf("release failed order_id=%s err=%v", orders[i].ID, err)
+			}
+		}
+		if len(orders) == 0 || len(orders) < w.batch {
+			break
+		}
+	}
+	return total, nil
+}
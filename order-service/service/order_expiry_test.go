@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/models"
+)
+
+type releaseRecorder struct {
+	released []string
+	failFor  string
+}
+
+func (h *releaseRecorder) OnOrderExpired(_ context.Context, order *models.Order) error {
+	if order.ID == h.failFor {
+		return errors.New("inventory unavailable")
+	}
+	h.released = append(h.released, order.ID)
+	return nil
+}
+
+func metricValue(m *expvar.Map, key string) int64 {
+	if v, ok := m.Get(key).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+func TestOrderExpiryWorkerExpiresOverdueOrdersInBatches(t *testing.T) {
+	old := time.Now().Add(-2 * time.Hour)
+	repo := newMemoryOrders(
+		&models.Order{ID: "o1", Status: models.OrderStatusPending, CreatedAt: old},
+		&models.Order{ID: "o2", Status: models.OrderStatusAwaitingPayment, CreatedAt: old},
+		&models.Order{ID: "o3", Status: models.OrderStatusPending, CreatedAt: old},
+		&models.Order{ID: "o4", Status: models.OrderStatusPaid, CreatedAt: old},
+		&models.Order{ID: "o5", Status: models.OrderStatusPending, CreatedAt: time.Now()},
+	)
+	hook := &releaseRecorder{failFor: "o2"}
+	metrics := new(expvar.Map)
+
+	worker := NewOrderExpiryWorker(repo, hook, log.New(io.Discard, "", 0), time.Hour, time.Minute, 2, metrics)
+	expired, err := worker.ExpireDue(context.Background())
+	if err != nil {
+		t.Fatalf("ExpireDue: %v", err)
+	}
+
+	if expired != 3 || len(repo.batches) != 2 || repo.batches[0] != 2 || repo.batches[1] != 1 {
+		t.Fatalf("expired %d in batches %v, want 3 in [2 1]", expired, repo.batches)
+	}
+	if repo.orders[3].Status != models.OrderStatusPaid || repo.orders[4].Status != models.OrderStatusPending {
+		t.Errorf("paid or recent order was expired: %+v", repo.orders)
+	}
+	for _, meta := range repo.metas {
+		if meta.Actor != "system" || meta.Reason != expiryReasonPaymentTimeout {
+			t.Errorf("meta = %+v", meta)
+		}
+	}
+	if len(hook.released) != 2 || hook.released[0] != "o1" || hook.released[1] != "o3" {
+		t.Errorf("released = %v, want [o1 o3]", hook.released)
+	}
+	if metricValue(metrics, "expired") != 3 || metricValue(metrics, "hook_errors") != 1 || metricValue(metrics, "runs") != 1 {
+		t.Errorf("metrics = %s", metrics.String())
+	}
+}
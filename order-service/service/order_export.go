@@ -0,0 +1,254 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+
+	exportBatch     = 500
+	exportChunkSize = 64 << 10
+)
+
+var ErrInvalidExport = errors.New("invalid export")
+
+// exportItem is an order item in an NDJSON export. CSV exports list items as
+// "ref:quantity" pairs, the format imports read.
+type exportItem struct {
+	ProductID string `json:"product_id"`
+	SKU       string `json:"sku,omitempty"`
+	Name      string `json:"name"`
+	Quantity  int32  `json:"quantity"`
+	UnitPrice string `json:"unit_price"`
+}
+
+type exportColumn struct {
+	name  string
+	value func(o *models.Order) any
+}
+
+func decimalColumn(get func(o *models.Order) int64) func(o *models.Order) any {
+	return func(o *models.Order) any { return money.New(get(o), o.Currency).Decimal() }
+}
+
+// exportColumns are the columns of an export, in their default order.
+var exportColumns = []exportColumn{
+	{"id", func(o *models.Order) any { return o.ID }},
+	{"order_number", func(o *models.Order) any { return o.Number }},
+	{"user_id", func(o *models.Order) any { return o.UserID }},
+	{"status", func(o *models.Order) any { return o.Status }},
+	{"currency", func(o *models.Order) any { return o.Currency }},
+	{"items", exportItems},
+	{"quantity", func(o *models.Order) any { return o.Quantity }},
+	{"product_name", func(o *models.Order) any { return o.ProductName }},
+	{"subtotal", decimalColumn(func(o *models.Order) int64 { return o.SubtotalAmount })},
+	{"discount", decimalColumn(func(o *models.Order) int64 { return o.DiscountAmount })},
+	{"tax", decimalColumn(func(o *models.Order) int64 { return o.TaxAmount })},
+	{"shipping", decimalColumn(func(o *models.Order) int64 { return o.ShippingAmount })},
+	{"total", decimalColumn(func(o *models.Order) int64 { return o.TotalAmount })},
+	{"refunded", decimalColumn(func(o *models.Order) int64 { return o.RefundedAmount })},
+	{"base_currency", func(o *models.Order) any { return o.BaseCurrency }},
+	{"exchange_rate", func(o *models.Order) any { return exchangeRate(o) }},
+	{"base_total", func(o *models.Order) any { return money.New(o.BaseTotalAmount, o.BaseCurrency).Decimal() }},
+	{"coupon_code", func(o *models.Order) any { return o.CouponCode }},
+	{"shipping_method", func(o *models.Order) any { return o.ShippingMethod }},
+	{"shipping_carrier", func(o *models.Order) any { return o.ShippingCarrier }},
+	{"shipping_country", func(o *models.Order) any {
+		if o.ShippingAddress == nil {
+			return ""
+		}
+		return o.ShippingAddress.Country
+	}},
+	{"created_at", func(o *models.Order) any { return o.CreatedAt.UTC().Format(time.RFC3339) }},
+	{"updated_at", func(o *models.Order) any { return o.UpdatedAt.UTC().Format(time.RFC3339) }},
+}
+
+func exportItems(o *models.Order) any {
+	items := make([]exportItem, len(o.Items))
+	for i, item := range o.Items {
+		items[i] = exportItem{
+			ProductID: item.ProductID,
+			SKU:       item.SKU,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			UnitPrice: money.New(item.UnitAmount, item.Currency).Decimal(),
+		}
+	}
+	return items
+}
+
+func (s *orderSearchService) ExportOrders(ctx context.Context, req *orderpb.ExportOrdersRequest, send func([]byte) error) error {
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if format != ExportFormatCSV && format != ExportFormatNDJSON {
+		return fmt.Errorf("%w: format must be csv or ndjson", ErrInvalidExport)
+	}
+	cols, err := selectExportColumns(req.Columns)
+	if err != nil {
+		return err
+	}
+
+	var search orderpb.SearchOrdersRequest
+	if req.Filter != nil {
+		search = *req.Filter
+	}
+	search.PageSize, search.PageToken = 0, ""
+	filter, _, err := searchFilter(&search, s.currency)
+	if err != nil {
+		return err
+	}
+	ok, err := s.filterByCustomer(ctx, &filter, search.CustomerEmail)
+	if err != nil {
+		return err
+	}
+
+	w := &exportWriter{format: format, cols: cols, send: send}
+	if err := w.header(); err != nil {
+		return err
+	}
+	if ok {
+		err = s.repo.Export(ctx, filter, exportBatch, func(orders []models.Order) error {
+			for i := range orders {
+				if err := w.write(&orders[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+func selectExportColumns(names []string) ([]exportColumn, error) {
+	if len(names) == 0 {
+		return exportColumns, nil
+	}
+	cols := make([]exportColumn, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidExport, name)
+		}
+		seen[name] = true
+		i := 0
+		for i < len(exportColumns) && exportColumns[i].name != name {
+			i++
+		}
+		if i == len(exportColumns) {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidExport, name)
+		}
+		cols = append(cols, exportColumns[i])
+	}
+	return cols, nil
+}
+
+// exportWriter buffers rows and sends them in chunks of about
+// exportChunkSize bytes.
+type exportWriter struct {
+	format string
+	cols   []exportColumn
+	send   func([]byte) error
+	buf    bytes.Buffer
+	csv    *csv.Writer
+}
+
+func (w *exportWriter) header() error {
+	if w.format != ExportFormatCSV {
+		return nil
+	}
+	w.csv = csv.NewWriter(&w.buf)
+	record := make([]string, len(w.cols))
+	for i, col := range w.cols {
+		record[i] = col.name
+	}
+	return w.writeCSV(record)
+}
+
+func (w *exportWriter) write(o *models.Order) error {
+	if w.format == ExportFormatCSV {
+		record := make([]string, len(w.cols))
+		for i, col := range w.cols {
+			record[i] = csvValue(col.value(o))
+		}
+		if err := w.writeCSV(record); err != nil {
+			return err
+		}
+	} else {
+		w.buf.WriteByte('{')
+		for i, col := range w.cols {
+			if i > 0 {
+				w.buf.WriteByte(',')
+			}
+			value, err := json.Marshal(col.value(o))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(&w.buf, "%q:%s", col.name, value)
+		}
+		w.buf.WriteString("}\n")
+	}
+
+	if w.buf.Len() >= exportChunkSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *exportWriter) writeCSV(record []string) error {
+	if err := w.csv.Write(record); err != nil {
+		return err
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// flush sends what is buffered. gRPC may hold on to a sent message, so the
+// chunk is a copy.
+func (w *exportWriter) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	err := w.send(bytes.Clone(w.buf.Bytes()))
+	w.buf.Reset()
+	return err
+}
+
+func csvValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case []exportItem:
+		pairs := make([]string, len(v))
+		for i, item := range v {
+			ref := item.SKU
+			if ref == "" {
+				ref = item.ProductID
+			}
+			pairs[i] = ref + ":" + strconv.FormatInt(int64(item.Quantity), 10)
+		}
+		return strings.Join(pairs, ";")
+	}
+	return fmt.Sprint(v)
+}
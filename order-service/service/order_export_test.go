@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func exportedOrder() *models.Order {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	return &models.Order{
+		ID: "8f328abb-4ae4-493b-a460-a63f1206b2f3", UserID: searchUserID, Status: models.OrderStatusPaid,
+		Currency: "USD", TotalAmount: 6000, ShippingAddress: &models.Address{Country: "US"}, CreatedAt: at, UpdatedAt: at,
+		Items: []models.OrderItem{
+			{ProductID: "p-1", SKU: "mouse-01", Name: "Mouse, wireless", Quantity: 2, Currency: "USD", UnitAmount: 2500},
+			{ProductID: "p-2", Name: "Pad", Quantity: 1, Currency: "USD", UnitAmount: 1000},
+		},
+	}
+}
+
+func TestExportOrdersCSV(t *testing.T) {
+	repo := newMemoryOrders(exportedOrder())
+	svc := NewOrderSearchService(repo, &fakeDirectory{}, "USD")
+
+	var chunks []string
+	err := svc.ExportOrders(context.Background(), &orderpb.ExportOrdersRequest{
+		Filter:  &orderpb.SearchOrdersRequest{Statuses: []string{"paid"}, PageSize: 5, PageToken: "ignored"},
+		Columns: []string{"id", "items", "total", "shipping_country", "created_at"},
+	}, func(data []byte) error {
+		chunks = append(chunks, string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+	want := "id,items,total,shipping_country,created_at\n" +
+		"8f328abb-4ae4-493b-a460-a63f1206b2f3,mouse-01:2;p-2:1,60.00,US,2026-03-01T09:30:00Z\n"
+	if got := strings.Join(chunks, ""); got != want {
+		t.Fatalf("export = %q, want %q", got, want)
+	}
+	if len(repo.filters[0].Statuses) != 1 || repo.filters[0].After != nil {
+		t.Fatalf("filter = %+v", repo.filters[0])
+	}
+}
+
+func TestExportOrdersNDJSONInChunks(t *testing.T) {
+	var orders []*models.Order
+	for range 2000 {
+		orders = append(orders, exportedOrder())
+	}
+	svc := NewOrderSearchService(newMemoryOrders(orders...), &fakeDirectory{}, "USD")
+
+	var chunks []string
+	err := svc.ExportOrders(context.Background(), &orderpb.ExportOrdersRequest{Format: "NDJSON"}, func(data []byte) error {
+		chunks = append(chunks, string(data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportOrders: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("export was sent in %d chunk(s)", len(chunks))
+	}
+	lines := strings.Split(strings.TrimSuffix(strings.Join(chunks, ""), "\n"), "\n")
+	if len(lines) != 2000 {
+		t.Fatalf("%d lines", len(lines))
+	}
+	var row struct {
+		ID    string `json:"id"`
+		Total string `json:"total"`
+		Items []struct {
+			SKU       string `json:"sku"`
+			UnitPrice string `json:"unit_price"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatalf("decode %s: %v", lines[0], err)
+	}
+	if row.Total != "60.00" || len(row.Items) != 2 || row.Items[0].UnitPrice != "25.00" {
+		t.Fatalf("row = %+v", row)
+	}
+}
+
+func TestExportOrdersRejects(t *testing.T) {
+	cases := []struct {
+		name string
+		req  *orderpb.ExportOrdersRequest
+		want error
+	}{
+		{"format", &orderpb.ExportOrdersRequest{Format: "xlsx"}, ErrInvalidExport},
+		{"unknown column", &orderpb.ExportOrdersRequest{Columns: []string{"id", "password"}}, ErrInvalidExport},
+		{"duplicate column", &orderpb.ExportOrdersRequest{Columns: []string{"id", "ID"}}, ErrInvalidExport},
+		{"filter", &orderpb.ExportOrdersRequest{Filter: &orderpb.SearchOrdersRequest{Sort: "name"}}, ErrInvalidSort},
+	}
+	svc := NewOrderSearchService(newMemoryOrders(), &fakeDirectory{}, "USD")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sent := false
+			err := svc.ExportOrders(context.Background(), tc.req, func([]byte) error { sent = true; return nil })
+			if !errors.Is(err, tc.want) || sent {
+				t.Fatalf("err = %v, sent = %t, want %v", err, sent, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const (
+	maxImportErrors    = 1000
+	importChangeReason = "order_import"
+)
+
+var (
+	ErrInvalidImport    = errors.New("invalid import")
+	ErrInvalidImportRow = errors.New("invalid row")
+	ErrInvalidImportID  = errors.New("invalid import_id")
+)
+
+// OrderImportService creates orders in bulk from a CSV or NDJSON file.
+type OrderImportService interface {
+	// ImportOrders validates every row of data with the rules of
+	// CreateOrder and stores the valid ones in batches. Rows that fail are
+	// reported and skipped. Rows handled by an earlier run of the same
+	// import_id are skipped too, so a failed import can be run again with the
+	// same file to resume it.
+	ImportOrders(ctx context.Context, req *orderpb.ImportOrdersRequest, data io.Reader) (*orderpb.ImportOrdersResponse, error)
+}
+
+type orderImportService struct {
+	orders   OrderService
+	imports  repository.OrderImportRepository
+	currency string
+	batch    int
+	logger   *log.Logger
+}
+
+func NewOrderImportService(orders OrderService, imports repository.OrderImportRepository, currency string, batch int, logger *log.Logger) OrderImportService {
+	return &orderImportService{orders: orders, imports: imports, currency: currency, batch: max(batch, 1), logger: logger}
+}
+
+func (s *orderImportService) ImportOrders(ctx context.Context, req *orderpb.ImportOrdersRequest, data io.Reader) (*orderpb.ImportOrdersResponse, error) {
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	id := strings.ToLower(strings.TrimSpace(req.ImportId))
+	if id == "" {
+		id = uuid.NewString()
+	} else if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidImportID
+	}
+
+	imp, err := s.imports.Get(ctx, id)
+	switch {
+	case errors.Is(err, repository.ErrImportNotFound):
+		imp = &models.OrderImport{ID: id, Format: format, Source: req.Source, ActorID: req.ActorId}
+	case err != nil:
+		return nil, err
+	case imp.Format != format:
+		return nil, fmt.Errorf("%w: import %s is %s, not %s", ErrInvalidImport, id, imp.Format, format)
+	}
+
+	rows, err := newRecordReader(format, data, s.currency)
+	if err != nil {
+		return nil, err
+	}
+	if !req.DryRun {
+		if imp, err = s.imports.Start(ctx, imp); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &orderpb.ImportOrdersResponse{ImportId: id, DryRun: req.DryRun, Errors: []*orderpb.ImportRowError{}}
+	run := &importRun{svc: s, resp: resp, importID: id, after: imp.CommittedRow, dryRun: req.DryRun,
+		meta: changeMeta(ctx, req.ActorId, importChangeReason)}
+	if err := run.read(ctx, rows, imp.CommittedRow); err != nil {
+		resp.Status = models.OrderImportFailed
+		resp.Error = err.Error()
+	} else {
+		resp.Status = models.OrderImportCompleted
+	}
+
+	if !req.DryRun {
+		// Record the outcome even when the client went away mid-stream.
+		if err := s.imports.Finish(context.WithoutCancel(ctx), id, resp.Status); err != nil {
+			s.logger.Printf("order import %s: record status: %v", id, err)
+		}
+	}
+	s.logger.Printf("order import %s: %s dry_run=%t read=%d imported=%d failed=%d skipped=%d error=%q",
+		id, resp.Status, req.DryRun, resp.RowsRead, resp.RowsImported, resp.RowsFailed, resp.RowsSkipped, resp.Error)
+	return resp, nil
+}
+
+// importRun is the state of one pass over an import file.
+type importRun struct {
+	svc      *orderImportService
+	resp     *orderpb.ImportOrdersResponse
+	importID string
+	after    int64
+	dryRun   bool
+	meta     repository.ChangeMeta
+	batch    []repository.ImportRow
+}
+
+func (r *importRun) read(ctx context.Context, rows recordReader, done int64) error {
+	for {
+		rec, err := rows.next()
+		if err == io.EOF {
+			return r.flush(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		r.resp.RowsRead++
+		if rec.line <= done {
+			r.resp.RowsSkipped++
+			continue
+		}
+
+		row := repository.ImportRow{Line: rec.line, Err: rec.err}
+		if row.Err == nil {
+			row.Order, row.Err = r.svc.orders.BuildOrder(ctx, rec.req)
+			// Rows are only failed for what is wrong with them: when
+			// user-service is down or the client is gone, stop so the rest
+			// can be resumed later.
+			if errors.Is(row.Err, ErrUserServiceUnavailable) {
+				return row.Err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		r.batch = append(r.batch, row)
+		if len(r.batch) >= r.svc.batch {
+			if err := r.flush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// flush stores the pending batch and reports its rows. Nothing is stored in
+// a dry run. Orders are numbered only here, so a dry run uses up no numbers.
+func (r *importRun) flush(ctx context.Context) error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	if !r.dryRun {
+		for _, row := range r.batch {
+			if row.Err != nil {
+				continue
+			}
+			if err := r.svc.orders.NumberOrder(ctx, row.Order); err != nil {
+				return err
+			}
+		}
+		if err := r.svc.imports.CommitBatch(ctx, r.importID, r.after, r.batch, r.meta); err != nil {
+			return err
+		}
+		r.after = r.batch[len(r.batch)-1].Line
+	}
+
+	for _, row := range r.batch {
+		if row.Err == nil {
+			r.resp.RowsImported++
+			continue
+		}
+		r.resp.RowsFailed++
+		if len(r.resp.Errors) < maxImportErrors {
+			r.resp.Errors = append(r.resp.Errors, &orderpb.ImportRowError{Row: row.Line, Message: row.Err.Error()})
+		} else {
+			r.resp.ErrorsTruncated = true
+		}
+	}
+	r.batch = r.batch[:0]
+	return nil
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	maxImportLine = 1 << 20
+)
+
+// csvImportColumns are the columns an import CSV may have, in any order.
+// items lists product ids or SKUs with their quantity, like "mouse-01:2;pad:1".
+var csvImportColumns = map[string]bool{
+	"user_id":              true,
+	"items":                true,
+	"currency":             true,
+	"expected_total":       true,
+	"coupon_code":          true,
+	"shipping_method":      true,
+	"shipping_name":        true,
+	"shipping_line1":       true,
+	"shipping_line2":       true,
+	"shipping_city":        true,
+	"shipping_region":      true,
+	"shipping_postal_code": true,
+	"shipping_country":     true,
+}
+
+// importRecord is one order of an import file. err is set when the row
+// could not be decoded into a request.
+type importRecord struct {
+	line int64
+	req  *orderpb.CreateOrderRequest
+	err  error
+}
+
+// recordReader returns io.EOF after the last record. Any other error ends
+// the import.
+type recordReader interface {
+	next() (importRecord, error)
+}
+
+func newRecordReader(format string, r io.Reader, currency string) (recordReader, error) {
+	switch format {
+	case ImportFormatCSV:
+		return newCSVRecordReader(r, currency)
+	case ImportFormatNDJSON:
+		return &ndjsonRecordReader{r: bufio.NewReaderSize(r, maxImportLine)}, nil
+	}
+	return nil, fmt.Errorf("%w: format must be csv or ndjson", ErrInvalidImport)
+}
+
+// ndjsonRecordReader reads one CreateOrderRequest per line. Blank lines are
+// skipped.
+type ndjsonRecordReader struct {
+	r    *bufio.Reader
+	line int64
+}
+
+func (n *ndjsonRecordReader) next() (importRecord, error) {
+	for {
+		raw, err := n.r.ReadSlice('\n')
+		if len(raw) == 0 && err != nil {
+			return importRecord{}, err
+		}
+		n.line++
+		if errors.Is(err, bufio.ErrBufferFull) {
+			for errors.Is(err, bufio.ErrBufferFull) {
+				_, err = n.r.ReadSlice('\n')
+			}
+			if err != nil && err != io.EOF {
+				return importRecord{}, err
+			}
+			return importRecord{line: n.line, err: fmt.Errorf("%w: longer than %d bytes", ErrInvalidImportRow, maxImportLine)}, nil
+		}
+		if err != nil && err != io.EOF {
+			return importRecord{}, err
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+		req := new(orderpb.CreateOrderRequest)
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(req); err != nil {
+			return importRecord{line: n.line, err: fmt.Errorf("%w: %v", ErrInvalidImportRow, err)}, nil
+		}
+		if dec.More() {
+			return importRecord{line: n.line, err: fmt.Errorf("%w: more than one object on the line", ErrInvalidImportRow)}, nil
+		}
+		return importRecord{line: n.line, req: req}, nil
+	}
+}
+
+// csvRecordReader reads one order per row, with a header row naming the
+// columns.
+type csvRecordReader struct {
+	r        *csv.Reader
+	cols     map[string]int
+	currency string
+}
+
+func newCSVRecordReader(r io.Reader, currency string) (*csvRecordReader, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImport)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !csvImportColumns[name] {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name)
+		}
+		if _, dup := cols[name]; dup {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidImport, name)
+		}
+		cols[name] = i
+	}
+	for _, name := range []string{"user_id", "items"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidImport, name)
+		}
+	}
+	return &csvRecordReader{r: cr, cols: cols, currency: currency}, nil
+}
+
+func (c *csvRecordReader) next() (importRecord, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			if errors.Is(perr.Err, csv.ErrFieldCount) {
+				return importRecord{line: int64(perr.StartLine), err: fmt.Errorf("%w: has %d columns, the header has %d", ErrInvalidImportRow, len(record), len(c.cols))}, nil
+			}
+			return importRecord{}, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+		return importRecord{}, err
+	}
+	line, _ := c.r.FieldPos(0)
+	req, err := c.request(record)
+	return importRecord{line: int64(line), req: req, err: err}, nil
+}
+
+func (c *csvRecordReader) request(record []string) (*orderpb.CreateOrderRequest, error) {
+	get := func(name string) string {
+		if i, ok := c.cols[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &orderpb.CreateOrderRequest{
+		UserId:         get("user_id"),
+		Currency:       get("currency"),
+		CouponCode:     get("coupon_code"),
+		ShippingMethod: get("shipping_method"),
+	}
+	items, err := parseImportItems(get("items"))
+	if err != nil {
+		return nil, err
+	}
+	req.Items = items
+
+	if total := get("expected_total"); total != "" {
+		currency := strings.ToUpper(req.Currency)
+		if currency == "" {
+			currency = c.currency
+		}
+		expected, err := money.Parse(total, currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: expected_total: %v", ErrInvalidImportRow, err)
+		}
+		req.ExpectedTotalAmount = &expected
+	}
+
+	address := &orderpb.Address{
+		Name:       get("shipping_name"),
+		Line1:      get("shipping_line1"),
+		Line2:      get("shipping_line2"),
+		City:       get("shipping_city"),
+		Region:     get("shipping_region"),
+		PostalCode: get("shipping_postal_code"),
+		Country:    get("shipping_country"),
+	}
+	if *address != (orderpb.Address{}) {
+		req.ShippingAddress = address
+	}
+	return req, nil
+}
+
+// parseImportItems parses "ref:quantity" pairs separated by semicolons.
+func parseImportItems(s string) ([]*orderpb.OrderItemInput, error) {
+	var items []*orderpb.OrderItemInput
+	for _, pair := range strings.Split(s, ";") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		ref, qty, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: item %q has no quantity", ErrInvalidImportRow, pair)
+		}
+		quantity, err := strconv.ParseInt(strings.TrimSpace(qty), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%w: item %q has an invalid quantity", ErrInvalidImportRow, pair)
+		}
+		items = append(items, &orderpb.OrderItemInput{ProductId: strings.TrimSpace(ref), Quantity: int32(quantity)})
+	}
+	return items, nil
+}
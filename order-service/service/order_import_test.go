@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type fakeImportRepo struct {
+	stored    *models.OrderImport
+	batches   [][]repository.ImportRow
+	failAfter int
+	finished  string
+}
+
+func (r *fakeImportRepo) Start(_ context.Context, imp *models.OrderImport) (*models.OrderImport, error) {
+	if r.stored == nil {
+		r.stored = imp
+	}
+	r.stored.Status = models.OrderImportRunning
+	return r.stored, nil
+}
+
+func (r *fakeImportRepo) Get(_ context.Context, id string) (*models.OrderImport, error) {
+	if r.stored == nil || r.stored.ID != id {
+		return nil, repository.ErrImportNotFound
+	}
+	return r.stored, nil
+}
+
+func (r *fakeImportRepo) CommitBatch(_ context.Context, importID string, after int64, rows []repository.ImportRow, _ repository.ChangeMeta) error {
+	if r.failAfter > 0 && len(r.batches) == r.failAfter {
+		return errors.New("connection reset")
+	}
+	if r.stored.CommittedRow != after {
+		return repository.ErrImportConflict
+	}
+	r.batches = append(r.batches, append([]repository.ImportRow(nil), rows...))
+	r.stored.CommittedRow = rows[len(rows)-1].Line
+	return nil
+}
+
+func (r *fakeImportRepo) Finish(_ context.Context, _, status string) error {
+	r.finished = status
+	return nil
+}
+
+const importUser = "4e427d78-58c5-4f78-bfc1-e2c196e0b506"
+
+func importPricer() *pricing.Pricer {
+	return testPricer(
+		pricing.Price{ProductID: "mouse", Name: "Mouse", UnitPrice: money.New(2500, "USD")},
+		pricing.Price{ProductID: "pad", Name: "Pad", UnitPrice: money.New(1000, "USD")},
+	)
+}
+
+func newImportService(repo *fakeImportRepo) OrderImportService {
+	orders, _ := newTestOrderService(OrderServiceDeps{Pricer: importPricer()})
+	return NewOrderImportService(orders, repo, "USD", 2, log.New(io.Discard, "", 0))
+}
+
+const importCSV = `user_id,items,expected_total
+` + importUser + `,mouse:2;pad:1,60.00
+` + importUser + `,pad:0,
+` + importUser + `,mouse:1,
+not-a-user,mouse:1,
+` + importUser + `,mouse:1,99.00
+` + importUser + `,pad:3,
+`
+
+func TestImportOrdersCSV(t *testing.T) {
+	repo := &fakeImportRepo{}
+	resp, err := newImportService(repo).ImportOrders(context.Background(), &orderpb.ImportOrdersRequest{Format: "CSV"}, strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+	if resp.Status != models.OrderImportCompleted || resp.RowsRead != 6 || resp.RowsImported != 3 || resp.RowsFailed != 3 {
+		t.Fatalf("report = %+v", resp)
+	}
+	if len(repo.batches) != 3 || repo.stored.CommittedRow != 7 || repo.finished != models.OrderImportCompleted {
+		t.Fatalf("%d batches, committed row %d, finished %q", len(repo.batches), repo.stored.CommittedRow, repo.finished)
+	}
+	if order := repo.batches[0][0].Order; order.TotalAmount != 6000 || len(order.Items) != 2 {
+		t.Fatalf("first order total %d with %d items", order.TotalAmount, len(order.Items))
+	}
+
+	want := map[int64]error{3: ErrInvalidQuantity, 5: ErrInvalidUserID, 6: pricing.ErrPriceMismatch}
+	for _, e := range resp.Errors {
+		if want[e.Row] == nil || !strings.Contains(e.Message, want[e.Row].Error()) {
+			t.Errorf("row %d: %s", e.Row, e.Message)
+		}
+	}
+}
+
+func TestImportOrdersResumesAfterFailure(t *testing.T) {
+	repo := &fakeImportRepo{failAfter: 1}
+	svc := newImportService(repo)
+	resp, err := svc.ImportOrders(context.Background(), &orderpb.ImportOrdersRequest{Format: "csv"}, strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+	if resp.Status != models.OrderImportFailed || resp.Error == "" || resp.RowsImported != 1 || repo.finished != models.OrderImportFailed {
+		t.Fatalf("failed run = %+v", resp)
+	}
+
+	repo.failAfter = 0
+	resp, err = svc.ImportOrders(context.Background(), &orderpb.ImportOrdersRequest{ImportId: resp.ImportId, Format: "csv"}, strings.NewReader(importCSV))
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if resp.Status != models.OrderImportCompleted || resp.RowsSkipped != 2 || resp.RowsImported != 2 || resp.RowsFailed != 2 {
+		t.Fatalf("resumed run = %+v", resp)
+	}
+	if len(repo.batches) != 3 || repo.batches[1][0].Line != 4 {
+		t.Fatalf("batches after resume: %+v", repo.batches)
+	}
+}
+
+func TestImportOrdersDryRunNDJSON(t *testing.T) {
+	data := `{"user_id":"` + importUser + `","items":[{"sku":"mouse","quantity":1}]}
+
+{"user_id":"` + importUser + `","items":[{"product_id":"cable","quantity":1}]}
+{"user_id":"` + importUser + `","itemz":[]}
+`
+	repo := &fakeImportRepo{}
+	resp, err := newImportService(repo).ImportOrders(context.Background(), &orderpb.ImportOrdersRequest{Format: "ndjson", DryRun: true}, strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+	if !resp.DryRun || resp.RowsRead != 3 || resp.RowsImported != 1 || resp.RowsFailed != 2 {
+		t.Fatalf("report = %+v", resp)
+	}
+	if resp.Errors[0].Row != 3 || resp.Errors[1].Row != 4 || !strings.Contains(resp.Errors[1].Message, "unknown field") {
+		t.Fatalf("errors = %+v %+v", resp.Errors[0], resp.Errors[1])
+	}
+	if repo.stored != nil || len(repo.batches) != 0 {
+		t.Fatal("dry run stored the import")
+	}
+}
+
+func TestImportOrdersRejectsFile(t *testing.T) {
+	cases := []struct {
+		name string
+		req  *orderpb.ImportOrdersRequest
+		data string
+		want error
+	}{
+		{"format", &orderpb.ImportOrdersRequest{Format: "xlsx"}, "", ErrInvalidImport},
+		{"import id", &orderpb.ImportOrdersRequest{Format: "csv", ImportId: "first"}, "", ErrInvalidImportID},
+		{"empty csv", &orderpb.ImportOrdersRequest{Format: "csv"}, "", ErrInvalidImport},
+		{"unknown column", &orderpb.ImportOrdersRequest{Format: "csv"}, "user_id,items,notes\n", ErrInvalidImport},
+		{"missing column", &orderpb.ImportOrdersRequest{Format: "csv"}, "user_id\n", ErrInvalidImport},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeImportRepo{}
+			_, err := newImportService(repo).ImportOrders(context.Background(), tc.req, strings.NewReader(tc.data))
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+			if repo.stored != nil {
+				t.Fatal("rejected import was stored")
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const maxOrderItems = 100
+
+// maxItemQuantity and maxOrderQuantity bound the units of a line and of an
+// order, which keeps their sums and amounts well within range.
+const (
+	maxItemQuantity  = 10_000
+	maxOrderQuantity = 100_000
+)
+
+// orderCurrency is the currency the customer pays in. Whether it can be
+// priced in depends on the exchange rates, which the pricer checks.
+func orderCurrency(req *orderpb.CreateOrderRequest, storeCurrency string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		return storeCurrency, nil
+	}
+	if !money.IsKnownCurrency(currency) {
+		return "", fmt.Errorf("%w: %s", ErrInvalidCurrency, req.Currency)
+	}
+	return currency, nil
+}
+
+// expectedAmount prefers the Money field and falls back to the deprecated
+// floating point field, which is interpreted in the order currency.
+func expectedAmount(m *orderpb.Money, legacy float64, currency string) (money.Money, error) {
+	if m != nil {
+		if !strings.EqualFold(m.Currency, currency) {
+			return money.Money{}, fmt.Errorf("%w: %s", ErrInvalidCurrency, m.Currency)
+		}
+		if m.Amount < 0 {
+			return money.Money{}, ErrInvalidPrice
+		}
+		return money.New(m.Amount, currency), nil
+	}
+	if legacy < 0 {
+		return money.Money{}, ErrInvalidPrice
+	}
+	expected, err := money.FromMajor(legacy, currency)
+	if err != nil {
+		return money.Money{}, ErrInvalidPrice
+	}
+	return expected, nil
+}
+
+func lineRequests(req *orderpb.CreateOrderRequest, currency string) ([]pricing.LineRequest, error) {
+	if len(req.Items) == 0 {
+		if strings.TrimSpace(req.ProductName) != "" || req.Quantity != 0 {
+			return nil, ErrLegacyItem
+		}
+		return nil, ErrNoItems
+	}
+	if len(req.Items) > maxOrderItems {
+		return nil, ErrTooManyItems
+	}
+
+	lines := make([]pricing.LineRequest, 0, len(req.Items))
+	var units int64
+	for _, in := range req.Items {
+		if in == nil {
+			return nil, ErrInvalidProduct
+		}
+		ref := strings.TrimSpace(in.ProductId)
+		if ref == "" {
+			ref = strings.TrimSpace(in.Sku)
+		}
+		if ref == "" {
+			return nil, ErrInvalidProduct
+		}
+		if err := checkQuantity(in.Quantity); err != nil {
+			return nil, err
+		}
+		if units += int64(in.Quantity); units > maxOrderQuantity {
+			return nil, fmt.Errorf("%w: at most %d units per order", ErrInvalidQuantity, maxOrderQuantity)
+		}
+		expected, err := expectedAmount(in.ExpectedUnitAmount, in.UnitPrice, currency)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, pricing.LineRequest{Ref: ref, Quantity: in.Quantity, ExpectedUnitPrice: expected})
+	}
+	return lines, nil
+}
+
+func checkQuantity(quantity int32) error {
+	if quantity <= 0 {
+		return fmt.Errorf("%w: must be greater than 0", ErrInvalidQuantity)
+	}
+	if quantity > maxItemQuantity {
+		return fmt.Errorf("%w: at most %d per item", ErrInvalidQuantity, maxItemQuantity)
+	}
+	return nil
+}
+
+func buildOrderItems(orderID string, quote *pricing.Quote) []models.OrderItem {
+	items := make([]models.OrderItem, 0, len(quote.Lines))
+	for i, line := range quote.Lines {
+		items = append(items, models.OrderItem{
+			ID:             uuid.NewString(),
+			OrderID:        orderID,
+			Position:       int32(i),
+			ProductID:      line.ProductID,
+			SKU:            line.SKU,
+			Name:           line.Name,
+			TaxClass:       line.TaxClass,
+			WeightGrams:    line.WeightGrams,
+			Currency:       quote.Currency,
+			UnitAmount:     line.UnitPrice.Amount,
+			DiscountAmount: line.Discount.Amount,
+			LineAmount:     line.LineTotal.Amount,
+			UnitPrice:      line.UnitPrice.Major(),
+			Quantity:       line.Quantity,
+			Discount:       line.Discount.Major(),
+			LineTotal:      line.LineTotal.Major(),
+		})
+	}
+	return items
+}
+
+func applyQuote(order *models.Order, quote *pricing.Quote) {
+	order.Items = buildOrderItems(order.ID, quote)
+	order.Discounts = buildOrderDiscounts(order, quote)
+	order.FreeShipping = quote.FreeShipping
+	order.TaxLines = buildOrderTaxLines(order, quote)
+	if quote.Tax != nil {
+		order.TaxRulesVersion = quote.Tax.Version
+		order.PricesIncludeTax = quote.Tax.Inclusive
+		order.TaxExempt = quote.Tax.Exempt
+		order.TaxExemptAmount = quote.Tax.Waived.Amount
+	}
+	if quote.Shipping != nil {
+		order.ShippingMethod = quote.Shipping.Method
+		order.ShippingCarrier = quote.Shipping.Carrier
+		order.ShippingZone = quote.Shipping.Zone
+	}
+	order.ShippingAmount = quote.ShippingTotal.Amount
+	order.Currency = quote.Currency
+	order.BaseCurrency = quote.Rate.Base
+	order.ExchangeRate = quote.Rate.String()
+	order.ExchangeRateFrom = nil
+	if !quote.Rate.EffectiveFrom.IsZero() {
+		from := quote.Rate.EffectiveFrom
+		order.ExchangeRateFrom = &from
+	}
+	order.BaseTotalAmount = quote.BaseTotal.Amount
+	order.SubtotalAmount = quote.Subtotal.Amount
+	order.DiscountAmount = quote.DiscountTotal.Amount
+	order.TaxAmount = quote.TaxTotal.Amount
+	order.TotalAmount = quote.Total.Amount
+	order.Subtotal = quote.Subtotal.Major()
+	order.DiscountTotal = quote.DiscountTotal.Major()
+	order.TaxTotal = quote.TaxTotal.Major()
+	order.TotalPrice = quote.Total.Major()
+
+	var units int32
+	for _, item := range order.Items {
+		units += item.Quantity
+	}
+	order.Quantity = units
+	order.ProductName = order.Items[0].Name
+	if len(order.Items) > 1 {
+		name := []rune(order.Items[0].Name)
+		if len(name) > 230 {
+			name = name[:230]
+		}
+		order.ProductName = fmt.Sprintf("%s (+%d more)", string(name), len(order.Items)-1)
+	}
+}
+
+func orderLines(order *models.Order) []models.OrderItem {
+	if len(order.Items) > 0 {
+		return order.Items
+	}
+	unit := money.New(order.TotalAmount, order.Currency)
+	if order.Quantity > 0 {
+		unit = unit.MulRatio(1, int64(order.Quantity))
+	}
+	return []models.OrderItem{{
+		ID:         order.ID,
+		OrderID:    order.ID,
+		Name:       order.ProductName,
+		Currency:   order.Currency,
+		UnitAmount: unit.Amount,
+		LineAmount: order.TotalAmount,
+		UnitPrice:  unit.Major(),
+		Quantity:   order.Quantity,
+		LineTotal:  order.TotalPrice,
+	}}
+}
+
+func toPBItems(order *models.Order) []*orderpb.OrderItemData {
+	lines := orderLines(order)
+	items := make([]*orderpb.OrderItemData, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, &orderpb.OrderItemData{
+			Id:             line.ID,
+			ProductId:      line.ProductID,
+			Sku:            line.SKU,
+			Name:           line.Name,
+			UnitPrice:      line.UnitPrice,
+			Quantity:       line.Quantity,
+			Discount:       line.Discount,
+			LineTotal:      line.LineTotal,
+			UnitAmount:     amount(line.UnitAmount, line.Currency),
+			DiscountAmount: amount(line.DiscountAmount, line.Currency),
+			LineAmount:     amount(line.LineAmount, line.Currency),
+			TaxClass:       line.TaxClass,
+			WeightGrams:    line.WeightGrams,
+		})
+	}
+	return items
+}
+
+func amount(minor int64, currency string) *orderpb.Money {
+	m := money.New(minor, currency)
+	return &m
+}
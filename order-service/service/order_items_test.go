@@ -0,0 +1,147 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+func TestApplyQuoteSummarizesLines(t *testing.T) {
+	quote := &pricing.Quote{
+		Currency: "USD",
+		Lines: []pricing.Line{
+			{Price: pricing.Price{ProductID: "laptop-14", Name: "Laptop", UnitPrice: usd(149999)}, Quantity: 1, LineTotal: usd(149999)},
+			{Price: pricing.Price{ProductID: "mouse-01", Name: "Mouse", UnitPrice: usd(1995)}, Quantity: 3, LineTotal: usd(5985)},
+		},
+		Subtotal:  usd(155984),
+		Total:     usd(155984),
+		Rate:      exchange.Identity("USD"),
+		BaseTotal: usd(155984),
+	}
+
+	order := &models.Order{ID: "order-1"}
+	applyQuote(order, quote)
+
+	if len(order.Items) != 2 || order.Items[1].Position != 1 || order.Items[1].OrderID != "order-1" {
+		t.Fatalf("unexpected items: %+v", order.Items)
+	}
+	if order.TotalAmount != 155984 || order.TotalPrice != 1559.84 || order.Currency != "USD" {
+		t.Errorf("total = %d %s (%v), want 155984 USD", order.TotalAmount, order.Currency, order.TotalPrice)
+	}
+	if order.Items[1].LineAmount != 5985 || order.Items[1].LineTotal != 59.85 {
+		t.Errorf("mouse line = %d (%v), want 5985", order.Items[1].LineAmount, order.Items[1].LineTotal)
+	}
+	if order.Quantity != 4 {
+		t.Errorf("quantity = %d, want 4", order.Quantity)
+	}
+	if order.ProductName != "Laptop (+1 more)" {
+		t.Errorf("product name = %q", order.ProductName)
+	}
+}
+
+func TestApplyQuoteStoresExchangeRate(t *testing.T) {
+	eur := func(amount int64) money.Money { return money.New(amount, "EUR") }
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	rate, err := exchange.NewRate("USD", "EUR", "1.0850", from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := &pricing.Quote{
+		Currency:  "EUR",
+		Lines:     []pricing.Line{{Price: pricing.Price{ProductID: "mouse-01", Name: "Mouse", UnitPrice: eur(1840)}, Quantity: 1, LineTotal: eur(1840)}},
+		Subtotal:  eur(1840),
+		Total:     eur(1840),
+		Rate:      rate,
+		BaseTotal: usd(1996),
+	}
+
+	order := &models.Order{ID: "order-1"}
+	applyQuote(order, quote)
+	if order.Currency != "EUR" || order.BaseCurrency != "USD" || order.ExchangeRate != "1.085" || order.BaseTotalAmount != 1996 {
+		t.Fatalf("order in %s, base %s at %s, base total %d", order.Currency, order.BaseCurrency, order.ExchangeRate, order.BaseTotalAmount)
+	}
+	data := toPBOrder(order)
+	if data.ExchangeRateEffectiveFrom != "2026-10-01T00:00:00Z" || !data.BaseTotalAmount.Equal(usd(1996)) || !data.TotalAmount.Equal(eur(1840)) {
+		t.Fatalf("order data: rate from %q, base total %v, total %v", data.ExchangeRateEffectiveFrom, data.BaseTotalAmount, data.TotalAmount)
+	}
+}
+
+func TestLegacyRequestIsRejected(t *testing.T) {
+	_, err := lineRequests(&orderpb.CreateOrderRequest{ProductName: "Laptop", Quantity: 2, TotalPrice: 30}, "USD")
+	if !errors.Is(err, ErrLegacyItem) {
+		t.Fatalf("err = %v, want ErrLegacyItem", err)
+	}
+	if _, err := lineRequests(&orderpb.CreateOrderRequest{}, "USD"); !errors.Is(err, ErrNoItems) {
+		t.Fatalf("no items: err = %v, want ErrNoItems", err)
+	}
+}
+
+func TestLegacyOrderBecomesOneLine(t *testing.T) {
+	legacy := &models.Order{ID: "order-1", ProductName: "Laptop", Quantity: 2, Currency: "USD", TotalAmount: 3000, TotalPrice: 30}
+	items := orderLines(legacy)
+	if len(items) != 1 || items[0].LineAmount != 3000 || items[0].UnitAmount != 1500 || items[0].UnitPrice != 15 {
+		t.Fatalf("unexpected legacy lines: %+v", items)
+	}
+}
+
+func TestLineRequestsRejectsInvalidLines(t *testing.T) {
+	cases := map[string][]*orderpb.OrderItemInput{
+		"empty":          nil,
+		"no product ref": {{Name: "A", Quantity: 1}},
+		"zero qty":       {{ProductId: "a"}},
+		"negative price": {{ProductId: "a", Quantity: 1, UnitPrice: -1}},
+		"other currency": {{ProductId: "a", Quantity: 1, ExpectedUnitAmount: &orderpb.Money{Amount: 100, Currency: "EUR"}}},
+	}
+	for name, items := range cases {
+		if _, err := lineRequests(&orderpb.CreateOrderRequest{Items: items}, "USD"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLineRequestsCapsQuantities(t *testing.T) {
+	if _, err := lineRequests(&orderpb.CreateOrderRequest{Items: []*orderpb.OrderItemInput{{ProductId: "a", Quantity: maxItemQuantity}}}, "USD"); err != nil {
+		t.Fatalf("largest line: %v", err)
+	}
+	huge := []*orderpb.OrderItemInput{{ProductId: "a", Quantity: math.MaxInt32}}
+	if _, err := lineRequests(&orderpb.CreateOrderRequest{Items: huge}, "USD"); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("line over the cap: err = %v, want ErrInvalidQuantity", err)
+	}
+
+	var many []*orderpb.OrderItemInput
+	for i := 0; i < maxOrderQuantity/maxItemQuantity+1; i++ {
+		many = append(many, &orderpb.OrderItemInput{ProductId: fmt.Sprintf("p%d", i), Quantity: maxItemQuantity})
+	}
+	if _, err := lineRequests(&orderpb.CreateOrderRequest{Items: many}, "USD"); !errors.Is(err, ErrInvalidQuantity) {
+		t.Errorf("order over the cap: err = %v, want ErrInvalidQuantity", err)
+	}
+}
+
+func TestExpectedAmountAcceptsMoneyAndLegacyFloats(t *testing.T) {
+	got, err := expectedAmount(nil, 0.1+0.2, "USD")
+	if err != nil || !got.Equal(usd(30)) {
+		t.Fatalf("legacy = %v, %v; want 0.30 USD", got, err)
+	}
+	got, err = expectedAmount(&orderpb.Money{Amount: 1999, Currency: "usd"}, 0, "USD")
+	if err != nil || !got.Equal(usd(1999)) {
+		t.Fatalf("money = %v, %v; want 19.99 USD", got, err)
+	}
+	if got, err := orderCurrency(&orderpb.CreateOrderRequest{Currency: "eur"}, "USD"); err != nil || got != "EUR" {
+		t.Fatalf("orderCurrency(eur) = %q, %v", got, err)
+	}
+	if _, err := orderCurrency(&orderpb.CreateOrderRequest{Currency: "XYZ"}, "USD"); err == nil {
+		t.Fatal("expected unsupported currency error")
+	}
+}
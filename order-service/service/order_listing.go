@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var orderSorts = map[string]repository.OrderSort{
+	"created_at":  {Field: repository.SortByCreatedAt},
+	"-created_at": {Field: repository.SortByCreatedAt, Desc: true},
+	"total":       {Field: repository.SortByTotal},
+	"-total":      {Field: repository.SortByTotal, Desc: true},
+}
+
+type pageToken struct {
+	Sort        string    `json:"s"`
+	CreatedAt   time.Time `json:"c"`
+	TotalAmount int64     `json:"t"`
+	ID          string    `json:"i"`
+}
+
+func encodePageToken(sort string, last *models.Order) string {
+	data, _ := json.Marshal(pageToken{Sort: sort, CreatedAt: last.CreatedAt, TotalAmount: last.BaseTotalAmount, ID: last.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageToken(token, sort string) (*repository.Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var t pageToken
+	if err := json.Unmarshal(data, &t); err != nil || t.ID == "" || t.Sort != sort {
+		return nil, ErrInvalidPageToken
+	}
+	return &repository.Cursor{CreatedAt: t.CreatedAt, BaseTotalAmount: t.TotalAmount, ID: t.ID}, nil
+}
+
+func listFilter(req *orderpb.GetOrdersByUserIdRequest, currency string) (repository.OrderFilter, string, error) {
+	filter, sort, err := pageFilter(req.PageSize, req.PageToken, req.Sort)
+	if err != nil {
+		return filter, "", err
+	}
+	filter.UserID = req.UserId
+
+	if req.Status != "" {
+		if !IsKnownStatus(req.Status) {
+			return filter, "", ErrInvalidStatus
+		}
+		filter.Statuses = []string{req.Status}
+	}
+
+	if err := rangeFilter(&filter, req.CreatedFrom, req.CreatedTo, req.MinTotal, req.MaxTotal, currency); err != nil {
+		return filter, "", err
+	}
+	return filter, sort, nil
+}
+
+// pageFilter reads the page size, sort and page token shared by every order
+// listing. It returns the sort so that the next page token can be bound to it.
+func pageFilter(pageSize int32, pageToken, sort string) (repository.OrderFilter, string, error) {
+	filter := repository.OrderFilter{Limit: defaultPageSize}
+	if pageSize < 0 {
+		return filter, "", ErrInvalidPageSize
+	}
+	if pageSize > 0 {
+		filter.Limit = min(int(pageSize), maxPageSize)
+	}
+
+	sort = strings.TrimSpace(sort)
+	if sort == "" {
+		sort = "-created_at"
+	}
+	orderSort, ok := orderSorts[sort]
+	if !ok {
+		return filter, "", ErrInvalidSort
+	}
+	filter.Sort = orderSort
+
+	if pageToken != "" {
+		cursor, err := decodePageToken(pageToken, sort)
+		if err != nil {
+			return filter, "", err
+		}
+		filter.After = cursor
+	}
+	return filter, sort, nil
+}
+
+func rangeFilter(filter *repository.OrderFilter, createdFrom, createdTo, minTotal, maxTotal, currency string) error {
+	var err error
+	if filter.CreatedFrom, err = parseTimeFilter(createdFrom); err != nil {
+		return err
+	}
+	if filter.CreatedTo, err = parseTimeFilter(createdTo); err != nil {
+		return err
+	}
+	if filter.MinTotal, err = parseAmountFilter(minTotal, currency); err != nil {
+		return err
+	}
+	if filter.MaxTotal, err = parseAmountFilter(maxTotal, currency); err != nil {
+		return err
+	}
+	return nil
+}
+
+// listPage fetches one page of orders, asking for one more than the page
+// size to learn whether there is a next page.
+func listPage(ctx context.Context, repo repository.OrderRepository, filter repository.OrderFilter, sort string) ([]*orderpb.OrderData, string, error) {
+	pageSize := filter.Limit
+	filter.Limit++
+
+	orders, err := repo.List(ctx, filter)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var next string
+	if len(orders) > pageSize {
+		orders = orders[:pageSize]
+		next = encodePageToken(sort, &orders[len(orders)-1])
+	}
+	data := make([]*orderpb.OrderData, 0, len(orders))
+	for i := range orders {
+		data = append(data, toPBOrder(&orders[i]))
+	}
+	return data, next, nil
+}
+
+func parseTimeFilter(v string) (*time.Time, error) {
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, ErrInvalidFilter
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+func parseAmountFilter(v, currency string) (*int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	m, err := money.Parse(v, currency)
+	if err != nil || m.Amount < 0 {
+		return nil, ErrInvalidFilter
+	}
+	return &m.Amount, nil
+}
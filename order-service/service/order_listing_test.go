@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	last := &models.Order{ID: "order-9", CreatedAt: time.Date(2026, 3, 1, 10, 0, 0, 123456000, time.UTC), BaseTotalAmount: 4200}
+	token := encodePageToken("-created_at", last)
+
+	cursor, err := decodePageToken(token, "-created_at")
+	if err != nil {
+		t.Fatalf("decodePageToken: %v", err)
+	}
+	if cursor.ID != "order-9" || !cursor.CreatedAt.Equal(last.CreatedAt) || cursor.BaseTotalAmount != 4200 {
+		t.Fatalf("unexpected cursor: %+v", cursor)
+	}
+
+	if _, err := decodePageToken(token, "total"); !errors.Is(err, ErrInvalidPageToken) {
+		t.Fatalf("token reused with another sort: err = %v", err)
+	}
+	if _, err := decodePageToken("not-a-token", "-created_at"); !errors.Is(err, ErrInvalidPageToken) {
+		t.Fatalf("garbage token: err = %v", err)
+	}
+}
+
+func TestListFilterParsesRequest(t *testing.T) {
+	filter, sort, err := listFilter(&orderpb.GetOrdersByUserIdRequest{
+		UserId:      "u1",
+		PageSize:    500,
+		Status:      models.OrderStatusPaid,
+		CreatedFrom: "2026-01-01T00:00:00+07:00",
+		MinTotal:    "10.5",
+		Sort:        "total",
+	}, "USD")
+	if err != nil {
+		t.Fatalf("listFilter: %v", err)
+	}
+	if sort != "total" || filter.Sort != (repository.OrderSort{Field: repository.SortByTotal}) {
+		t.Errorf("sort = %q %+v", sort, filter.Sort)
+	}
+	if filter.Limit != maxPageSize {
+		t.Errorf("limit = %d, want %d", filter.Limit, maxPageSize)
+	}
+	if filter.CreatedFrom == nil || !filter.CreatedFrom.Equal(time.Date(2025, 12, 31, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("created_from = %v", filter.CreatedFrom)
+	}
+	if filter.MinTotal == nil || *filter.MinTotal != 1050 || filter.MaxTotal != nil {
+		t.Errorf("totals = %v %v", filter.MinTotal, filter.MaxTotal)
+	}
+
+	_, sort, err = listFilter(&orderpb.GetOrdersByUserIdRequest{UserId: "u1"}, "USD")
+	if err != nil || sort != "-created_at" {
+		t.Fatalf("default sort = %q, %v", sort, err)
+	}
+}
+
+func TestListFilterRejectsInvalidInput(t *testing.T) {
+	cases := map[string]*orderpb.GetOrdersByUserIdRequest{
+		"negative page size": {PageSize: -1},
+		"unknown sort":       {Sort: "name"},
+		"unknown status":     {Status: "lost"},
+		"bad date":           {CreatedTo: "yesterday"},
+		"bad amount":         {MaxTotal: "ten"},
+		"negative amount":    {MinTotal: "-1"},
+	}
+	for name, req := range cases {
+		if _, _, err := listFilter(req, "USD"); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
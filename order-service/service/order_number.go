@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/ordernumber"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// GetOrderByNumber finds an order by its order number, ignoring case, for
+// the customer who placed it or an admin.
+func (s *orderService) GetOrderByNumber(ctx context.Context, req *orderpb.GetOrderByNumberRequest) (*orderpb.GetOrderByIdResponse, error) {
+	number := ordernumber.Normalize(req.OrderNumber)
+	if !ordernumber.Pattern.MatchString(number) {
+		return nil, ErrInvalidNumber
+	}
+
+	order, err := s.repo.GetByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orderActor(order, req.ActorId, req.ActorIsAdmin); err != nil {
+		return nil, err
+	}
+	return s.orderResponse(ctx, order, req.IncludeHistory)
+}
+
+// NumberOrder gives order the next order number of the store. Without a
+// generator orders are left unnumbered.
+func (s *orderService) NumberOrder(ctx context.Context, order *models.Order) error {
+	if s.numbers == nil {
+		return nil
+	}
+	number, err := s.numbers.Next(ctx, order.CreatedAt)
+	if err != nil {
+		return err
+	}
+	order.Number = number
+	return nil
+}
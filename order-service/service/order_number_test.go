@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/ordernumber"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type counterSequence struct {
+	last int64
+}
+
+func (s *counterSequence) Next(context.Context, string, string) (int64, error) {
+	s.last++
+	return s.last, nil
+}
+
+func newNumberGenerator(t *testing.T) *ordernumber.Generator {
+	t.Helper()
+	format, err := ordernumber.ParseFormat("{store}-{seq:4}")
+	if err != nil {
+		t.Fatalf("ParseFormat: %v", err)
+	}
+	numbers, err := ordernumber.NewGenerator(format, "jkt", &counterSequence{})
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	return numbers
+}
+
+func newNumberService(t *testing.T) (OrderService, *memoryOrders) {
+	return newTestOrderService(OrderServiceDeps{Numbers: newNumberGenerator(t)})
+}
+
+func TestCreatedOrdersAreNumbered(t *testing.T) {
+	svc, repo := newNumberService(t)
+	ctx := context.Background()
+	req := func() *orderpb.CreateOrderRequest {
+		return &orderpb.CreateOrderRequest{UserId: uuid.NewString(), Items: []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}}}
+	}
+
+	created, err := svc.CreateOrder(ctx, req())
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	if created.Order.OrderNumber != "JKT-0001" || repo.created[0].Number != "JKT-0001" {
+		t.Fatalf("order number = %q", created.Order.OrderNumber)
+	}
+	built, err := svc.BuildOrder(ctx, req())
+	if err != nil {
+		t.Fatalf("BuildOrder: %v", err)
+	}
+	if built.Number != "" {
+		t.Fatalf("built order number = %q, want none", built.Number)
+	}
+	created, err = svc.CreateOrder(ctx, req())
+	if err != nil || created.Order.OrderNumber != "JKT-0002" {
+		t.Fatalf("second order number = %v, %v", created, err)
+	}
+
+	found, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: " jkt-0001 ", ActorId: repo.created[0].UserID})
+	if err != nil {
+		t.Fatalf("GetOrderByNumber: %v", err)
+	}
+	if found.Order.Id != repo.created[0].ID {
+		t.Fatalf("found %s, want %s", found.Order.Id, repo.created[0].ID)
+	}
+}
+
+func TestGetOrderByNumberRejects(t *testing.T) {
+	svc, _ := newNumberService(t)
+	ctx := context.Background()
+	for _, number := range []string{"", "JKT 0001", "JKT-0001%"} {
+		if _, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: number}); !errors.Is(err, ErrInvalidNumber) {
+			t.Errorf("%q: err = %v, want ErrInvalidNumber", number, err)
+		}
+	}
+	if _, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: "JKT-9999"}); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("unknown number: err = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestImportNumbersOnlyCommittedOrders(t *testing.T) {
+	orders, _ := newTestOrderService(OrderServiceDeps{Pricer: importPricer(), Numbers: newNumberGenerator(t)})
+	ctx := context.Background()
+
+	dry := &fakeImportRepo{}
+	svc := NewOrderImportService(orders, dry, "USD", 2, log.New(io.Discard, "", 0))
+	if _, err := svc.ImportOrders(ctx, &orderpb.ImportOrdersRequest{Format: "csv", DryRun: true}, strings.NewReader(importCSV)); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+
+	repo := &fakeImportRepo{}
+	svc = NewOrderImportService(orders, repo, "USD", 2, log.New(io.Discard, "", 0))
+	if _, err := svc.ImportOrders(ctx, &orderpb.ImportOrdersRequest{Format: "csv"}, strings.NewReader(importCSV)); err != nil {
+		t.Fatalf("ImportOrders: %v", err)
+	}
+	var numbers []string
+	for _, batch := range repo.batches {
+		for _, row := range batch {
+			if row.Err == nil {
+				numbers = append(numbers, row.Order.Number)
+			}
+		}
+	}
+	if strings.Join(numbers, ",") != "JKT-0001,JKT-0002,JKT-0003" {
+		t.Fatalf("numbers = %v", numbers)
+	}
+}
+
+func TestGetOrderByNumberIsForOwnerOrAdmin(t *testing.T) {
+	svc, repo := newNumberService(t)
+	ctx := context.Background()
+	userID := uuid.NewString()
+	if _, err := svc.CreateOrder(ctx, &orderpb.CreateOrderRequest{UserId: userID, Items: []*orderpb.OrderItemInput{{ProductId: "mouse", Quantity: 1}}}); err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+
+	if _, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: "JKT-0001", ActorId: uuid.NewString()}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("other user: err = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: "JKT-0001"}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("anonymous: err = %v, want ErrForbidden", err)
+	}
+	resp, err := svc.GetOrderByNumber(ctx, &orderpb.GetOrderByNumberRequest{OrderNumber: "JKT-0001", ActorIsAdmin: true})
+	if err != nil || resp.Order.Id != repo.created[0].ID {
+		t.Errorf("admin: %v, %v", resp, err)
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func TestReassignOrdersMovesOrdersAndBumpsVersion(t *testing.T) {
+	from, to := uuid.NewString(), uuid.NewString()
+	first := &models.Order{ID: uuid.NewString(), UserID: from, Version: 1}
+	other := &models.Order{ID: uuid.NewString(), UserID: uuid.NewString(), Version: 1}
+	second := &models.Order{ID: uuid.NewString(), UserID: from, Version: 4}
+	svc, repo := newTestOrderService(OrderServiceDeps{Repo: newMemoryOrders(first, other, second)})
+	ctx := context.Background()
+
+	resp, err := svc.ReassignOrders(ctx, &orderpb.ReassignOrdersRequest{FromUserId: from, ToUserId: to, DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if want := []string{first.ID, second.ID}; !reflect.DeepEqual(resp.OrderIds, want) {
+		t.Fatalf("dry run ids = %v, want %v", resp.OrderIds, want)
+	}
+	if repo.stored(first.ID).UserID != from || repo.stored(first.ID).Version != 1 {
+		t.Fatal("dry run moved an order")
+	}
+
+	resp, err = svc.ReassignOrders(ctx, &orderpb.ReassignOrdersRequest{FromUserId: from, ToUserId: to, OrderIds: []string{second.ID}})
+	if err != nil {
+		t.Fatalf("ReassignOrders: %v", err)
+	}
+	if !reflect.DeepEqual(resp.OrderIds, []string{second.ID}) {
+		t.Fatalf("ids = %v, want only the selected order", resp.OrderIds)
+	}
+	if got := repo.stored(second.ID); got.UserID != to || got.Version != 5 {
+		t.Fatalf("moved order = %s v%d, want %s v5", got.UserID, got.Version, to)
+	}
+	if repo.stored(first.ID).UserID != from || repo.stored(other.ID).Version != 1 {
+		t.Fatal("orders that were not selected were moved")
+	}
+
+	resp, err = svc.ReassignOrders(ctx, &orderpb.ReassignOrdersRequest{FromUserId: uuid.NewString(), ToUserId: to})
+	if err != nil || resp.OrderIds == nil || len(resp.OrderIds) != 0 {
+		t.Fatalf("no orders: ids = %v, err = %v, want an empty list", resp.OrderIds, err)
+	}
+}
+
+func TestReassignOrdersRejects(t *testing.T) {
+	svc, _ := newTestOrderService(OrderServiceDeps{})
+	user := uuid.NewString()
+	cases := []struct {
+		name string
+		req  *orderpb.ReassignOrdersRequest
+		want error
+	}{
+		{"bad from", &orderpb.ReassignOrdersRequest{FromUserId: "nope", ToUserId: user}, ErrInvalidUserParam},
+		{"bad to", &orderpb.ReassignOrdersRequest{FromUserId: user, ToUserId: ""}, ErrInvalidUserParam},
+		{"same user", &orderpb.ReassignOrdersRequest{FromUserId: user, ToUserId: user}, ErrSameUser},
+		{"bad order id", &orderpb.ReassignOrdersRequest{FromUserId: user, ToUserId: uuid.NewString(), OrderIds: []string{"1"}}, ErrInvalidOrderID},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.ReassignOrders(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
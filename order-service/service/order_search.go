@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"online-store-microservice/order-service/ordernumber"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+	userpb "online-store-microservice/proto/user"
+)
+
+const maxProductNameFilter = 255
+
+// UserDirectory finds users by something other than their id.
+type UserDirectory interface {
+	FindUserByEmail(ctx context.Context, email string) (*userpb.UserData, error)
+}
+
+// OrderSearchService searches the orders of every user, for admins.
+type OrderSearchService interface {
+	SearchOrders(ctx context.Context, req *orderpb.SearchOrdersRequest) (*orderpb.SearchOrdersResponse, error)
+	// ExportOrders writes every order matching the search as CSV or NDJSON
+	// and passes the file to send in chunks.
+	ExportOrders(ctx context.Context, req *orderpb.ExportOrdersRequest, send func([]byte) error) error
+	GetSalesReport(ctx context.Context, req *orderpb.SalesReportRequest) (*orderpb.SalesReportResponse, error)
+}
+
+type orderSearchService struct {
+	repo     repository.OrderRepository
+	users    UserDirectory
+	currency string
+}
+
+func NewOrderSearchService(repo repository.OrderRepository, users UserDirectory, currency string) OrderSearchService {
+	return &orderSearchService{repo: repo, users: users, currency: currency}
+}
+
+func (s *orderSearchService) SearchOrders(ctx context.Context, req *orderpb.SearchOrdersRequest) (*orderpb.SearchOrdersResponse, error) {
+	filter, sort, err := searchFilter(req, s.currency)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.filterByCustomer(ctx, &filter, req.CustomerEmail)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &orderpb.SearchOrdersResponse{Orders: []*orderpb.OrderData{}}, nil
+	}
+
+	orders, next, err := listPage(ctx, s.repo, filter, sort)
+	if err != nil {
+		return nil, err
+	}
+	return &orderpb.SearchOrdersResponse{Orders: orders, NextPageToken: next}, nil
+}
+
+// filterByCustomer narrows filter to the user with email, if one is given.
+// It returns false when no order can match: there is no such customer, or the
+// email belongs to someone other than the filter's user.
+func (s *orderSearchService) filterByCustomer(ctx context.Context, filter *repository.OrderFilter, email string) (bool, error) {
+	if email = strings.TrimSpace(email); email == "" {
+		return true, nil
+	}
+	userID, err := s.customerID(ctx, email)
+	if err != nil || userID == "" || (filter.UserID != "" && filter.UserID != userID) {
+		return false, err
+	}
+	filter.UserID = userID
+	return true, nil
+}
+
+// customerID resolves an email through user-service. An unknown email
+// returns an empty id rather than an error.
+func (s *orderSearchService) customerID(ctx context.Context, email string) (string, error) {
+	if _, err := mail.ParseAddress(email); err != nil {
+		return "", fmt.Errorf("%w: customer_email", ErrInvalidFilter)
+	}
+	if s.users == nil {
+		return "", ErrUserServiceUnavailable
+	}
+	user, err := s.users.FindUserByEmail(ctx, email)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("%w: %v", ErrUserServiceUnavailable, err)
+	}
+	return user.Id, nil
+}
+
+func searchFilter(req *orderpb.SearchOrdersRequest, currency string) (repository.OrderFilter, string, error) {
+	filter, sort, err := pageFilter(req.PageSize, req.PageToken, req.Sort)
+	if err != nil {
+		return filter, "", err
+	}
+
+	for _, st := range req.Statuses {
+		st = strings.TrimSpace(strings.ToLower(st))
+		if !IsKnownStatus(st) {
+			return filter, "", ErrInvalidStatus
+		}
+		filter.Statuses = append(filter.Statuses, st)
+	}
+	if err := rangeFilter(&filter, req.CreatedFrom, req.CreatedTo, req.MinTotal, req.MaxTotal, currency); err != nil {
+		return filter, "", err
+	}
+
+	if req.UserId != "" {
+		if _, err := uuid.Parse(req.UserId); err != nil {
+			return filter, "", ErrInvalidUserParam
+		}
+		filter.UserID = req.UserId
+	}
+
+	filter.ProductName = strings.TrimSpace(req.ProductName)
+	if len(filter.ProductName) > maxProductNameFilter {
+		return filter, "", fmt.Errorf("%w: product_name is too long", ErrInvalidFilter)
+	}
+
+	if number := ordernumber.Normalize(req.OrderNumber); number != "" {
+		switch id, err := uuid.Parse(number); {
+		case err == nil:
+			filter.ID = id.String()
+		case ordernumber.Pattern.MatchString(number):
+			filter.Number = number
+		default:
+			return filter, "", fmt.Errorf("%w: order_number", ErrInvalidFilter)
+		}
+	}
+	return filter, sort, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+	userpb "online-store-microservice/proto/user"
+)
+
+type fakeDirectory struct {
+	emails map[string]string
+	err    error
+}
+
+func (d *fakeDirectory) FindUserByEmail(_ context.Context, email string) (*userpb.UserData, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	id, ok := d.emails[email]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &userpb.UserData{Id: id, Email: email}, nil
+}
+
+const searchUserID = "4e427d78-58c5-4f78-bfc1-e2c196e0b506"
+
+func TestSearchOrdersBuildsFilter(t *testing.T) {
+	now := time.Now().UTC()
+	repo := newMemoryOrders(
+		&models.Order{ID: "a", Currency: "USD", CreatedAt: now, UpdatedAt: now},
+		&models.Order{ID: "b", Currency: "USD", CreatedAt: now, UpdatedAt: now},
+		&models.Order{ID: "c", Currency: "USD", CreatedAt: now, UpdatedAt: now},
+	)
+	svc := NewOrderSearchService(repo, &fakeDirectory{emails: map[string]string{"budi@example.com": searchUserID}}, "USD")
+
+	resp, err := svc.SearchOrders(context.Background(), &orderpb.SearchOrdersRequest{
+		PageSize:      2,
+		Sort:          "-total",
+		Statuses:      []string{"Paid", "shipped"},
+		MinTotal:      "10.50",
+		ProductName:   "  50% off ",
+		CustomerEmail: "budi@example.com",
+		OrderNumber:   "8F328ABB-4AE4-493B-A460-A63F1206B2F3",
+	})
+	if err != nil {
+		t.Fatalf("SearchOrders: %v", err)
+	}
+	if len(resp.Orders) != 2 || resp.NextPageToken == "" {
+		t.Fatalf("got %d orders, next page token %q", len(resp.Orders), resp.NextPageToken)
+	}
+
+	f := repo.filters[0]
+	if f.UserID != searchUserID || f.ID != "8f328abb-4ae4-493b-a460-a63f1206b2f3" || f.ProductName != "50% off" || *f.MinTotal != 1050 {
+		t.Fatalf("filter = %+v", f)
+	}
+	if len(f.Statuses) != 2 || f.Statuses[0] != models.OrderStatusPaid || !f.Sort.Desc || f.Sort.Field != repository.SortByTotal || f.Limit != 3 {
+		t.Fatalf("filter = %+v", f)
+	}
+}
+
+func TestSearchOrdersByOrderNumber(t *testing.T) {
+	repo := newMemoryOrders()
+	svc := NewOrderSearchService(repo, &fakeDirectory{}, "USD")
+	for _, number := range []string{" ord-2026-000123 ", "2026"} {
+		if _, err := svc.SearchOrders(context.Background(), &orderpb.SearchOrdersRequest{OrderNumber: number}); err != nil {
+			t.Fatalf("SearchOrders(%q): %v", number, err)
+		}
+	}
+
+	if f := repo.filters[0]; f.Number != "ORD-2026-000123" || f.ID != "" {
+		t.Fatalf("order number filter = %+v", f)
+	}
+	// Digits are an order number too, never the start of an id.
+	if f := repo.filters[1]; f.Number != "2026" || f.ID != "" {
+		t.Fatalf("digits filter = %+v", f)
+	}
+}
+
+func TestSearchOrdersByCustomerEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryOrders()
+	svc := NewOrderSearchService(repo, &fakeDirectory{emails: map[string]string{"budi@example.com": searchUserID}}, "USD")
+
+	resp, err := svc.SearchOrders(ctx, &orderpb.SearchOrdersRequest{CustomerEmail: "nobody@example.com"})
+	if err != nil || len(resp.Orders) != 0 || len(repo.filters) != 0 {
+		t.Fatalf("unknown email: err=%v, %d queries", err, len(repo.filters))
+	}
+
+	other := "c1d2e3f4-5a6b-4c7d-8e9f-0a1b2c3d4e5f"
+	resp, err = svc.SearchOrders(ctx, &orderpb.SearchOrdersRequest{CustomerEmail: "budi@example.com", UserId: other})
+	if err != nil || len(resp.Orders) != 0 || len(repo.filters) != 0 {
+		t.Fatalf("email of another user: err=%v, %d queries", err, len(repo.filters))
+	}
+
+	down := NewOrderSearchService(repo, &fakeDirectory{err: status.Error(codes.Unavailable, "connection refused")}, "USD")
+	if _, err := down.SearchOrders(ctx, &orderpb.SearchOrdersRequest{CustomerEmail: "budi@example.com"}); !errors.Is(err, ErrUserServiceUnavailable) {
+		t.Fatalf("user-service down: err = %v", err)
+	}
+}
+
+func TestSearchOrdersRejectsInvalidFilters(t *testing.T) {
+	cases := []struct {
+		name string
+		req  *orderpb.SearchOrdersRequest
+		want error
+	}{
+		{"status", &orderpb.SearchOrdersRequest{Statuses: []string{"lost"}}, ErrInvalidStatus},
+		{"sort", &orderpb.SearchOrdersRequest{Sort: "name"}, ErrInvalidSort},
+		{"date", &orderpb.SearchOrdersRequest{CreatedFrom: "yesterday"}, ErrInvalidFilter},
+		{"email", &orderpb.SearchOrdersRequest{CustomerEmail: "not-an-email"}, ErrInvalidFilter},
+		{"order number with spaces", &orderpb.SearchOrdersRequest{OrderNumber: "ORD 2026"}, ErrInvalidFilter},
+		{"order number pattern", &orderpb.SearchOrdersRequest{OrderNumber: "8f32%"}, ErrInvalidFilter},
+		{"user id", &orderpb.SearchOrdersRequest{UserId: "me"}, ErrInvalidUserParam},
+	}
+	svc := NewOrderSearchService(newMemoryOrders(), &fakeDirectory{}, "USD")
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.SearchOrders(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -2,71 +2,275 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 
+	"online-store-microservice/order-service/exchange"
 	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/ordernumber"
+	"online-store-microservice/order-service/pricing"
 	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
 	orderpb "online-store-microservice/proto/order"
 )
 
 var (
-	ErrInvalidUserID    = errors.New("invalid user_id")
-	ErrInvalidProduct   = errors.New("product_name is required")
-	ErrInvalidQuantity  = errors.New("quantity must be greater than 0")
-	ErrInvalidPrice     = errors.New("total_price must be greater than 0")
-	ErrInvalidOrderID   = errors.New("invalid order id")
-	ErrInvalidUserParam = errors.New("invalid user id")
+	ErrInvalidUserID     = errors.New("invalid user_id")
+	ErrInvalidProduct    = errors.New("items[].product_id or items[].sku is required")
+	ErrLegacyItem        = errors.New("product_name and quantity are no longer accepted; send items with product_id or sku and quantity")
+	ErrInvalidQuantity   = errors.New("invalid quantity")
+	ErrInvalidPrice      = errors.New("expected price must not be negative")
+	ErrInvalidCurrency   = errors.New("unsupported currency")
+	ErrNoItems           = errors.New("order must contain at least one item")
+	ErrTooManyItems      = errors.New("order has too many items")
+	ErrInvalidOrderID    = errors.New("invalid order id")
+	ErrInvalidNumber     = errors.New("invalid order number")
+	ErrInvalidUserParam  = errors.New("invalid user id")
+	ErrSameUser          = errors.New("from_user_id and to_user_id must differ")
+	ErrInvalidStatus     = errors.New("invalid order status")
+	ErrInvalidTransition = errors.New("illegal order status transition")
+	ErrInvalidReason     = errors.New("invalid cancellation reason_code")
+	ErrNotCancellable    = errors.New("order can no longer be cancelled")
+	ErrUseCancelOrder    = errors.New("orders are cancelled with CancelOrder, which records the reason and compensates a paid order")
+	ErrForbidden         = errors.New("not allowed to modify this order")
+	ErrInvalidPageSize   = errors.New("page_size must not be negative")
+	ErrInvalidPageToken  = errors.New("invalid page_token")
+	ErrInvalidSort       = errors.New("invalid sort")
+	ErrInvalidFilter     = errors.New("invalid filter")
+	ErrInvalidVersion    = errors.New("expected_version must not be negative")
+	ErrVersionConflict   = errors.New("order version does not match expected_version")
 )
 
+var cancellationReasons = map[string]bool{
+	"changed_mind":       true,
+	"ordered_by_mistake": true,
+	"found_cheaper":      true,
+	"delivery_too_slow":  true,
+	"payment_issue":      true,
+	"duplicate_order":    true,
+	"fraud_suspected":    true,
+	"out_of_stock":       true,
+	"other":              true,
+}
+
 type OrderService interface {
 	CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error)
 	GetOrderByID(ctx context.Context, req *orderpb.GetOrderByIdRequest) (*orderpb.GetOrderByIdResponse, error)
+	GetOrderByNumber(ctx context.Context, req *orderpb.GetOrderByNumberRequest) (*orderpb.GetOrderByIdResponse, error)
 	GetOrdersByUserID(ctx context.Context, req *orderpb.GetOrdersByUserIdRequest) (*orderpb.GetOrdersByUserIdResponse, error)
+	ReassignOrders(ctx context.Context, req *orderpb.ReassignOrdersRequest) (*orderpb.ReassignOrdersResponse, error)
+	UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error)
+	CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest) (*orderpb.CancelOrderResponse, error)
+	GetOrderHistory(ctx context.Context, req *orderpb.GetOrderHistoryRequest) (*orderpb.GetOrderHistoryResponse, error)
+	Reorder(ctx context.Context, req *orderpb.ReorderRequest) (*orderpb.ReorderResponse, error)
+	AmendOrder(ctx context.Context, req *orderpb.AmendOrderRequest) (*orderpb.AmendOrderResponse, error)
+	BuildOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*models.Order, error)
+	NumberOrder(ctx context.Context, order *models.Order) error
 }
 
 type orderService struct {
-	repo repository.OrderRepository
+	repo         repository.OrderRepository
+	keys         repository.IdempotencyRepository
+	coupons      repository.CouponRepository
+	exemptions   repository.TaxExemptionRepository
+	pricer       *pricing.Pricer
+	users        *UserChecker
+	compensation CompensationHook
+	invoices     Invoicer
+	numbers      *ordernumber.Generator
+	logger       *log.Logger
 }
 
-func NewOrderService(repo repository.OrderRepository) OrderService {
-	return &orderService{repo: repo}
+// OrderServiceDeps are what the order service is built from. Repo and Pricer
+// are required. Without Keys idempotency keys are not honoured, without
+// Coupons, Exemptions, Users, Invoices or Numbers the feature they back is
+// off, and Compensation and Logger default to doing nothing.
+type OrderServiceDeps struct {
+	Repo         repository.OrderRepository
+	Keys         repository.IdempotencyRepository
+	Coupons      repository.CouponRepository
+	Exemptions   repository.TaxExemptionRepository
+	Pricer       *pricing.Pricer
+	Users        *UserChecker
+	Compensation CompensationHook
+	Invoices     Invoicer
+	Numbers      *ordernumber.Generator
+	Logger       *log.Logger
+}
+
+func NewOrderService(deps OrderServiceDeps) OrderService {
+	if deps.Compensation == nil {
+		deps.Compensation = CompensationHooks{}
+	}
+	if deps.Logger == nil {
+		deps.Logger = log.New(io.Discard, "", 0)
+	}
+	return &orderService{
+		repo:         deps.Repo,
+		keys:         deps.Keys,
+		coupons:      deps.Coupons,
+		exemptions:   deps.Exemptions,
+		pricer:       deps.Pricer,
+		users:        deps.Users,
+		compensation: deps.Compensation,
+		invoices:     deps.Invoices,
+		numbers:      deps.Numbers,
+		logger:       deps.Logger,
+	}
 }
 
 func (s *orderService) CreateOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*orderpb.CreateOrderResponse, error) {
+	lines, expectedTotal, verified, err := s.validateCreate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := idempotencyKey(ctx)
+	if key == "" || s.keys == nil {
+		return s.createOrder(ctx, s.repo, req, lines, expectedTotal, verified)
+	}
+
+	record := &models.IdempotencyKey{Scope: "create_order:" + req.UserId, Key: key, Fingerprint: fingerprint(req)}
+	body, err := s.keys.Run(ctx, record, func(orders repository.OrderRepository) ([]byte, error) {
+		resp, err := s.createOrder(ctx, orders, req, lines, expectedTotal, verified)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(resp)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var resp orderpb.CreateOrderResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decode stored response: %w", err)
+	}
+	return &resp, nil
+}
+
+// validateCreate checks and normalizes req in place. It returns the lines to
+// price, the expected total and whether the user could be verified.
+func (s *orderService) validateCreate(ctx context.Context, req *orderpb.CreateOrderRequest) ([]pricing.LineRequest, money.Money, bool, error) {
 	if _, err := uuid.Parse(req.UserId); err != nil {
-		return nil, ErrInvalidUserID
+		return nil, money.Money{}, false, ErrInvalidUserID
 	}
-	if strings.TrimSpace(req.ProductName) == "" {
-		return nil, ErrInvalidProduct
+	currency, err := orderCurrency(req, s.pricer.Currency())
+	if err != nil {
+		return nil, money.Money{}, false, err
 	}
-	if req.Quantity <= 0 {
-		return nil, ErrInvalidQuantity
+	req.Currency = currency
+	expectedTotal, err := expectedAmount(req.ExpectedTotalAmount, req.TotalPrice, currency)
+	if err != nil {
+		return nil, money.Money{}, false, err
+	}
+	lines, err := lineRequests(req, currency)
+	if err != nil {
+		return nil, money.Money{}, false, err
+	}
+	req.CouponCode = normalizeCouponCode(req.CouponCode)
+	if req.ShippingAddress, err = normalizeAddress(req.ShippingAddress); err != nil {
+		return nil, money.Money{}, false, err
+	}
+	req.ShippingMethod = strings.ToLower(strings.TrimSpace(req.ShippingMethod))
+	if req.ShippingMethod != "" && req.ShippingAddress == nil {
+		return nil, money.Money{}, false, fmt.Errorf("%w: required with a shipping_method", ErrInvalidAddress)
+	}
+
+	verified := true
+	if s.users != nil {
+		if verified, err = s.users.Check(ctx, req.UserId); err != nil {
+			return nil, money.Money{}, false, err
+		}
 	}
-	if req.TotalPrice <= 0 {
-		return nil, ErrInvalidPrice
+	return lines, expectedTotal, verified, nil
+}
+
+// BuildOrder validates req with the rules of CreateOrder and returns the
+// order it would create, without storing it. The order has no number yet:
+// call NumberOrder right before storing it.
+func (s *orderService) BuildOrder(ctx context.Context, req *orderpb.CreateOrderRequest) (*models.Order, error) {
+	lines, expectedTotal, verified, err := s.validateCreate(ctx, req)
+	if err != nil {
+		return nil, err
 	}
+	return s.buildOrder(ctx, req, lines, expectedTotal, verified)
+}
 
+func (s *orderService) createOrder(ctx context.Context, orders repository.OrderRepository, req *orderpb.CreateOrderRequest, lines []pricing.LineRequest, expectedTotal money.Money, userVerified bool) (*orderpb.CreateOrderResponse, error) {
+	order, err := s.buildOrder(ctx, req, lines, expectedTotal, userVerified)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.NumberOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	if err := orders.Create(ctx, order, changeMeta(ctx, req.UserId, "")); err != nil {
+		return nil, err
+	}
+
+	return &orderpb.CreateOrderResponse{Order: toPBOrder(order)}, nil
+}
+
+// buildOrder prices a validated request and returns the pending order it
+// would create, without storing it.
+func (s *orderService) buildOrder(ctx context.Context, req *orderpb.CreateOrderRequest, lines []pricing.LineRequest, expectedTotal money.Money, userVerified bool) (*models.Order, error) {
 	now := time.Now().UTC()
+	var promo *pricing.Promotion
+	if req.CouponCode != "" {
+		var err error
+		if promo, err = s.promotion(ctx, req.CouponCode, now); err != nil {
+			return nil, err
+		}
+	}
+	quote, err := s.quote(ctx, req, lines, promo)
+	if err != nil {
+		return nil, err
+	}
+	if err := quote.CheckExpectedTotal(expectedTotal); err != nil {
+		return nil, err
+	}
+
 	order := &models.Order{
-		ID:          uuid.NewString(),
-		UserID:      req.UserId,
-		ProductName: strings.TrimSpace(req.ProductName),
-		Quantity:    req.Quantity,
-		TotalPrice:  req.TotalPrice,
-		Status:      "pending",
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		ID:              uuid.NewString(),
+		UserID:          req.UserId,
+		Status:          models.OrderStatusPending,
+		CouponCode:      req.CouponCode,
+		CouponTerms:     couponTerms(promo),
+		ShippingAddress: addressFromPB(req.ShippingAddress),
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
+	if userVerified {
+		order.UserVerifiedAt = &now
+	}
+	applyQuote(order, quote)
+	return order, nil
+}
 
-	if err := s.repo.Create(ctx, order); err != nil {
+// quote prices a validated request at today's prices, with the terms of its
+// coupon in promo.
+func (s *orderService) quote(ctx context.Context, req *orderpb.CreateOrderRequest, lines []pricing.LineRequest, promo *pricing.Promotion) (*pricing.Quote, error) {
+	exempt, err := s.taxExempt(ctx, req.UserId)
+	if err != nil {
 		return nil, err
 	}
 
-	return &orderpb.CreateOrderResponse{Order: toPBOrder(order)}, nil
+	return s.pricer.QuoteOrder(ctx, pricing.QuoteRequest{
+		Lines:          lines,
+		Promotion:      promo,
+		Destination:    taxDestination(req.ShippingAddress),
+		TaxExempt:      exempt,
+		ShippingMethod: req.ShippingMethod,
+		Currency:       req.Currency,
+	})
 }
 
 func (s *orderService) GetOrderByID(ctx context.Context, req *orderpb.GetOrderByIdRequest) (*orderpb.GetOrderByIdResponse, error) {
@@ -78,7 +282,34 @@ func (s *orderService) GetOrderByID(ctx context.Context, req *orderpb.GetOrderBy
 	if err != nil {
 		return nil, err
 	}
-	return &orderpb.GetOrderByIdResponse{Order: toPBOrder(order)}, nil
+	return s.orderResponse(ctx, order, req.IncludeHistory)
+}
+
+func (s *orderService) orderResponse(ctx context.Context, order *models.Order, includeHistory bool) (*orderpb.GetOrderByIdResponse, error) {
+	data := toPBOrder(order)
+	if includeHistory {
+		changes, err := s.repo.History(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.History = toPBHistory(changes)
+	}
+	return &orderpb.GetOrderByIdResponse{Order: data}, nil
+}
+
+func (s *orderService) GetOrderHistory(ctx context.Context, req *orderpb.GetOrderHistoryRequest) (*orderpb.GetOrderHistoryResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	if _, err := s.repo.GetByID(ctx, req.OrderId); err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.History(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	return &orderpb.GetOrderHistoryResponse{History: toPBHistory(changes)}, nil
 }
 
 func (s *orderService) GetOrdersByUserID(ctx context.Context, req *orderpb.GetOrdersByUserIdRequest) (*orderpb.GetOrdersByUserIdResponse, error) {
@@ -86,28 +317,225 @@ func (s *orderService) GetOrdersByUserID(ctx context.Context, req *orderpb.GetOr
 		return nil, ErrInvalidUserParam
 	}
 
-	orders, err := s.repo.GetByUserID(ctx, req.UserId)
+	filter, sort, err := listFilter(req, s.pricer.Currency())
 	if err != nil {
 		return nil, err
 	}
 
-	resp := &orderpb.GetOrdersByUserIdResponse{Orders: make([]*orderpb.OrderData, 0, len(orders))}
-	for i := range orders {
-		resp.Orders = append(resp.Orders, toPBOrder(&orders[i]))
+	orders, next, err := listPage(ctx, s.repo, filter, sort)
+	if err != nil {
+		return nil, err
 	}
+	return &orderpb.GetOrdersByUserIdResponse{Orders: orders, NextPageToken: next}, nil
+}
 
-	return resp, nil
+func (s *orderService) ReassignOrders(ctx context.Context, req *orderpb.ReassignOrdersRequest) (*orderpb.ReassignOrdersResponse, error) {
+	if _, err := uuid.Parse(req.FromUserId); err != nil {
+		return nil, ErrInvalidUserParam
+	}
+	if _, err := uuid.Parse(req.ToUserId); err != nil {
+		return nil, ErrInvalidUserParam
+	}
+	if req.FromUserId == req.ToUserId {
+		return nil, ErrSameUser
+	}
+	for _, id := range req.OrderIds {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, ErrInvalidOrderID
+		}
+	}
+
+	ids, err := s.repo.ReassignUser(ctx, req.FromUserId, req.ToUserId, req.OrderIds, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if ids == nil {
+		ids = []string{}
+	}
+	return &orderpb.ReassignOrdersResponse{OrderIds: ids}, nil
+}
+
+func (s *orderService) UpdateOrderStatus(ctx context.Context, req *orderpb.UpdateOrderStatusRequest) (*orderpb.UpdateOrderStatusResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	req.Status = strings.TrimSpace(strings.ToLower(req.Status))
+	if !IsKnownStatus(req.Status) {
+		return nil, ErrInvalidStatus
+	}
+	if req.Status == models.OrderStatusCancelled {
+		return nil, ErrUseCancelOrder
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	order, err := s.repo.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if !CanTransition(order.Status, req.Status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrInvalidTransition, order.Status, req.Status)
+	}
+
+	actor := strings.TrimSpace(req.ActorId)
+	if actor == "" {
+		actor = "admin"
+	}
+	if err := s.repo.UpdateStatus(ctx, order, req.Status, changeMeta(ctx, actor, strings.TrimSpace(req.Reason))); err != nil {
+		return nil, versionError(err, req.ExpectedVersion)
+	}
+
+	// A failed invoice is issued on the next GetInvoice instead.
+	if req.Status == models.OrderStatusPaid && s.invoices != nil {
+		if _, err := s.invoices.IssueInvoice(ctx, order); err != nil {
+			s.logger.Printf("issue invoice failed order_id=%s err=%v", order.ID, err)
+		}
+	}
+
+	return &orderpb.UpdateOrderStatusResponse{Order: toPBOrder(order)}, nil
+}
+
+func (s *orderService) CancelOrder(ctx context.Context, req *orderpb.CancelOrderRequest) (*orderpb.CancelOrderResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	req.ReasonCode = strings.TrimSpace(strings.ToLower(req.ReasonCode))
+	if !cancellationReasons[req.ReasonCode] {
+		return nil, ErrInvalidReason
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	order, err := s.repo.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	actor, err := orderActor(order, req.ActorId, req.ActorIsAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if !CanTransition(order.Status, models.OrderStatusCancelled) {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotCancellable, order.Status)
+	}
+
+	previous := order.Status
+	if err := s.repo.Cancel(ctx, order, changeMeta(ctx, actor, req.ReasonCode)); err != nil {
+		return nil, versionError(err, req.ExpectedVersion)
+	}
+
+	if previous == models.OrderStatusPaid {
+		if err := s.compensation.OnPaidOrderCancelled(ctx, order); err != nil {
+			s.logger.Printf("compensation failed order_id=%s err=%v", order.ID, err)
+		}
+	}
+
+	return &orderpb.CancelOrderResponse{Order: toPBOrder(order)}, nil
+}
+
+// checkVersion rejects the change when the caller asked for a specific order
+// version and the order has moved on. Zero means the caller did not ask.
+func checkVersion(order *models.Order, expected int64) error {
+	if expected != 0 && order.Version != expected {
+		return fmt.Errorf("%w: order is at version %d", ErrVersionConflict, order.Version)
+	}
+	return nil
+}
+
+// versionError reports a write that lost a race as a version conflict when
+// the caller pinned a version, since its view of the order is now outdated.
+func versionError(err error, expected int64) error {
+	if expected != 0 && errors.Is(err, repository.ErrStaleOrder) {
+		return fmt.Errorf("%w: order was modified concurrently", ErrVersionConflict)
+	}
+	return err
+}
+
+// exchangeRate is the stored rate of order without the padding of the
+// numeric column.
+func exchangeRate(order *models.Order) string {
+	v, err := exchange.ParseRate(order.ExchangeRate)
+	if err != nil {
+		return order.ExchangeRate
+	}
+	return exchange.Rate{Value: v}.String()
 }
 
 func toPBOrder(order *models.Order) *orderpb.OrderData {
-	return &orderpb.OrderData{
-		Id:          order.ID,
-		UserId:      order.UserID,
-		ProductName: order.ProductName,
-		Quantity:    order.Quantity,
-		TotalPrice:  order.TotalPrice,
-		Status:      order.Status,
-		CreatedAt:   order.CreatedAt.Format(time.RFC3339),
-		UpdatedAt:   order.UpdatedAt.Format(time.RFC3339),
+	data := &orderpb.OrderData{
+		Id:                 order.ID,
+		OrderNumber:        order.Number,
+		UserId:             order.UserID,
+		ProductName:        order.ProductName,
+		Quantity:           order.Quantity,
+		TotalPrice:         order.TotalPrice,
+		Subtotal:           order.Subtotal,
+		DiscountTotal:      order.DiscountTotal,
+		TaxTotal:           order.TaxTotal,
+		Currency:           order.Currency,
+		SubtotalAmount:     amount(order.SubtotalAmount, order.Currency),
+		DiscountAmount:     amount(order.DiscountAmount, order.Currency),
+		TaxAmount:          amount(order.TaxAmount, order.Currency),
+		TotalAmount:        amount(order.TotalAmount, order.Currency),
+		Status:             order.Status,
+		CreatedAt:          order.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          order.UpdatedAt.Format(time.RFC3339),
+		CancellationReason: order.CancellationReason,
+		CancelledBy:        order.CancelledBy,
+		Items:              toPBItems(order),
+		Version:            order.Version,
+		RefundedAmount:     amount(order.RefundedAmount, order.Currency),
+		NetTotalAmount:     amount(order.TotalAmount-order.RefundedAmount, order.Currency),
+		CouponCode:         order.CouponCode,
+		FreeShipping:       order.FreeShipping,
+		Discounts:          toPBDiscounts(order),
+		ShippingAddress:    toPBAddress(order.ShippingAddress),
+		TaxRulesVersion:    order.TaxRulesVersion,
+		PricesIncludeTax:   order.PricesIncludeTax,
+		TaxExempt:          order.TaxExempt,
+		TaxLines:           toPBTaxLines(order),
+		ShippingMethod:     order.ShippingMethod,
+		ShippingCarrier:    order.ShippingCarrier,
+		ShippingZone:       order.ShippingZone,
+		ShippingAmount:     amount(order.ShippingAmount, order.Currency),
+		BaseCurrency:       order.BaseCurrency,
+		ExchangeRate:       exchangeRate(order),
+		BaseTotalAmount:    amount(order.BaseTotalAmount, order.BaseCurrency),
+	}
+	if order.ExchangeRateFrom != nil {
+		data.ExchangeRateEffectiveFrom = order.ExchangeRateFrom.Format(time.RFC3339)
+	}
+	if order.TaxExempt {
+		data.TaxExemptAmount = amount(order.TaxExemptAmount, order.Currency)
+	}
+	if order.CancelledAt != nil {
+		data.CancelledAt = order.CancelledAt.Format(time.RFC3339)
+	}
+	return data
+}
+
+func toPBHistory(changes []models.OrderStatusChange) []*orderpb.OrderStatusChangeData {
+	history := make([]*orderpb.OrderStatusChangeData, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, &orderpb.OrderStatusChangeData{
+			Id:         change.ID,
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Actor:      change.Actor,
+			Reason:     change.Reason,
+			RequestId:  change.RequestID,
+			CreatedAt:  change.CreatedAt.Format(time.RFC3339),
+			Changes:    toPBChanges(change.Changes),
+		})
 	}
+	return history
 }
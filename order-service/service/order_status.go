@@ -0,0 +1,29 @@
+package service
+
+import "online-store-microservice/order-service/models"
+
+var orderTransitions = map[string][]string{
+	models.OrderStatusPending:         {models.OrderStatusAwaitingPayment, models.OrderStatusCancelled, models.OrderStatusExpired},
+	models.OrderStatusAwaitingPayment: {models.OrderStatusPaid, models.OrderStatusCancelled, models.OrderStatusExpired},
+	models.OrderStatusPaid:            {models.OrderStatusFulfilling, models.OrderStatusCancelled, models.OrderStatusRefunded},
+	models.OrderStatusFulfilling:      {models.OrderStatusShipped},
+	models.OrderStatusShipped:         {models.OrderStatusDelivered},
+	models.OrderStatusDelivered:       {models.OrderStatusRefunded},
+	models.OrderStatusCancelled:       {models.OrderStatusRefunded},
+	models.OrderStatusRefunded:        {},
+	models.OrderStatusExpired:         {},
+}
+
+func IsKnownStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+
+	"online-store-microservice/order-service/models"
+)
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.OrderStatusPending, models.OrderStatusAwaitingPayment, true},
+		{models.OrderStatusAwaitingPayment, models.OrderStatusPaid, true},
+		{models.OrderStatusPaid, models.OrderStatusFulfilling, true},
+		{models.OrderStatusFulfilling, models.OrderStatusShipped, true},
+		{models.OrderStatusShipped, models.OrderStatusDelivered, true},
+		{models.OrderStatusPending, models.OrderStatusCancelled, true},
+		{models.OrderStatusPaid, models.OrderStatusRefunded, true},
+		{models.OrderStatusDelivered, models.OrderStatusRefunded, true},
+		{models.OrderStatusAwaitingPayment, models.OrderStatusExpired, true},
+		{models.OrderStatusPaid, models.OrderStatusExpired, false},
+		{models.OrderStatusExpired, models.OrderStatusPaid, false},
+		{models.OrderStatusPending, models.OrderStatusPaid, false},
+		{models.OrderStatusShipped, models.OrderStatusCancelled, false},
+		{models.OrderStatusDelivered, models.OrderStatusPending, false},
+		{models.OrderStatusRefunded, models.OrderStatusPaid, false},
+		{models.OrderStatusPending, models.OrderStatusPending, false},
+		{"unknown", models.OrderStatusPaid, false},
+	}
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestEveryTransitionTargetIsKnown(t *testing.T) {
+	for from, targets := range orderTransitions {
+		for _, to := range targets {
+			if !IsKnownStatus(to) {
+				t.Errorf("transition %q -> %q targets an unknown status", from, to)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func TestUpdateOrderStatusChecksExpectedVersion(t *testing.T) {
+	id := uuid.NewString()
+	svc, _ := newTestOrderService(OrderServiceDeps{Repo: newMemoryOrders(&models.Order{ID: id, Status: models.OrderStatusPending, Version: 3})})
+
+	_, err := svc.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+		Id: id, Status: models.OrderStatusAwaitingPayment, ExpectedVersion: 2,
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+
+	resp, err := svc.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+		Id: id, Status: models.OrderStatusAwaitingPayment, ExpectedVersion: 3,
+	})
+	if err != nil {
+		t.Fatalf("UpdateOrderStatus: %v", err)
+	}
+	if resp.Order.Version != 4 {
+		t.Fatalf("version = %d, want 4", resp.Order.Version)
+	}
+
+	resp, err = svc.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{
+		Id: id, Status: models.OrderStatusPaid,
+	})
+	if err != nil {
+		t.Fatalf("UpdateOrderStatus without expected version: %v", err)
+	}
+	if resp.Order.Version != 5 {
+		t.Fatalf("version = %d, want 5", resp.Order.Version)
+	}
+}
+
+func TestCancelOrderReportsLostRaceAsVersionConflict(t *testing.T) {
+	id := uuid.NewString()
+	userID := uuid.NewString()
+	repo := newMemoryOrders(&models.Order{ID: id, UserID: userID, Status: models.OrderStatusPending, Version: 1})
+	repo.stale = true
+	svc, _ := newTestOrderService(OrderServiceDeps{Repo: repo})
+
+	_, err := svc.CancelOrder(context.Background(), &orderpb.CancelOrderRequest{
+		Id: id, ReasonCode: "changed_mind", ActorId: userID, ExpectedVersion: 1,
+	})
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+
+	_, err = svc.CancelOrder(context.Background(), &orderpb.CancelOrderRequest{
+		Id: id, ReasonCode: "changed_mind", ActorId: userID,
+	})
+	if !errors.Is(err, repository.ErrStaleOrder) {
+		t.Fatalf("err = %v, want ErrStaleOrder", err)
+	}
+}
+
+func TestUpdateOrderStatusRejectsCancelled(t *testing.T) {
+	id := uuid.NewString()
+	svc, repo := newTestOrderService(OrderServiceDeps{Repo: newMemoryOrders(&models.Order{ID: id, Status: models.OrderStatusPaid, Version: 2})})
+
+	_, err := svc.UpdateOrderStatus(context.Background(), &orderpb.UpdateOrderStatusRequest{Id: id, Status: "Cancelled"})
+	if !errors.Is(err, ErrUseCancelOrder) {
+		t.Fatalf("err = %v, want ErrUseCancelOrder", err)
+	}
+	if order := repo.stored(id); order.Status != models.OrderStatusPaid || order.Version != 2 {
+		t.Fatalf("order = %s v%d, want it untouched", order.Status, order.Version)
+	}
+}
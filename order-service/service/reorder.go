@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var ErrNothingToReorder = errors.New("none of the order's items are sold anymore")
+
+// Reorder orders the items of an earlier order again at today's prices and
+// exchange rate. Items that are no longer sold are left out. The response
+// lists them, along with the items whose price changed. The new order keeps
+// the currency, shipping address and method of the earlier one but not its
+// coupon, and goes through CreateOrder, so an Idempotency-Key works the same
+// way.
+func (s *orderService) Reorder(ctx context.Context, req *orderpb.ReorderRequest) (*orderpb.ReorderResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	previous, err := s.repo.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orderActor(previous, req.ActorId, req.ActorIsAdmin); err != nil {
+		return nil, err
+	}
+
+	items := orderLines(previous)
+	refs := make([]string, 0, len(items))
+	for _, item := range items {
+		refs = append(refs, reorderRef(item))
+	}
+	prices, err := s.pricer.Prices(ctx, refs, previous.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &orderpb.ReorderResponse{Preview: req.Preview, UnavailableItems: []*orderpb.ReorderItemData{}, PriceChanges: []*orderpb.ReorderItemData{}}
+	create := &orderpb.CreateOrderRequest{
+		UserId:          previous.UserID,
+		Currency:        previous.Currency,
+		ShippingAddress: toPBAddress(previous.ShippingAddress),
+		ShippingMethod:  previous.ShippingMethod,
+	}
+	for i, item := range items {
+		data := &orderpb.ReorderItemData{
+			OrderItemId:        item.ID,
+			ProductId:          item.ProductID,
+			Sku:                item.SKU,
+			Name:               item.Name,
+			Quantity:           item.Quantity,
+			PreviousUnitAmount: amount(saleUnitAmount(previous, item), item.Currency),
+		}
+		price, ok := prices[refs[i]]
+		if !ok {
+			resp.UnavailableItems = append(resp.UnavailableItems, data)
+			continue
+		}
+		unit := price.EffectiveUnitPrice()
+		data.UnitAmount = &unit
+		if !unit.Equal(*data.PreviousUnitAmount) {
+			resp.PriceChanges = append(resp.PriceChanges, data)
+		}
+		create.Items = append(create.Items, &orderpb.OrderItemInput{ProductId: price.ProductID, Quantity: item.Quantity})
+	}
+	if len(create.Items) == 0 {
+		return nil, ErrNothingToReorder
+	}
+
+	if !req.Preview {
+		created, err := s.CreateOrder(ctx, create)
+		if err != nil {
+			return nil, err
+		}
+		resp.Order = created.Order
+		return resp, nil
+	}
+
+	lines, err := lineRequests(create, create.Currency)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.buildOrder(ctx, create, lines, money.Money{}, true)
+	if err != nil {
+		return nil, err
+	}
+	resp.Order = toPBOrder(order)
+	resp.Order.Id = ""
+	return resp, nil
+}
+
+// reorderRef is how an item of an earlier order is looked up in the price
+// source. Orders from before multi-item orders only have a product name,
+// which is what they were priced by.
+func reorderRef(item models.OrderItem) string {
+	switch {
+	case item.ProductID != "":
+		return item.ProductID
+	case item.SKU != "":
+		return item.SKU
+	default:
+		return item.Name
+	}
+}
+
+// saleUnitAmount is what one unit of item cost in order after sale prices but
+// before coupons, which is what the current effective price compares with.
+func saleUnitAmount(order *models.Order, item models.OrderItem) int64 {
+	unit := item.UnitAmount
+	if item.Quantity <= 0 {
+		return unit
+	}
+	for _, d := range order.Discounts {
+		if d.Source == pricing.DiscountSourceSale && d.OrderItemID != nil && *d.OrderItemID == item.ID {
+			unit -= d.Amount / int64(item.Quantity)
+		}
+	}
+	return unit
+}
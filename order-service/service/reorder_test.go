@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// previousOrder bought two mice on sale at 80.00 and a pad at 50.00 with a
+// coupon, and a cable that is no longer sold.
+func previousOrder() *models.Order {
+	id := uuid.NewString()
+	mouse, pad, cable := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	return &models.Order{
+		ID:         id,
+		UserID:     uuid.NewString(),
+		Status:     models.OrderStatusDelivered,
+		Currency:   "USD",
+		CouponCode: "WELCOME10",
+		Items: []models.OrderItem{
+			{ID: mouse, OrderID: id, ProductID: "mouse", Name: "Mouse", Quantity: 2, Currency: "USD", UnitAmount: 10000},
+			{ID: pad, OrderID: id, ProductID: "pad", Name: "Pad", Quantity: 1, Currency: "USD", UnitAmount: 5000},
+			{ID: cable, OrderID: id, ProductID: "cable", Name: "Cable", Quantity: 3, Currency: "USD", UnitAmount: 1000},
+		},
+		Discounts: []models.OrderDiscount{
+			{OrderItemID: &mouse, Source: pricing.DiscountSourceSale, Amount: 4000},
+			{OrderItemID: &pad, Source: pricing.DiscountSourceCoupon, Code: "WELCOME10", Amount: 500},
+		},
+	}
+}
+
+func newReorderService(previous *models.Order) (OrderService, *memoryOrders) {
+	return newTestOrderService(OrderServiceDeps{Repo: newMemoryOrders(previous)})
+}
+
+func TestReorderRepricesAndReportsChanges(t *testing.T) {
+	previous := previousOrder()
+	svc, repo := newReorderService(previous)
+
+	resp, err := svc.Reorder(context.Background(), &orderpb.ReorderRequest{OrderId: previous.ID, ActorId: previous.UserID})
+	if err != nil {
+		t.Fatalf("Reorder: %v", err)
+	}
+	if len(repo.created) != 1 || resp.Order.Id != repo.created[0].ID || resp.Order.UserId != previous.UserID {
+		t.Fatalf("created %d orders, response order %q", len(repo.created), resp.Order.Id)
+	}
+	order := repo.created[0]
+	if len(order.Items) != 2 || order.TotalAmount != 23000 || order.CouponCode != "" {
+		t.Fatalf("new order has %d items, total %d, coupon %q", len(order.Items), order.TotalAmount, order.CouponCode)
+	}
+
+	if len(resp.UnavailableItems) != 1 || resp.UnavailableItems[0].ProductId != "cable" || resp.UnavailableItems[0].UnitAmount != nil {
+		t.Fatalf("unavailable = %+v", resp.UnavailableItems)
+	}
+	// The pad's coupon discount is not a price change; the mouse's sale price is.
+	if len(resp.PriceChanges) != 1 || resp.PriceChanges[0].ProductId != "mouse" {
+		t.Fatalf("price changes = %+v", resp.PriceChanges)
+	}
+	if change := resp.PriceChanges[0]; change.PreviousUnitAmount.Amount != 8000 || change.UnitAmount.Amount != 9000 {
+		t.Fatalf("mouse went from %d to %d", change.PreviousUnitAmount.Amount, change.UnitAmount.Amount)
+	}
+}
+
+func TestReorderPreviewDoesNotCreate(t *testing.T) {
+	previous := previousOrder()
+	svc, repo := newReorderService(previous)
+
+	resp, err := svc.Reorder(context.Background(), &orderpb.ReorderRequest{OrderId: previous.ID, ActorIsAdmin: true, Preview: true})
+	if err != nil {
+		t.Fatalf("Reorder preview: %v", err)
+	}
+	if !resp.Preview || resp.Order.Id != "" || resp.Order.TotalAmount.Amount != 23000 || len(repo.created) != 0 {
+		t.Fatalf("preview order %q total %d, created %d", resp.Order.Id, resp.Order.TotalAmount.Amount, len(repo.created))
+	}
+}
+
+func TestReorderRejects(t *testing.T) {
+	previous := previousOrder()
+	svc, _ := newReorderService(previous)
+	ctx := context.Background()
+
+	if _, err := svc.Reorder(ctx, &orderpb.ReorderRequest{OrderId: previous.ID, ActorId: uuid.NewString()}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("other user: err = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.Reorder(ctx, &orderpb.ReorderRequest{OrderId: "nope", ActorIsAdmin: true}); !errors.Is(err, ErrInvalidOrderID) {
+		t.Errorf("bad id: err = %v, want ErrInvalidOrderID", err)
+	}
+
+	previous.Items = previous.Items[2:]
+	if _, err := svc.Reorder(ctx, &orderpb.ReorderRequest{OrderId: previous.ID, ActorIsAdmin: true}); !errors.Is(err, ErrNothingToReorder) {
+		t.Errorf("nothing sold: err = %v, want ErrNothingToReorder", err)
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"online-store-microservice/order-service/repository"
+)
+
+type requestIDCtx struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDCtx{}, id)
+}
+
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtx{}).(string)
+	return id
+}
+
+func changeMeta(ctx context.Context, actor, reason string) repository.ChangeMeta {
+	return repository.ChangeMeta{Actor: actor, Reason: reason, RequestID: requestID(ctx)}
+}
@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/refund"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var (
+	ErrInvalidReturnID     = errors.New("invalid return id")
+	ErrInvalidReturnReason = errors.New("invalid return reason_code")
+	ErrInvalidReturnItems  = errors.New("return must list order items with a quantity greater than 0")
+	ErrInvalidReturnStatus = errors.New("invalid return status")
+	ErrInvalidRefundAmount = errors.New("refund amount must be greater than 0 and at most the requested amount")
+	ErrNotReturnable       = errors.New("only delivered orders can be returned")
+	ErrReturnWindowClosed  = errors.New("return window has closed")
+	ErrReturnTransition    = errors.New("illegal return status transition")
+	ErrRefundExceedsOrder  = errors.New("refund exceeds the amount left on the order")
+	ErrRefundGateway       = errors.New("refund gateway failed")
+)
+
+var returnReasons = map[string]bool{
+	"damaged":          true,
+	"defective":        true,
+	"wrong_item":       true,
+	"not_as_described": true,
+	"changed_mind":     true,
+	"other":            true,
+}
+
+type ReturnService interface {
+	RequestReturn(ctx context.Context, req *orderpb.RequestReturnRequest) (*orderpb.ReturnResponse, error)
+	ListOrderReturns(ctx context.Context, req *orderpb.ListOrderReturnsRequest) (*orderpb.ListOrderReturnsResponse, error)
+	UpdateReturnStatus(ctx context.Context, req *orderpb.UpdateReturnStatusRequest) (*orderpb.ReturnResponse, error)
+	RefundReturn(ctx context.Context, req *orderpb.RefundReturnRequest) (*orderpb.ReturnResponse, error)
+}
+
+type returnService struct {
+	orders   repository.OrderRepository
+	returns  repository.ReturnRepository
+	gateway  refund.Gateway
+	invoices Invoicer
+	window   time.Duration
+	logger   *log.Logger
+	now      func() time.Time
+}
+
+func NewReturnService(orders repository.OrderRepository, returns repository.ReturnRepository, gateway refund.Gateway, invoices Invoicer, window time.Duration, logger *log.Logger) ReturnService {
+	return &returnService{orders: orders, returns: returns, gateway: gateway, invoices: invoices, window: window, logger: logger, now: time.Now}
+}
+
+func (s *returnService) RequestReturn(ctx context.Context, req *orderpb.RequestReturnRequest) (*orderpb.ReturnResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	req.ReasonCode = strings.TrimSpace(strings.ToLower(req.ReasonCode))
+	if !returnReasons[req.ReasonCode] {
+		return nil, ErrInvalidReturnReason
+	}
+	quantities, err := returnQuantities(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	actor, err := orderActor(order, req.ActorId, req.ActorIsAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.OrderStatusDelivered {
+		return nil, fmt.Errorf("%w: order is %s", ErrNotReturnable, order.Status)
+	}
+	deliveredAt, err := s.deliveredAt(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if s.now().After(deliveredAt.Add(s.window)) {
+		return nil, fmt.Errorf("%w: order was delivered on %s", ErrReturnWindowClosed, deliveredAt.Format(time.RFC3339))
+	}
+
+	items := make(map[string]models.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+	}
+
+	now := s.now().UTC()
+	ret := &models.OrderReturn{
+		ID:          uuid.NewString(),
+		OrderID:     order.ID,
+		UserID:      order.UserID,
+		Status:      models.ReturnStatusRequested,
+		Reason:      req.ReasonCode,
+		Note:        strings.TrimSpace(req.Note),
+		Currency:    order.Currency,
+		RequestedBy: actor,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, in := range req.Items {
+		qty, ok := quantities[in.OrderItemId]
+		if !ok {
+			continue
+		}
+		delete(quantities, in.OrderItemId)
+
+		item, ok := items[in.OrderItemId]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is not part of the order", ErrInvalidReturnItems, in.OrderItemId)
+		}
+		if qty > item.Quantity {
+			return nil, fmt.Errorf("%w: %s was bought %d times", repository.ErrReturnQuantityExceeded, item.Name, item.Quantity)
+		}
+		amount := refundableAmount(order, item, qty)
+		ret.Items = append(ret.Items, models.OrderReturnItem{
+			ID:          uuid.NewString(),
+			ReturnID:    ret.ID,
+			OrderItemID: item.ID,
+			Name:        item.Name,
+			Quantity:    qty,
+			Amount:      amount.Amount,
+		})
+		ret.RequestedAmount += amount.Amount
+	}
+
+	if err := s.returns.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+	return &orderpb.ReturnResponse{Return: toPBReturn(ret), Order: toPBOrder(order)}, nil
+}
+
+func (s *returnService) ListOrderReturns(ctx context.Context, req *orderpb.ListOrderReturnsRequest) (*orderpb.ListOrderReturnsResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	order, err := s.orders.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orderActor(order, req.ActorId, req.ActorIsAdmin); err != nil {
+		return nil, err
+	}
+
+	returns, err := s.returns.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]*orderpb.ReturnData, 0, len(returns))
+	for i := range returns {
+		data = append(data, toPBReturn(&returns[i]))
+	}
+	return &orderpb.ListOrderReturnsResponse{Returns: data, Order: toPBOrder(order)}, nil
+}
+
+func (s *returnService) UpdateReturnStatus(ctx context.Context, req *orderpb.UpdateReturnStatusRequest) (*orderpb.ReturnResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidReturnID
+	}
+	req.Status = strings.TrimSpace(strings.ToLower(req.Status))
+	switch req.Status {
+	case models.ReturnStatusApproved, models.ReturnStatusRejected, models.ReturnStatusReceived:
+	default:
+		return nil, ErrInvalidReturnStatus
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	ret, err := s.returns.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	order, err := s.orders.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if !CanTransitionReturn(ret.Status, req.Status) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrReturnTransition, ret.Status, req.Status)
+	}
+
+	actor := strings.TrimSpace(req.ActorId)
+	if actor == "" {
+		actor = "admin"
+	}
+	if err := s.returns.UpdateStatus(ctx, ret, req.Status, actor, strings.TrimSpace(req.Note)); err != nil {
+		return nil, err
+	}
+	return &orderpb.ReturnResponse{Return: toPBReturn(ret), Order: toPBOrder(order)}, nil
+}
+
+// RefundReturn pays out a received return through the refund gateway and
+// records it. The gateway is called with the return id as its key, so a
+// retry after a failed write returns the original payout instead of a new one.
+func (s *returnService) RefundReturn(ctx context.Context, req *orderpb.RefundReturnRequest) (*orderpb.ReturnResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidReturnID
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	ret, err := s.returns.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !CanTransitionReturn(ret.Status, models.ReturnStatusRefunded) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrReturnTransition, ret.Status, models.ReturnStatusRefunded)
+	}
+	amount, err := refundAmount(req.Amount, ret)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orders.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	left := order.TotalAmount - order.RefundedAmount
+	if amount.Amount > left {
+		return nil, fmt.Errorf("%w: %s left", ErrRefundExceedsOrder, money.New(left, order.Currency))
+	}
+
+	res, err := s.gateway.Refund(ctx, refund.Request{Key: ret.ID, OrderID: order.ID, Amount: amount, Reason: ret.Reason})
+	if err != nil {
+		s.logger.Printf("refund failed return_id=%s order_id=%s err=%v", ret.ID, order.ID, err)
+		return nil, fmt.Errorf("%w: %v", ErrRefundGateway, err)
+	}
+
+	orderStatus := order.Status
+	if order.RefundedAmount+res.Amount.Amount == order.TotalAmount && CanTransition(order.Status, models.OrderStatusRefunded) {
+		orderStatus = models.OrderStatusRefunded
+	}
+	actor := strings.TrimSpace(req.ActorId)
+	if actor == "" {
+		actor = "admin"
+	}
+	outcome := repository.Refund{Amount: res.Amount.Amount, Reference: res.Reference, OrderStatus: orderStatus}
+	if err := s.returns.Refund(ctx, ret, order, outcome, changeMeta(ctx, actor, "return_refunded")); err != nil {
+		return nil, versionError(err, req.ExpectedVersion)
+	}
+
+	// A failed credit note is issued on the next GetInvoice instead.
+	if s.invoices != nil {
+		if _, err := s.invoices.IssueCreditNote(ctx, order, ret); err != nil {
+			s.logger.Printf("issue credit note failed return_id=%s order_id=%s err=%v", ret.ID, order.ID, err)
+		}
+	}
+	return &orderpb.ReturnResponse{Return: toPBReturn(ret), Order: toPBOrder(order)}, nil
+}
+
+// deliveredAt is when the order last moved to delivered. Orders delivered
+// before status history existed fall back to their last update.
+func (s *returnService) deliveredAt(ctx context.Context, order *models.Order) (time.Time, error) {
+	changes, err := s.orders.History(ctx, order.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].ToStatus == models.OrderStatusDelivered {
+			return changes[i].CreatedAt, nil
+		}
+	}
+	return order.UpdatedAt, nil
+}
+
+// orderActor returns who is acting on the order: the owner or "admin".
+func orderActor(order *models.Order, actorID string, isAdmin bool) (string, error) {
+	if isAdmin {
+		return "admin", nil
+	}
+	if actorID == "" || actorID != order.UserID {
+		return "", ErrForbidden
+	}
+	return actorID, nil
+}
+
+// returnQuantities sums the requested quantity per order item so that an
+// item listed twice is checked against what was bought only once.
+func returnQuantities(items []*orderpb.ReturnItemInput) (map[string]int32, error) {
+	if len(items) == 0 || len(items) > maxOrderItems {
+		return nil, ErrInvalidReturnItems
+	}
+	quantities := make(map[string]int32, len(items))
+	for _, in := range items {
+		if in == nil || in.Quantity <= 0 {
+			return nil, ErrInvalidReturnItems
+		}
+		if _, err := uuid.Parse(in.OrderItemId); err != nil {
+			return nil, ErrInvalidReturnItems
+		}
+		quantities[in.OrderItemId] += in.Quantity
+	}
+	return quantities, nil
+}
+
+// refundableAmount is what qty units of an order line are worth: their share
+// of the discounted line total plus their share of the order's tax.
+func refundableAmount(order *models.Order, item models.OrderItem, qty int32) money.Money {
+	value := money.New(item.LineAmount, order.Currency).MulRatio(int64(qty), int64(item.Quantity))
+	net := order.SubtotalAmount - order.DiscountAmount
+	// Line amounts already contain tax when prices include it; an exempt
+	// customer on such prices paid the line amount less the waived tax.
+	paid := net + order.TaxAmount
+	if order.PricesIncludeTax {
+		paid = net - order.TaxExemptAmount
+	}
+	if paid == net || net <= 0 {
+		return value
+	}
+	return value.MulRatio(paid, net)
+}
+
+func refundAmount(m *orderpb.Money, ret *models.OrderReturn) (money.Money, error) {
+	if m == nil {
+		return money.New(ret.RequestedAmount, ret.Currency), nil
+	}
+	if !strings.EqualFold(m.Currency, ret.Currency) {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrInvalidCurrency, m.Currency)
+	}
+	if m.Amount <= 0 || m.Amount > ret.RequestedAmount {
+		return money.Money{}, ErrInvalidRefundAmount
+	}
+	return money.New(m.Amount, ret.Currency), nil
+}
+
+func toPBReturn(ret *models.OrderReturn) *orderpb.ReturnData {
+	items := make([]*orderpb.ReturnItemData, 0, len(ret.Items))
+	for _, item := range ret.Items {
+		items = append(items, &orderpb.ReturnItemData{
+			Id:          item.ID,
+			OrderItemId: item.OrderItemID,
+			Name:        item.Name,
+			Quantity:    item.Quantity,
+			Amount:      amount(item.Amount, ret.Currency),
+		})
+	}
+	data := &orderpb.ReturnData{
+		Id:              ret.ID,
+		OrderId:         ret.OrderID,
+		UserId:          ret.UserID,
+		Status:          ret.Status,
+		ReasonCode:      ret.Reason,
+		Note:            ret.Note,
+		Items:           items,
+		RequestedAmount: amount(ret.RequestedAmount, ret.Currency),
+		RefundedAmount:  amount(ret.RefundedAmount, ret.Currency),
+		RefundReference: ret.RefundReference,
+		RequestedBy:     ret.RequestedBy,
+		DecidedBy:       ret.DecidedBy,
+		DecisionNote:    ret.DecisionNote,
+		CreatedAt:       ret.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       ret.UpdatedAt.Format(time.RFC3339),
+	}
+	if ret.ReceivedAt != nil {
+		data.ReceivedAt = ret.ReceivedAt.Format(time.RFC3339)
+	}
+	if ret.RefundedAt != nil {
+		data.RefundedAt = ret.RefundedAt.Format(time.RFC3339)
+	}
+	return data
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/refund"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type memoryReturns struct {
+	repository.ReturnRepository
+	orders  *memoryOrders
+	returns map[string]*models.OrderReturn
+}
+
+func (r *memoryReturns) Create(_ context.Context, ret *models.OrderReturn) error {
+	r.returns[ret.ID] = ret
+	return nil
+}
+
+func (r *memoryReturns) GetByID(_ context.Context, id string) (*models.OrderReturn, error) {
+	ret, ok := r.returns[id]
+	if !ok {
+		return nil, repository.ErrReturnNotFound
+	}
+	copied := *ret
+	return &copied, nil
+}
+
+func (r *memoryReturns) UpdateStatus(_ context.Context, ret *models.OrderReturn, to, _, _ string) error {
+	ret.Status = to
+	r.returns[ret.ID] = ret
+	return nil
+}
+
+func (r *memoryReturns) Refund(_ context.Context, ret *models.OrderReturn, order *models.Order, refund repository.Refund, _ repository.ChangeMeta) error {
+	if r.orders.stale {
+		return repository.ErrStaleOrder
+	}
+	ret.Status = models.ReturnStatusRefunded
+	ret.RefundedAmount = refund.Amount
+	ret.RefundReference = refund.Reference
+	r.returns[ret.ID] = ret
+	order.RefundedAmount += refund.Amount
+	order.Status = refund.OrderStatus
+	order.Version++
+	r.orders.put(order)
+	return nil
+}
+
+// deliveredOrder is a delivered order of 2 x 100.00 and 1 x 50.00 with 10% tax.
+func deliveredOrder() *models.Order {
+	id := uuid.NewString()
+	return &models.Order{
+		ID:             id,
+		UserID:         uuid.NewString(),
+		Status:         models.OrderStatusDelivered,
+		Currency:       "USD",
+		SubtotalAmount: 25000,
+		TaxAmount:      2500,
+		TotalAmount:    27500,
+		Version:        5,
+		Items: []models.OrderItem{
+			{ID: uuid.NewString(), OrderID: id, Name: "Mouse", Quantity: 2, UnitAmount: 10000, LineAmount: 20000},
+			{ID: uuid.NewString(), OrderID: id, Name: "Pad", Quantity: 1, UnitAmount: 5000, LineAmount: 5000},
+		},
+	}
+}
+
+func newReturnService(order *models.Order, deliveredAt time.Time) (*returnService, *memoryReturns) {
+	orders := newMemoryOrders(order)
+	orders.history[order.ID] = []models.OrderStatusChange{
+		{ToStatus: models.OrderStatusPending, CreatedAt: deliveredAt.Add(-72 * time.Hour)},
+		{FromStatus: models.OrderStatusShipped, ToStatus: models.OrderStatusDelivered, CreatedAt: deliveredAt},
+	}
+	returns := &memoryReturns{orders: orders, returns: map[string]*models.OrderReturn{}}
+	logger := log.New(io.Discard, "", 0)
+	svc := NewReturnService(orders, returns, refund.NewFakeGateway(logger), nil, 30*24*time.Hour, logger).(*returnService)
+	return svc, returns
+}
+
+func TestRequestReturnPricesItemsWithTax(t *testing.T) {
+	order := deliveredOrder()
+	svc, _ := newReturnService(order, time.Now().Add(-24*time.Hour))
+
+	resp, err := svc.RequestReturn(context.Background(), &orderpb.RequestReturnRequest{
+		OrderId:    order.ID,
+		ActorId:    order.UserID,
+		ReasonCode: "damaged",
+		Items:      []*orderpb.ReturnItemInput{{OrderItemId: order.Items[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+	if got := resp.Return.RequestedAmount.Amount; got != 11000 {
+		t.Fatalf("requested amount = %d, want 11000", got)
+	}
+	if resp.Return.Status != models.ReturnStatusRequested || resp.Return.RequestedBy != order.UserID {
+		t.Fatalf("unexpected return %+v", resp.Return)
+	}
+}
+
+func TestRequestReturnRejectsInvalidRequests(t *testing.T) {
+	order := deliveredOrder()
+	item := order.Items[0].ID
+	cases := []struct {
+		name        string
+		deliveredAt time.Time
+		status      string
+		req         *orderpb.RequestReturnRequest
+		want        error
+	}{
+		{"other user", time.Now(), models.OrderStatusDelivered, &orderpb.RequestReturnRequest{ActorId: uuid.NewString(), ReasonCode: "damaged", Items: []*orderpb.ReturnItemInput{{OrderItemId: item, Quantity: 1}}}, ErrForbidden},
+		{"not delivered", time.Now(), models.OrderStatusShipped, &orderpb.RequestReturnRequest{ActorIsAdmin: true, ReasonCode: "damaged", Items: []*orderpb.ReturnItemInput{{OrderItemId: item, Quantity: 1}}}, ErrNotReturnable},
+		{"window closed", time.Now().Add(-31 * 24 * time.Hour), models.OrderStatusDelivered, &orderpb.RequestReturnRequest{ActorIsAdmin: true, ReasonCode: "damaged", Items: []*orderpb.ReturnItemInput{{OrderItemId: item, Quantity: 1}}}, ErrReturnWindowClosed},
+		{"unknown reason", time.Now(), models.OrderStatusDelivered, &orderpb.RequestReturnRequest{ActorIsAdmin: true, ReasonCode: "bored", Items: []*orderpb.ReturnItemInput{{OrderItemId: item, Quantity: 1}}}, ErrInvalidReturnReason},
+		{"foreign item", time.Now(), models.OrderStatusDelivered, &orderpb.RequestReturnRequest{ActorIsAdmin: true, ReasonCode: "damaged", Items: []*orderpb.ReturnItemInput{{OrderItemId: uuid.NewString(), Quantity: 1}}}, ErrInvalidReturnItems},
+		{"too many units", time.Now(), models.OrderStatusDelivered, &orderpb.RequestReturnRequest{ActorIsAdmin: true, ReasonCode: "damaged", Items: []*orderpb.ReturnItemInput{{OrderItemId: item, Quantity: 2}, {OrderItemId: item, Quantity: 1}}}, repository.ErrReturnQuantityExceeded},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			o := *order
+			o.Status = tc.status
+			svc, _ := newReturnService(&o, tc.deliveredAt)
+			tc.req.OrderId = o.ID
+			if _, err := svc.RequestReturn(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestRefundReturnUpdatesOrderNetTotal(t *testing.T) {
+	order := deliveredOrder()
+	svc, returns := newReturnService(order, time.Now())
+	ctx := context.Background()
+
+	resp, err := svc.RequestReturn(ctx, &orderpb.RequestReturnRequest{
+		OrderId:      order.ID,
+		ActorIsAdmin: true,
+		ReasonCode:   "defective",
+		Items:        []*orderpb.ReturnItemInput{{OrderItemId: order.Items[0].ID, Quantity: 2}, {OrderItemId: order.Items[1].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+	id := resp.Return.Id
+
+	if _, err := svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id}); !errors.Is(err, ErrReturnTransition) {
+		t.Fatalf("refund before receipt err = %v, want ErrReturnTransition", err)
+	}
+	for _, status := range []string{models.ReturnStatusApproved, models.ReturnStatusReceived} {
+		if _, err := svc.UpdateReturnStatus(ctx, &orderpb.UpdateReturnStatusRequest{Id: id, Status: status}); err != nil {
+			t.Fatalf("UpdateReturnStatus(%s): %v", status, err)
+		}
+	}
+	if _, err := svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id, Amount: &orderpb.Money{Amount: 30000, Currency: "USD"}}); !errors.Is(err, ErrInvalidRefundAmount) {
+		t.Fatalf("over-refund err = %v, want ErrInvalidRefundAmount", err)
+	}
+
+	resp, err = svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id})
+	if err != nil {
+		t.Fatalf("RefundReturn: %v", err)
+	}
+	if resp.Return.Status != models.ReturnStatusRefunded || resp.Return.RefundReference == "" {
+		t.Fatalf("unexpected return %+v", resp.Return)
+	}
+	if resp.Order.RefundedAmount.Amount != 27500 || resp.Order.NetTotalAmount.Amount != 0 {
+		t.Fatalf("refunded = %d, net = %d", resp.Order.RefundedAmount.Amount, resp.Order.NetTotalAmount.Amount)
+	}
+	if resp.Order.Status != models.OrderStatusRefunded {
+		t.Fatalf("order status = %s, want refunded", resp.Order.Status)
+	}
+	if returns.returns[id].RefundedAmount != 27500 {
+		t.Fatalf("stored refund = %d", returns.returns[id].RefundedAmount)
+	}
+}
+
+func TestReturnsCheckExpectedVersion(t *testing.T) {
+	order := deliveredOrder()
+	svc, returns := newReturnService(order, time.Now())
+	ctx := context.Background()
+
+	resp, err := svc.RequestReturn(ctx, &orderpb.RequestReturnRequest{
+		OrderId:      order.ID,
+		ActorIsAdmin: true,
+		ReasonCode:   "defective",
+		Items:        []*orderpb.ReturnItemInput{{OrderItemId: order.Items[1].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("RequestReturn: %v", err)
+	}
+	id := resp.Return.Id
+
+	if _, err := svc.UpdateReturnStatus(ctx, &orderpb.UpdateReturnStatusRequest{Id: id, Status: models.ReturnStatusApproved, ExpectedVersion: 4}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale update err = %v, want ErrVersionConflict", err)
+	}
+	if returns.returns[id].Status != models.ReturnStatusRequested {
+		t.Fatalf("return = %s after a stale update", returns.returns[id].Status)
+	}
+	for _, status := range []string{models.ReturnStatusApproved, models.ReturnStatusReceived} {
+		if _, err := svc.UpdateReturnStatus(ctx, &orderpb.UpdateReturnStatusRequest{Id: id, Status: status, ExpectedVersion: 5}); err != nil {
+			t.Fatalf("UpdateReturnStatus(%s): %v", status, err)
+		}
+	}
+
+	if _, err := svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id, ExpectedVersion: 4}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale refund err = %v, want ErrVersionConflict", err)
+	}
+	returns.orders.stale = true
+	if _, err := svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id, ExpectedVersion: 5}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("concurrent refund err = %v, want ErrVersionConflict", err)
+	}
+	returns.orders.stale = false
+	refunded, err := svc.RefundReturn(ctx, &orderpb.RefundReturnRequest{Id: id, ExpectedVersion: 5})
+	if err != nil {
+		t.Fatalf("RefundReturn: %v", err)
+	}
+	if refunded.Order.Version != 6 {
+		t.Fatalf("version = %d, want 6", refunded.Order.Version)
+	}
+}
@@ -0,0 +1,22 @@
+package service
+
+import "online-store-microservice/order-service/models"
+
+// Refunding is not a plain status change: it goes through RefundReturn, which
+// moves a received return to refunded once the refund gateway has paid out.
+var returnTransitions = map[string][]string{
+	models.ReturnStatusRequested: {models.ReturnStatusApproved, models.ReturnStatusRejected},
+	models.ReturnStatusApproved:  {models.ReturnStatusReceived, models.ReturnStatusRejected},
+	models.ReturnStatusReceived:  {models.ReturnStatusRefunded},
+	models.ReturnStatusRejected:  {},
+	models.ReturnStatusRefunded:  {},
+}
+
+func CanTransitionReturn(from, to string) bool {
+	for _, next := range returnTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"online-store-microservice/order-service/exchange"
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// salesStatuses are the statuses of orders that were paid for.
+var salesStatuses = []string{
+	models.OrderStatusPaid,
+	models.OrderStatusFulfilling,
+	models.OrderStatusShipped,
+	models.OrderStatusDelivered,
+	models.OrderStatusRefunded,
+}
+
+// GetSalesReport sums paid orders in the store currency. Orders in other
+// currencies are converted at the rate stored on them when they were priced,
+// so the report for a past period does not change when rates do.
+func (s *orderSearchService) GetSalesReport(ctx context.Context, req *orderpb.SalesReportRequest) (*orderpb.SalesReportResponse, error) {
+	var period repository.OrderFilter
+	if err := rangeFilter(&period, req.CreatedFrom, req.CreatedTo, "", "", s.currency); err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.Sales(ctx, repository.SalesFilter{
+		BaseCurrency: s.currency,
+		Statuses:     salesStatuses,
+		CreatedFrom:  period.CreatedFrom,
+		CreatedTo:    period.CreatedTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &orderpb.SalesReportResponse{
+		BaseCurrency:   s.currency,
+		TotalAmount:    amount(0, s.currency),
+		RefundedAmount: amount(0, s.currency),
+		Currencies:     []*orderpb.CurrencySales{},
+	}
+	var sales *orderpb.CurrencySales
+	for _, row := range rows {
+		value, err := exchange.ParseRate(row.ExchangeRate)
+		if err != nil {
+			return nil, fmt.Errorf("stored %s rate: %w", row.Currency, err)
+		}
+		rate := exchange.Rate{Base: s.currency, Currency: row.Currency, Value: value}
+		refunded, err := rate.ToBase(money.New(row.RefundedAmount, row.Currency))
+		if err != nil {
+			return nil, err
+		}
+
+		// Rows come sorted by currency, one per rate.
+		if sales == nil || sales.Currency != row.Currency {
+			sales = &orderpb.CurrencySales{
+				Currency:           row.Currency,
+				TotalAmount:        amount(0, row.Currency),
+				RefundedAmount:     amount(0, row.Currency),
+				BaseTotalAmount:    amount(0, s.currency),
+				BaseRefundedAmount: amount(0, s.currency),
+			}
+			resp.Currencies = append(resp.Currencies, sales)
+		}
+		sales.Orders += row.Orders
+		sales.TotalAmount.Amount += row.TotalAmount
+		sales.RefundedAmount.Amount += row.RefundedAmount
+		sales.BaseTotalAmount.Amount += row.BaseTotalAmount
+		sales.BaseRefundedAmount.Amount += refunded.Amount
+
+		resp.Orders += row.Orders
+		resp.TotalAmount.Amount += row.BaseTotalAmount
+		resp.RefundedAmount.Amount += refunded.Amount
+	}
+	resp.NetAmount = amount(resp.TotalAmount.Amount-resp.RefundedAmount.Amount, s.currency)
+	return resp, nil
+}
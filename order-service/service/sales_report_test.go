@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	orderpb "online-store-microservice/proto/order"
+)
+
+func TestSalesReportConvertsAtStoredRates(t *testing.T) {
+	repo := newMemoryOrders()
+	repo.sales = []repository.SalesRow{
+		{Currency: "EUR", ExchangeRate: "1.1000000000", Orders: 2, TotalAmount: 10000, RefundedAmount: 1000, BaseTotalAmount: 11000},
+		{Currency: "EUR", ExchangeRate: "1.2000000000", Orders: 1, TotalAmount: 5000, BaseTotalAmount: 6000},
+		{Currency: "USD", ExchangeRate: "1.0000000000", Orders: 3, TotalAmount: 20000, RefundedAmount: 500, BaseTotalAmount: 20000},
+	}
+	svc := NewOrderSearchService(repo, nil, "USD")
+
+	resp, err := svc.GetSalesReport(context.Background(), &orderpb.SalesReportRequest{CreatedFrom: "2026-10-01T00:00:00Z", CreatedTo: "2026-11-01T00:00:00Z"})
+	if err != nil {
+		t.Fatalf("GetSalesReport: %v", err)
+	}
+	if repo.salesFilter.BaseCurrency != "USD" || repo.salesFilter.CreatedFrom == nil || repo.salesFilter.CreatedTo == nil || len(repo.salesFilter.Statuses) != len(salesStatuses) {
+		t.Fatalf("filter = %+v", repo.salesFilter)
+	}
+	for _, st := range repo.salesFilter.Statuses {
+		if st == models.OrderStatusPending || st == models.OrderStatusCancelled {
+			t.Fatalf("unpaid status %s counted", st)
+		}
+	}
+
+	if len(resp.Currencies) != 2 {
+		t.Fatalf("currencies = %+v", resp.Currencies)
+	}
+	eur := resp.Currencies[0]
+	if eur.Currency != "EUR" || eur.Orders != 3 || eur.TotalAmount.Amount != 15000 || eur.BaseTotalAmount.Amount != 17000 || eur.BaseRefundedAmount.Amount != 1100 {
+		t.Fatalf("EUR sales = %+v", eur)
+	}
+	if resp.Orders != 6 || !resp.TotalAmount.Equal(usd(37000)) || !resp.RefundedAmount.Equal(usd(1600)) || !resp.NetAmount.Equal(usd(35400)) {
+		t.Fatalf("totals = %d orders, %v total, %v refunded, %v net", resp.Orders, resp.TotalAmount, resp.RefundedAmount, resp.NetAmount)
+	}
+}
+
+func TestSalesReportRejectsBadPeriod(t *testing.T) {
+	svc := NewOrderSearchService(newMemoryOrders(), nil, "USD")
+	if _, err := svc.GetSalesReport(context.Background(), &orderpb.SalesReportRequest{CreatedFrom: "last week"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Fatalf("err = %v, want ErrInvalidFilter", err)
+	}
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/shipping"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var (
+	ErrInvalidShipmentID     = errors.New("invalid shipment id")
+	ErrInvalidShipmentItems  = errors.New("shipment must list order items with a quantity greater than 0")
+	ErrInvalidShipmentStatus = errors.New("invalid shipment status")
+	ErrNoCarrier             = errors.New("carrier is required for orders without a shipping method")
+	ErrOrderNotShippable     = errors.New("only paid orders can be shipped")
+	ErrNothingToShip         = errors.New("every item of the order has already been shipped")
+	ErrShipmentTransition    = errors.New("illegal shipment status transition")
+	ErrCarrier               = errors.New("carrier failed")
+)
+
+type ShipmentService interface {
+	CreateShipment(ctx context.Context, req *orderpb.CreateShipmentRequest) (*orderpb.ShipmentResponse, error)
+	ListOrderShipments(ctx context.Context, req *orderpb.ListOrderShipmentsRequest) (*orderpb.ListOrderShipmentsResponse, error)
+	UpdateShipmentStatus(ctx context.Context, req *orderpb.UpdateShipmentStatusRequest) (*orderpb.ShipmentResponse, error)
+}
+
+type shipmentService struct {
+	orders    repository.OrderRepository
+	shipments repository.ShipmentRepository
+	carriers  shipping.Carriers
+	logger    *log.Logger
+	now       func() time.Time
+}
+
+func NewShipmentService(orders repository.OrderRepository, shipments repository.ShipmentRepository, carriers shipping.Carriers, logger *log.Logger) ShipmentService {
+	return &shipmentService{orders: orders, shipments: shipments, carriers: carriers, logger: logger, now: time.Now}
+}
+
+// CreateShipment books a label with the carrier and records the shipment.
+// The first shipment moves a paid order to fulfilling; the one that ships the
+// last outstanding unit moves it to shipped.
+func (s *shipmentService) CreateShipment(ctx context.Context, req *orderpb.CreateShipmentRequest) (*orderpb.ShipmentResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+	quantities, err := shipmentQuantities(req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	order, err := s.orders.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	switch order.Status {
+	case models.OrderStatusPaid, models.OrderStatusFulfilling, models.OrderStatusShipped:
+	default:
+		return nil, fmt.Errorf("%w: order is %s", ErrOrderNotShippable, order.Status)
+	}
+	name := strings.TrimSpace(req.Carrier)
+	if name == "" {
+		name = order.ShippingCarrier
+	}
+	if name == "" {
+		return nil, ErrNoCarrier
+	}
+	carrier, err := s.carriers.Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.shipments.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	left := unshipped(order, existing)
+	for id := range quantities {
+		if _, ok := left[id]; !ok {
+			return nil, fmt.Errorf("%w: %s is not part of the order", ErrInvalidShipmentItems, id)
+		}
+	}
+	if len(quantities) == 0 {
+		for _, item := range order.Items {
+			if left[item.ID] > 0 {
+				quantities[item.ID] = left[item.ID]
+			}
+		}
+		if len(quantities) == 0 {
+			return nil, ErrNothingToShip
+		}
+	}
+
+	actor := strings.TrimSpace(req.ActorId)
+	if actor == "" {
+		actor = "admin"
+	}
+	now := s.now().UTC()
+	shipment := &models.Shipment{
+		ID:        uuid.NewString(),
+		OrderID:   order.ID,
+		Carrier:   name,
+		Status:    models.ShipmentStatusPending,
+		CreatedBy: actor,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	complete := true
+	for _, item := range order.Items {
+		qty := quantities[item.ID]
+		if qty > left[item.ID] {
+			return nil, fmt.Errorf("%w: %d of %s left to ship", repository.ErrShipmentQuantityExceeded, left[item.ID], item.Name)
+		}
+		if qty < left[item.ID] {
+			complete = false
+		}
+		if qty == 0 {
+			continue
+		}
+		shipment.Items = append(shipment.Items, models.ShipmentItem{
+			ID:          uuid.NewString(),
+			ShipmentID:  shipment.ID,
+			OrderItemID: item.ID,
+			Name:        item.Name,
+			Quantity:    qty,
+		})
+		shipment.WeightGrams += item.WeightGrams * int64(qty)
+	}
+
+	var path []string
+	if order.Status == models.OrderStatusPaid {
+		path = append(path, models.OrderStatusFulfilling)
+	}
+	if complete && order.Status != models.OrderStatusShipped {
+		path = append(path, models.OrderStatusShipped)
+	}
+
+	label, err := carrier.CreateLabel(ctx, shipping.Parcel{Key: shipment.ID, OrderID: order.ID, Destination: order.ShippingAddress, WeightGrams: shipment.WeightGrams})
+	if err != nil {
+		s.logger.Printf("create label failed order_id=%s carrier=%s err=%v", order.ID, name, err)
+		return nil, fmt.Errorf("%w: %v", ErrCarrier, err)
+	}
+	shipment.TrackingNumber = label.TrackingNumber
+	shipment.TrackingURL = label.TrackingURL
+
+	if err := s.shipments.Create(ctx, shipment, order, path, changeMeta(ctx, actor, "shipment_created")); err != nil {
+		return nil, versionError(err, req.ExpectedVersion)
+	}
+	return &orderpb.ShipmentResponse{Shipment: toPBShipment(shipment), Order: toPBOrder(order)}, nil
+}
+
+func (s *shipmentService) ListOrderShipments(ctx context.Context, req *orderpb.ListOrderShipmentsRequest) (*orderpb.ListOrderShipmentsResponse, error) {
+	if _, err := uuid.Parse(req.OrderId); err != nil {
+		return nil, ErrInvalidOrderID
+	}
+
+	order, err := s.orders.GetByID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := orderActor(order, req.ActorId, req.ActorIsAdmin); err != nil {
+		return nil, err
+	}
+
+	shipments, err := s.shipments.ListByOrder(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]*orderpb.ShipmentData, 0, len(shipments))
+	for i := range shipments {
+		data = append(data, toPBShipment(&shipments[i]))
+	}
+	return &orderpb.ListOrderShipmentsResponse{Shipments: data, Order: toPBOrder(order)}, nil
+}
+
+// UpdateShipmentStatus records a status reported by the carrier, or asks the
+// carrier for it when none is given. Once every unit of a shipped order has
+// been delivered the order moves to delivered.
+func (s *shipmentService) UpdateShipmentStatus(ctx context.Context, req *orderpb.UpdateShipmentStatusRequest) (*orderpb.ShipmentResponse, error) {
+	if _, err := uuid.Parse(req.Id); err != nil {
+		return nil, ErrInvalidShipmentID
+	}
+	to := strings.TrimSpace(strings.ToLower(req.Status))
+	switch to {
+	case "", models.ShipmentStatusInTransit, models.ShipmentStatusDelivered, models.ShipmentStatusFailed:
+	default:
+		return nil, ErrInvalidShipmentStatus
+	}
+	if req.ExpectedVersion < 0 {
+		return nil, ErrInvalidVersion
+	}
+
+	shipment, err := s.shipments.GetByID(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if to == "" {
+		carrier, err := s.carriers.Get(shipment.Carrier)
+		if err != nil {
+			return nil, err
+		}
+		if to, err = carrier.Track(ctx, shipment.TrackingNumber); err != nil {
+			s.logger.Printf("track failed shipment_id=%s carrier=%s err=%v", shipment.ID, shipment.Carrier, err)
+			return nil, fmt.Errorf("%w: %v", ErrCarrier, err)
+		}
+	}
+
+	order, err := s.orders.GetByID(ctx, shipment.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(order, req.ExpectedVersion); err != nil {
+		return nil, err
+	}
+	if to == shipment.Status && req.Status == "" {
+		return &orderpb.ShipmentResponse{Shipment: toPBShipment(shipment), Order: toPBOrder(order)}, nil
+	}
+	if !CanTransitionShipment(shipment.Status, to) {
+		return nil, fmt.Errorf("%w: %s -> %s", ErrShipmentTransition, shipment.Status, to)
+	}
+
+	var path []string
+	if to == models.ShipmentStatusDelivered && order.Status == models.OrderStatusShipped {
+		shipments, err := s.shipments.ListByOrder(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+		if allDelivered(order, shipments, shipment.ID) {
+			path = append(path, models.OrderStatusDelivered)
+		}
+	}
+
+	actor := strings.TrimSpace(req.ActorId)
+	if actor == "" {
+		actor = "carrier"
+	}
+	if err := s.shipments.UpdateStatus(ctx, shipment, to, order, path, changeMeta(ctx, actor, "shipment_"+to)); err != nil {
+		return nil, versionError(err, req.ExpectedVersion)
+	}
+	return &orderpb.ShipmentResponse{Shipment: toPBShipment(shipment), Order: toPBOrder(order)}, nil
+}
+
+// shipmentQuantities sums the quantity per order item. An empty list means
+// everything that has not been shipped yet.
+func shipmentQuantities(items []*orderpb.ShipmentItemInput) (map[string]int32, error) {
+	if len(items) > maxOrderItems {
+		return nil, ErrInvalidShipmentItems
+	}
+	quantities := make(map[string]int32, len(items))
+	for _, in := range items {
+		if in == nil || in.Quantity <= 0 {
+			return nil, ErrInvalidShipmentItems
+		}
+		if _, err := uuid.Parse(in.OrderItemId); err != nil {
+			return nil, ErrInvalidShipmentItems
+		}
+		quantities[in.OrderItemId] += in.Quantity
+	}
+	return quantities, nil
+}
+
+// unshipped is the quantity of each order item not covered by a shipment
+// that is still on its way or delivered.
+func unshipped(order *models.Order, shipments []models.Shipment) map[string]int32 {
+	left := make(map[string]int32, len(order.Items))
+	for _, item := range order.Items {
+		left[item.ID] = item.Quantity
+	}
+	for _, shipment := range shipments {
+		if shipment.Status == models.ShipmentStatusFailed {
+			continue
+		}
+		for _, item := range shipment.Items {
+			left[item.OrderItemID] -= item.Quantity
+		}
+	}
+	return left
+}
+
+// allDelivered reports whether every unit of the order is in a delivered
+// shipment, counting the shipment with id deliveredID as delivered.
+func allDelivered(order *models.Order, shipments []models.Shipment, deliveredID string) bool {
+	var delivered []models.Shipment
+	for _, shipment := range shipments {
+		if shipment.ID == deliveredID || shipment.Status == models.ShipmentStatusDelivered {
+			delivered = append(delivered, shipment)
+		}
+	}
+	for _, qty := range unshipped(order, delivered) {
+		if qty > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func toPBShipment(shipment *models.Shipment) *orderpb.ShipmentData {
+	items := make([]*orderpb.ShipmentItemData, 0, len(shipment.Items))
+	for _, item := range shipment.Items {
+		items = append(items, &orderpb.ShipmentItemData{
+			Id:          item.ID,
+			OrderItemId: item.OrderItemID,
+			Name:        item.Name,
+			Quantity:    item.Quantity,
+		})
+	}
+	data := &orderpb.ShipmentData{
+		Id:             shipment.ID,
+		OrderId:        shipment.OrderID,
+		Carrier:        shipment.Carrier,
+		TrackingNumber: shipment.TrackingNumber,
+		TrackingUrl:    shipment.TrackingURL,
+		Status:         shipment.Status,
+		WeightGrams:    shipment.WeightGrams,
+		Items:          items,
+		CreatedBy:      shipment.CreatedBy,
+		CreatedAt:      shipment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:      shipment.UpdatedAt.Format(time.RFC3339),
+	}
+	if shipment.DeliveredAt != nil {
+		data.DeliveredAt = shipment.DeliveredAt.Format(time.RFC3339)
+	}
+	return data
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/shipping"
+	orderpb "online-store-microservice/proto/order"
+)
+
+// memoryShipments records the status path of every write so tests can check
+// how the order moved.
+type memoryShipments struct {
+	repository.ShipmentRepository
+	orders    *memoryOrders
+	shipments []*models.Shipment
+	paths     [][]string
+}
+
+func (r *memoryShipments) Create(_ context.Context, shipment *models.Shipment, order *models.Order, path []string, _ repository.ChangeMeta) error {
+	if r.orders.stale {
+		return repository.ErrStaleOrder
+	}
+	r.shipments = append(r.shipments, shipment)
+	r.advance(order, path)
+	return nil
+}
+
+func (r *memoryShipments) GetByID(_ context.Context, id string) (*models.Shipment, error) {
+	for _, shipment := range r.shipments {
+		if shipment.ID == id {
+			copied := *shipment
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrShipmentNotFound
+}
+
+func (r *memoryShipments) ListByOrder(context.Context, string) ([]models.Shipment, error) {
+	shipments := make([]models.Shipment, 0, len(r.shipments))
+	for _, shipment := range r.shipments {
+		shipments = append(shipments, *shipment)
+	}
+	return shipments, nil
+}
+
+func (r *memoryShipments) UpdateStatus(_ context.Context, shipment *models.Shipment, to string, order *models.Order, path []string, _ repository.ChangeMeta) error {
+	if r.orders.stale {
+		return repository.ErrStaleOrder
+	}
+	shipment.Status = to
+	for i := range r.shipments {
+		if r.shipments[i].ID == shipment.ID {
+			r.shipments[i] = shipment
+		}
+	}
+	r.advance(order, path)
+	return nil
+}
+
+func (r *memoryShipments) advance(order *models.Order, path []string) {
+	r.paths = append(r.paths, path)
+	if len(path) > 0 {
+		order.Status = path[len(path)-1]
+	}
+	order.Version++
+	r.orders.put(order)
+}
+
+// paidOrder is a paid order of 2 mice and 1 keyboard shipped with the fake carrier.
+func paidOrder() *models.Order {
+	id := uuid.NewString()
+	return &models.Order{
+		ID:              id,
+		UserID:          uuid.NewString(),
+		Status:          models.OrderStatusPaid,
+		Currency:        "USD",
+		ShippingCarrier: "fake",
+		Version:         3,
+		Items: []models.OrderItem{
+			{ID: uuid.NewString(), OrderID: id, Name: "Mouse", Quantity: 2, WeightGrams: 90},
+			{ID: uuid.NewString(), OrderID: id, Name: "Keyboard", Quantity: 1, WeightGrams: 950},
+		},
+	}
+}
+
+func newShipmentService(order *models.Order) (*shipmentService, *memoryShipments) {
+	orders := newMemoryOrders(order)
+	shipments := &memoryShipments{orders: orders}
+	logger := log.New(io.Discard, "", 0)
+	carriers := shipping.Carriers{"fake": shipping.NewFakeCarrier(logger)}
+	return NewShipmentService(orders, shipments, carriers, logger).(*shipmentService), shipments
+}
+
+func TestPartialShipmentsMoveOrderToShippedAndDelivered(t *testing.T) {
+	order := paidOrder()
+	svc, shipments := newShipmentService(order)
+	ctx := context.Background()
+
+	first, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{
+		OrderId: order.ID,
+		Items:   []*orderpb.ShipmentItemInput{{OrderItemId: order.Items[0].ID, Quantity: 1}},
+	})
+	if err != nil {
+		t.Fatalf("first shipment: %v", err)
+	}
+	if first.Order.Status != models.OrderStatusFulfilling || first.Shipment.TrackingNumber == "" || first.Shipment.WeightGrams != 90 {
+		t.Fatalf("first shipment = %+v, order %s", first.Shipment, first.Order.Status)
+	}
+
+	second, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID})
+	if err != nil {
+		t.Fatalf("rest of the order: %v", err)
+	}
+	if second.Order.Status != models.OrderStatusShipped || len(second.Shipment.Items) != 2 || second.Shipment.Items[0].Quantity != 1 {
+		t.Fatalf("second shipment = %+v, order %s", second.Shipment, second.Order.Status)
+	}
+	if _, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID}); !errors.Is(err, ErrNothingToShip) {
+		t.Fatalf("third shipment err = %v, want ErrNothingToShip", err)
+	}
+
+	resp, err := svc.UpdateShipmentStatus(ctx, &orderpb.UpdateShipmentStatusRequest{Id: first.Shipment.Id, Status: models.ShipmentStatusDelivered})
+	if err != nil {
+		t.Fatalf("deliver first: %v", err)
+	}
+	if resp.Order.Status != models.OrderStatusShipped {
+		t.Fatalf("order = %s after one of two deliveries, want shipped", resp.Order.Status)
+	}
+	// The fake carrier reports in_transit, then delivered.
+	for _, want := range []string{models.ShipmentStatusInTransit, models.ShipmentStatusDelivered} {
+		if resp, err = svc.UpdateShipmentStatus(ctx, &orderpb.UpdateShipmentStatusRequest{Id: second.Shipment.Id}); err != nil {
+			t.Fatalf("track second: %v", err)
+		}
+		if resp.Shipment.Status != want {
+			t.Fatalf("tracked status = %s, want %s", resp.Shipment.Status, want)
+		}
+	}
+	if resp.Order.Status != models.OrderStatusDelivered {
+		t.Fatalf("order = %s after all deliveries, want delivered", resp.Order.Status)
+	}
+
+	want := [][]string{
+		{models.OrderStatusFulfilling},
+		{models.OrderStatusShipped},
+		nil,
+		nil,
+		{models.OrderStatusDelivered},
+	}
+	if !reflect.DeepEqual(shipments.paths, want) {
+		t.Fatalf("order status paths = %v, want %v", shipments.paths, want)
+	}
+}
+
+func TestCreateShipmentRejectsInvalidRequests(t *testing.T) {
+	cases := []struct {
+		name   string
+		status string
+		items  func(*models.Order) []*orderpb.ShipmentItemInput
+		want   error
+	}{
+		{"unpaid order", models.OrderStatusPending, nil, ErrOrderNotShippable},
+		{"too many units", models.OrderStatusPaid, func(o *models.Order) []*orderpb.ShipmentItemInput {
+			return []*orderpb.ShipmentItemInput{{OrderItemId: o.Items[1].ID, Quantity: 2}}
+		}, repository.ErrShipmentQuantityExceeded},
+		{"foreign item", models.OrderStatusPaid, func(*models.Order) []*orderpb.ShipmentItemInput {
+			return []*orderpb.ShipmentItemInput{{OrderItemId: uuid.NewString(), Quantity: 1}}
+		}, ErrInvalidShipmentItems},
+		{"zero quantity", models.OrderStatusPaid, func(o *models.Order) []*orderpb.ShipmentItemInput {
+			return []*orderpb.ShipmentItemInput{{OrderItemId: o.Items[0].ID}}
+		}, ErrInvalidShipmentItems},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			order := paidOrder()
+			order.Status = tc.status
+			svc, shipments := newShipmentService(order)
+			req := &orderpb.CreateShipmentRequest{OrderId: order.ID}
+			if tc.items != nil {
+				req.Items = tc.items(order)
+			}
+			if _, err := svc.CreateShipment(context.Background(), req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+			if len(shipments.shipments) != 0 {
+				t.Fatalf("stored %d shipments", len(shipments.shipments))
+			}
+		})
+	}
+}
+
+func TestFailedShipmentCanBeShippedAgain(t *testing.T) {
+	order := paidOrder()
+	svc, _ := newShipmentService(order)
+	ctx := context.Background()
+
+	lost, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID})
+	if err != nil {
+		t.Fatalf("CreateShipment: %v", err)
+	}
+	if _, err := svc.UpdateShipmentStatus(ctx, &orderpb.UpdateShipmentStatusRequest{Id: lost.Shipment.Id, Status: models.ShipmentStatusFailed}); err != nil {
+		t.Fatalf("fail shipment: %v", err)
+	}
+	if _, err := svc.UpdateShipmentStatus(ctx, &orderpb.UpdateShipmentStatusRequest{Id: lost.Shipment.Id, Status: models.ShipmentStatusDelivered}); !errors.Is(err, ErrShipmentTransition) {
+		t.Fatalf("deliver failed shipment err = %v, want ErrShipmentTransition", err)
+	}
+
+	again, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID})
+	if err != nil {
+		t.Fatalf("reship: %v", err)
+	}
+	if len(again.Shipment.Items) != 2 || again.Order.Status != models.OrderStatusShipped {
+		t.Fatalf("reshipment = %+v, order %s", again.Shipment, again.Order.Status)
+	}
+}
+
+func TestShipmentsCheckExpectedVersion(t *testing.T) {
+	order := paidOrder()
+	svc, shipments := newShipmentService(order)
+	ctx := context.Background()
+
+	if _, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID, ExpectedVersion: 2}); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale create err = %v, want ErrVersionConflict", err)
+	}
+	if len(shipments.shipments) != 0 {
+		t.Fatalf("stored %d shipments", len(shipments.shipments))
+	}
+	created, err := svc.CreateShipment(ctx, &orderpb.CreateShipmentRequest{OrderId: order.ID, ExpectedVersion: 3})
+	if err != nil {
+		t.Fatalf("CreateShipment: %v", err)
+	}
+	if created.Order.Version != 4 {
+		t.Fatalf("version = %d, want 4", created.Order.Version)
+	}
+
+	update := &orderpb.UpdateShipmentStatusRequest{Id: created.Shipment.Id, Status: models.ShipmentStatusDelivered, ExpectedVersion: 3}
+	if _, err := svc.UpdateShipmentStatus(ctx, update); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("stale update err = %v, want ErrVersionConflict", err)
+	}
+	shipments.orders.stale = true
+	update.ExpectedVersion = 4
+	if _, err := svc.UpdateShipmentStatus(ctx, update); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("concurrent update err = %v, want ErrVersionConflict", err)
+	}
+}
@@ -0,0 +1,21 @@
+package service
+
+import "online-store-microservice/order-service/models"
+
+// A failed shipment is lost or refused; its items count as not shipped, so
+// they can go out again in a new shipment.
+var shipmentTransitions = map[string][]string{
+	models.ShipmentStatusPending:   {models.ShipmentStatusInTransit, models.ShipmentStatusDelivered, models.ShipmentStatusFailed},
+	models.ShipmentStatusInTransit: {models.ShipmentStatusDelivered, models.ShipmentStatusFailed},
+	models.ShipmentStatusDelivered: {},
+	models.ShipmentStatusFailed:    {},
+}
+
+func CanTransitionShipment(from, to string) bool {
+	for _, next := range shipmentTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/tax"
+	orderpb "online-store-microservice/proto/order"
+)
+
+var (
+	ErrInvalidAddress      = errors.New("invalid shipping_address")
+	ErrInvalidTaxExemption = errors.New("invalid tax exemption")
+)
+
+var (
+	countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+	regionCodePattern  = regexp.MustCompile(`^[A-Z0-9]{1,3}$`)
+)
+
+type TaxService interface {
+	GetTaxExemption(ctx context.Context, req *orderpb.GetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error)
+	SetTaxExemption(ctx context.Context, req *orderpb.SetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error)
+	RemoveTaxExemption(ctx context.Context, req *orderpb.RemoveTaxExemptionRequest) (*orderpb.RemoveTaxExemptionResponse, error)
+}
+
+type taxService struct {
+	exemptions repository.TaxExemptionRepository
+}
+
+func NewTaxService(exemptions repository.TaxExemptionRepository) TaxService {
+	return &taxService{exemptions: exemptions}
+}
+
+func (s *taxService) GetTaxExemption(ctx context.Context, req *orderpb.GetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, ErrInvalidUserParam
+	}
+	exemption, err := s.exemptions.Get(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &orderpb.TaxExemptionResponse{Exemption: toPBTaxExemption(exemption)}, nil
+}
+
+func (s *taxService) SetTaxExemption(ctx context.Context, req *orderpb.SetTaxExemptionRequest) (*orderpb.TaxExemptionResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, ErrInvalidUserParam
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || len([]rune(reason)) > 255 {
+		return nil, fmt.Errorf("%w: reason is required and must be at most 255 characters", ErrInvalidTaxExemption)
+	}
+	certificate := strings.TrimSpace(req.Certificate)
+	if len([]rune(certificate)) > 100 {
+		return nil, fmt.Errorf("%w: certificate must be at most 100 characters", ErrInvalidTaxExemption)
+	}
+	actor := req.ActorId
+	if actor == "" {
+		actor = "admin"
+	}
+
+	now := time.Now().UTC()
+	exemption := &models.TaxExemption{UserID: req.UserId, Reason: reason, Certificate: certificate, CreatedBy: actor, CreatedAt: now, UpdatedAt: now}
+	if err := s.exemptions.Upsert(ctx, exemption); err != nil {
+		return nil, err
+	}
+	stored, err := s.exemptions.Get(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return &orderpb.TaxExemptionResponse{Exemption: toPBTaxExemption(stored)}, nil
+}
+
+func (s *taxService) RemoveTaxExemption(ctx context.Context, req *orderpb.RemoveTaxExemptionRequest) (*orderpb.RemoveTaxExemptionResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, ErrInvalidUserParam
+	}
+	if err := s.exemptions.Delete(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+	return &orderpb.RemoveTaxExemptionResponse{}, nil
+}
+
+// taxExempt reports whether userID is flagged as tax exempt. Orders placed
+// before the flag is set keep the tax they were charged.
+func (s *orderService) taxExempt(ctx context.Context, userID string) (bool, error) {
+	if s.exemptions == nil {
+		return false, nil
+	}
+	_, err := s.exemptions.Get(ctx, userID)
+	if errors.Is(err, repository.ErrTaxExemptionNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// normalizeAddress trims the address and upper-cases the country and region
+// codes. A nil address stays nil and is taxed at the default country.
+func normalizeAddress(a *orderpb.Address) (*orderpb.Address, error) {
+	if a == nil {
+		return nil, nil
+	}
+	out := &orderpb.Address{
+		Name:       strings.TrimSpace(a.Name),
+		Line1:      strings.TrimSpace(a.Line1),
+		Line2:      strings.TrimSpace(a.Line2),
+		City:       strings.TrimSpace(a.City),
+		Region:     strings.ToUpper(strings.TrimSpace(a.Region)),
+		PostalCode: strings.TrimSpace(a.PostalCode),
+		Country:    strings.ToUpper(strings.TrimSpace(a.Country)),
+	}
+	if !countryCodePattern.MatchString(out.Country) {
+		return nil, fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidAddress)
+	}
+	if out.Region != "" && !regionCodePattern.MatchString(out.Region) {
+		return nil, fmt.Errorf("%w: region must be an ISO 3166-2 subdivision code such as CA", ErrInvalidAddress)
+	}
+	for _, field := range []string{out.Name, out.Line1, out.Line2, out.City} {
+		if len([]rune(field)) > 255 {
+			return nil, fmt.Errorf("%w: fields must be at most 255 characters", ErrInvalidAddress)
+		}
+	}
+	if len([]rune(out.PostalCode)) > 20 {
+		return nil, fmt.Errorf("%w: postal_code must be at most 20 characters", ErrInvalidAddress)
+	}
+	return out, nil
+}
+
+func addressFromPB(a *orderpb.Address) *models.Address {
+	if a == nil {
+		return nil
+	}
+	return &models.Address{Name: a.Name, Line1: a.Line1, Line2: a.Line2, City: a.City, Region: a.Region, PostalCode: a.PostalCode, Country: a.Country}
+}
+
+func taxDestination(a *orderpb.Address) tax.Address {
+	if a == nil {
+		return tax.Address{}
+	}
+	return tax.Address{Country: a.Country, Region: a.Region}
+}
+
+func toPBAddress(a *models.Address) *orderpb.Address {
+	if a == nil {
+		return nil
+	}
+	return &orderpb.Address{Name: a.Name, Line1: a.Line1, Line2: a.Line2, City: a.City, Region: a.Region, PostalCode: a.PostalCode, Country: a.Country}
+}
+
+func buildOrderTaxLines(order *models.Order, quote *pricing.Quote) []models.OrderTaxLine {
+	if quote.Tax == nil {
+		return nil
+	}
+	lines := make([]models.OrderTaxLine, 0, len(quote.Tax.Lines))
+	for i, l := range quote.Tax.Lines {
+		line := models.OrderTaxLine{
+			ID:            uuid.NewString(),
+			OrderID:       order.ID,
+			Position:      int32(i),
+			Country:       l.Country,
+			Region:        l.Region,
+			TaxClass:      l.Class,
+			Name:          l.Name,
+			Rate:          l.Rate,
+			TaxableAmount: l.Taxable.Amount,
+			Amount:        l.Amount.Amount,
+			Exempt:        l.Exempt,
+		}
+		if l.Item >= 0 {
+			line.OrderItemID = &order.Items[l.Item].ID
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func toPBTaxLines(order *models.Order) []*orderpb.OrderTaxLineData {
+	lines := make([]*orderpb.OrderTaxLineData, 0, len(order.TaxLines))
+	for _, l := range order.TaxLines {
+		data := &orderpb.OrderTaxLineData{
+			Country:       l.Country,
+			Region:        l.Region,
+			TaxClass:      l.TaxClass,
+			Name:          l.Name,
+			Rate:          tax.FormatRate(l.Rate),
+			TaxableAmount: amount(l.TaxableAmount, order.Currency),
+			Amount:        amount(l.Amount, order.Currency),
+			Exempt:        l.Exempt,
+		}
+		if l.OrderItemID != nil {
+			data.OrderItemId = *l.OrderItemID
+		}
+		lines = append(lines, data)
+	}
+	return lines
+}
+
+func toPBTaxExemption(e *models.TaxExemption) *orderpb.TaxExemptionData {
+	return &orderpb.TaxExemptionData{
+		UserId:      e.UserID,
+		Reason:      e.Reason,
+		Certificate: e.Certificate,
+		CreatedBy:   e.CreatedBy,
+		CreatedAt:   e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   e.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type fixedTaxRules struct {
+	rules *tax.Rules
+}
+
+func (f fixedTaxRules) Rules(context.Context, time.Time) (*tax.Rules, error) {
+	return f.rules, nil
+}
+
+type memoryExemptions struct {
+	repository.TaxExemptionRepository
+	users map[string]bool
+}
+
+func (m *memoryExemptions) Get(_ context.Context, userID string) (*models.TaxExemption, error) {
+	if !m.users[userID] {
+		return nil, repository.ErrTaxExemptionNotFound
+	}
+	return &models.TaxExemption{UserID: userID, Reason: "charity"}, nil
+}
+
+const exemptUserID = "9d0c6f7e-2a3b-4c5d-8e9f-0a1b2c3d4e5f"
+
+func newTaxOrderService() OrderService {
+	rules := &tax.Rules{
+		Version:          "2026-01",
+		PricesIncludeTax: true,
+		Rounding:         tax.RoundPerLine,
+		DefaultCountry:   "DE",
+		Rates: []tax.Rate{
+			{Country: "DE", Class: tax.StandardClass, Name: "MwSt", Rate: 190000},
+			{Country: "DE", Class: "books", Name: "MwSt ermäßigt", Rate: 70000},
+		},
+	}
+	pricer := pricing.NewPricer(pricing.NewStaticProvider([]pricing.Price{
+		{ProductID: "lamp-01", Name: "Lamp", UnitPrice: money.New(11900, "EUR")},
+		{ProductID: "book-01", Name: "Book", UnitPrice: money.New(1070, "EUR"), TaxClass: "books"},
+	}), "EUR", fixedTaxRules{rules: rules}, nil, nil)
+	exemptions := &memoryExemptions{users: map[string]bool{exemptUserID: true}}
+	svc, _ := newTestOrderService(OrderServiceDeps{Exemptions: exemptions, Pricer: pricer})
+	return svc
+}
+
+func TestCreateOrderStoresTaxLines(t *testing.T) {
+	svc := newTaxOrderService()
+	items := []*orderpb.OrderItemInput{{ProductId: "lamp-01", Quantity: 1}, {ProductId: "book-01", Quantity: 1}}
+
+	resp, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{
+		UserId:          "4e427d78-58c5-4f78-bfc1-e2c196e0b506",
+		Items:           items,
+		ShippingAddress: &orderpb.Address{Name: "Erika Mustermann", City: "Berlin", Country: "de"},
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder: %v", err)
+	}
+	order := resp.Order
+	if !order.PricesIncludeTax || order.TaxRulesVersion != "2026-01" || order.ShippingAddress.Country != "DE" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+	if order.TotalAmount.Amount != 12970 || order.TaxAmount.Amount != 1970 {
+		t.Fatalf("total = %d, tax = %d, want 12970 and 1970", order.TotalAmount.Amount, order.TaxAmount.Amount)
+	}
+	if len(order.TaxLines) != 2 || order.TaxLines[1].Rate != "0.07" || order.TaxLines[1].OrderItemId != order.Items[1].Id {
+		t.Fatalf("unexpected tax lines %+v", order.TaxLines)
+	}
+
+	resp, err = svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{UserId: exemptUserID, Items: items})
+	if err != nil {
+		t.Fatalf("CreateOrder exempt: %v", err)
+	}
+	order = resp.Order
+	if !order.TaxExempt || order.TaxAmount.Amount != 0 || order.TaxExemptAmount.Amount != 1970 || order.TotalAmount.Amount != 11000 {
+		t.Fatalf("exempt order: tax = %d, waived = %v, total = %d", order.TaxAmount.Amount, order.TaxExemptAmount, order.TotalAmount.Amount)
+	}
+}
+
+func TestCreateOrderRejectsUntaxableDestination(t *testing.T) {
+	svc := newTaxOrderService()
+	items := []*orderpb.OrderItemInput{{ProductId: "lamp-01", Quantity: 1}}
+	cases := []struct {
+		address *orderpb.Address
+		want    error
+	}{
+		{&orderpb.Address{Country: "Germany"}, ErrInvalidAddress},
+		{&orderpb.Address{Country: "DE", Region: "Berlin"}, ErrInvalidAddress},
+		{&orderpb.Address{Country: "FR"}, tax.ErrNoRate},
+	}
+	for _, tc := range cases {
+		_, err := svc.CreateOrder(context.Background(), &orderpb.CreateOrderRequest{UserId: "4e427d78-58c5-4f78-bfc1-e2c196e0b506", Items: items, ShippingAddress: tc.address})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("%+v: err = %v, want %v", tc.address, err, tc.want)
+		}
+	}
+}
+
+func TestRefundableAmountWithInclusiveTax(t *testing.T) {
+	item := models.OrderItem{Quantity: 2, LineAmount: 23800}
+	order := &models.Order{Currency: "EUR", SubtotalAmount: 23800, TaxAmount: 3800, TotalAmount: 23800, PricesIncludeTax: true}
+	if got := refundableAmount(order, item, 1); got.Amount != 11900 {
+		t.Fatalf("refundable = %d, want 11900", got.Amount)
+	}
+
+	order.TaxAmount, order.TaxExempt, order.TaxExemptAmount, order.TotalAmount = 0, true, 3800, 20000
+	if got := refundableAmount(order, item, 1); got.Amount != 10000 {
+		t.Fatalf("exempt refundable = %d, want 10000", got.Amount)
+	}
+}
@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/pricing"
+	"online-store-microservice/order-service/repository"
+	"online-store-microservice/order-service/tax"
+	"online-store-microservice/pkg/money"
+)
+
+// testPrices is the catalog the service tests order from.
+var testPrices = []pricing.Price{
+	{ProductID: "mouse", Name: "Mouse", UnitPrice: money.New(10000, "USD"), SalePrice: money.New(9000, "USD")},
+	{ProductID: "pad", Name: "Pad", UnitPrice: money.New(5000, "USD")},
+	{ProductID: "laptop-14", SKU: "LP-14-SLV", Name: "Laptop", UnitPrice: money.New(100000, "USD")},
+	{ProductID: "mouse-01", SKU: "MS-01-BLK", Name: "Mouse", UnitPrice: money.New(2000, "USD")},
+}
+
+// testPricer prices in USD without tax, from testPrices unless prices are
+// given.
+func testPricer(prices ...pricing.Price) *pricing.Pricer {
+	if len(prices) == 0 {
+		prices = testPrices
+	}
+	return pricing.NewPricer(pricing.NewStaticProvider(prices), "USD", tax.NewFlatSource(0), nil, nil)
+}
+
+// recordingCompensation records the paid orders it was told about. Amended
+// orders fail with fail, when set.
+type recordingCompensation struct {
+	cancelled []string
+	deltas    []money.Money
+	fail      error
+}
+
+func (c *recordingCompensation) OnPaidOrderCancelled(_ context.Context, order *models.Order) error {
+	c.cancelled = append(c.cancelled, order.ID)
+	return nil
+}
+
+func (c *recordingCompensation) OnPaidOrderAmended(_ context.Context, _ *models.Order, delta money.Money) error {
+	if c.fail != nil {
+		return c.fail
+	}
+	c.deltas = append(c.deltas, delta)
+	return nil
+}
+
+// newTestOrderService builds an order service from deps, on a new
+// memoryOrders and testPricer unless deps has its own.
+func newTestOrderService(deps OrderServiceDeps) (OrderService, *memoryOrders) {
+	if deps.Repo == nil {
+		deps.Repo = newMemoryOrders()
+	}
+	if deps.Pricer == nil {
+		deps.Pricer = testPricer()
+	}
+	repo, _ := deps.Repo.(*memoryOrders)
+	return NewOrderService(deps), repo
+}
+
+// memoryOrders is the order repository of the service tests. Orders are
+// handed out as copies and stored back on every write, like the database
+// would, and what the service asked for is recorded for the tests to check.
+type memoryOrders struct {
+	orders  []*models.Order
+	history map[string][]models.OrderStatusChange
+	sales   []repository.SalesRow
+	// stale makes every conditional write fail as if the order changed.
+	stale bool
+
+	created     []*models.Order
+	amended     []*models.Order
+	changes     []models.OrderChange
+	cancelled   []string
+	verified    []string
+	metas       []repository.ChangeMeta
+	filters     []repository.OrderFilter
+	salesFilter repository.SalesFilter
+	batches     []int
+}
+
+var _ repository.OrderRepository = (*memoryOrders)(nil)
+
+func newMemoryOrders(orders ...*models.Order) *memoryOrders {
+	return &memoryOrders{orders: orders, history: map[string][]models.OrderStatusChange{}}
+}
+
+func (r *memoryOrders) find(match func(*models.Order) bool) (*models.Order, error) {
+	for _, order := range r.orders {
+		if match(order) {
+			copied := *order
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// put stores order, replacing the one with the same id.
+func (r *memoryOrders) put(order *models.Order) {
+	for i := range r.orders {
+		if r.orders[i].ID == order.ID {
+			r.orders[i] = order
+			return
+		}
+	}
+	r.orders = append(r.orders, order)
+}
+
+// stored returns the current version of the order with id, or nil.
+func (r *memoryOrders) stored(id string) *models.Order {
+	for _, order := range r.orders {
+		if order.ID == id {
+			return order
+		}
+	}
+	return nil
+}
+
+func (r *memoryOrders) change(order *models.Order, to string, meta repository.ChangeMeta) error {
+	if r.stale {
+		return repository.ErrStaleOrder
+	}
+	r.history[order.ID] = append(r.history[order.ID], models.OrderStatusChange{OrderID: order.ID, FromStatus: order.Status, ToStatus: to, Actor: meta.Actor, Reason: meta.Reason})
+	r.metas = append(r.metas, meta)
+	order.Status = to
+	order.Version++
+	r.put(order)
+	return nil
+}
+
+func (r *memoryOrders) Create(_ context.Context, order *models.Order, meta repository.ChangeMeta) error {
+	r.created = append(r.created, order)
+	r.metas = append(r.metas, meta)
+	r.put(order)
+	return nil
+}
+
+func (r *memoryOrders) GetByID(_ context.Context, id string) (*models.Order, error) {
+	return r.find(func(o *models.Order) bool { return o.ID == id })
+}
+
+func (r *memoryOrders) GetByNumber(_ context.Context, number string) (*models.Order, error) {
+	return r.find(func(o *models.Order) bool { return o.Number != "" && o.Number == number })
+}
+
+// List records filter and returns the first filter.Limit orders, leaving
+// the filtering itself to the repository tests.
+func (r *memoryOrders) List(_ context.Context, filter repository.OrderFilter) ([]models.Order, error) {
+	r.filters = append(r.filters, filter)
+	var orders []models.Order
+	for _, order := range r.orders {
+		if filter.Limit > 0 && len(orders) == filter.Limit {
+			break
+		}
+		orders = append(orders, *order)
+	}
+	return orders, nil
+}
+
+func (r *memoryOrders) ReassignUser(_ context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error) {
+	wanted := map[string]bool{}
+	for _, id := range orderIDs {
+		wanted[id] = true
+	}
+	var ids []string
+	for _, order := range r.orders {
+		if order.UserID != fromUserID || (len(wanted) > 0 && !wanted[order.ID]) {
+			continue
+		}
+		ids = append(ids, order.ID)
+		if !dryRun {
+			order.UserID = toUserID
+			order.Version++
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryOrders) UpdateStatus(_ context.Context, order *models.Order, to string, meta repository.ChangeMeta) error {
+	return r.change(order, to, meta)
+}
+
+func (r *memoryOrders) Cancel(_ context.Context, order *models.Order, meta repository.ChangeMeta) error {
+	if err := r.change(order, models.OrderStatusCancelled, meta); err != nil {
+		return err
+	}
+	r.cancelled = append(r.cancelled, order.ID+":"+meta.Reason)
+	return nil
+}
+
+func (r *memoryOrders) Amend(_ context.Context, order *models.Order, changes []models.OrderChange, meta repository.ChangeMeta) error {
+	if r.stale {
+		return repository.ErrStaleOrder
+	}
+	r.history[order.ID] = append(r.history[order.ID], models.OrderStatusChange{OrderID: order.ID, FromStatus: order.Status, ToStatus: order.Status, Actor: meta.Actor, Reason: meta.Reason, Changes: changes, CreatedAt: time.Now().UTC()})
+	order.Version++
+	r.amended = append(r.amended, order)
+	r.changes = changes
+	r.metas = append(r.metas, meta)
+	r.put(order)
+	return nil
+}
+
+func (r *memoryOrders) History(_ context.Context, orderID string) ([]models.OrderStatusChange, error) {
+	return r.history[orderID], nil
+}
+
+func (r *memoryOrders) ListUnverifiedUsers(_ context.Context, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	for _, order := range r.orders {
+		if len(orders) < limit && order.UserVerifiedAt == nil && order.Status != models.OrderStatusCancelled {
+			orders = append(orders, *order)
+		}
+	}
+	return orders, nil
+}
+
+func (r *memoryOrders) MarkUserVerified(_ context.Context, id string) error {
+	r.verified = append(r.verified, id)
+	if order := r.stored(id); order != nil {
+		now := time.Now().UTC()
+		order.UserVerifiedAt = &now
+	}
+	return nil
+}
+
+func (r *memoryOrders) ExpireUnpaid(_ context.Context, createdBefore time.Time, limit int, meta repository.ChangeMeta) ([]models.Order, error) {
+	var expired []models.Order
+	for _, order := range r.orders {
+		if len(expired) == limit || !order.CreatedAt.Before(createdBefore) {
+			continue
+		}
+		if order.Status == models.OrderStatusPending || order.Status == models.OrderStatusAwaitingPayment {
+			if err := r.change(order, models.OrderStatusExpired, meta); err != nil {
+				return nil, err
+			}
+			expired = append(expired, *order)
+		}
+	}
+	r.batches = append(r.batches, len(expired))
+	return expired, nil
+}
+
+func (r *memoryOrders) Export(_ context.Context, filter repository.OrderFilter, batch int, fn func([]models.Order) error) error {
+	r.filters = append(r.filters, filter)
+	orders := make([]models.Order, 0, len(r.orders))
+	for _, order := range r.orders {
+		orders = append(orders, *order)
+	}
+	for len(orders) > 0 {
+		n := min(batch, len(orders))
+		if err := fn(orders[:n]); err != nil {
+			return err
+		}
+		orders = orders[n:]
+	}
+	return nil
+}
+
+func (r *memoryOrders) Sales(_ context.Context, filter repository.SalesFilter) ([]repository.SalesRow, error) {
+	r.salesFilter = filter
+	return r.sales, nil
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userpb "online-store-microservice/proto/user"
+)
+
+const (
+	// UserCheckFailClosed rejects orders while user-service is unavailable.
+	UserCheckFailClosed = "fail_closed"
+	// UserCheckVerifyLater accepts the order unverified and leaves it to the
+	// UserVerificationWorker.
+	UserCheckVerifyLater = "verify_later"
+)
+
+const maxCachedUsers = 10000
+
+var (
+	ErrUnknownUser            = errors.New("user does not exist")
+	ErrUserInactive           = errors.New("user account is not active")
+	ErrUserServiceUnavailable = errors.New("user-service is unavailable")
+)
+
+type UserLookup interface {
+	GetUser(ctx context.Context, id string) (*userpb.UserData, error)
+}
+
+type UserChecker struct {
+	lookup UserLookup
+	mode   string
+	ttl    time.Duration
+
+	mu     sync.Mutex
+	active map[string]time.Time
+}
+
+func NewUserChecker(lookup UserLookup, mode string, ttl time.Duration) (*UserChecker, error) {
+	if mode != UserCheckFailClosed && mode != UserCheckVerifyLater {
+		return nil, fmt.Errorf("unknown user check mode %q", mode)
+	}
+	return &UserChecker{lookup: lookup, mode: mode, ttl: ttl, active: make(map[string]time.Time)}, nil
+}
+
+// Check returns true when the user is known to exist and be active. It
+// returns false without an error when user-service could not be reached and
+// the checker is configured to verify later.
+func (c *UserChecker) Check(ctx context.Context, userID string) (bool, error) {
+	err := c.Verify(ctx, userID)
+	if errors.Is(err, ErrUserServiceUnavailable) && c.mode == UserCheckVerifyLater {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *UserChecker) Verify(ctx context.Context, userID string) error {
+	now := time.Now()
+	c.mu.Lock()
+	expires, ok := c.active[userID]
+	c.mu.Unlock()
+	if ok && now.Before(expires) {
+		return nil
+	}
+
+	user, err := c.lookup.GetUser(ctx, userID)
+	if status.Code(err) == codes.NotFound {
+		return ErrUnknownUser
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUserServiceUnavailable, err)
+	}
+	if !user.IsActive {
+		return ErrUserInactive
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.active) >= maxCachedUsers {
+		for id, exp := range c.active {
+			if now.After(exp) {
+				delete(c.active, id)
+			}
+		}
+	}
+	c.active[userID] = now.Add(c.ttl)
+	return nil
+}
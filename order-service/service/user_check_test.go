@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"online-store-microservice/order-service/models"
+	userpb "online-store-microservice/proto/user"
+)
+
+type fakeUserLookup struct {
+	users map[string]*userpb.UserData
+	err   error
+	calls int
+}
+
+func (f *fakeUserLookup) GetUser(_ context.Context, id string) (*userpb.UserData, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	user, ok := f.users[id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return user, nil
+}
+
+func TestUserCheckerCachesActiveUsers(t *testing.T) {
+	lookup := &fakeUserLookup{users: map[string]*userpb.UserData{
+		"active":   {Id: "active", IsActive: true},
+		"inactive": {Id: "inactive"},
+	}}
+	checker, err := NewUserChecker(lookup, UserCheckFailClosed, time.Minute)
+	if err != nil {
+		t.Fatalf("NewUserChecker: %v", err)
+	}
+
+	for range 2 {
+		if ok, err := checker.Check(context.Background(), "active"); !ok || err != nil {
+			t.Fatalf("active user: ok=%v err=%v", ok, err)
+		}
+	}
+	if lookup.calls != 1 {
+		t.Errorf("lookups = %d, want 1 (cached)", lookup.calls)
+	}
+
+	if _, err := checker.Check(context.Background(), "inactive"); !errors.Is(err, ErrUserInactive) {
+		t.Errorf("inactive user err = %v", err)
+	}
+	if _, err := checker.Check(context.Background(), "missing"); !errors.Is(err, ErrUnknownUser) {
+		t.Errorf("missing user err = %v", err)
+	}
+	if _, err := checker.Check(context.Background(), "inactive"); lookup.calls != 4 || err == nil {
+		t.Errorf("negative results must not be cached: calls=%d err=%v", lookup.calls, err)
+	}
+}
+
+func TestUserCheckerUnavailableModes(t *testing.T) {
+	lookup := &fakeUserLookup{err: status.Error(codes.Unavailable, "connection refused")}
+
+	closed, _ := NewUserChecker(lookup, UserCheckFailClosed, time.Minute)
+	if _, err := closed.Check(context.Background(), "u1"); !errors.Is(err, ErrUserServiceUnavailable) {
+		t.Errorf("fail_closed err = %v, want ErrUserServiceUnavailable", err)
+	}
+
+	later, _ := NewUserChecker(lookup, UserCheckVerifyLater, time.Minute)
+	if ok, err := later.Check(context.Background(), "u1"); ok || err != nil {
+		t.Errorf("verify_later: ok=%v err=%v, want unverified without error", ok, err)
+	}
+
+	if _, err := NewUserChecker(lookup, "maybe", time.Minute); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+func TestUserVerificationWorkerCancelsOrdersOfUnknownUsers(t *testing.T) {
+	repo := newMemoryOrders(
+		&models.Order{ID: "o1", UserID: "active", Status: models.OrderStatusPending},
+		&models.Order{ID: "o2", UserID: "missing", Status: models.OrderStatusPending},
+		&models.Order{ID: "o3", UserID: "missing", Status: models.OrderStatusShipped},
+	)
+	lookup := &fakeUserLookup{users: map[string]*userpb.UserData{"active": {Id: "active", IsActive: true}}}
+	checker, _ := NewUserChecker(lookup, UserCheckVerifyLater, time.Minute)
+
+	worker := NewUserVerificationWorker(repo, checker, log.New(io.Discard, "", 0), time.Minute, 10)
+	if err := worker.VerifyBatch(context.Background()); err != nil {
+		t.Fatalf("VerifyBatch: %v", err)
+	}
+
+	if len(repo.verified) != 2 || repo.verified[0] != "o1" || repo.verified[1] != "o3" {
+		t.Errorf("verified = %v, want [o1 o3]", repo.verified)
+	}
+	if len(repo.cancelled) != 1 || repo.cancelled[0] != "o2:"+cancelReasonUserNotVerified {
+		t.Errorf("cancelled = %v", repo.cancelled)
+	}
+}
+
+func TestUserVerificationWorkerLogsOnlyCancelledOrders(t *testing.T) {
+	lookup := &fakeUserLookup{users: map[string]*userpb.UserData{}}
+	checker, _ := NewUserChecker(lookup, UserCheckVerifyLater, time.Minute)
+
+	var logs strings.Builder
+	repo := newMemoryOrders(&models.Order{ID: "o1", UserID: "missing", Status: models.OrderStatusPending})
+	worker := NewUserVerificationWorker(repo, checker, log.New(&logs, "", 0), time.Minute, 10)
+	if err := worker.VerifyBatch(context.Background()); err != nil {
+		t.Fatalf("VerifyBatch: %v", err)
+	}
+	if got := logs.String(); got != "cancelled order_id=o1 user_id=missing reason=user_not_verified\n" {
+		t.Errorf("log = %q", got)
+	}
+
+	logs.Reset()
+	repo = newMemoryOrders(&models.Order{ID: "o2", UserID: "missing", Status: models.OrderStatusPending})
+	repo.stale = true
+	worker = NewUserVerificationWorker(repo, checker, log.New(&logs, "", 0), time.Minute, 10)
+	if err := worker.VerifyBatch(context.Background()); err != nil {
+		t.Fatalf("VerifyBatch with a stale order: %v", err)
+	}
+	if got := logs.String(); strings.Contains(got, "cancelled") || !strings.Contains(got, "order_id=o2") {
+		t.Errorf("log = %q, want the stale order skipped", got)
+	}
+}
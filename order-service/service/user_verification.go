@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/order-service/repository"
+)
+
+// cancelReasonUserNotVerified is set by the system when an order accepted
+// while user-service was down turns out to belong to an unknown or inactive
+// user. Customers cannot choose it.
+const cancelReasonUserNotVerified = "user_not_verified"
+
+type UserVerificationWorker struct {
+	repo     repository.OrderRepository
+	users    *UserChecker
+	logger   *log.Logger
+	interval time.Duration
+	batch    int
+}
+
+func NewUserVerificationWorker(repo repository.OrderRepository, users *UserChecker, logger *log.Logger, interval time.Duration, batch int) *UserVerificationWorker {
+	return &UserVerificationWorker{repo: repo, users: users, logger: logger, interval: interval, batch: batch}
+}
+
+func (w *UserVerificationWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.VerifyBatch(ctx); err != nil {
+				w.logger.Printf("user verification failed: %v", err)
+			}
+		}
+	}
+}
+
+func (w *UserVerificationWorker) VerifyBatch(ctx context.Context) error {
+	orders, err := w.repo.ListUnverifiedUsers(ctx, w.batch)
+	if err != nil {
+		return err
+	}
+
+	for i := range orders {
+		order := &orders[i]
+		err := w.users.Verify(ctx, order.UserID)
+		switch {
+		case err == nil:
+			if err := w.repo.MarkUserVerified(ctx, order.ID); err != nil {
+				return err
+			}
+		case errors.Is(err, ErrUnknownUser), errors.Is(err, ErrUserInactive):
+			if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusAwaitingPayment {
+				w.logger.Printf("order_id=%s belongs to invalid user %s but is already %s", order.ID, order.UserID, order.Status)
+				if err := w.repo.MarkUserVerified(ctx, order.ID); err != nil {
+					return err
+				}
+				continue
+			}
+			// A stale order was paid or cancelled meanwhile; the next batch
+			// looks at it again if it is still unverified.
+			err := w.repo.Cancel(ctx, order, repository.ChangeMeta{Actor: "system", Reason: cancelReasonUserNotVerified})
+			switch {
+			case errors.Is(err, repository.ErrStaleOrder):
+				w.logger.Printf("order_id=%s changed while verifying its user, skipped", order.ID)
+			case err != nil:
+				return err
+			default:
+				w.logger.Printf("cancelled order_id=%s user_id=%s reason=%s", order.ID, order.UserID, cancelReasonUserNotVerified)
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
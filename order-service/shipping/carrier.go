@@ -0,0 +1,95 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+
+	"online-store-microservice/order-service/models"
+)
+
+var (
+	ErrUnknownCarrier  = errors.New("unknown carrier")
+	ErrUnknownTracking = errors.New("carrier does not know this tracking number")
+)
+
+type Parcel struct {
+	// Key identifies the shipment; carriers must return the original label
+	// when the same key is sent again so that retries never buy two labels.
+	Key         string
+	OrderID     string
+	Destination *models.Address
+	WeightGrams int64
+}
+
+type Label struct {
+	TrackingNumber string
+	TrackingURL    string
+}
+
+// Carrier books labels with a shipping company and reports where a parcel
+// is. Track returns one of the models.ShipmentStatus values.
+type Carrier interface {
+	CreateLabel(ctx context.Context, parcel Parcel) (Label, error)
+	Track(ctx context.Context, trackingNumber string) (string, error)
+}
+
+// Carriers maps the carrier names used by shipping methods and shipments to
+// their integrations.
+type Carriers map[string]Carrier
+
+func (c Carriers) Get(name string) (Carrier, error) {
+	carrier, ok := c[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCarrier, name)
+	}
+	return carrier, nil
+}
+
+// FakeCarrier hands out made-up tracking numbers and moves a parcel one step
+// further, from pending to delivered, every time it is tracked. It is meant
+// for local development and tests.
+type FakeCarrier struct {
+	logger *log.Logger
+
+	mu       sync.Mutex
+	labels   map[string]Label
+	statuses map[string]string
+}
+
+func NewFakeCarrier(logger *log.Logger) *FakeCarrier {
+	return &FakeCarrier{logger: logger, labels: make(map[string]Label), statuses: make(map[string]string)}
+}
+
+func (c *FakeCarrier) CreateLabel(_ context.Context, parcel Parcel) (Label, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if label, ok := c.labels[parcel.Key]; ok {
+		return label, nil
+	}
+	number := fmt.Sprintf("FAKE%010d", rand.Int63n(1e10))
+	label := Label{TrackingNumber: number, TrackingURL: "https://tracking.example.com/" + number}
+	c.labels[parcel.Key] = label
+	c.statuses[number] = models.ShipmentStatusPending
+	c.logger.Printf("fake label order_id=%s key=%s weight_grams=%d tracking=%s", parcel.OrderID, parcel.Key, parcel.WeightGrams, number)
+	return label, nil
+}
+
+func (c *FakeCarrier) Track(_ context.Context, trackingNumber string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.statuses[trackingNumber]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTracking, trackingNumber)
+	}
+	switch status {
+	case models.ShipmentStatusPending:
+		c.statuses[trackingNumber] = models.ShipmentStatusInTransit
+	case models.ShipmentStatusInTransit:
+		c.statuses[trackingNumber] = models.ShipmentStatusDelivered
+	}
+	return c.statuses[trackingNumber], nil
+}
@@ -0,0 +1,144 @@
+package shipping
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+	"online-store-microservice/pkg/money"
+)
+
+// Catalog looks up the shipping methods a customer can pick at checkout.
+type Catalog interface {
+	Method(ctx context.Context, code string) (*Method, error)
+}
+
+type methodFile struct {
+	Code     string     `json:"code"`
+	Name     string     `json:"name"`
+	Carrier  string     `json:"carrier"`
+	Currency string     `json:"currency"`
+	Zones    []zoneFile `json:"zones"`
+}
+
+type zoneFile struct {
+	Name      string       `json:"name"`
+	Countries []string     `json:"countries"`
+	Kind      string       `json:"kind"`
+	Amount    json.Number  `json:"amount"`
+	PerKg     *json.Number `json:"per_kg,omitempty"`
+	FreeAbove *json.Number `json:"free_above,omitempty"`
+}
+
+func parseAmount(n *json.Number, currency string) (money.Money, error) {
+	if n == nil || *n == "" {
+		return money.New(0, currency), nil
+	}
+	return money.Parse(n.String(), currency)
+}
+
+func (f methodFile) method(defaultCurrency string) (*Method, error) {
+	currency := strings.ToUpper(f.Currency)
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	m := &Method{Code: f.Code, Name: f.Name, Carrier: f.Carrier, Currency: currency, Zones: make([]Zone, 0, len(f.Zones))}
+	for _, z := range f.Zones {
+		zone := Zone{Name: z.Name, Countries: z.Countries, Kind: strings.ToLower(z.Kind)}
+		var err error
+		if zone.Amount, err = parseAmount(&z.Amount, currency); err != nil {
+			return nil, fmt.Errorf("%s: zone %s: amount: %w", f.Code, z.Name, err)
+		}
+		if zone.PerKg, err = parseAmount(z.PerKg, currency); err != nil {
+			return nil, fmt.Errorf("%s: zone %s: per_kg: %w", f.Code, z.Name, err)
+		}
+		if zone.FreeAbove, err = parseAmount(z.FreeAbove, currency); err != nil {
+			return nil, fmt.Errorf("%s: zone %s: free_above: %w", f.Code, z.Name, err)
+		}
+		m.Zones = append(m.Zones, zone)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type staticCatalog struct {
+	methods map[string]*Method
+}
+
+func NewStaticCatalog(methods []*Method) Catalog {
+	index := make(map[string]*Method, len(methods))
+	for _, m := range methods {
+		index[m.Code] = m
+	}
+	return &staticCatalog{methods: index}
+}
+
+// LoadFileCatalog reads a JSON list of shipping methods. Amounts are decimal
+// numbers in major units; methods without a currency use defaultCurrency.
+func LoadFileCatalog(path, defaultCurrency string) (Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read shipping methods: %w", err)
+	}
+	var files []methodFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("parse shipping methods: %w", err)
+	}
+	methods := make([]*Method, 0, len(files))
+	for _, f := range files {
+		m, err := f.method(defaultCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("parse shipping methods: %w", err)
+		}
+		methods = append(methods, m)
+	}
+	return NewStaticCatalog(methods), nil
+}
+
+func (c *staticCatalog) Method(_ context.Context, code string) (*Method, error) {
+	m, ok := c.methods[strings.ToLower(strings.TrimSpace(code))]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, code)
+	}
+	return m, nil
+}
+
+type postgresCatalog struct {
+	db *gorm.DB
+}
+
+// NewPostgresCatalog reads active methods from the shipping_methods table.
+func NewPostgresCatalog(db *gorm.DB) Catalog {
+	return &postgresCatalog{db: db}
+}
+
+func (c *postgresCatalog) Method(ctx context.Context, code string) (*Method, error) {
+	var row models.ShippingMethod
+	err := c.db.WithContext(ctx).
+		Where("code = ? AND active = ?", strings.ToLower(strings.TrimSpace(code)), true).
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMethod, code)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	f := methodFile{Code: row.Code, Name: row.Name, Carrier: row.Carrier, Currency: row.Currency}
+	if err := json.Unmarshal(row.Zones, &f.Zones); err != nil {
+		return nil, fmt.Errorf("parse shipping method %s: %w", row.Code, err)
+	}
+	m, err := f.method(row.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("parse shipping method %s: %w", row.Code, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,129 @@
+package shipping
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"online-store-microservice/pkg/money"
+)
+
+const (
+	RateFlat   = "flat"
+	RateWeight = "weight"
+)
+
+// AnyCountry is the zone for every destination no other zone lists.
+const AnyCountry = "*"
+
+var (
+	ErrUnknownMethod = errors.New("unknown shipping_method")
+	ErrNotShippable  = errors.New("shipping method does not ship to this destination")
+	ErrInvalidMethod = errors.New("invalid shipping method")
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Zone prices one group of destination countries. A flat zone charges Amount;
+// a weight zone charges Amount plus PerKg for every started kilogram. Orders
+// whose merchandise total reaches FreeAbove ship for free.
+type Zone struct {
+	Name      string
+	Countries []string
+	Kind      string
+	Amount    money.Money
+	PerKg     money.Money
+	FreeAbove money.Money
+}
+
+type Method struct {
+	Code     string
+	Name     string
+	Carrier  string
+	Currency string
+	Zones    []Zone
+}
+
+// Charge is what a method costs for one order.
+type Charge struct {
+	Method  string
+	Carrier string
+	Zone    string
+	Amount  money.Money
+}
+
+func (m *Method) validate() error {
+	m.Code = strings.ToLower(strings.TrimSpace(m.Code))
+	if m.Code == "" {
+		return fmt.Errorf("%w: code is required", ErrInvalidMethod)
+	}
+	if strings.TrimSpace(m.Carrier) == "" {
+		return fmt.Errorf("%w: %s: carrier is required", ErrInvalidMethod, m.Code)
+	}
+	if len(m.Zones) == 0 {
+		return fmt.Errorf("%w: %s: at least one zone is required", ErrInvalidMethod, m.Code)
+	}
+	for i := range m.Zones {
+		zone := &m.Zones[i]
+		switch zone.Kind {
+		case RateFlat, RateWeight:
+		default:
+			return fmt.Errorf("%w: %s: zone %s: kind must be flat or weight", ErrInvalidMethod, m.Code, zone.Name)
+		}
+		if len(zone.Countries) == 0 {
+			return fmt.Errorf("%w: %s: zone %s lists no countries", ErrInvalidMethod, m.Code, zone.Name)
+		}
+		for j, country := range zone.Countries {
+			country = strings.ToUpper(strings.TrimSpace(country))
+			if country != AnyCountry && !countryPattern.MatchString(country) {
+				return fmt.Errorf("%w: %s: zone %s: country %q must be an ISO 3166 alpha-2 code or *", ErrInvalidMethod, m.Code, zone.Name, country)
+			}
+			zone.Countries[j] = country
+		}
+		if zone.Amount.Amount < 0 || zone.PerKg.Amount < 0 || zone.FreeAbove.Amount < 0 {
+			return fmt.Errorf("%w: %s: zone %s: amounts must not be negative", ErrInvalidMethod, m.Code, zone.Name)
+		}
+	}
+	return nil
+}
+
+// zone returns the zone listing country, or the * zone when none does.
+func (m *Method) zone(country string) (*Zone, bool) {
+	var fallback *Zone
+	for i := range m.Zones {
+		for _, c := range m.Zones[i].Countries {
+			if c == country {
+				return &m.Zones[i], true
+			}
+			if c == AnyCountry && fallback == nil {
+				fallback = &m.Zones[i]
+			}
+		}
+	}
+	return fallback, fallback != nil
+}
+
+// Rate prices shipping weightGrams of goods worth merchandise to country.
+func (m *Method) Rate(country string, weightGrams int64, merchandise money.Money) (Charge, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	zone, ok := m.zone(country)
+	if !ok {
+		return Charge{}, fmt.Errorf("%w: %s does not ship to %s", ErrNotShippable, m.Code, country)
+	}
+
+	charge := Charge{Method: m.Code, Carrier: m.Carrier, Zone: zone.Name, Amount: money.New(0, m.Currency)}
+	if !zone.FreeAbove.IsZero() && merchandise.Amount >= zone.FreeAbove.Amount {
+		return charge, nil
+	}
+	charge.Amount = zone.Amount
+	if zone.Kind == RateWeight && weightGrams > 0 {
+		kilos := (weightGrams + 999) / 1000
+		perKg, err := zone.PerKg.Mul(kilos)
+		if err != nil {
+			return Charge{}, fmt.Errorf("rate %s: %w", m.Code, err)
+		}
+		charge.Amount, _ = charge.Amount.Add(perKg)
+	}
+	return charge, nil
+}
@@ -0,0 +1,85 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"online-store-microservice/pkg/money"
+)
+
+func idr(amount int64) money.Money {
+	return money.New(amount, "IDR")
+}
+
+func TestRatePicksZoneByCountry(t *testing.T) {
+	method := &Method{Code: "regular", Carrier: "fake", Currency: "IDR", Zones: []Zone{
+		{Name: "rest of world", Countries: []string{"*"}, Kind: RateFlat, Amount: idr(250000)},
+		{Name: "asean", Countries: []string{"SG", "MY"}, Kind: RateWeight, Amount: idr(50000), PerKg: idr(30000)},
+	}}
+	if err := method.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	tests := []struct {
+		country string
+		grams   int64
+		zone    string
+		want    money.Money
+	}{
+		{"sg", 1000, "asean", idr(80000)},
+		{"MY", 1001, "asean", idr(110000)},
+		{"MY", 0, "asean", idr(50000)},
+		{"JP", 5000, "rest of world", idr(250000)},
+	}
+	for _, tt := range tests {
+		charge, err := method.Rate(tt.country, tt.grams, idr(0))
+		if err != nil {
+			t.Fatalf("Rate(%s): %v", tt.country, err)
+		}
+		if charge.Zone != tt.zone || !charge.Amount.Equal(tt.want) {
+			t.Errorf("Rate(%s, %d) = %s %v, want %s %v", tt.country, tt.grams, charge.Zone, charge.Amount, tt.zone, tt.want)
+		}
+	}
+}
+
+func TestRateWithoutZone(t *testing.T) {
+	method := &Method{Code: "domestic", Carrier: "fake", Currency: "IDR", Zones: []Zone{
+		{Name: "indonesia", Countries: []string{"ID"}, Kind: RateFlat, Amount: idr(15000)},
+	}}
+	if _, err := method.Rate("SG", 0, idr(0)); !errors.Is(err, ErrNotShippable) {
+		t.Fatalf("err = %v, want ErrNotShippable", err)
+	}
+}
+
+func TestLoadFileCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "methods.json")
+	data := `[{"code": "Regular", "name": "Regular", "carrier": "fake", "zones": [
+		{"name": "indonesia", "countries": ["id"], "kind": "flat", "amount": 15000, "free_above": 500000}
+	]}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	catalog, err := LoadFileCatalog(path, "IDR")
+	if err != nil {
+		t.Fatalf("LoadFileCatalog: %v", err)
+	}
+
+	method, err := catalog.Method(context.Background(), "regular")
+	if err != nil {
+		t.Fatalf("Method: %v", err)
+	}
+	charge, _ := method.Rate("ID", 0, idr(49999999))
+	if !charge.Amount.Equal(idr(1500000)) {
+		t.Errorf("below threshold = %v, want 15000.00 IDR", charge.Amount)
+	}
+	charge, _ = method.Rate("ID", 0, idr(50000000))
+	if !charge.Amount.IsZero() {
+		t.Errorf("at threshold = %v, want free", charge.Amount)
+	}
+	if _, err := catalog.Method(context.Background(), "express"); !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("unknown method err = %v", err)
+	}
+}
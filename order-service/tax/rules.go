@@ -0,0 +1,226 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"online-store-microservice/pkg/money"
+)
+
+const (
+	RoundPerLine  = "per_line"
+	RoundPerTotal = "per_total"
+)
+
+const (
+	// StandardClass is used for products without a tax class and for classes
+	// that have no rate of their own in a jurisdiction.
+	StandardClass = "standard"
+	// AnyCountry matches every destination that has no more specific rate.
+	AnyCountry = "*"
+)
+
+// RateScale is the denominator of Rate.Rate: 110000 is 11%.
+const RateScale = 1_000_000
+
+var (
+	ErrNoRate       = errors.New("no tax rate for destination")
+	ErrNoRules      = errors.New("no tax rules in effect")
+	ErrInvalidRules = errors.New("invalid tax rules")
+)
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+type Rate struct {
+	Country string
+	Region  string
+	Class   string
+	Name    string
+	Rate    int64
+}
+
+// Rules is one version of the tax configuration. A new version is published
+// with a later EffectiveFrom instead of editing an old one, so orders keep
+// pointing at the rules they were taxed with.
+type Rules struct {
+	Version          string
+	EffectiveFrom    time.Time
+	PricesIncludeTax bool
+	Rounding         string
+	DefaultCountry   string
+	Rates            []Rate
+}
+
+type Address struct {
+	Country string
+	Region  string
+}
+
+// Item is the taxable amount of one quote line after discounts.
+type Item struct {
+	Class  string
+	Amount money.Money
+}
+
+// Line is one stored tax line. Item is the index of the quote line it
+// belongs to, or -1 when per-total rounding groups several lines.
+type Line struct {
+	Item    int
+	Country string
+	Region  string
+	Class   string
+	Name    string
+	Rate    int64
+	Taxable money.Money
+	Amount  money.Money
+	Exempt  bool
+}
+
+type Result struct {
+	Version   string
+	Inclusive bool
+	Exempt    bool
+	Lines     []Line
+	// Total is the tax charged; Waived is the tax an exempt customer did not pay.
+	Total  money.Money
+	Waived money.Money
+}
+
+func (r *Rules) validate() error {
+	if strings.TrimSpace(r.Version) == "" {
+		return fmt.Errorf("%w: version is required", ErrInvalidRules)
+	}
+	switch r.Rounding {
+	case "":
+		r.Rounding = RoundPerLine
+	case RoundPerLine, RoundPerTotal:
+	default:
+		return fmt.Errorf("%w: %s: rounding must be per_line or per_total", ErrInvalidRules, r.Version)
+	}
+	r.DefaultCountry = strings.ToUpper(strings.TrimSpace(r.DefaultCountry))
+	if r.DefaultCountry != "" && !countryPattern.MatchString(r.DefaultCountry) {
+		return fmt.Errorf("%w: %s: default_country must be an ISO 3166 alpha-2 code", ErrInvalidRules, r.Version)
+	}
+	for i := range r.Rates {
+		rate := &r.Rates[i]
+		rate.Country = strings.ToUpper(strings.TrimSpace(rate.Country))
+		rate.Region = strings.ToUpper(strings.TrimSpace(rate.Region))
+		rate.Class = strings.ToLower(strings.TrimSpace(rate.Class))
+		if rate.Class == "" {
+			rate.Class = StandardClass
+		}
+		if rate.Country != AnyCountry && !countryPattern.MatchString(rate.Country) {
+			return fmt.Errorf("%w: %s: country %q must be an ISO 3166 alpha-2 code or *", ErrInvalidRules, r.Version, rate.Country)
+		}
+		if rate.Rate < 0 || rate.Rate > RateScale {
+			return fmt.Errorf("%w: %s: rate for %s must be between 0 and 1", ErrInvalidRules, r.Version, rate.Country)
+		}
+	}
+	return nil
+}
+
+// rate finds the rate for class at to. The region rate wins over the country
+// rate, which wins over the * fallback; a class without a rate of its own
+// falls back to the standard class of the same jurisdiction.
+func (r *Rules) rate(to Address, class string) (Rate, bool) {
+	jurisdictions := []Address{{Country: to.Country}, {Country: AnyCountry}}
+	if to.Region != "" {
+		jurisdictions = append([]Address{to}, jurisdictions...)
+	}
+	for _, jurisdiction := range jurisdictions {
+		for _, c := range []string{class, StandardClass} {
+			for _, rate := range r.Rates {
+				if rate.Country == jurisdiction.Country && rate.Region == jurisdiction.Region && rate.Class == c {
+					return rate, true
+				}
+			}
+		}
+	}
+	return Rate{}, false
+}
+
+// Calculate taxes items shipped to to. An exempt customer is charged no tax;
+// the lines are still recorded, flagged as exempt, for the audit trail.
+func (r *Rules) Calculate(items []Item, to Address, exempt bool, currency string) (*Result, error) {
+	to.Country = strings.ToUpper(strings.TrimSpace(to.Country))
+	to.Region = strings.ToUpper(strings.TrimSpace(to.Region))
+	if to.Country == "" {
+		to.Country = r.DefaultCountry
+	}
+
+	zero := money.New(0, currency)
+	res := &Result{Version: r.Version, Inclusive: r.PricesIncludeTax, Exempt: exempt, Total: zero, Waived: zero}
+	for i, item := range items {
+		class := item.Class
+		if class == "" {
+			class = StandardClass
+		}
+		rate, ok := r.rate(to, class)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoRate, strings.Trim(to.Country+"-"+to.Region, "-"))
+		}
+
+		line := Line{Item: i, Country: to.Country, Region: rate.Region, Class: class, Name: rate.Name, Rate: rate.Rate, Taxable: item.Amount, Exempt: exempt}
+		if r.Rounding == RoundPerTotal {
+			if j := res.group(line); j >= 0 {
+				res.Lines[j].Taxable, _ = res.Lines[j].Taxable.Add(item.Amount)
+				continue
+			}
+			line.Item = -1
+		}
+		res.Lines = append(res.Lines, line)
+	}
+
+	for i := range res.Lines {
+		line := &res.Lines[i]
+		line.Amount = r.tax(line.Taxable, line.Rate)
+		if exempt {
+			res.Waived, _ = res.Waived.Add(line.Amount)
+		} else {
+			res.Total, _ = res.Total.Add(line.Amount)
+		}
+	}
+	return res, nil
+}
+
+func (res *Result) group(line Line) int {
+	for i, l := range res.Lines {
+		if l.Region == line.Region && l.Class == line.Class && l.Name == line.Name && l.Rate == line.Rate {
+			return i
+		}
+	}
+	return -1
+}
+
+// tax is the tax on amount: added on top of it, or contained in it when
+// prices include tax.
+func (r *Rules) tax(amount money.Money, rate int64) money.Money {
+	if r.PricesIncludeTax {
+		return amount.MulRatio(rate, RateScale+rate)
+	}
+	return amount.MulRatio(rate, RateScale)
+}
+
+// ParseRate converts a decimal rate such as "0.0725" to RateScale units.
+func ParseRate(s string) (int64, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, fmt.Errorf("%w: rate %q is not a number", ErrInvalidRules, s)
+	}
+	r.Mul(r, big.NewRat(RateScale, 1))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("%w: rate %q has more than 6 decimals", ErrInvalidRules, s)
+	}
+	return r.Num().Int64(), nil
+}
+
+// FormatRate is the inverse of ParseRate.
+func FormatRate(rate int64) string {
+	s := new(big.Rat).SetFrac64(rate, RateScale).FloatString(6)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,147 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"online-store-microservice/pkg/money"
+)
+
+func usd(amount int64) money.Money {
+	return money.New(amount, "USD")
+}
+
+func testRules(rounding string, inclusive bool) *Rules {
+	rules := &Rules{
+		Version:          "test",
+		PricesIncludeTax: inclusive,
+		Rounding:         rounding,
+		DefaultCountry:   "US",
+		Rates: []Rate{
+			{Country: "US", Class: StandardClass, Rate: 0},
+			{Country: "US", Region: "CA", Class: StandardClass, Name: "CA sales tax", Rate: 72500},
+			{Country: "US", Region: "CA", Class: "food", Name: "CA food", Rate: 0},
+			{Country: "DE", Class: StandardClass, Name: "MwSt", Rate: 190000},
+			{Country: "DE", Class: "reduced", Name: "MwSt ermäßigt", Rate: 70000},
+		},
+	}
+	if err := rules.validate(); err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+func TestCalculatePicksMostSpecificRate(t *testing.T) {
+	rules := testRules(RoundPerLine, false)
+	items := []Item{{Class: "food", Amount: usd(1000)}, {Class: "books", Amount: usd(1000)}, {Amount: usd(1000)}}
+
+	res, err := rules.Calculate(items, Address{Country: "us", Region: "ca"}, false, "USD")
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	want := []int64{0, 73, 73}
+	for i, line := range res.Lines {
+		if line.Item != i || line.Amount.Amount != want[i] {
+			t.Errorf("line %d = %+v, want tax %d", i, line, want[i])
+		}
+	}
+	if !res.Total.Equal(usd(146)) {
+		t.Errorf("total = %v, want 1.46 USD", res.Total)
+	}
+
+	res, err = rules.Calculate(items, Address{}, false, "USD")
+	if err != nil || !res.Total.IsZero() || res.Lines[0].Country != "US" {
+		t.Fatalf("default country: res = %+v, err = %v", res, err)
+	}
+
+	if _, err := rules.Calculate(items, Address{Country: "FR"}, false, "USD"); !errors.Is(err, ErrNoRate) {
+		t.Fatalf("err = %v, want ErrNoRate", err)
+	}
+}
+
+func TestCalculateRounding(t *testing.T) {
+	// Three lines of 0.10 at 7.25%: 0.00725 each.
+	items := []Item{{Amount: usd(10)}, {Amount: usd(10)}, {Amount: usd(10)}}
+	to := Address{Country: "US", Region: "CA"}
+
+	perLine, err := testRules(RoundPerLine, false).Calculate(items, to, false, "USD")
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if len(perLine.Lines) != 3 || !perLine.Total.Equal(usd(3)) {
+		t.Errorf("per line: %d lines, total %v, want 3 lines and 0.03", len(perLine.Lines), perLine.Total)
+	}
+
+	perTotal, err := testRules(RoundPerTotal, false).Calculate(items, to, false, "USD")
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if len(perTotal.Lines) != 1 || perTotal.Lines[0].Item != -1 || !perTotal.Total.Equal(usd(2)) {
+		t.Errorf("per total: %+v, want one grouped line of 0.02", perTotal.Lines)
+	}
+}
+
+func TestCalculateInclusiveAndExempt(t *testing.T) {
+	rules := testRules(RoundPerLine, true)
+	items := []Item{{Amount: money.New(11900, "EUR")}, {Class: "reduced", Amount: money.New(10700, "EUR")}}
+
+	res, err := rules.Calculate(items, Address{Country: "DE"}, false, "EUR")
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if !res.Inclusive || res.Lines[0].Amount.Amount != 1900 || res.Lines[1].Amount.Amount != 700 {
+		t.Fatalf("inclusive lines = %+v", res.Lines)
+	}
+
+	res, err = rules.Calculate(items, Address{Country: "DE"}, true, "EUR")
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+	if !res.Total.IsZero() || res.Waived.Amount != 2600 || !res.Lines[0].Exempt {
+		t.Fatalf("exempt result = %+v", res)
+	}
+}
+
+func TestFileSourcePicksVersionInEffect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tax.json")
+	data := `[
+  {"version": "2026-07", "effective_from": "2026-07-01T00:00:00Z", "default_country": "ID", "rates": [{"country": "ID", "name": "PPN", "rate": 0.12}]},
+  {"version": "2025-01", "effective_from": "2025-01-01T00:00:00Z", "default_country": "ID", "rates": [{"country": "ID", "name": "PPN", "rate": "0.11"}]}
+]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := LoadFileSource(path)
+	if err != nil {
+		t.Fatalf("LoadFileSource: %v", err)
+	}
+
+	rules, err := src.Rules(context.Background(), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || rules.Version != "2025-01" || rules.Rates[0].Rate != 110000 {
+		t.Fatalf("rules = %+v, err = %v", rules, err)
+	}
+	rules, err = src.Rules(context.Background(), time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || rules.Version != "2026-07" || rules.Rounding != RoundPerLine {
+		t.Fatalf("rules = %+v, err = %v", rules, err)
+	}
+	if _, err := src.Rules(context.Background(), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrNoRules) {
+		t.Fatalf("err = %v, want ErrNoRules", err)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("0.0725")
+	if err != nil || rate != 72500 || FormatRate(rate) != "0.0725" {
+		t.Fatalf("rate = %d (%s), err = %v", rate, FormatRate(rate), err)
+	}
+	if _, err := ParseRate("0.12345678"); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("err = %v, want ErrInvalidRules", err)
+	}
+	if FormatRate(0) != "0" || FormatRate(RateScale) != "1" {
+		t.Fatalf("FormatRate(0) = %s, FormatRate(1) = %s", FormatRate(0), FormatRate(RateScale))
+	}
+}
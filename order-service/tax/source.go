@@ -0,0 +1,153 @@
+package tax
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"online-store-microservice/order-service/models"
+)
+
+// Source returns the rules in effect at a point in time.
+type Source interface {
+	Rules(ctx context.Context, at time.Time) (*Rules, error)
+}
+
+type flatSource struct {
+	rules *Rules
+}
+
+// NewFlatSource applies one rate to every order, computed on the order total.
+// It keeps the behaviour of ORDER_TAX_RATE for stores without tax rules.
+func NewFlatSource(rate float64) Source {
+	return &flatSource{rules: &Rules{
+		Version:  "flat",
+		Rounding: RoundPerTotal,
+		Rates:    []Rate{{Country: AnyCountry, Class: StandardClass, Rate: int64(math.Round(rate * RateScale))}},
+	}}
+}
+
+func (s *flatSource) Rules(context.Context, time.Time) (*Rules, error) {
+	return s.rules, nil
+}
+
+type ruleSetFile struct {
+	Version          string        `json:"version"`
+	EffectiveFrom    time.Time     `json:"effective_from"`
+	PricesIncludeTax bool          `json:"prices_include_tax"`
+	Rounding         string        `json:"rounding"`
+	DefaultCountry   string        `json:"default_country"`
+	Rates            []rateFileRow `json:"rates"`
+}
+
+type rateFileRow struct {
+	Country string      `json:"country"`
+	Region  string      `json:"region"`
+	Class   string      `json:"class"`
+	Name    string      `json:"name"`
+	Rate    json.Number `json:"rate"`
+}
+
+func (f ruleSetFile) rules() (*Rules, error) {
+	rules := &Rules{
+		Version:          f.Version,
+		EffectiveFrom:    f.EffectiveFrom.UTC(),
+		PricesIncludeTax: f.PricesIncludeTax,
+		Rounding:         f.Rounding,
+		DefaultCountry:   f.DefaultCountry,
+		Rates:            make([]Rate, 0, len(f.Rates)),
+	}
+	for _, row := range f.Rates {
+		rate, err := ParseRate(row.Rate.String())
+		if err != nil {
+			return nil, err
+		}
+		rules.Rates = append(rules.Rates, Rate{Country: row.Country, Region: row.Region, Class: row.Class, Name: row.Name, Rate: rate})
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+type versionedSource struct {
+	versions []*Rules
+}
+
+// LoadFileSource reads a JSON list of rule versions. The version with the
+// latest effective_from that has passed applies.
+func LoadFileSource(path string) (Source, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read tax rules: %w", err)
+	}
+	var files []ruleSetFile
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("parse tax rules: %w", err)
+	}
+	versions := make([]*Rules, 0, len(files))
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		rules, err := f.rules()
+		if err != nil {
+			return nil, fmt.Errorf("parse tax rules: %w", err)
+		}
+		if seen[rules.Version] {
+			return nil, fmt.Errorf("parse tax rules: %w: version %s is listed twice", ErrInvalidRules, rules.Version)
+		}
+		seen[rules.Version] = true
+		versions = append(versions, rules)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].EffectiveFrom.Before(versions[j].EffectiveFrom) })
+	return &versionedSource{versions: versions}, nil
+}
+
+func (s *versionedSource) Rules(_ context.Context, at time.Time) (*Rules, error) {
+	for i := len(s.versions) - 1; i >= 0; i-- {
+		if !s.versions[i].EffectiveFrom.After(at) {
+			return s.versions[i], nil
+		}
+	}
+	return nil, ErrNoRules
+}
+
+type postgresSource struct {
+	db *gorm.DB
+}
+
+// NewPostgresSource reads rule versions from the tax_rule_sets table.
+func NewPostgresSource(db *gorm.DB) Source {
+	return &postgresSource{db: db}
+}
+
+func (s *postgresSource) Rules(ctx context.Context, at time.Time) (*Rules, error) {
+	var row models.TaxRuleSet
+	err := s.db.WithContext(ctx).
+		Where("effective_from <= ?", at).
+		Order("effective_from DESC").
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoRules
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f ruleSetFile
+	if err := json.Unmarshal(row.Rules, &f); err != nil {
+		return nil, fmt.Errorf("parse tax rules %s: %w", row.Version, err)
+	}
+	f.Version, f.EffectiveFrom = row.Version, row.EffectiveFrom
+	rules, err := f.rules()
+	if err != nil {
+		return nil, fmt.Errorf("parse tax rules %s: %w", row.Version, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,184 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// exponents lists the ISO 4217 minor unit digits for supported currencies.
+var exponents = map[string]int{
+	"IDR": 2, "USD": 2, "EUR": 2, "GBP": 2, "SGD": 2, "MYR": 2, "AUD": 2,
+	"CHF": 2, "CNY": 2, "THB": 2, "PHP": 2, "INR": 2,
+	"JPY": 0, "KRW": 0, "VND": 0,
+	"KWD": 3, "BHD": 3, "OMR": 3,
+}
+
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+func Exponent(currency string) (int, error) {
+	exp, ok := exponents[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+	return exp, nil
+}
+
+func IsKnownCurrency(currency string) bool {
+	_, err := Exponent(currency)
+	return err == nil
+}
+
+// Parse converts a decimal string such as "1499.99" into minor units,
+// rounding half away from zero when it has more digits than the currency allows.
+func Parse(s, currency string) (Money, error) {
+	exp, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	return fromRat(r, exp, currency)
+}
+
+// FromMajor converts a legacy floating point major-unit amount. It goes through
+// the shortest decimal representation so 0.1 becomes exactly 10 cents.
+func FromMajor(v float64, currency string) (Money, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return Money{}, ErrInvalidAmount
+	}
+	return Parse(fmt.Sprintf("%v", v), currency)
+}
+
+func fromRat(r *big.Rat, exp int, currency string) (Money, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(exp)))
+	amount := roundHalfAwayFromZero(scaled)
+	if !amount.IsInt64() {
+		return Money{}, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+	return New(amount.Int64(), currency), nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount+o.Amount, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	return New(m.Amount-o.Amount, m.Currency), nil
+}
+
+// Mul multiplies by qty. A product that does not fit in an int64 is an
+// error rather than wrapping around.
+func (m Money) Mul(qty int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(qty))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s x %d is out of range", ErrInvalidAmount, m, qty)
+	}
+	return New(product.Int64(), m.Currency), nil
+}
+
+// MulRatio multiplies by num/den and rounds half away from zero to the
+// currency's minor unit.
+func (m Money) MulRatio(num, den int64) Money {
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(num)), big.NewInt(den))
+	return New(roundHalfAwayFromZero(r).Int64(), m.Currency)
+}
+
+// Convert converts m to currency, where rate is the price of one unit of m's
+// currency in currency. The result is rounded half away from zero to the
+// minor unit of currency.
+func (m Money) Convert(currency string, rate *big.Rat) (Money, error) {
+	from, err := Exponent(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	to, err := Exponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	r := new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(from))
+	return fromRat(r.Mul(r, rate), to, currency)
+}
+
+func (m Money) Equal(o Money) bool {
+	return m.Amount == o.Amount && strings.EqualFold(m.Currency, o.Currency)
+}
+
+// Major returns the amount in major units. It is only meant for legacy
+// fields that still carry floating point prices.
+func (m Money) Major() float64 {
+	exp, _ := Exponent(m.Currency)
+	f, _ := new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(exp)).Float64()
+	return f
+}
+
+func (m Money) String() string {
+	if _, err := Exponent(m.Currency); err != nil {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	return m.Decimal() + " " + m.Currency
+}
+
+// Decimal formats the amount in major units without the currency, like
+// "12.50", which Parse reads back.
+func (m Money) Decimal() string {
+	exp, err := Exponent(m.Currency)
+	if err != nil {
+		return fmt.Sprint(m.Amount)
+	}
+	return new(big.Rat).SetFrac(big.NewInt(m.Amount), pow10(exp)).FloatString(exp)
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if !strings.EqualFold(m.Currency, o.Currency) {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func roundHalfAwayFromZero(r *big.Rat) *big.Int {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if new(big.Int).Mul(rem, big.NewInt(2)).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	return q
+}
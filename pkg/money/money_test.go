@@ -0,0 +1,115 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseUsesCurrencyExponent(t *testing.T) {
+	cases := []struct {
+		in       string
+		currency string
+		want     int64
+	}{
+		{"1499.99", "USD", 149999},
+		{"0.1", "USD", 10},
+		{"0.005", "USD", 1},
+		{"-0.005", "USD", -1},
+		{"0.004", "USD", 0},
+		{"1500", "JPY", 1500},
+		{"1500.5", "JPY", 1501},
+		{"1.2345", "KWD", 1235},
+		{"15000000", "IDR", 1500000000},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.in, tc.currency)
+		if err != nil {
+			t.Fatalf("Parse(%q, %s): %v", tc.in, tc.currency, err)
+		}
+		if got.Amount != tc.want {
+			t.Errorf("Parse(%q, %s) = %d, want %d", tc.in, tc.currency, got.Amount, tc.want)
+		}
+	}
+}
+
+func TestFromMajorAvoidsBinaryRounding(t *testing.T) {
+	m, err := FromMajor(0.1+0.2, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Amount != 30 {
+		t.Errorf("amount = %d, want 30", m.Amount)
+	}
+}
+
+func TestMulRatioRoundsHalfAwayFromZero(t *testing.T) {
+	price := New(1999, "USD")
+	if got := price.MulRatio(11, 100).Amount; got != 220 {
+		t.Errorf("11%% of 19.99 = %d, want 220", got)
+	}
+	if got := New(5, "USD").MulRatio(1, 2).Amount; got != 3 {
+		t.Errorf("half of 5 = %d, want 3", got)
+	}
+}
+
+func TestMulRejectsOverflow(t *testing.T) {
+	if m, err := New(1999, "USD").Mul(3); err != nil || m.Amount != 5997 {
+		t.Fatalf("3 x 19.99 = %v, %v", m, err)
+	}
+	for _, qty := range []int64{2, -3} {
+		if _, err := New(math.MaxInt64/2+1, "USD").Mul(qty); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Mul(%d) err = %v, want ErrInvalidAmount", qty, err)
+		}
+	}
+}
+
+func TestArithmeticRejectsMixedCurrencies(t *testing.T) {
+	if _, err := New(1, "USD").Add(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("err = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := New(149999, "USD").String(); got != "1499.99 USD" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := New(1500, "JPY").String(); got != "1500 JPY" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestDecimalRoundTrips(t *testing.T) {
+	m := New(-250, "USD")
+	if got := m.Decimal(); got != "-2.50" {
+		t.Fatalf("Decimal() = %q", got)
+	}
+	if back, err := Parse(m.Decimal(), "USD"); err != nil || !back.Equal(m) {
+		t.Fatalf("Parse(%q) = %v, %v", m.Decimal(), back, err)
+	}
+}
+
+func TestConvertScalesBetweenExponents(t *testing.T) {
+	cases := []struct {
+		from Money
+		to   string
+		rate string
+		want Money
+	}{
+		{New(1000, "USD"), "IDR", "16250.5", New(16250500, "IDR")},
+		{New(16250500, "IDR"), "USD", "0.0000615", New(999, "USD")},
+		{New(1999, "USD"), "JPY", "149.5", New(2989, "JPY")},
+		{New(1500, "JPY"), "KWD", "0.00205", New(3075, "KWD")},
+	}
+	for _, tc := range cases {
+		rate, _ := new(big.Rat).SetString(tc.rate)
+		got, err := tc.from.Convert(tc.to, rate)
+		if err != nil || !got.Equal(tc.want) {
+			t.Errorf("%s at %s = %v, %v, want %s", tc.from, tc.rate, got, err, tc.want)
+		}
+	}
+	if _, err := New(1, "USD").Convert("XXX", big.NewRat(1, 1)); !errors.Is(err, ErrUnknownCurrency) {
+		t.Fatalf("err = %v, want ErrUnknownCurrency", err)
+	}
+}
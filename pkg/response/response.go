@@ -3,10 +3,21 @@ package response
 import "github.com/gin-gonic/gin"
 
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   interface{} `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message"`
+	Data       interface{} `json:"data,omitempty"`
+	Error      interface{} `json:"error,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+}
+
+type Pagination struct {
+	Count         int    `json:"count"`
+	NextPageToken string `json:"next_page_token,omitempty"`
+	HasMore       bool   `json:"has_more"`
+}
+
+func NewPagination(count int, nextPageToken string) *Pagination {
+	return &Pagination{Count: count, NextPageToken: nextPageToken, HasMore: nextPageToken != ""}
 }
 
 func OK(c *gin.Context, status int, message string, data interface{}) {
@@ -16,3 +27,7 @@ func OK(c *gin.Context, status int, message string, data interface{}) {
 func Fail(c *gin.Context, status int, message string, err interface{}) {
 	c.JSON(status, APIResponse{Success: false, Message: message, Error: err})
 }
+
+func Page(c *gin.Context, status int, message string, data interface{}, pagination *Pagination) {
+	c.JSON(status, APIResponse{Success: true, Message: message, Data: data, Pagination: pagination})
+}
@@ -6,24 +6,149 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"online-store-microservice/pkg/money"
+)
+
+type Money = money.Money
+
+const (
+	// RequestIDMetadata is the gRPC metadata key carrying the gateway's X-Request-ID.
+	RequestIDMetadata = "x-request-id"
+	// IdempotencyKeyMetadata is the gRPC metadata key carrying the client's Idempotency-Key.
+	IdempotencyKeyMetadata = "idempotency-key"
+	// ReasonIdempotencyKeyReused is the ErrorInfo reason sent when a key is reused with a different request.
+	ReasonIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
 )
 
 type OrderData struct {
-	Id          string  `json:"id"`
-	UserId      string  `json:"user_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int32   `json:"quantity"`
-	TotalPrice  float64 `json:"total_price"`
-	Status      string  `json:"status"`
-	CreatedAt   string  `json:"created_at"`
-	UpdatedAt   string  `json:"updated_at"`
+	Id                        string                   `json:"id"`
+	UserId                    string                   `json:"user_id"`
+	ProductName               string                   `json:"product_name"`
+	Quantity                  int32                    `json:"quantity"`
+	TotalPrice                float64                  `json:"total_price"`
+	Status                    string                   `json:"status"`
+	CreatedAt                 string                   `json:"created_at"`
+	UpdatedAt                 string                   `json:"updated_at"`
+	CancellationReason        string                   `json:"cancellation_reason,omitempty"`
+	CancelledBy               string                   `json:"cancelled_by,omitempty"`
+	CancelledAt               string                   `json:"cancelled_at,omitempty"`
+	Items                     []*OrderItemData         `json:"items"`
+	Subtotal                  float64                  `json:"subtotal"`
+	DiscountTotal             float64                  `json:"discount_total"`
+	TaxTotal                  float64                  `json:"tax_total"`
+	Currency                  string                   `json:"currency"`
+	SubtotalAmount            *Money                   `json:"subtotal_amount"`
+	DiscountAmount            *Money                   `json:"discount_amount"`
+	TaxAmount                 *Money                   `json:"tax_amount"`
+	TotalAmount               *Money                   `json:"total_amount"`
+	History                   []*OrderStatusChangeData `json:"history,omitempty"`
+	Version                   int64                    `json:"version"`
+	RefundedAmount            *Money                   `json:"refunded_amount"`
+	NetTotalAmount            *Money                   `json:"net_total_amount"`
+	CouponCode                string                   `json:"coupon_code,omitempty"`
+	FreeShipping              bool                     `json:"free_shipping"`
+	Discounts                 []*OrderDiscountData     `json:"discounts"`
+	ShippingAddress           *Address                 `json:"shipping_address,omitempty"`
+	TaxRulesVersion           string                   `json:"tax_rules_version,omitempty"`
+	PricesIncludeTax          bool                     `json:"prices_include_tax"`
+	TaxExempt                 bool                     `json:"tax_exempt"`
+	TaxExemptAmount           *Money                   `json:"tax_exempt_amount,omitempty"`
+	TaxLines                  []*OrderTaxLineData      `json:"tax_lines"`
+	ShippingMethod            string                   `json:"shipping_method,omitempty"`
+	ShippingCarrier           string                   `json:"shipping_carrier,omitempty"`
+	ShippingZone              string                   `json:"shipping_zone,omitempty"`
+	ShippingAmount            *Money                   `json:"shipping_amount"`
+	BaseCurrency              string                   `json:"base_currency"`
+	ExchangeRate              string                   `json:"exchange_rate"`
+	ExchangeRateEffectiveFrom string                   `json:"exchange_rate_effective_from,omitempty"`
+	BaseTotalAmount           *Money                   `json:"base_total_amount"`
+	OrderNumber               string                   `json:"order_number,omitempty"`
+}
+
+type Address struct {
+	Name       string `json:"name,omitempty"`
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country"`
+}
+
+type OrderTaxLineData struct {
+	OrderItemId   string `json:"order_item_id,omitempty"`
+	Country       string `json:"country"`
+	Region        string `json:"region,omitempty"`
+	TaxClass      string `json:"tax_class"`
+	Name          string `json:"name,omitempty"`
+	Rate          string `json:"rate"`
+	TaxableAmount *Money `json:"taxable_amount"`
+	Amount        *Money `json:"amount"`
+	Exempt        bool   `json:"exempt,omitempty"`
+}
+
+type OrderDiscountData struct {
+	Source      string `json:"source"`
+	CouponCode  string `json:"coupon_code,omitempty"`
+	Kind        string `json:"kind,omitempty"`
+	OrderItemId string `json:"order_item_id,omitempty"`
+	Amount      *Money `json:"amount"`
+}
+
+type OrderStatusChangeData struct {
+	Id         string             `json:"id"`
+	FromStatus string             `json:"from_status"`
+	ToStatus   string             `json:"to_status"`
+	Actor      string             `json:"actor"`
+	Reason     string             `json:"reason,omitempty"`
+	RequestId  string             `json:"request_id,omitempty"`
+	CreatedAt  string             `json:"created_at"`
+	Changes    []*OrderChangeData `json:"changes,omitempty"`
+}
+
+type OrderChangeData struct {
+	Field string `json:"field"`
+	From  string `json:"from,omitempty"`
+	To    string `json:"to,omitempty"`
+}
+
+type OrderItemData struct {
+	Id             string  `json:"id"`
+	ProductId      string  `json:"product_id,omitempty"`
+	Sku            string  `json:"sku,omitempty"`
+	Name           string  `json:"name"`
+	UnitPrice      float64 `json:"unit_price"`
+	Quantity       int32   `json:"quantity"`
+	LineTotal      float64 `json:"line_total"`
+	Discount       float64 `json:"discount"`
+	UnitAmount     *Money  `json:"unit_amount"`
+	DiscountAmount *Money  `json:"discount_amount"`
+	LineAmount     *Money  `json:"line_amount"`
+	TaxClass       string  `json:"tax_class,omitempty"`
+	WeightGrams    int64   `json:"weight_grams,omitempty"`
+}
+
+type OrderItemInput struct {
+	ProductId          string  `json:"product_id"`
+	Sku                string  `json:"sku"`
+	Name               string  `json:"name"`
+	UnitPrice          float64 `json:"unit_price"`
+	Quantity           int32   `json:"quantity"`
+	ExpectedUnitAmount *Money  `json:"expected_unit_amount"`
 }
 
 type CreateOrderRequest struct {
-	UserId      string  `json:"user_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int32   `json:"quantity"`
-	TotalPrice  float64 `json:"total_price"`
+	UserId              string            `json:"user_id"`
+	ProductName         string            `json:"product_name"`
+	Quantity            int32             `json:"quantity"`
+	TotalPrice          float64           `json:"total_price"`
+	Items               []*OrderItemInput `json:"items"`
+	Currency            string            `json:"currency"`
+	ExpectedTotalAmount *Money            `json:"expected_total_amount"`
+	CouponCode          string            `json:"coupon_code"`
+	ShippingAddress     *Address          `json:"shipping_address"`
+	ShippingMethod      string            `json:"shipping_method"`
 }
 
 type CreateOrderResponse struct {
@@ -31,7 +156,8 @@ type CreateOrderResponse struct {
 }
 
 type GetOrderByIdRequest struct {
-	Id string `json:"id"`
+	Id             string `json:"id"`
+	IncludeHistory bool   `json:"include_history"`
 }
 
 type GetOrderByIdResponse struct {
@@ -39,17 +165,489 @@ type GetOrderByIdResponse struct {
 }
 
 type GetOrdersByUserIdRequest struct {
-	UserId string `json:"user_id"`
+	UserId      string `json:"user_id"`
+	PageSize    int32  `json:"page_size"`
+	PageToken   string `json:"page_token"`
+	Status      string `json:"status"`
+	CreatedFrom string `json:"created_from"`
+	CreatedTo   string `json:"created_to"`
+	MinTotal    string `json:"min_total"`
+	MaxTotal    string `json:"max_total"`
+	Sort        string `json:"sort"`
 }
 
 type GetOrdersByUserIdResponse struct {
-	Orders []*OrderData `json:"orders"`
+	Orders        []*OrderData `json:"orders"`
+	NextPageToken string       `json:"next_page_token"`
+}
+
+type ReassignOrdersRequest struct {
+	FromUserId string   `json:"from_user_id"`
+	ToUserId   string   `json:"to_user_id"`
+	OrderIds   []string `json:"order_ids"`
+	DryRun     bool     `json:"dry_run"`
+}
+
+type ReassignOrdersResponse struct {
+	OrderIds []string `json:"order_ids"`
+}
+
+type UpdateOrderStatusRequest struct {
+	Id              string `json:"id"`
+	Status          string `json:"status"`
+	ActorId         string `json:"actor_id"`
+	Reason          string `json:"reason"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type UpdateOrderStatusResponse struct {
+	Order *OrderData `json:"order"`
+}
+
+type CancelOrderRequest struct {
+	Id              string `json:"id"`
+	ReasonCode      string `json:"reason_code"`
+	ActorId         string `json:"actor_id"`
+	ActorIsAdmin    bool   `json:"actor_is_admin"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type CancelOrderResponse struct {
+	Order *OrderData `json:"order"`
+}
+
+type GetOrderHistoryRequest struct {
+	OrderId string `json:"order_id"`
+}
+
+type GetOrderHistoryResponse struct {
+	History []*OrderStatusChangeData `json:"history"`
+}
+
+type ReturnItemInput struct {
+	OrderItemId string `json:"order_item_id"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type ReturnItemData struct {
+	Id          string `json:"id"`
+	OrderItemId string `json:"order_item_id"`
+	Name        string `json:"name"`
+	Quantity    int32  `json:"quantity"`
+	Amount      *Money `json:"amount"`
+}
+
+type ReturnData struct {
+	Id              string            `json:"id"`
+	OrderId         string            `json:"order_id"`
+	UserId          string            `json:"user_id"`
+	Status          string            `json:"status"`
+	ReasonCode      string            `json:"reason_code"`
+	Note            string            `json:"note,omitempty"`
+	Items           []*ReturnItemData `json:"items"`
+	RequestedAmount *Money            `json:"requested_amount"`
+	RefundedAmount  *Money            `json:"refunded_amount"`
+	RefundReference string            `json:"refund_reference,omitempty"`
+	RequestedBy     string            `json:"requested_by"`
+	DecidedBy       string            `json:"decided_by,omitempty"`
+	DecisionNote    string            `json:"decision_note,omitempty"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
+	ReceivedAt      string            `json:"received_at,omitempty"`
+	RefundedAt      string            `json:"refunded_at,omitempty"`
+}
+
+type RequestReturnRequest struct {
+	OrderId      string             `json:"order_id"`
+	ActorId      string             `json:"actor_id"`
+	ActorIsAdmin bool               `json:"actor_is_admin"`
+	ReasonCode   string             `json:"reason_code"`
+	Note         string             `json:"note"`
+	Items        []*ReturnItemInput `json:"items"`
+}
+
+type ListOrderReturnsRequest struct {
+	OrderId      string `json:"order_id"`
+	ActorId      string `json:"actor_id"`
+	ActorIsAdmin bool   `json:"actor_is_admin"`
+}
+
+type ListOrderReturnsResponse struct {
+	Returns []*ReturnData `json:"returns"`
+	Order   *OrderData    `json:"order"`
+}
+
+type UpdateReturnStatusRequest struct {
+	Id              string `json:"id"`
+	Status          string `json:"status"`
+	ActorId         string `json:"actor_id"`
+	Note            string `json:"note"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type RefundReturnRequest struct {
+	Id              string `json:"id"`
+	Amount          *Money `json:"amount"`
+	ActorId         string `json:"actor_id"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type ReturnResponse struct {
+	Return *ReturnData `json:"return"`
+	Order  *OrderData  `json:"order"`
+}
+
+type CouponData struct {
+	Code             string   `json:"code"`
+	Description      string   `json:"description"`
+	Kind             string   `json:"kind"`
+	PercentOffBps    int64    `json:"percent_off_bps"`
+	AmountOff        *Money   `json:"amount_off"`
+	BuyQuantity      int32    `json:"buy_quantity"`
+	GetQuantity      int32    `json:"get_quantity"`
+	MinSubtotal      *Money   `json:"min_subtotal"`
+	StartsAt         string   `json:"starts_at"`
+	EndsAt           string   `json:"ends_at"`
+	MaxRedemptions   int32    `json:"max_redemptions"`
+	MaxPerUser       int32    `json:"max_per_user"`
+	EligibleProducts []string `json:"eligible_products"`
+	Active           bool     `json:"active"`
+	Redemptions      int32    `json:"redemptions"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+}
+
+type CreateCouponRequest struct {
+	Coupon *CouponData `json:"coupon"`
+}
+
+type UpdateCouponRequest struct {
+	Coupon *CouponData `json:"coupon"`
+}
+
+type CouponResponse struct {
+	Coupon *CouponData `json:"coupon"`
+}
+
+type ListCouponsRequest struct {
+	IncludeInactive bool `json:"include_inactive"`
+}
+
+type ListCouponsResponse struct {
+	Coupons []*CouponData `json:"coupons"`
+}
+
+type TaxExemptionData struct {
+	UserId      string `json:"user_id"`
+	Reason      string `json:"reason"`
+	Certificate string `json:"certificate,omitempty"`
+	CreatedBy   string `json:"created_by"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+}
+
+type GetTaxExemptionRequest struct {
+	UserId string `json:"user_id"`
+}
+
+type SetTaxExemptionRequest struct {
+	UserId      string `json:"user_id"`
+	Reason      string `json:"reason"`
+	Certificate string `json:"certificate"`
+	ActorId     string `json:"actor_id"`
+}
+
+type TaxExemptionResponse struct {
+	Exemption *TaxExemptionData `json:"exemption"`
+}
+
+type RemoveTaxExemptionRequest struct {
+	UserId string `json:"user_id"`
+}
+
+type RemoveTaxExemptionResponse struct{}
+
+type ShipmentItemInput struct {
+	OrderItemId string `json:"order_item_id"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type ShipmentItemData struct {
+	Id          string `json:"id"`
+	OrderItemId string `json:"order_item_id"`
+	Name        string `json:"name"`
+	Quantity    int32  `json:"quantity"`
+}
+
+type ShipmentData struct {
+	Id             string              `json:"id"`
+	OrderId        string              `json:"order_id"`
+	Carrier        string              `json:"carrier"`
+	TrackingNumber string              `json:"tracking_number"`
+	TrackingUrl    string              `json:"tracking_url,omitempty"`
+	Status         string              `json:"status"`
+	WeightGrams    int64               `json:"weight_grams"`
+	Items          []*ShipmentItemData `json:"items"`
+	CreatedBy      string              `json:"created_by"`
+	CreatedAt      string              `json:"created_at"`
+	UpdatedAt      string              `json:"updated_at"`
+	DeliveredAt    string              `json:"delivered_at,omitempty"`
+}
+
+type CreateShipmentRequest struct {
+	OrderId         string               `json:"order_id"`
+	Carrier         string               `json:"carrier"`
+	Items           []*ShipmentItemInput `json:"items"`
+	ActorId         string               `json:"actor_id"`
+	ExpectedVersion int64                `json:"expected_version"`
+}
+
+type ShipmentResponse struct {
+	Shipment *ShipmentData `json:"shipment"`
+	Order    *OrderData    `json:"order"`
+}
+
+type ListOrderShipmentsRequest struct {
+	OrderId      string `json:"order_id"`
+	ActorId      string `json:"actor_id"`
+	ActorIsAdmin bool   `json:"actor_is_admin"`
+}
+
+type ListOrderShipmentsResponse struct {
+	Shipments []*ShipmentData `json:"shipments"`
+	Order     *OrderData      `json:"order"`
+}
+
+type UpdateShipmentStatusRequest struct {
+	Id              string `json:"id"`
+	Status          string `json:"status"`
+	ActorId         string `json:"actor_id"`
+	ExpectedVersion int64  `json:"expected_version"`
+}
+
+type InvoicePartyData struct {
+	Name         string   `json:"name"`
+	Email        string   `json:"email"`
+	TaxId        string   `json:"tax_id"`
+	AddressLines []string `json:"address_lines"`
+}
+
+type InvoiceLineData struct {
+	Description    string `json:"description"`
+	Sku            string `json:"sku"`
+	Quantity       int32  `json:"quantity"`
+	UnitAmount     *Money `json:"unit_amount"`
+	DiscountAmount *Money `json:"discount_amount"`
+	Amount         *Money `json:"amount"`
+}
+
+type InvoiceTaxLineData struct {
+	Name          string `json:"name"`
+	Rate          string `json:"rate"`
+	TaxableAmount *Money `json:"taxable_amount"`
+	Amount        *Money `json:"amount"`
+	Exempt        bool   `json:"exempt"`
+}
+
+type InvoiceData struct {
+	Id               string                `json:"id"`
+	Kind             string                `json:"kind"`
+	Number           string                `json:"number"`
+	OrderId          string                `json:"order_id"`
+	ReturnId         string                `json:"return_id"`
+	InvoiceNumber    string                `json:"invoice_number"`
+	IssuedAt         string                `json:"issued_at"`
+	Seller           *InvoicePartyData     `json:"seller"`
+	Buyer            *InvoicePartyData     `json:"buyer"`
+	Currency         string                `json:"currency"`
+	Lines            []*InvoiceLineData    `json:"lines"`
+	TaxLines         []*InvoiceTaxLineData `json:"tax_lines"`
+	SubtotalAmount   *Money                `json:"subtotal_amount"`
+	DiscountAmount   *Money                `json:"discount_amount"`
+	ShippingAmount   *Money                `json:"shipping_amount"`
+	TaxAmount        *Money                `json:"tax_amount"`
+	TotalAmount      *Money                `json:"total_amount"`
+	PricesIncludeTax bool                  `json:"prices_include_tax"`
+	TaxExempt        bool                  `json:"tax_exempt"`
+	Note             string                `json:"note"`
+}
+
+type GetInvoiceRequest struct {
+	OrderId      string `json:"order_id"`
+	Number       string `json:"number"`
+	Format       string `json:"format"`
+	ActorId      string `json:"actor_id"`
+	ActorIsAdmin bool   `json:"actor_is_admin"`
+}
+
+type InvoiceResponse struct {
+	Invoice     *InvoiceData   `json:"invoice"`
+	CreditNotes []*InvoiceData `json:"credit_notes"`
+	ContentType string         `json:"content_type"`
+	Content     []byte         `json:"content"`
+}
+
+type ReorderRequest struct {
+	OrderId      string `json:"order_id"`
+	ActorId      string `json:"actor_id"`
+	ActorIsAdmin bool   `json:"actor_is_admin"`
+	Preview      bool   `json:"preview"`
+}
+
+type ReorderItemData struct {
+	OrderItemId        string `json:"order_item_id"`
+	ProductId          string `json:"product_id"`
+	Sku                string `json:"sku"`
+	Name               string `json:"name"`
+	Quantity           int32  `json:"quantity"`
+	PreviousUnitAmount *Money `json:"previous_unit_amount"`
+	UnitAmount         *Money `json:"unit_amount"`
+}
+
+type ReorderResponse struct {
+	Order            *OrderData         `json:"order"`
+	Preview          bool               `json:"preview"`
+	UnavailableItems []*ReorderItemData `json:"unavailable_items"`
+	PriceChanges     []*ReorderItemData `json:"price_changes"`
+}
+
+type SearchOrdersRequest struct {
+	PageSize      int32    `json:"page_size"`
+	PageToken     string   `json:"page_token"`
+	Sort          string   `json:"sort"`
+	Statuses      []string `json:"statuses"`
+	CreatedFrom   string   `json:"created_from"`
+	CreatedTo     string   `json:"created_to"`
+	MinTotal      string   `json:"min_total"`
+	MaxTotal      string   `json:"max_total"`
+	ProductName   string   `json:"product_name"`
+	CustomerEmail string   `json:"customer_email"`
+	UserId        string   `json:"user_id"`
+	OrderNumber   string   `json:"order_number"`
+}
+
+type SearchOrdersResponse struct {
+	Orders        []*OrderData `json:"orders"`
+	NextPageToken string       `json:"next_page_token"`
+}
+
+type ImportOrdersRequest struct {
+	ImportId string `json:"import_id"`
+	Format   string `json:"format"`
+	DryRun   bool   `json:"dry_run"`
+	Source   string `json:"source"`
+	ActorId  string `json:"actor_id"`
+	Chunk    []byte `json:"chunk"`
+}
+
+type ImportRowError struct {
+	Row     int64  `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportOrdersResponse struct {
+	ImportId        string            `json:"import_id"`
+	DryRun          bool              `json:"dry_run"`
+	Status          string            `json:"status"`
+	RowsRead        int64             `json:"rows_read"`
+	RowsImported    int64             `json:"rows_imported"`
+	RowsFailed      int64             `json:"rows_failed"`
+	RowsSkipped     int64             `json:"rows_skipped"`
+	Errors          []*ImportRowError `json:"errors"`
+	ErrorsTruncated bool              `json:"errors_truncated"`
+	Error           string            `json:"error"`
+}
+
+type ExportOrdersRequest struct {
+	Filter  *SearchOrdersRequest `json:"filter"`
+	Format  string               `json:"format"`
+	Columns []string             `json:"columns"`
+}
+
+type ExportOrdersChunk struct {
+	Data []byte `json:"data"`
+}
+
+type SalesReportRequest struct {
+	CreatedFrom string `json:"created_from,omitempty"`
+	CreatedTo   string `json:"created_to,omitempty"`
+}
+
+type CurrencySales struct {
+	Currency           string `json:"currency"`
+	Orders             int64  `json:"orders"`
+	TotalAmount        *Money `json:"total_amount"`
+	RefundedAmount     *Money `json:"refunded_amount"`
+	BaseTotalAmount    *Money `json:"base_total_amount"`
+	BaseRefundedAmount *Money `json:"base_refunded_amount"`
+}
+
+type SalesReportResponse struct {
+	BaseCurrency   string           `json:"base_currency"`
+	Orders         int64            `json:"orders"`
+	TotalAmount    *Money           `json:"total_amount"`
+	RefundedAmount *Money           `json:"refunded_amount"`
+	NetAmount      *Money           `json:"net_amount"`
+	Currencies     []*CurrencySales `json:"currencies"`
+}
+
+type AmendOrderRequest struct {
+	Id                  string            `json:"id"`
+	ActorId             string            `json:"actor_id,omitempty"`
+	ActorIsAdmin        bool              `json:"actor_is_admin,omitempty"`
+	ExpectedVersion     int64             `json:"expected_version,omitempty"`
+	Items               []*OrderItemInput `json:"items,omitempty"`
+	ShippingAddress     *Address          `json:"shipping_address,omitempty"`
+	ExpectedTotalAmount *Money            `json:"expected_total_amount,omitempty"`
+	Preview             bool              `json:"preview,omitempty"`
+}
+
+type AmendOrderResponse struct {
+	Order               *OrderData         `json:"order"`
+	Preview             bool               `json:"preview"`
+	Changes             []*OrderChangeData `json:"changes"`
+	PreviousTotalAmount *Money             `json:"previous_total_amount"`
+	DeltaAmount         *Money             `json:"delta_amount"`
+	ChargeAmount        *Money             `json:"charge_amount"`
+	RefundAmount        *Money             `json:"refund_amount"`
+}
+
+type GetOrderByNumberRequest struct {
+	OrderNumber    string `json:"order_number"`
+	IncludeHistory bool   `json:"include_history"`
+	ActorId        string `json:"actor_id"`
+	ActorIsAdmin   bool   `json:"actor_is_admin"`
 }
 
 type OrderServiceClient interface {
 	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
 	GetOrderById(ctx context.Context, in *GetOrderByIdRequest, opts ...grpc.CallOption) (*GetOrderByIdResponse, error)
 	GetOrdersByUserId(ctx context.Context, in *GetOrdersByUserIdRequest, opts ...grpc.CallOption) (*GetOrdersByUserIdResponse, error)
+	ReassignOrders(ctx context.Context, in *ReassignOrdersRequest, opts ...grpc.CallOption) (*ReassignOrdersResponse, error)
+	UpdateOrderStatus(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error)
+	RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error)
+	ListOrderReturns(ctx context.Context, in *ListOrderReturnsRequest, opts ...grpc.CallOption) (*ListOrderReturnsResponse, error)
+	UpdateReturnStatus(ctx context.Context, in *UpdateReturnStatusRequest, opts ...grpc.CallOption) (*ReturnResponse, error)
+	RefundReturn(ctx context.Context, in *RefundReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error)
+	CreateCoupon(ctx context.Context, in *CreateCouponRequest, opts ...grpc.CallOption) (*CouponResponse, error)
+	UpdateCoupon(ctx context.Context, in *UpdateCouponRequest, opts ...grpc.CallOption) (*CouponResponse, error)
+	ListCoupons(ctx context.Context, in *ListCouponsRequest, opts ...grpc.CallOption) (*ListCouponsResponse, error)
+	GetTaxExemption(ctx context.Context, in *GetTaxExemptionRequest, opts ...grpc.CallOption) (*TaxExemptionResponse, error)
+	SetTaxExemption(ctx context.Context, in *SetTaxExemptionRequest, opts ...grpc.CallOption) (*TaxExemptionResponse, error)
+	RemoveTaxExemption(ctx context.Context, in *RemoveTaxExemptionRequest, opts ...grpc.CallOption) (*RemoveTaxExemptionResponse, error)
+	CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*ShipmentResponse, error)
+	ListOrderShipments(ctx context.Context, in *ListOrderShipmentsRequest, opts ...grpc.CallOption) (*ListOrderShipmentsResponse, error)
+	UpdateShipmentStatus(ctx context.Context, in *UpdateShipmentStatusRequest, opts ...grpc.CallOption) (*ShipmentResponse, error)
+	GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*InvoiceResponse, error)
+	Reorder(ctx context.Context, in *ReorderRequest, opts ...grpc.CallOption) (*ReorderResponse, error)
+	SearchOrders(ctx context.Context, in *SearchOrdersRequest, opts ...grpc.CallOption) (*SearchOrdersResponse, error)
+	ImportOrders(ctx context.Context, opts ...grpc.CallOption) (OrderService_ImportOrdersClient, error)
+	ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (OrderService_ExportOrdersClient, error)
+	GetSalesReport(ctx context.Context, in *SalesReportRequest, opts ...grpc.CallOption) (*SalesReportResponse, error)
+	AmendOrder(ctx context.Context, in *AmendOrderRequest, opts ...grpc.CallOption) (*AmendOrderResponse, error)
+	GetOrderByNumber(ctx context.Context, in *GetOrderByNumberRequest, opts ...grpc.CallOption) (*GetOrderByIdResponse, error)
 }
 
 type orderServiceClient struct {
@@ -87,86 +685,901 @@ func (c *orderServiceClient) GetOrdersByUserId(ctx context.Context, in *GetOrder
 	return out, nil
 }
 
-type OrderServiceServer interface {
-	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
-	GetOrderById(context.Context, *GetOrderByIdRequest) (*GetOrderByIdResponse, error)
-	GetOrdersByUserId(context.Context, *GetOrdersByUserIdRequest) (*GetOrdersByUserIdResponse, error)
-	mustEmbedUnimplementedOrderServiceServer()
+func (c *orderServiceClient) ReassignOrders(ctx context.Context, in *ReassignOrdersRequest, opts ...grpc.CallOption) (*ReassignOrdersResponse, error) {
+	out := new(ReassignOrdersResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/ReassignOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-type UnimplementedOrderServiceServer struct{}
+func (c *orderServiceClient) UpdateOrderStatus(ctx context.Context, in *UpdateOrderStatusRequest, opts ...grpc.CallOption) (*UpdateOrderStatusResponse, error) {
+	out := new(UpdateOrderStatusResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/UpdateOrderStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-func (UnimplementedOrderServiceServer) CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
+func (c *orderServiceClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/CancelOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (UnimplementedOrderServiceServer) GetOrderById(context.Context, *GetOrderByIdRequest) (*GetOrderByIdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetOrderById not implemented")
+func (c *orderServiceClient) GetOrderHistory(ctx context.Context, in *GetOrderHistoryRequest, opts ...grpc.CallOption) (*GetOrderHistoryResponse, error) {
+	out := new(GetOrderHistoryResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/GetOrderHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (UnimplementedOrderServiceServer) GetOrdersByUserId(context.Context, *GetOrdersByUserIdRequest) (*GetOrdersByUserIdResponse, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetOrdersByUserId not implemented")
+func (c *orderServiceClient) RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
+	out := new(ReturnResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/RequestReturn", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
+func (c *orderServiceClient) ListOrderReturns(ctx context.Context, in *ListOrderReturnsRequest, opts ...grpc.CallOption) (*ListOrderReturnsResponse, error) {
+	out := new(ListOrderReturnsResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/ListOrderReturns", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
 
-func RegisterOrderServiceServer(s grpc.ServiceRegistrar, srv OrderServiceServer) {
-	s.RegisterService(&OrderService_ServiceDesc, srv)
+func (c *orderServiceClient) UpdateReturnStatus(ctx context.Context, in *UpdateReturnStatusRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
+	out := new(ReturnResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/UpdateReturnStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
 }
 
-func _OrderService_CreateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateOrderRequest)
-	if err := dec(in); err != nil {
+func (c *orderServiceClient) RefundReturn(ctx context.Context, in *RefundReturnRequest, opts ...grpc.CallOption) (*ReturnResponse, error) {
+	out := new(ReturnResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/RefundReturn", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(OrderServiceServer).CreateOrder(ctx, in)
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateCoupon(ctx context.Context, in *CreateCouponRequest, opts ...grpc.CallOption) (*CouponResponse, error) {
+	out := new(CouponResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/CreateCoupon", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/CreateOrder"}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(OrderServiceServer).CreateOrder(ctx, req.(*CreateOrderRequest))
+	return out, nil
+}
+
+func (c *orderServiceClient) UpdateCoupon(ctx context.Context, in *UpdateCouponRequest, opts ...grpc.CallOption) (*CouponResponse, error) {
+	out := new(CouponResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/UpdateCoupon", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-func _OrderService_GetOrderById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetOrderByIdRequest)
-	if err := dec(in); err != nil {
+func (c *orderServiceClient) ListCoupons(ctx context.Context, in *ListCouponsRequest, opts ...grpc.CallOption) (*ListCouponsResponse, error) {
+	out := new(ListCouponsResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/ListCoupons", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(OrderServiceServer).GetOrderById(ctx, in)
+	return out, nil
+}
+
+func (c *orderServiceClient) GetTaxExemption(ctx context.Context, in *GetTaxExemptionRequest, opts ...grpc.CallOption) (*TaxExemptionResponse, error) {
+	out := new(TaxExemptionResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/GetTaxExemption", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrderById"}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(OrderServiceServer).GetOrderById(ctx, req.(*GetOrderByIdRequest))
+	return out, nil
+}
+
+func (c *orderServiceClient) SetTaxExemption(ctx context.Context, in *SetTaxExemptionRequest, opts ...grpc.CallOption) (*TaxExemptionResponse, error) {
+	out := new(TaxExemptionResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/SetTaxExemption", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-func _OrderService_GetOrdersByUserId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetOrdersByUserIdRequest)
-	if err := dec(in); err != nil {
+func (c *orderServiceClient) RemoveTaxExemption(ctx context.Context, in *RemoveTaxExemptionRequest, opts ...grpc.CallOption) (*RemoveTaxExemptionResponse, error) {
+	out := new(RemoveTaxExemptionResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/RemoveTaxExemption", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	if interceptor == nil {
-		return srv.(OrderServiceServer).GetOrdersByUserId(ctx, in)
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateShipment(ctx context.Context, in *CreateShipmentRequest, opts ...grpc.CallOption) (*ShipmentResponse, error) {
+	out := new(ShipmentResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/CreateShipment", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrdersByUserId"}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(OrderServiceServer).GetOrdersByUserId(ctx, req.(*GetOrdersByUserIdRequest))
+	return out, nil
+}
+
+func (c *orderServiceClient) ListOrderShipments(ctx context.Context, in *ListOrderShipmentsRequest, opts ...grpc.CallOption) (*ListOrderShipmentsResponse, error) {
+	out := new(ListOrderShipmentsResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/ListOrderShipments", in, out, opts...)
+	if err != nil {
+		return nil, err
 	}
-	return interceptor(ctx, in, info, handler)
+	return out, nil
 }
 
-var OrderService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "order.OrderService",
-	HandlerType: (*OrderServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{MethodName: "CreateOrder", Handler: _OrderService_CreateOrder_Handler},
-		{MethodName: "GetOrderById", Handler: _OrderService_GetOrderById_Handler},
-		{MethodName: "GetOrdersByUserId", Handler: _OrderService_GetOrdersByUserId_Handler},
+func (c *orderServiceClient) UpdateShipmentStatus(ctx context.Context, in *UpdateShipmentStatusRequest, opts ...grpc.CallOption) (*ShipmentResponse, error) {
+	out := new(ShipmentResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/UpdateShipmentStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*InvoiceResponse, error) {
+	out := new(InvoiceResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/GetInvoice", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) Reorder(ctx context.Context, in *ReorderRequest, opts ...grpc.CallOption) (*ReorderResponse, error) {
+	out := new(ReorderResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/Reorder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SearchOrders(ctx context.Context, in *SearchOrdersRequest, opts ...grpc.CallOption) (*SearchOrdersResponse, error) {
+	out := new(SearchOrdersResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/SearchOrders", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ImportOrders(ctx context.Context, opts ...grpc.CallOption) (OrderService_ImportOrdersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[0], "/order.OrderService/ImportOrders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &orderServiceImportOrdersClient{stream}, nil
+}
+
+type OrderService_ImportOrdersClient interface {
+	Send(*ImportOrdersRequest) error
+	CloseAndRecv() (*ImportOrdersResponse, error)
+	grpc.ClientStream
+}
+
+type orderServiceImportOrdersClient struct {
+	grpc.ClientStream
+}
+
+func (x *orderServiceImportOrdersClient) Send(m *ImportOrdersRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *orderServiceImportOrdersClient) CloseAndRecv() (*ImportOrdersResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportOrdersResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *orderServiceClient) ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (OrderService_ExportOrdersClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[1], "/order.OrderService/ExportOrders", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &orderServiceExportOrdersClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OrderService_ExportOrdersClient interface {
+	Recv() (*ExportOrdersChunk, error)
+	grpc.ClientStream
+}
+
+type orderServiceExportOrdersClient struct {
+	grpc.ClientStream
+}
+
+func (x *orderServiceExportOrdersClient) Recv() (*ExportOrdersChunk, error) {
+	m := new(ExportOrdersChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *orderServiceClient) GetSalesReport(ctx context.Context, in *SalesReportRequest, opts ...grpc.CallOption) (*SalesReportResponse, error) {
+	out := new(SalesReportResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/GetSalesReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AmendOrder(ctx context.Context, in *AmendOrderRequest, opts ...grpc.CallOption) (*AmendOrderResponse, error) {
+	out := new(AmendOrderResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/AmendOrder", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrderByNumber(ctx context.Context, in *GetOrderByNumberRequest, opts ...grpc.CallOption) (*GetOrderByIdResponse, error) {
+	out := new(GetOrderByIdResponse)
+	err := c.cc.Invoke(ctx, "/order.OrderService/GetOrderByNumber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type OrderServiceServer interface {
+	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
+	GetOrderById(context.Context, *GetOrderByIdRequest) (*GetOrderByIdResponse, error)
+	GetOrdersByUserId(context.Context, *GetOrdersByUserIdRequest) (*GetOrdersByUserIdResponse, error)
+	ReassignOrders(context.Context, *ReassignOrdersRequest) (*ReassignOrdersResponse, error)
+	UpdateOrderStatus(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error)
+	RequestReturn(context.Context, *RequestReturnRequest) (*ReturnResponse, error)
+	ListOrderReturns(context.Context, *ListOrderReturnsRequest) (*ListOrderReturnsResponse, error)
+	UpdateReturnStatus(context.Context, *UpdateReturnStatusRequest) (*ReturnResponse, error)
+	RefundReturn(context.Context, *RefundReturnRequest) (*ReturnResponse, error)
+	CreateCoupon(context.Context, *CreateCouponRequest) (*CouponResponse, error)
+	UpdateCoupon(context.Context, *UpdateCouponRequest) (*CouponResponse, error)
+	ListCoupons(context.Context, *ListCouponsRequest) (*ListCouponsResponse, error)
+	GetTaxExemption(context.Context, *GetTaxExemptionRequest) (*TaxExemptionResponse, error)
+	SetTaxExemption(context.Context, *SetTaxExemptionRequest) (*TaxExemptionResponse, error)
+	RemoveTaxExemption(context.Context, *RemoveTaxExemptionRequest) (*RemoveTaxExemptionResponse, error)
+	CreateShipment(context.Context, *CreateShipmentRequest) (*ShipmentResponse, error)
+	ListOrderShipments(context.Context, *ListOrderShipmentsRequest) (*ListOrderShipmentsResponse, error)
+	UpdateShipmentStatus(context.Context, *UpdateShipmentStatusRequest) (*ShipmentResponse, error)
+	GetInvoice(context.Context, *GetInvoiceRequest) (*InvoiceResponse, error)
+	Reorder(context.Context, *ReorderRequest) (*ReorderResponse, error)
+	SearchOrders(context.Context, *SearchOrdersRequest) (*SearchOrdersResponse, error)
+	ImportOrders(OrderService_ImportOrdersServer) error
+	ExportOrders(*ExportOrdersRequest, OrderService_ExportOrdersServer) error
+	GetSalesReport(context.Context, *SalesReportRequest) (*SalesReportResponse, error)
+	AmendOrder(context.Context, *AmendOrderRequest) (*AmendOrderResponse, error)
+	GetOrderByNumber(context.Context, *GetOrderByNumberRequest) (*GetOrderByIdResponse, error)
+	mustEmbedUnimplementedOrderServiceServer()
+}
+
+type UnimplementedOrderServiceServer struct{}
+
+func (UnimplementedOrderServiceServer) CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrder not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetOrderById(context.Context, *GetOrderByIdRequest) (*GetOrderByIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderById not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetOrdersByUserId(context.Context, *GetOrdersByUserIdRequest) (*GetOrdersByUserIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrdersByUserId not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ReassignOrders(context.Context, *ReassignOrdersRequest) (*ReassignOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReassignOrders not implemented")
+}
+
+func (UnimplementedOrderServiceServer) UpdateOrderStatus(context.Context, *UpdateOrderStatusRequest) (*UpdateOrderStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrderStatus not implemented")
+}
+
+func (UnimplementedOrderServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetOrderHistory(context.Context, *GetOrderHistoryRequest) (*GetOrderHistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderHistory not implemented")
+}
+
+func (UnimplementedOrderServiceServer) RequestReturn(context.Context, *RequestReturnRequest) (*ReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestReturn not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ListOrderReturns(context.Context, *ListOrderReturnsRequest) (*ListOrderReturnsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrderReturns not implemented")
+}
+
+func (UnimplementedOrderServiceServer) UpdateReturnStatus(context.Context, *UpdateReturnStatusRequest) (*ReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReturnStatus not implemented")
+}
+
+func (UnimplementedOrderServiceServer) RefundReturn(context.Context, *RefundReturnRequest) (*ReturnResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefundReturn not implemented")
+}
+
+func (UnimplementedOrderServiceServer) CreateCoupon(context.Context, *CreateCouponRequest) (*CouponResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCoupon not implemented")
+}
+
+func (UnimplementedOrderServiceServer) UpdateCoupon(context.Context, *UpdateCouponRequest) (*CouponResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCoupon not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ListCoupons(context.Context, *ListCouponsRequest) (*ListCouponsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCoupons not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetTaxExemption(context.Context, *GetTaxExemptionRequest) (*TaxExemptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTaxExemption not implemented")
+}
+
+func (UnimplementedOrderServiceServer) SetTaxExemption(context.Context, *SetTaxExemptionRequest) (*TaxExemptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetTaxExemption not implemented")
+}
+
+func (UnimplementedOrderServiceServer) RemoveTaxExemption(context.Context, *RemoveTaxExemptionRequest) (*RemoveTaxExemptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTaxExemption not implemented")
+}
+
+func (UnimplementedOrderServiceServer) CreateShipment(context.Context, *CreateShipmentRequest) (*ShipmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateShipment not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ListOrderShipments(context.Context, *ListOrderShipmentsRequest) (*ListOrderShipmentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOrderShipments not implemented")
+}
+
+func (UnimplementedOrderServiceServer) UpdateShipmentStatus(context.Context, *UpdateShipmentStatusRequest) (*ShipmentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateShipmentStatus not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetInvoice(context.Context, *GetInvoiceRequest) (*InvoiceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInvoice not implemented")
+}
+
+func (UnimplementedOrderServiceServer) Reorder(context.Context, *ReorderRequest) (*ReorderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reorder not implemented")
+}
+
+func (UnimplementedOrderServiceServer) SearchOrders(context.Context, *SearchOrdersRequest) (*SearchOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchOrders not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ImportOrders(OrderService_ImportOrdersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportOrders not implemented")
+}
+
+func (UnimplementedOrderServiceServer) ExportOrders(*ExportOrdersRequest, OrderService_ExportOrdersServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportOrders not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetSalesReport(context.Context, *SalesReportRequest) (*SalesReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSalesReport not implemented")
+}
+
+func (UnimplementedOrderServiceServer) AmendOrder(context.Context, *AmendOrderRequest) (*AmendOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AmendOrder not implemented")
+}
+
+func (UnimplementedOrderServiceServer) GetOrderByNumber(context.Context, *GetOrderByNumberRequest) (*GetOrderByIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderByNumber not implemented")
+}
+
+func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
+
+func RegisterOrderServiceServer(s grpc.ServiceRegistrar, srv OrderServiceServer) {
+	s.RegisterService(&OrderService_ServiceDesc, srv)
+}
+
+func _OrderService_CreateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/CreateOrder"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateOrder(ctx, req.(*CreateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderById_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderByIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderById(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrderById"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderById(ctx, req.(*GetOrderByIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrdersByUserId_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrdersByUserIdRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrdersByUserId(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrdersByUserId"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrdersByUserId(ctx, req.(*GetOrdersByUserIdRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ReassignOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReassignOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ReassignOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/ReassignOrders"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ReassignOrders(ctx, req.(*ReassignOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateOrderStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOrderStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateOrderStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/UpdateOrderStatus"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateOrderStatus(ctx, req.(*UpdateOrderStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/CancelOrder"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrderHistory"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderHistory(ctx, req.(*GetOrderHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RequestReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RequestReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/RequestReturn"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RequestReturn(ctx, req.(*RequestReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListOrderReturns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrderReturnsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListOrderReturns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/ListOrderReturns"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListOrderReturns(ctx, req.(*ListOrderReturnsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateReturnStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReturnStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateReturnStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/UpdateReturnStatus"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateReturnStatus(ctx, req.(*UpdateReturnStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RefundReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RefundReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/RefundReturn"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RefundReturn(ctx, req.(*RefundReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/CreateCoupon"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateCoupon(ctx, req.(*CreateCouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateCoupon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCouponRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateCoupon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/UpdateCoupon"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateCoupon(ctx, req.(*UpdateCouponRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListCoupons_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCouponsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListCoupons(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/ListCoupons"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListCoupons(ctx, req.(*ListCouponsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetTaxExemption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaxExemptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetTaxExemption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetTaxExemption"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetTaxExemption(ctx, req.(*GetTaxExemptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SetTaxExemption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTaxExemptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetTaxExemption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/SetTaxExemption"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetTaxExemption(ctx, req.(*SetTaxExemptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RemoveTaxExemption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTaxExemptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RemoveTaxExemption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/RemoveTaxExemption"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RemoveTaxExemption(ctx, req.(*RemoveTaxExemptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateShipment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateShipmentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateShipment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/CreateShipment"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateShipment(ctx, req.(*CreateShipmentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListOrderShipments_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrderShipmentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListOrderShipments(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/ListOrderShipments"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListOrderShipments(ctx, req.(*ListOrderShipmentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateShipmentStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateShipmentStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateShipmentStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/UpdateShipmentStatus"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateShipmentStatus(ctx, req.(*UpdateShipmentStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetInvoice"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetInvoice(ctx, req.(*GetInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_Reorder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReorderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).Reorder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/Reorder"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).Reorder(ctx, req.(*ReorderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SearchOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SearchOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/SearchOrders"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SearchOrders(ctx, req.(*SearchOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ImportOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(OrderServiceServer).ImportOrders(&orderServiceImportOrdersServer{stream})
+}
+
+type OrderService_ImportOrdersServer interface {
+	SendAndClose(*ImportOrdersResponse) error
+	Recv() (*ImportOrdersRequest, error)
+	grpc.ServerStream
+}
+
+type orderServiceImportOrdersServer struct {
+	grpc.ServerStream
+}
+
+func (x *orderServiceImportOrdersServer) SendAndClose(m *ImportOrdersResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *orderServiceImportOrdersServer) Recv() (*ImportOrdersRequest, error) {
+	m := new(ImportOrdersRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _OrderService_ExportOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServiceServer).ExportOrders(m, &orderServiceExportOrdersServer{stream})
+}
+
+type OrderService_ExportOrdersServer interface {
+	Send(*ExportOrdersChunk) error
+	grpc.ServerStream
+}
+
+type orderServiceExportOrdersServer struct {
+	grpc.ServerStream
+}
+
+func (x *orderServiceExportOrdersServer) Send(m *ExportOrdersChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _OrderService_GetSalesReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SalesReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetSalesReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetSalesReport"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetSalesReport(ctx, req.(*SalesReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AmendOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AmendOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AmendOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/AmendOrder"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AmendOrder(ctx, req.(*AmendOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderByNumber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderByNumberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderByNumber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/order.OrderService/GetOrderByNumber"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderByNumber(ctx, req.(*GetOrderByNumberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var OrderService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "order.OrderService",
+	HandlerType: (*OrderServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "CreateOrder", Handler: _OrderService_CreateOrder_Handler},
+		{MethodName: "GetOrderById", Handler: _OrderService_GetOrderById_Handler},
+		{MethodName: "GetOrdersByUserId", Handler: _OrderService_GetOrdersByUserId_Handler},
+		{MethodName: "ReassignOrders", Handler: _OrderService_ReassignOrders_Handler},
+		{MethodName: "UpdateOrderStatus", Handler: _OrderService_UpdateOrderStatus_Handler},
+		{MethodName: "CancelOrder", Handler: _OrderService_CancelOrder_Handler},
+		{MethodName: "GetOrderHistory", Handler: _OrderService_GetOrderHistory_Handler},
+		{MethodName: "RequestReturn", Handler: _OrderService_RequestReturn_Handler},
+		{MethodName: "ListOrderReturns", Handler: _OrderService_ListOrderReturns_Handler},
+		{MethodName: "UpdateReturnStatus", Handler: _OrderService_UpdateReturnStatus_Handler},
+		{MethodName: "RefundReturn", Handler: _OrderService_RefundReturn_Handler},
+		{MethodName: "CreateCoupon", Handler: _OrderService_CreateCoupon_Handler},
+		{MethodName: "UpdateCoupon", Handler: _OrderService_UpdateCoupon_Handler},
+		{MethodName: "ListCoupons", Handler: _OrderService_ListCoupons_Handler},
+		{MethodName: "GetTaxExemption", Handler: _OrderService_GetTaxExemption_Handler},
+		{MethodName: "SetTaxExemption", Handler: _OrderService_SetTaxExemption_Handler},
+		{MethodName: "RemoveTaxExemption", Handler: _OrderService_RemoveTaxExemption_Handler},
+		{MethodName: "CreateShipment", Handler: _OrderService_CreateShipment_Handler},
+		{MethodName: "ListOrderShipments", Handler: _OrderService_ListOrderShipments_Handler},
+		{MethodName: "UpdateShipmentStatus", Handler: _OrderService_UpdateShipmentStatus_Handler},
+		{MethodName: "GetInvoice", Handler: _OrderService_GetInvoice_Handler},
+		{MethodName: "Reorder", Handler: _OrderService_Reorder_Handler},
+		{MethodName: "SearchOrders", Handler: _OrderService_SearchOrders_Handler},
+		{MethodName: "GetSalesReport", Handler: _OrderService_GetSalesReport_Handler},
+		{MethodName: "AmendOrder", Handler: _OrderService_AmendOrder_Handler},
+		{MethodName: "GetOrderByNumber", Handler: _OrderService_GetOrderByNumber_Handler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "ImportOrders", Handler: _OrderService_ImportOrders_Handler, ClientStreams: true},
+		{StreamName: "ExportOrders", Handler: _OrderService_ExportOrders_Handler, ServerStreams: true},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "order.proto",
 }
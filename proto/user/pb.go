@@ -9,15 +9,19 @@ import (
 )
 
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
+	Email                string `json:"email"`
+	Password             string `json:"password"`
+	Name                 string `json:"name"`
+	AcceptedTermsVersion string `json:"accepted_terms_version"`
+	IpAddress            string `json:"ip_address"`
+	UserAgent            string `json:"user_agent"`
 }
 
 type UserData struct {
 	Id        string `json:"id"`
 	Email     string `json:"email"`
 	Name      string `json:"name"`
+	IsActive  bool   `json:"is_active"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
@@ -44,10 +48,90 @@ type GetUserByIdResponse struct {
 	User *UserData `json:"user"`
 }
 
+type GetUserByEmailRequest struct {
+	Email string `json:"email"`
+}
+
+type UserMergeData struct {
+	Id            string   `json:"id"`
+	SourceUserId  string   `json:"source_user_id"`
+	TargetUserId  string   `json:"target_user_id"`
+	MovedOrderIds []string `json:"moved_order_ids"`
+	Status        string   `json:"status"`
+	CreatedAt     string   `json:"created_at"`
+	RevertedAt    string   `json:"reverted_at,omitempty"`
+}
+
+type MergeUsersRequest struct {
+	SourceId string `json:"source_id"`
+	TargetId string `json:"target_id"`
+	DryRun   bool   `json:"dry_run"`
+}
+
+type MergeUsersResponse struct {
+	DryRun  bool           `json:"dry_run"`
+	Merge   *UserMergeData `json:"merge"`
+	Changes []string       `json:"changes"`
+}
+
+type RevertUserMergeRequest struct {
+	MergeId string `json:"merge_id"`
+}
+
+type RevertUserMergeResponse struct {
+	Merge *UserMergeData `json:"merge"`
+}
+
+type ConsentRecordData struct {
+	Id            string `json:"id"`
+	UserId        string `json:"user_id"`
+	Purpose       string `json:"purpose"`
+	Granted       bool   `json:"granted"`
+	PolicyVersion string `json:"policy_version"`
+	Source        string `json:"source"`
+	IpAddress     string `json:"ip_address,omitempty"`
+	UserAgent     string `json:"user_agent,omitempty"`
+	RecordedAt    string `json:"recorded_at"`
+}
+
+type RecordConsentRequest struct {
+	UserId        string `json:"user_id"`
+	Purpose       string `json:"purpose"`
+	Granted       bool   `json:"granted"`
+	PolicyVersion string `json:"policy_version"`
+	Source        string `json:"source"`
+	IpAddress     string `json:"ip_address"`
+	UserAgent     string `json:"user_agent"`
+	ActorId       string `json:"actor_id"`
+	ActorIsAdmin  bool   `json:"actor_is_admin"`
+}
+
+type RecordConsentResponse struct {
+	Consent *ConsentRecordData `json:"consent"`
+}
+
+type GetConsentStateRequest struct {
+	UserId         string `json:"user_id"`
+	IncludeHistory bool   `json:"include_history"`
+	ActorId        string `json:"actor_id"`
+	ActorIsAdmin   bool   `json:"actor_is_admin"`
+}
+
+type GetConsentStateResponse struct {
+	CurrentTermsVersion string               `json:"current_terms_version"`
+	Current             []*ConsentRecordData `json:"current"`
+	History             []*ConsentRecordData `json:"history,omitempty"`
+}
+
 type UserServiceClient interface {
 	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	GetUserById(ctx context.Context, in *GetUserByIdRequest, opts ...grpc.CallOption) (*GetUserByIdResponse, error)
+	MergeUsers(ctx context.Context, in *MergeUsersRequest, opts ...grpc.CallOption) (*MergeUsersResponse, error)
+	RevertUserMerge(ctx context.Context, in *RevertUserMergeRequest, opts ...grpc.CallOption) (*RevertUserMergeResponse, error)
+	RecordConsent(ctx context.Context, in *RecordConsentRequest, opts ...grpc.CallOption) (*RecordConsentResponse, error)
+	GetConsentState(ctx context.Context, in *GetConsentStateRequest, opts ...grpc.CallOption) (*GetConsentStateResponse, error)
+	GetUserByEmail(ctx context.Context, in *GetUserByEmailRequest, opts ...grpc.CallOption) (*GetUserByIdResponse, error)
 }
 
 type userServiceClient struct {
@@ -85,10 +169,60 @@ func (c *userServiceClient) GetUserById(ctx context.Context, in *GetUserByIdRequ
 	return out, nil
 }
 
+func (c *userServiceClient) MergeUsers(ctx context.Context, in *MergeUsersRequest, opts ...grpc.CallOption) (*MergeUsersResponse, error) {
+	out := new(MergeUsersResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/MergeUsers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RevertUserMerge(ctx context.Context, in *RevertUserMergeRequest, opts ...grpc.CallOption) (*RevertUserMergeResponse, error) {
+	out := new(RevertUserMergeResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/RevertUserMerge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) RecordConsent(ctx context.Context, in *RecordConsentRequest, opts ...grpc.CallOption) (*RecordConsentResponse, error) {
+	out := new(RecordConsentResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/RecordConsent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetConsentState(ctx context.Context, in *GetConsentStateRequest, opts ...grpc.CallOption) (*GetConsentStateResponse, error) {
+	out := new(GetConsentStateResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/GetConsentState", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetUserByEmail(ctx context.Context, in *GetUserByEmailRequest, opts ...grpc.CallOption) (*GetUserByIdResponse, error) {
+	out := new(GetUserByIdResponse)
+	err := c.cc.Invoke(ctx, "/user.UserService/GetUserByEmail", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 type UserServiceServer interface {
 	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	GetUserById(context.Context, *GetUserByIdRequest) (*GetUserByIdResponse, error)
+	MergeUsers(context.Context, *MergeUsersRequest) (*MergeUsersResponse, error)
+	RevertUserMerge(context.Context, *RevertUserMergeRequest) (*RevertUserMergeResponse, error)
+	RecordConsent(context.Context, *RecordConsentRequest) (*RecordConsentResponse, error)
+	GetConsentState(context.Context, *GetConsentStateRequest) (*GetConsentStateResponse, error)
+	GetUserByEmail(context.Context, *GetUserByEmailRequest) (*GetUserByIdResponse, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -106,6 +240,26 @@ func (UnimplementedUserServiceServer) GetUserById(context.Context, *GetUserByIdR
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserById not implemented")
 }
 
+func (UnimplementedUserServiceServer) MergeUsers(context.Context, *MergeUsersRequest) (*MergeUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeUsers not implemented")
+}
+
+func (UnimplementedUserServiceServer) RevertUserMerge(context.Context, *RevertUserMergeRequest) (*RevertUserMergeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevertUserMerge not implemented")
+}
+
+func (UnimplementedUserServiceServer) RecordConsent(context.Context, *RecordConsentRequest) (*RecordConsentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecordConsent not implemented")
+}
+
+func (UnimplementedUserServiceServer) GetConsentState(context.Context, *GetConsentStateRequest) (*GetConsentStateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsentState not implemented")
+}
+
+func (UnimplementedUserServiceServer) GetUserByEmail(context.Context, *GetUserByEmailRequest) (*GetUserByIdResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserByEmail not implemented")
+}
+
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer) {
@@ -157,6 +311,81 @@ func _UserService_GetUserById_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_MergeUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).MergeUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/user.UserService/MergeUsers"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).MergeUsers(ctx, req.(*MergeUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RevertUserMerge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertUserMergeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RevertUserMerge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/user.UserService/RevertUserMerge"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RevertUserMerge(ctx, req.(*RevertUserMergeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_RecordConsent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecordConsentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).RecordConsent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/user.UserService/RecordConsent"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).RecordConsent(ctx, req.(*RecordConsentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetConsentState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsentStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetConsentState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/user.UserService/GetConsentState"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetConsentState(ctx, req.(*GetConsentStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetUserByEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserByEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUserByEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/user.UserService/GetUserByEmail"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUserByEmail(ctx, req.(*GetUserByEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var UserService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "user.UserService",
 	HandlerType: (*UserServiceServer)(nil),
@@ -164,6 +393,11 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 		{MethodName: "Register", Handler: _UserService_Register_Handler},
 		{MethodName: "Login", Handler: _UserService_Login_Handler},
 		{MethodName: "GetUserById", Handler: _UserService_GetUserById_Handler},
+		{MethodName: "MergeUsers", Handler: _UserService_MergeUsers_Handler},
+		{MethodName: "RevertUserMerge", Handler: _UserService_RevertUserMerge_Handler},
+		{MethodName: "RecordConsent", Handler: _UserService_RecordConsent_Handler},
+		{MethodName: "GetConsentState", Handler: _UserService_GetConsentState_Handler},
+		{MethodName: "GetUserByEmail", Handler: _UserService_GetUserByEmail_Handler},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "user.proto",
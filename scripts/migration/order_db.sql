@@ -13,3 +13,483 @@ CREATE TABLE IF NOT EXISTS orders (
 
 CREATE INDEX IF NOT EXISTS idx_orders_user_id ON orders(user_id);
 CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancellation_reason VARCHAR(50);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_by VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    product_id VARCHAR(64),
+    sku VARCHAR(64),
+    name VARCHAR(255) NOT NULL,
+    unit_price NUMERIC(12,2) NOT NULL CHECK (unit_price > 0),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    line_total NUMERIC(12,2) NOT NULL CHECK (line_total > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
+
+INSERT INTO order_items (id, order_id, position, name, unit_price, quantity, line_total)
+SELECT gen_random_uuid(), o.id, 0, o.product_name, GREATEST(ROUND(o.total_price / o.quantity, 2), 0.01), o.quantity, o.total_price
+FROM orders o
+WHERE NOT EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = o.id);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal NUMERIC(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total NUMERIC(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total NUMERIC(12,2) NOT NULL DEFAULT 0;
+UPDATE orders SET subtotal = total_price WHERE subtotal = 0;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id VARCHAR(64) PRIMARY KEY,
+    sku VARCHAR(64) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    unit_price NUMERIC(12,2) NOT NULL CHECK (unit_price > 0),
+    sale_price NUMERIC(12,2) CHECK (sale_price > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Exact money: amounts in minor units plus an ISO 4217 currency. The numeric
+-- columns above are kept in sync for existing readers. Legacy rows are IDR,
+-- which has two minor digits.
+ALTER TABLE product_prices ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS subtotal_amount BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_amount BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_amount BIGINT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS total_amount BIGINT;
+UPDATE orders SET
+    subtotal_amount = ROUND(subtotal * 100)::BIGINT,
+    discount_amount = ROUND(discount_total * 100)::BIGINT,
+    tax_amount = ROUND(tax_total * 100)::BIGINT,
+    total_amount = ROUND(total_price * 100)::BIGINT
+WHERE total_amount IS NULL;
+ALTER TABLE orders ALTER COLUMN subtotal_amount SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN subtotal_amount SET DEFAULT 0;
+ALTER TABLE orders ALTER COLUMN discount_amount SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN discount_amount SET DEFAULT 0;
+ALTER TABLE orders ALTER COLUMN tax_amount SET NOT NULL;
+ALTER TABLE orders ALTER COLUMN tax_amount SET DEFAULT 0;
+ALTER TABLE orders ALTER COLUMN total_amount SET NOT NULL;
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS unit_amount BIGINT;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS discount_amount BIGINT;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS line_amount BIGINT;
+UPDATE order_items SET
+    unit_amount = ROUND(unit_price * 100)::BIGINT,
+    discount_amount = ROUND(discount * 100)::BIGINT,
+    line_amount = ROUND(line_total * 100)::BIGINT
+WHERE line_amount IS NULL;
+ALTER TABLE order_items ALTER COLUMN unit_amount SET NOT NULL;
+ALTER TABLE order_items ALTER COLUMN discount_amount SET NOT NULL;
+ALTER TABLE order_items ALTER COLUMN discount_amount SET DEFAULT 0;
+ALTER TABLE order_items ALTER COLUMN line_amount SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_orders_user_created ON orders(user_id, created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_user_total ON orders(user_id, total_amount, id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(100) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    response BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns
+        WHERE table_name = 'orders' AND column_name = 'user_verified_at'
+    ) THEN
+        ALTER TABLE orders ADD COLUMN user_verified_at TIMESTAMP;
+        UPDATE orders SET user_verified_at = created_at;
+    END IF;
+END $$;
+CREATE INDEX IF NOT EXISTS idx_orders_user_unverified ON orders(created_at) WHERE user_verified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    actor VARCHAR(64) NOT NULL,
+    reason VARCHAR(255),
+    request_id VARCHAR(64),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id, created_at);
+
+-- Orders created before the history table only get their creation and, when
+-- known, their cancellation; intermediate changes were never recorded.
+INSERT INTO order_status_history (id, order_id, from_status, to_status, actor, created_at)
+SELECT gen_random_uuid(), o.id, NULL, 'pending', o.user_id::TEXT, o.created_at
+FROM orders o
+WHERE NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_id = o.id);
+
+INSERT INTO order_status_history (id, order_id, from_status, to_status, actor, reason, created_at)
+SELECT gen_random_uuid(), o.id, NULL, 'cancelled', o.cancelled_by, o.cancellation_reason, o.cancelled_at
+FROM orders o
+WHERE o.cancelled_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM order_status_history h WHERE h.order_id = o.id AND h.to_status = 'cancelled');
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS refunded_amount BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS order_returns (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    note TEXT,
+    currency CHAR(3) NOT NULL,
+    requested_amount BIGINT NOT NULL,
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    refund_reference VARCHAR(100),
+    requested_by VARCHAR(64) NOT NULL,
+    decided_by VARCHAR(64),
+    decision_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    received_at TIMESTAMP,
+    refunded_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_returns_order_id ON order_returns(order_id, created_at);
+
+CREATE TABLE IF NOT EXISTS order_return_items (
+    id UUID PRIMARY KEY,
+    return_id UUID NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES order_items(id),
+    name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_return_items_return_id ON order_return_items(return_id);
+CREATE INDEX IF NOT EXISTS idx_order_return_items_order_item_id ON order_return_items(order_item_id);
+
+CREATE TABLE IF NOT EXISTS coupons (
+    code VARCHAR(64) PRIMARY KEY,
+    description VARCHAR(255),
+    kind VARCHAR(20) NOT NULL,
+    percent_off_bps BIGINT NOT NULL DEFAULT 0,
+    currency CHAR(3) NOT NULL,
+    amount_off BIGINT NOT NULL DEFAULT 0,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    min_subtotal BIGINT NOT NULL DEFAULT 0,
+    starts_at TIMESTAMP,
+    ends_at TIMESTAMP,
+    max_redemptions INT NOT NULL DEFAULT 0,
+    max_per_user INT NOT NULL DEFAULT 0,
+    redemptions INT NOT NULL DEFAULT 0,
+    eligible_products JSONB NOT NULL DEFAULT '[]',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS coupon_redemptions (
+    id UUID PRIMARY KEY,
+    coupon_code VARCHAR(64) NOT NULL REFERENCES coupons(code),
+    user_id UUID NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_coupon_redemptions_coupon_user ON coupon_redemptions(coupon_code, user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_coupon_redemptions_order ON coupon_redemptions(order_id, coupon_code);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_code VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS free_shipping BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A 100% coupon, or a fixed one worth the whole eligible amount, brings a line or an order to zero.
+ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_total_price_check;
+ALTER TABLE orders ADD CONSTRAINT orders_total_price_check CHECK (total_price >= 0);
+ALTER TABLE order_items DROP CONSTRAINT IF EXISTS order_items_line_total_check;
+ALTER TABLE order_items ADD CONSTRAINT order_items_line_total_check CHECK (line_total >= 0);
+
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES order_items(id),
+    position INT NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    code VARCHAR(64),
+    kind VARCHAR(20),
+    amount BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order_id ON order_discounts(order_id);
+
+ALTER TABLE product_prices ADD COLUMN IF NOT EXISTS tax_class VARCHAR(50) NOT NULL DEFAULT 'standard';
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_class VARCHAR(50);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_rules_version VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_exempt BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_exempt_amount BIGINT NOT NULL DEFAULT 0;
+
+-- Rule sets are never edited in place: publish a new version with a later
+-- effective_from. rules uses the TAX_RULES_FILE entry format.
+CREATE TABLE IF NOT EXISTS tax_rule_sets (
+    version VARCHAR(64) PRIMARY KEY,
+    effective_from TIMESTAMP NOT NULL,
+    rules JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_rule_sets_effective_from ON tax_rule_sets(effective_from);
+
+INSERT INTO tax_rule_sets (version, effective_from, rules)
+VALUES ('2025-01', '2025-01-01T00:00:00Z', '{"prices_include_tax": false, "rounding": "per_line", "default_country": "ID", "rates": [{"country": "ID", "class": "standard", "name": "PPN", "rate": "0.11"}, {"country": "ID", "class": "exempt", "name": "PPN exempt", "rate": "0"}]}')
+ON CONFLICT (version) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS tax_exemptions (
+    user_id UUID PRIMARY KEY,
+    reason VARCHAR(255) NOT NULL,
+    certificate VARCHAR(100),
+    created_by VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS order_tax_lines (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id UUID REFERENCES order_items(id),
+    position INT NOT NULL,
+    country VARCHAR(2) NOT NULL,
+    region VARCHAR(10),
+    tax_class VARCHAR(50) NOT NULL,
+    name VARCHAR(100),
+    rate BIGINT NOT NULL,
+    taxable_amount BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    exempt BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_tax_lines_order_id ON order_tax_lines(order_id);
+
+ALTER TABLE product_prices ADD COLUMN IF NOT EXISTS weight_grams BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS weight_grams BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_carrier VARCHAR(64);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_zone VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_amount BIGINT NOT NULL DEFAULT 0;
+
+-- zones uses the SHIPPING_METHODS_FILE entry format, with amounts in major units.
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    code VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    carrier VARCHAR(64) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    zones JSONB NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO shipping_methods (code, name, carrier, currency, zones)
+VALUES
+    ('regular', 'Regular (3-5 days)', 'fake', 'IDR', '[{"name": "indonesia", "countries": ["ID"], "kind": "flat", "amount": "15000", "free_above": "500000"}, {"name": "international", "countries": ["*"], "kind": "weight", "amount": "150000", "per_kg": "90000"}]'),
+    ('express', 'Express (next day)', 'fake', 'IDR', '[{"name": "indonesia", "countries": ["ID"], "kind": "weight", "amount": "25000", "per_kg": "10000"}]')
+ON CONFLICT (code) DO NOTHING;
+
+-- A failed shipment was lost or refused; its items no longer count as shipped.
+CREATE TABLE IF NOT EXISTS shipments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    carrier VARCHAR(64) NOT NULL,
+    tracking_number VARCHAR(100) NOT NULL,
+    tracking_url VARCHAR(500),
+    status VARCHAR(50) NOT NULL,
+    weight_grams BIGINT NOT NULL DEFAULT 0,
+    created_by VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipments_order_id ON shipments(order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_shipments_carrier_tracking ON shipments(carrier, tracking_number);
+
+CREATE TABLE IF NOT EXISTS shipment_items (
+    id UUID PRIMARY KEY,
+    shipment_id UUID NOT NULL REFERENCES shipments(id) ON DELETE CASCADE,
+    order_item_id UUID NOT NULL REFERENCES order_items(id),
+    name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipment_items_shipment_id ON shipment_items(shipment_id);
+CREATE INDEX IF NOT EXISTS idx_shipment_items_order_item_id ON shipment_items(order_item_id);
+
+-- Last number handed out per series (INV, CN) and year. Bumped in the same
+-- transaction that stores the document, so numbers never have gaps.
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    series VARCHAR(10) NOT NULL,
+    year INT NOT NULL,
+    last_number BIGINT NOT NULL,
+    PRIMARY KEY (series, year)
+);
+
+-- Invoices and credit notes are kept for as long as the law requires, so they
+-- are not deleted with their order.
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    number VARCHAR(32) NOT NULL,
+    year INT NOT NULL,
+    sequence BIGINT NOT NULL,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    return_id UUID REFERENCES order_returns(id),
+    invoice_id UUID REFERENCES invoices(id),
+    currency CHAR(3) NOT NULL,
+    total_amount BIGINT NOT NULL,
+    tax_amount BIGINT NOT NULL,
+    document JSONB NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_number ON invoices(number);
+CREATE INDEX IF NOT EXISTS idx_invoices_order_id ON invoices(order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_order_invoice ON invoices(order_id) WHERE kind = 'invoice';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_return_id ON invoices(return_id) WHERE return_id IS NOT NULL;
+
+-- Unpaid orders are expired ORDER_PAYMENT_TTL after creation; the expiry worker scans this index.
+CREATE INDEX IF NOT EXISTS idx_orders_unpaid_created_at ON orders(created_at)
+    WHERE status IN ('pending', 'awaiting_payment');
+
+-- Admin order search across all users. Product names are matched with ILIKE '%...%', which the
+-- trigram indexes serve. Order numbers are matched exactly on orders.number (see below).
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_orders_created ON orders(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_orders_total ON orders(total_amount, id);
+CREATE INDEX IF NOT EXISTS idx_orders_status_created ON orders(status, created_at, id);
+DROP INDEX IF EXISTS idx_orders_id_text;
+CREATE INDEX IF NOT EXISTS idx_orders_product_name_trgm ON orders USING gin (product_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_order_items_name_trgm ON order_items USING gin (name gin_trgm_ops);
+
+-- Bulk order imports. committed_row is the last line of the file stored or rejected, advanced in
+-- the same transaction as each batch of orders, so a resumed import skips exactly those lines.
+CREATE TABLE IF NOT EXISTS order_imports (
+    id UUID PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,
+    source VARCHAR(255) NOT NULL DEFAULT '',
+    actor_id VARCHAR(64) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    committed_row BIGINT NOT NULL DEFAULT 0,
+    rows_imported BIGINT NOT NULL DEFAULT 0,
+    rows_failed BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Multi-currency orders. Rates are what one unit of currency is worth in base_currency, the store
+-- currency; publish a new row with a later effective_from instead of editing an old one. Orders keep
+-- the rate they were priced at, and reports convert with it.
+CREATE TABLE IF NOT EXISTS currencies (
+    code CHAR(3) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    currency CHAR(3) NOT NULL REFERENCES currencies(code),
+    effective_from TIMESTAMP NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    source VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, currency, effective_from)
+);
+
+INSERT INTO currencies (code, name)
+VALUES ('USD', 'US Dollar'), ('SGD', 'Singapore Dollar'), ('EUR', 'Euro')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO exchange_rates (base_currency, currency, effective_from, rate, source)
+VALUES
+    ('IDR', 'USD', '2026-01-01T00:00:00Z', 16250, 'seed'),
+    ('IDR', 'SGD', '2026-01-01T00:00:00Z', 12600, 'seed'),
+    ('IDR', 'EUR', '2026-01-01T00:00:00Z', 17800, 'seed')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS base_currency CHAR(3);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_rate NUMERIC(20,10) NOT NULL DEFAULT 1;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_rate_from TIMESTAMP;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS base_total_amount BIGINT NOT NULL DEFAULT 0;
+UPDATE orders SET base_currency = currency, base_total_amount = total_amount WHERE base_currency IS NULL;
+ALTER TABLE orders ALTER COLUMN base_currency SET NOT NULL;
+
+-- Totals are filtered and sorted in the store currency.
+DROP INDEX IF EXISTS idx_orders_total;
+CREATE INDEX IF NOT EXISTS idx_orders_base_total ON orders(base_total_amount, id);
+CREATE INDEX IF NOT EXISTS idx_orders_sales ON orders(base_currency, created_at) WHERE status IN ('paid', 'fulfilling', 'shipped', 'delivered', 'refunded');
+
+-- Amendments are recorded in the history with what they changed.
+ALTER TABLE order_status_history ADD COLUMN IF NOT EXISTS changes JSONB;
+
+-- Order numbers. Existing orders are numbered in the default
+-- ORD-{year}-{seq:6} format for the "main" store, and the counters continue
+-- from there.
+CREATE TABLE IF NOT EXISTS order_number_sequences (
+    store VARCHAR(64) NOT NULL,
+    period VARCHAR(16) NOT NULL,
+    last_value BIGINT NOT NULL,
+    PRIMARY KEY (store, period)
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS number VARCHAR(64);
+
+WITH numbered AS (
+    SELECT id,
+           EXTRACT(YEAR FROM created_at)::int AS year,
+           row_number() OVER (PARTITION BY EXTRACT(YEAR FROM created_at) ORDER BY created_at, id) AS seq
+    FROM orders
+    WHERE number IS NULL
+)
+UPDATE orders o
+SET number = 'ORD-' || n.year || '-' || lpad(n.seq::text, 6, '0')
+FROM numbered n
+WHERE o.id = n.id
+  AND NOT EXISTS (SELECT 1 FROM order_number_sequences WHERE store = 'MAIN');
+
+INSERT INTO order_number_sequences (store, period, last_value)
+SELECT 'MAIN', EXTRACT(YEAR FROM created_at)::int::text, COUNT(*)
+FROM orders
+WHERE number LIKE 'ORD-%'
+GROUP BY EXTRACT(YEAR FROM created_at)
+ON CONFLICT DO NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_number ON orders(number) WHERE number IS NOT NULL;
+
+-- Amending a paid order replaces its invoice: a credit note cancels the old one and a new invoice
+-- is issued, both with invoice_id pointing at the old one. Each invoice is replaced only once.
+DROP INDEX IF EXISTS idx_invoices_order_invoice;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_order_first_invoice ON invoices(order_id) WHERE kind = 'invoice' AND invoice_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_replaced ON invoices(invoice_id, kind) WHERE return_id IS NULL;
+
+-- The coupon terms an order was placed with, which amending it applies again. Orders placed
+-- before this column existed keep it NULL and are amended with the coupon as it is now.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS coupon_terms JSONB;
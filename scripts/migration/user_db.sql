@@ -10,3 +10,44 @@ CREATE TABLE IF NOT EXISTS users (
 );
 
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS user_merges (
+    id UUID PRIMARY KEY,
+    source_user_id UUID NOT NULL REFERENCES users(id),
+    target_user_id UUID NOT NULL REFERENCES users(id),
+    moved_order_ids JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    reverted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_merges_source_user_id ON user_merges(source_user_id);
+CREATE INDEX IF NOT EXISTS idx_user_merges_target_user_id ON user_merges(target_user_id);
+
+CREATE TABLE IF NOT EXISTS consent_records (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    purpose VARCHAR(50) NOT NULL,
+    granted BOOLEAN NOT NULL,
+    policy_version VARCHAR(50) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent VARCHAR(512),
+    recorded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_consent_records_user_purpose ON consent_records(user_id, purpose, recorded_at DESC);
+
+CREATE OR REPLACE FUNCTION consent_records_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'consent_records is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_consent_records_append_only ON consent_records;
+CREATE TRIGGER trg_consent_records_append_only
+    BEFORE UPDATE OR DELETE ON consent_records
+    FOR EACH ROW EXECUTE FUNCTION consent_records_append_only();
@@ -21,6 +21,7 @@ export USER_DB_PORT="${USER_DB_PORT:-5432}"
 export USER_DB_USER="${USER_DB_USER:-postgres}"
 export USER_DB_PASSWORD="${USER_DB_PASSWORD:-postgres}"
 export USER_DB_NAME="${USER_DB_NAME:-user_db}"
+export TERMS_VERSION="${TERMS_VERSION:-2026-01}"
 
 export ORDER_SERVICE_GRPC_PORT="${ORDER_SERVICE_GRPC_PORT:-50052}"
 export ORDER_DB_HOST="${ORDER_DB_HOST:-localhost}"
@@ -28,10 +29,40 @@ export ORDER_DB_PORT="${ORDER_DB_PORT:-5433}"
 export ORDER_DB_USER="${ORDER_DB_USER:-postgres}"
 export ORDER_DB_PASSWORD="${ORDER_DB_PASSWORD:-postgres}"
 export ORDER_DB_NAME="${ORDER_DB_NAME:-order_db}"
+export PRICE_SOURCE="${PRICE_SOURCE:-postgres}"
+export PRICE_FILE="${PRICE_FILE:-$ROOT_DIR/scripts/dev/prices.json}"
+export ORDER_TAX_RATE="${ORDER_TAX_RATE:-0}"
+export TAX_SOURCE="${TAX_SOURCE:-flat}"
+export TAX_RULES_FILE="${TAX_RULES_FILE:-$ROOT_DIR/scripts/dev/tax_rules.json}"
+export STORE_CURRENCY="${STORE_CURRENCY:-IDR}"
+export IDEMPOTENCY_KEY_TTL="${IDEMPOTENCY_KEY_TTL:-24h}"
+export USER_CHECK_MODE="${USER_CHECK_MODE:-fail_closed}"
+export USER_CHECK_TIMEOUT="${USER_CHECK_TIMEOUT:-2s}"
+export USER_CACHE_TTL="${USER_CACHE_TTL:-30s}"
+export USER_VERIFY_INTERVAL="${USER_VERIFY_INTERVAL:-1m}"
+export ORDER_PAYMENT_TTL="${ORDER_PAYMENT_TTL:-30m}"
+export ORDER_EXPIRY_INTERVAL="${ORDER_EXPIRY_INTERVAL:-1m}"
+export ORDER_EXPIRY_BATCH="${ORDER_EXPIRY_BATCH:-100}"
+export ORDER_METRICS_ADDR="${ORDER_METRICS_ADDR-:9102}"
+export ORDER_IMPORT_BATCH="${ORDER_IMPORT_BATCH:-500}"
+export RETURN_WINDOW="${RETURN_WINDOW:-720h}"
+export REFUND_GATEWAY="${REFUND_GATEWAY:-fake}"
+export SHIPPING_SOURCE="${SHIPPING_SOURCE:-postgres}"
+export SHIPPING_METHODS_FILE="${SHIPPING_METHODS_FILE:-$ROOT_DIR/scripts/dev/shipping_methods.json}"
+export SHIPPING_CARRIER="${SHIPPING_CARRIER:-fake}"
+export EXCHANGE_RATE_SOURCE="${EXCHANGE_RATE_SOURCE:-postgres}"
+export EXCHANGE_RATES_FILE="${EXCHANGE_RATES_FILE:-$ROOT_DIR/scripts/dev/exchange_rates.json}"
+export ORDER_NUMBER_FORMAT="${ORDER_NUMBER_FORMAT:-ORD-{year\}-{seq:6\}}"
+export ORDER_NUMBER_STORE="${ORDER_NUMBER_STORE:-main}"
+export INVOICE_SELLER_NAME="${INVOICE_SELLER_NAME:-Online Store}"
+export INVOICE_SELLER_ADDRESS="${INVOICE_SELLER_ADDRESS:-}"
+export INVOICE_SELLER_TAX_ID="${INVOICE_SELLER_TAX_ID:-}"
+export INVOICE_SELLER_EMAIL="${INVOICE_SELLER_EMAIL:-}"
 
 export API_GATEWAY_PORT="${API_GATEWAY_PORT:-8080}"
 export USER_SERVICE_URL="${USER_SERVICE_URL:-localhost:50051}"
 export ORDER_SERVICE_URL="${ORDER_SERVICE_URL:-localhost:50052}"
+export ADMIN_API_KEY="${ADMIN_API_KEY:-}"
 
 "$ROOT_DIR/bin/user-service" &
 PID_USER=$!
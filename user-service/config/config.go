@@ -8,12 +8,14 @@ import (
 )
 
 type Config struct {
-	GRPCPort   string
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
+	GRPCPort        string
+	DBHost          string
+	DBPort          string
+	DBUser          string
+	DBPassword      string
+	DBName          string
+	OrderServiceURL string
+	TermsVersion    string
 }
 
 func Load() Config {
@@ -21,12 +23,14 @@ func Load() Config {
 	_ = godotenv.Load("../.env")
 
 	cfg := Config{
-		GRPCPort:   getEnv("USER_SERVICE_GRPC_PORT", "50051"),
-		DBHost:     getEnv("USER_DB_HOST", "localhost"),
-		DBPort:     getEnv("USER_DB_PORT", "5432"),
-		DBUser:     getEnv("USER_DB_USER", "postgres"),
-		DBPassword: getEnv("USER_DB_PASSWORD", "postgres"),
-		DBName:     getEnv("USER_DB_NAME", "user_db"),
+		GRPCPort:        getEnv("USER_SERVICE_GRPC_PORT", "50051"),
+		DBHost:          getEnv("USER_DB_HOST", "localhost"),
+		DBPort:          getEnv("USER_DB_PORT", "5432"),
+		DBUser:          getEnv("USER_DB_USER", "postgres"),
+		DBPassword:      getEnv("USER_DB_PASSWORD", "postgres"),
+		DBName:          getEnv("USER_DB_NAME", "user_db"),
+		OrderServiceURL: getEnv("ORDER_SERVICE_URL", "localhost:50052"),
+		TermsVersion:    getEnv("TERMS_VERSION", "2026-01"),
 	}
 
 	return cfg
@@ -0,0 +1,46 @@
+package grpc_clients
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"online-store-microservice/pkg/grpcjson"
+	orderpb "online-store-microservice/proto/order"
+)
+
+type OrderClient struct {
+	conn   *grpc.ClientConn
+	Client orderpb.OrderServiceClient
+}
+
+func NewOrderClient(addr string) (*OrderClient, error) {
+	conn, err := grpc.NewClient(
+		addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(grpcjson.Codec{})),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderClient{conn: conn, Client: orderpb.NewOrderServiceClient(conn)}, nil
+}
+
+func (c *OrderClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *OrderClient) ReassignOrders(ctx context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error) {
+	resp, err := c.Client.ReassignOrders(ctx, &orderpb.ReassignOrdersRequest{
+		FromUserId: fromUserID,
+		ToUserId:   toUserID,
+		OrderIds:   orderIDs,
+		DryRun:     dryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.OrderIds, nil
+}
@@ -18,6 +18,7 @@ import (
 	pkglog "online-store-microservice/pkg/logger"
 	userpb "online-store-microservice/proto/user"
 	"online-store-microservice/user-service/config"
+	"online-store-microservice/user-service/grpc_clients"
 	"online-store-microservice/user-service/repository"
 	"online-store-microservice/user-service/server"
 	"online-store-microservice/user-service/service"
@@ -33,8 +34,14 @@ func main() {
 		log.Fatalf("connect db: %v", err)
 	}
 
+	orderClient, err := grpc_clients.NewOrderClient(cfg.OrderServiceURL)
+	if err != nil {
+		log.Fatalf("connect order service: %v", err)
+	}
+	defer orderClient.Close()
+
 	repo := repository.NewUserRepository(db)
-	svc := service.NewUserService(repo)
+	svc := service.NewUserService(repo, orderClient, cfg.TermsVersion)
 	grpcSrv := server.NewGRPCServer(svc, log)
 
 	lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
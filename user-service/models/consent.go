@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+const (
+	ConsentPurposeTerms          = "terms_of_service"
+	ConsentPurposeMarketingEmail = "marketing_email"
+	ConsentPurposeAnalytics      = "analytics"
+)
+
+type ConsentRecord struct {
+	ID            string    `gorm:"type:uuid;primaryKey"`
+	UserID        string    `gorm:"type:uuid;not null;index"`
+	Purpose       string    `gorm:"type:varchar(50);not null"`
+	Granted       bool      `gorm:"not null"`
+	PolicyVersion string    `gorm:"type:varchar(50);not null"`
+	Source        string    `gorm:"type:varchar(50);not null"`
+	IPAddress     string    `gorm:"type:varchar(64)"`
+	UserAgent     string    `gorm:"type:varchar(512)"`
+	RecordedAt    time.Time `gorm:"not null"`
+}
+
+func (ConsentRecord) TableName() string {
+	return "consent_records"
+}
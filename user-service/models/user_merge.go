@@ -0,0 +1,41 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	MergeStatusCompleted = "completed"
+	MergeStatusReverted  = "reverted"
+)
+
+type UserMerge struct {
+	ID            string    `gorm:"type:uuid;primaryKey"`
+	SourceUserID  string    `gorm:"type:uuid;not null;index"`
+	TargetUserID  string    `gorm:"type:uuid;not null;index"`
+	MovedOrderIDs string    `gorm:"type:jsonb;not null"`
+	Status        string    `gorm:"type:varchar(20);not null"`
+	CreatedAt     time.Time `gorm:"not null"`
+	RevertedAt    *time.Time
+}
+
+func (UserMerge) TableName() string {
+	return "user_merges"
+}
+
+func (m *UserMerge) OrderIDs() []string {
+	var ids []string
+	if err := json.Unmarshal([]byte(m.MovedOrderIDs), &ids); err != nil {
+		return []string{}
+	}
+	return ids
+}
+
+func (m *UserMerge) SetOrderIDs(ids []string) {
+	if ids == nil {
+		ids = []string{}
+	}
+	b, _ := json.Marshal(ids)
+	m.MovedOrderIDs = string(b)
+}
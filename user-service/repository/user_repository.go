@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -10,8 +11,15 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
+	CreateWithConsents(ctx context.Context, user *models.User, consents []models.ConsentRecord) error
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	GetByID(ctx context.Context, id string) (*models.User, error)
+	ApplyMerge(ctx context.Context, merge *models.UserMerge) error
+	GetMergeByID(ctx context.Context, id string) (*models.UserMerge, error)
+	RevertMerge(ctx context.Context, merge *models.UserMerge) error
+	AppendConsent(ctx context.Context, record *models.ConsentRecord) error
+	GetCurrentConsents(ctx context.Context, userID string) ([]models.ConsentRecord, error)
+	GetConsentHistory(ctx context.Context, userID string) ([]models.ConsentRecord, error)
 }
 
 type userRepository struct {
@@ -26,6 +34,18 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+func (r *userRepository) CreateWithConsents(ctx context.Context, user *models.User, consents []models.ConsentRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		if len(consents) == 0 {
+			return nil
+		}
+		return tx.Create(&consents).Error
+	})
+}
+
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
 	err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error
@@ -43,3 +63,70 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*models.User,
 	}
 	return &user, nil
 }
+
+func (r *userRepository) ApplyMerge(ctx context.Context, merge *models.UserMerge) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", merge.SourceUserID).Updates(map[string]interface{}{
+			"is_active":      false,
+			"deactivated_at": merge.CreatedAt,
+			"updated_at":     merge.CreatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(merge).Error
+	})
+}
+
+func (r *userRepository) GetMergeByID(ctx context.Context, id string) (*models.UserMerge, error) {
+	var merge models.UserMerge
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&merge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *userRepository) RevertMerge(ctx context.Context, merge *models.UserMerge) error {
+	now := time.Now().UTC()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.User{}).Where("id = ?", merge.SourceUserID).Updates(map[string]interface{}{
+			"is_active":      true,
+			"deactivated_at": nil,
+			"updated_at":     now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		merge.Status = models.MergeStatusReverted
+		merge.RevertedAt = &now
+		return tx.Save(merge).Error
+	})
+}
+
+func (r *userRepository) AppendConsent(ctx context.Context, record *models.ConsentRecord) error {
+	return r.db.WithContext(ctx).Create(record).Error
+}
+
+func (r *userRepository) GetCurrentConsents(ctx context.Context, userID string) ([]models.ConsentRecord, error) {
+	var records []models.ConsentRecord
+	err := r.db.WithContext(ctx).Raw(
+		`SELECT DISTINCT ON (purpose) * FROM consent_records
+		WHERE user_id = ?
+		ORDER BY purpose, recorded_at DESC, id DESC`,
+		userID,
+	).Scan(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (r *userRepository) GetConsentHistory(ctx context.Context, userID string) ([]models.ConsentRecord, error) {
+	var records []models.ConsentRecord
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("recorded_at ASC, id ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
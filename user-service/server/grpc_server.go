@@ -50,16 +50,74 @@ func (s *GRPCServer) GetUserById(ctx context.Context, req *userpb.GetUserByIdReq
 	return resp, nil
 }
 
+func (s *GRPCServer) GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest) (*userpb.GetUserByIdResponse, error) {
+	resp, err := s.service.GetUserByEmail(ctx, req)
+	if err != nil {
+		s.logger.Printf("get user by email failed: %v", err)
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) MergeUsers(ctx context.Context, req *userpb.MergeUsersRequest) (*userpb.MergeUsersResponse, error) {
+	resp, err := s.service.MergeUsers(ctx, req)
+	if err != nil {
+		s.logger.Printf("merge users failed: %v", err)
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) RevertUserMerge(ctx context.Context, req *userpb.RevertUserMergeRequest) (*userpb.RevertUserMergeResponse, error) {
+	resp, err := s.service.RevertUserMerge(ctx, req)
+	if err != nil {
+		s.logger.Printf("revert user merge failed: %v", err)
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) RecordConsent(ctx context.Context, req *userpb.RecordConsentRequest) (*userpb.RecordConsentResponse, error) {
+	resp, err := s.service.RecordConsent(ctx, req)
+	if err != nil {
+		s.logger.Printf("record consent failed: %v", err)
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetConsentState(ctx context.Context, req *userpb.GetConsentStateRequest) (*userpb.GetConsentStateResponse, error) {
+	resp, err := s.service.GetConsentState(ctx, req)
+	if err != nil {
+		s.logger.Printf("get consent state failed: %v", err)
+		return nil, mapError(err)
+	}
+	return resp, nil
+}
+
 func mapError(err error) error {
 	switch {
 	case errors.Is(err, service.ErrInvalidEmail),
 		errors.Is(err, service.ErrInvalidName),
-		errors.Is(err, service.ErrInvalidPassword):
+		errors.Is(err, service.ErrInvalidPassword),
+		errors.Is(err, service.ErrInvalidUserID),
+		errors.Is(err, service.ErrInvalidMergeID),
+		errors.Is(err, service.ErrSameUser),
+		errors.Is(err, service.ErrTermsNotAccepted),
+		errors.Is(err, service.ErrInvalidPurpose),
+		errors.Is(err, service.ErrInvalidPolicy):
 		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrAccountInactive),
+		errors.Is(err, service.ErrMergeReverted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
 	case errors.Is(err, service.ErrEmailAlreadyUsed):
 		return status.Error(codes.AlreadyExists, err.Error())
 	case errors.Is(err, service.ErrInvalidCredential):
 		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrMergeNotFound):
+		return status.Error(codes.NotFound, err.Error())
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		return status.Error(codes.NotFound, "user not found")
 	default:
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	userpb "online-store-microservice/proto/user"
+	"online-store-microservice/user-service/models"
+)
+
+func newConsentService(t *testing.T) (UserService, *memoryUsers, string) {
+	t.Helper()
+	repo := newMemoryUsers()
+	svc := NewUserService(repo, nil, testTermsVersion)
+	resp, err := svc.Register(context.Background(), &userpb.RegisterRequest{
+		Email: "budi@example.com", Password: "secret123", Name: "Budi", AcceptedTermsVersion: testTermsVersion,
+	})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	return svc, repo, resp.User.Id
+}
+
+func TestRegisterRecordsTermsConsent(t *testing.T) {
+	_, repo, userID := newConsentService(t)
+
+	if len(repo.consents) != 1 {
+		t.Fatalf("consents = %d, want 1", len(repo.consents))
+	}
+	terms := repo.consents[0]
+	if terms.UserID != userID || terms.Purpose != models.ConsentPurposeTerms || !terms.Granted || terms.PolicyVersion != testTermsVersion || terms.Source != "register" {
+		t.Fatalf("terms consent = %+v", terms)
+	}
+}
+
+func TestConsentLedgerKeepsHistoryAndCurrentState(t *testing.T) {
+	svc, _, userID := newConsentService(t)
+	ctx := context.Background()
+
+	for _, granted := range []bool{true, false} {
+		_, err := svc.RecordConsent(ctx, &userpb.RecordConsentRequest{
+			UserId: userID, Purpose: " Marketing_Email ", Granted: granted, PolicyVersion: "2026-01", ActorId: userID,
+		})
+		if err != nil {
+			t.Fatalf("RecordConsent(%v): %v", granted, err)
+		}
+	}
+
+	state, err := svc.GetConsentState(ctx, &userpb.GetConsentStateRequest{UserId: userID, IncludeHistory: true, ActorId: userID})
+	if err != nil {
+		t.Fatalf("GetConsentState: %v", err)
+	}
+	if state.CurrentTermsVersion != testTermsVersion {
+		t.Fatalf("current terms version = %q", state.CurrentTermsVersion)
+	}
+	if len(state.Current) != 2 {
+		t.Fatalf("current = %d records, want one per purpose", len(state.Current))
+	}
+	marketing := state.Current[0]
+	if marketing.Purpose != models.ConsentPurposeMarketingEmail || marketing.Granted || marketing.Source != "api" {
+		t.Fatalf("current marketing consent = %+v, want the withdrawal", marketing)
+	}
+	if len(state.History) != 3 {
+		t.Fatalf("history = %d records, want every record kept", len(state.History))
+	}
+}
+
+func TestRecordConsentRejects(t *testing.T) {
+	svc, repo, userID := newConsentService(t)
+	cases := []struct {
+		name string
+		req  *userpb.RecordConsentRequest
+		want error
+	}{
+		{"bad user id", &userpb.RecordConsentRequest{UserId: "nope", Purpose: "analytics", PolicyVersion: "v1", ActorIsAdmin: true}, ErrInvalidUserID},
+		{"unknown purpose", &userpb.RecordConsentRequest{UserId: userID, Purpose: "newsletter", PolicyVersion: "v1", ActorId: userID}, ErrInvalidPurpose},
+		{"no policy version", &userpb.RecordConsentRequest{UserId: userID, Purpose: "analytics", PolicyVersion: " ", ActorId: userID}, ErrInvalidPolicy},
+		{"other user", &userpb.RecordConsentRequest{UserId: userID, Purpose: "analytics", PolicyVersion: "v1", ActorId: uuid.NewString()}, ErrForbidden},
+		{"anonymous", &userpb.RecordConsentRequest{UserId: userID, Purpose: "analytics", PolicyVersion: "v1"}, ErrForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.RecordConsent(context.Background(), tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+	if len(repo.consents) != 1 {
+		t.Fatalf("consents = %d, want only the terms consent", len(repo.consents))
+	}
+}
+
+func TestConsentStateIsForOwnerOrAdmin(t *testing.T) {
+	svc, _, userID := newConsentService(t)
+	ctx := context.Background()
+
+	if _, err := svc.GetConsentState(ctx, &userpb.GetConsentStateRequest{UserId: userID, ActorId: uuid.NewString()}); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("other user: err = %v, want ErrForbidden", err)
+	}
+	if _, err := svc.GetConsentState(ctx, &userpb.GetConsentStateRequest{UserId: userID, ActorIsAdmin: true}); err != nil {
+		t.Fatalf("admin: %v", err)
+	}
+	if _, err := svc.RecordConsent(ctx, &userpb.RecordConsentRequest{UserId: userID, Purpose: "analytics", Granted: true, PolicyVersion: "v1", ActorIsAdmin: true}); err != nil {
+		t.Fatalf("admin record: %v", err)
+	}
+}
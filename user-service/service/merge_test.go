@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	userpb "online-store-microservice/proto/user"
+	"online-store-microservice/user-service/models"
+)
+
+// newMergeService has a source and a target account with two and one orders.
+func newMergeService() (UserService, *memoryUsers, *memoryOrderOwners, *models.User, *models.User) {
+	source, target := activeUser("budi@example.com"), activeUser("budi.s@example.com")
+	users := newMemoryUsers(source, target)
+	orders := newMemoryOrderOwners()
+	orders.place(source.ID)
+	orders.place(target.ID)
+	orders.place(source.ID)
+	return NewUserService(users, orders, testTermsVersion), users, orders, source, target
+}
+
+func TestMergeUsersDryRunChangesNothing(t *testing.T) {
+	svc, users, orders, source, target := newMergeService()
+
+	resp, err := svc.MergeUsers(context.Background(), &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: target.ID, DryRun: true})
+	if err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	if !resp.DryRun || resp.Merge.Id != "" || len(resp.Merge.MovedOrderIds) != 2 || len(resp.Changes) != 2 {
+		t.Fatalf("dry run = %+v", resp)
+	}
+	if len(users.merges) != 0 || !users.users[source.ID].IsActive {
+		t.Fatal("dry run stored the merge")
+	}
+	for _, id := range resp.Merge.MovedOrderIds {
+		if orders.owners[id] != source.ID {
+			t.Fatalf("dry run moved order %s", id)
+		}
+	}
+}
+
+func TestMergeUsersAndRevert(t *testing.T) {
+	svc, users, orders, source, target := newMergeService()
+	ctx := context.Background()
+
+	merged, err := svc.MergeUsers(ctx, &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: target.ID})
+	if err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	moved := merged.Merge.MovedOrderIds
+	if want := []string{orders.ids[0], orders.ids[2]}; !reflect.DeepEqual(moved, want) {
+		t.Fatalf("moved = %v, want %v", moved, want)
+	}
+	for _, id := range orders.ids {
+		if orders.owners[id] != target.ID {
+			t.Fatalf("order %s still belongs to %s", id, orders.owners[id])
+		}
+	}
+	if users.users[source.ID].IsActive || users.users[source.ID].DeactivatedAt == nil {
+		t.Fatal("source account still active")
+	}
+	if merged.Merge.Status != models.MergeStatusCompleted {
+		t.Fatalf("merge status = %s", merged.Merge.Status)
+	}
+
+	// An order placed on the target after the merge stays there.
+	later := orders.place(target.ID)
+	reverted, err := svc.RevertUserMerge(ctx, &userpb.RevertUserMergeRequest{MergeId: merged.Merge.Id})
+	if err != nil {
+		t.Fatalf("RevertUserMerge: %v", err)
+	}
+	if reverted.Merge.Status != models.MergeStatusReverted || reverted.Merge.RevertedAt == "" {
+		t.Fatalf("reverted merge = %+v", reverted.Merge)
+	}
+	for _, id := range moved {
+		if orders.owners[id] != source.ID {
+			t.Fatalf("order %s was not moved back", id)
+		}
+	}
+	if orders.owners[orders.ids[1]] != target.ID || orders.owners[later] != target.ID {
+		t.Fatal("revert moved orders the merge did not move")
+	}
+	if !users.users[source.ID].IsActive || users.users[source.ID].DeactivatedAt != nil {
+		t.Fatal("source account not reactivated")
+	}
+
+	if _, err := svc.RevertUserMerge(ctx, &userpb.RevertUserMergeRequest{MergeId: merged.Merge.Id}); !errors.Is(err, ErrMergeReverted) {
+		t.Fatalf("second revert err = %v, want ErrMergeReverted", err)
+	}
+}
+
+func TestMergeUsersMovesOrdersBackWhenMergeFails(t *testing.T) {
+	svc, users, orders, source, target := newMergeService()
+	users.failMerge = errors.New("connection reset")
+
+	if _, err := svc.MergeUsers(context.Background(), &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: target.ID}); err == nil {
+		t.Fatal("MergeUsers succeeded")
+	}
+	if orders.owners[orders.ids[0]] != source.ID || orders.owners[orders.ids[2]] != source.ID || orders.owners[orders.ids[1]] != target.ID {
+		t.Fatalf("owners = %v, want them as before the merge", orders.owners)
+	}
+	if !users.users[source.ID].IsActive || len(users.merges) != 0 {
+		t.Fatal("failed merge left the source deactivated or a merge stored")
+	}
+}
+
+func TestMergeUsersRejects(t *testing.T) {
+	svc, users, orders, source, target := newMergeService()
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		req  *userpb.MergeUsersRequest
+		want error
+	}{
+		{"bad source id", &userpb.MergeUsersRequest{SourceId: "nope", TargetId: target.ID}, ErrInvalidUserID},
+		{"bad target id", &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: "nope"}, ErrInvalidUserID},
+		{"same user", &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: source.ID}, ErrSameUser},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.MergeUsers(ctx, tc.req); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+
+	users.users[target.ID].IsActive = false
+	if _, err := svc.MergeUsers(ctx, &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: target.ID}); !errors.Is(err, ErrAccountInactive) {
+		t.Fatalf("inactive target err = %v, want ErrAccountInactive", err)
+	}
+	if orders.owners[orders.ids[0]] != source.ID {
+		t.Fatal("a rejected merge moved orders")
+	}
+	if _, err := svc.RevertUserMerge(ctx, &userpb.RevertUserMergeRequest{MergeId: "0c8f9a9e-7a4b-4c61-9d2e-5b1f3a6c7d80"}); !errors.Is(err, ErrMergeNotFound) {
+		t.Fatalf("unknown merge err = %v, want ErrMergeNotFound", err)
+	}
+}
+
+func TestRevertUserMergeKeepsMergeWhenOrdersCannotMove(t *testing.T) {
+	svc, users, orders, source, target := newMergeService()
+	ctx := context.Background()
+
+	merged, err := svc.MergeUsers(ctx, &userpb.MergeUsersRequest{SourceId: source.ID, TargetId: target.ID})
+	if err != nil {
+		t.Fatalf("MergeUsers: %v", err)
+	}
+	orders.fail = errors.New("order-service unavailable")
+	if _, err := svc.RevertUserMerge(ctx, &userpb.RevertUserMergeRequest{MergeId: merged.Merge.Id}); err == nil {
+		t.Fatal("RevertUserMerge succeeded")
+	}
+	if users.merges[merged.Merge.Id].Status != models.MergeStatusCompleted || users.users[source.ID].IsActive {
+		t.Fatal("merge reverted although its orders stayed with the target")
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"online-store-microservice/user-service/models"
+	"online-store-microservice/user-service/repository"
+)
+
+const testTermsVersion = "2026-01"
+
+// memoryUsers is the user repository of the service tests. Users and merges
+// are handed out as copies, like the database would.
+type memoryUsers struct {
+	users    map[string]*models.User
+	merges   map[string]*models.UserMerge
+	consents []models.ConsentRecord
+	// failMerge makes ApplyMerge fail after the orders have moved.
+	failMerge error
+}
+
+var _ repository.UserRepository = (*memoryUsers)(nil)
+
+func newMemoryUsers(users ...*models.User) *memoryUsers {
+	r := &memoryUsers{users: map[string]*models.User{}, merges: map[string]*models.UserMerge{}}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *memoryUsers) Create(_ context.Context, user *models.User) error {
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *memoryUsers) CreateWithConsents(ctx context.Context, user *models.User, consents []models.ConsentRecord) error {
+	r.users[user.ID] = user
+	r.consents = append(r.consents, consents...)
+	return nil
+}
+
+func (r *memoryUsers) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryUsers) GetByID(_ context.Context, id string) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *memoryUsers) ApplyMerge(_ context.Context, merge *models.UserMerge) error {
+	if r.failMerge != nil {
+		return r.failMerge
+	}
+	source := r.users[merge.SourceUserID]
+	source.IsActive = false
+	source.DeactivatedAt = &merge.CreatedAt
+	copied := *merge
+	r.merges[merge.ID] = &copied
+	return nil
+}
+
+func (r *memoryUsers) GetMergeByID(_ context.Context, id string) (*models.UserMerge, error) {
+	merge, ok := r.merges[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *merge
+	return &copied, nil
+}
+
+func (r *memoryUsers) RevertMerge(_ context.Context, merge *models.UserMerge) error {
+	now := time.Now().UTC()
+	source := r.users[merge.SourceUserID]
+	source.IsActive = true
+	source.DeactivatedAt = nil
+	merge.Status = models.MergeStatusReverted
+	merge.RevertedAt = &now
+	copied := *merge
+	r.merges[merge.ID] = &copied
+	return nil
+}
+
+func (r *memoryUsers) AppendConsent(_ context.Context, record *models.ConsentRecord) error {
+	r.consents = append(r.consents, *record)
+	return nil
+}
+
+// GetCurrentConsents returns the latest record per purpose, ordered by
+// purpose, like the DISTINCT ON query.
+func (r *memoryUsers) GetCurrentConsents(ctx context.Context, userID string) ([]models.ConsentRecord, error) {
+	history, _ := r.GetConsentHistory(ctx, userID)
+	latest := map[string]models.ConsentRecord{}
+	for _, record := range history {
+		latest[record.Purpose] = record
+	}
+	current := make([]models.ConsentRecord, 0, len(latest))
+	for _, record := range latest {
+		current = append(current, record)
+	}
+	sort.Slice(current, func(i, j int) bool { return current[i].Purpose < current[j].Purpose })
+	return current, nil
+}
+
+func (r *memoryUsers) GetConsentHistory(_ context.Context, userID string) ([]models.ConsentRecord, error) {
+	var history []models.ConsentRecord
+	for _, record := range r.consents {
+		if record.UserID == userID {
+			history = append(history, record)
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].RecordedAt.Before(history[j].RecordedAt) })
+	return history, nil
+}
+
+// memoryOrderOwners stands in for order-service: it knows who owns which
+// order and reassigns them like ReassignOrders does.
+type memoryOrderOwners struct {
+	ids    []string
+	owners map[string]string
+	// fail makes every call fail as if order-service were down.
+	fail error
+}
+
+func newMemoryOrderOwners() *memoryOrderOwners {
+	return &memoryOrderOwners{owners: map[string]string{}}
+}
+
+func (o *memoryOrderOwners) place(userID string) string {
+	id := uuid.NewString()
+	o.ids = append(o.ids, id)
+	o.owners[id] = userID
+	return id
+}
+
+func (o *memoryOrderOwners) ReassignOrders(_ context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error) {
+	if o.fail != nil {
+		return nil, o.fail
+	}
+	wanted := map[string]bool{}
+	for _, id := range orderIDs {
+		wanted[id] = true
+	}
+	var moved []string
+	for _, id := range o.ids {
+		if o.owners[id] != fromUserID || (len(wanted) > 0 && !wanted[id]) {
+			continue
+		}
+		moved = append(moved, id)
+		if !dryRun {
+			o.owners[id] = toUserID
+		}
+	}
+	return moved, nil
+}
+
+func activeUser(email string) *models.User {
+	now := time.Now().UTC()
+	return &models.User{ID: uuid.NewString(), Email: email, Name: "Budi", IsActive: true, CreatedAt: now, UpdatedAt: now}
+}
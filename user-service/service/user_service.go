@@ -23,20 +23,47 @@ var (
 	ErrInvalidPassword   = errors.New("password must be at least 6 characters")
 	ErrEmailAlreadyUsed  = errors.New("email already registered")
 	ErrInvalidCredential = errors.New("invalid credentials")
+	ErrAccountInactive   = errors.New("account is inactive")
+	ErrInvalidUserID     = errors.New("invalid user id")
+	ErrInvalidMergeID    = errors.New("invalid merge id")
+	ErrSameUser          = errors.New("source_id and target_id must differ")
+	ErrMergeNotFound     = errors.New("merge not found")
+	ErrMergeReverted     = errors.New("merge already reverted")
+	ErrTermsNotAccepted  = errors.New("current terms of service version must be accepted")
+	ErrInvalidPurpose    = errors.New("invalid consent purpose")
+	ErrInvalidPolicy     = errors.New("policy_version is required")
+	ErrForbidden         = errors.New("only the user or an admin may do this")
 )
 
+var consentPurposes = map[string]bool{
+	models.ConsentPurposeTerms:          true,
+	models.ConsentPurposeMarketingEmail: true,
+	models.ConsentPurposeAnalytics:      true,
+}
+
+type OrderReassigner interface {
+	ReassignOrders(ctx context.Context, fromUserID, toUserID string, orderIDs []string, dryRun bool) ([]string, error)
+}
+
 type UserService interface {
 	Register(ctx context.Context, req *userpb.RegisterRequest) (*userpb.RegisterResponse, error)
 	Login(ctx context.Context, req *userpb.LoginRequest) (*userpb.LoginResponse, error)
 	GetUserByID(ctx context.Context, req *userpb.GetUserByIdRequest) (*userpb.GetUserByIdResponse, error)
+	GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest) (*userpb.GetUserByIdResponse, error)
+	MergeUsers(ctx context.Context, req *userpb.MergeUsersRequest) (*userpb.MergeUsersResponse, error)
+	RevertUserMerge(ctx context.Context, req *userpb.RevertUserMergeRequest) (*userpb.RevertUserMergeResponse, error)
+	RecordConsent(ctx context.Context, req *userpb.RecordConsentRequest) (*userpb.RecordConsentResponse, error)
+	GetConsentState(ctx context.Context, req *userpb.GetConsentStateRequest) (*userpb.GetConsentStateResponse, error)
 }
 
 type userService struct {
-	repo repository.UserRepository
+	repo         repository.UserRepository
+	orders       OrderReassigner
+	termsVersion string
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
-	return &userService{repo: repo}
+func NewUserService(repo repository.UserRepository, orders OrderReassigner, termsVersion string) UserService {
+	return &userService{repo: repo, orders: orders, termsVersion: termsVersion}
 }
 
 func (s *userService) Register(ctx context.Context, req *userpb.RegisterRequest) (*userpb.RegisterResponse, error) {
@@ -51,6 +78,9 @@ func (s *userService) Register(ctx context.Context, req *userpb.RegisterRequest)
 	if len(req.Password) < 6 {
 		return nil, ErrInvalidPassword
 	}
+	if strings.TrimSpace(req.AcceptedTermsVersion) != s.termsVersion {
+		return nil, ErrTermsNotAccepted
+	}
 
 	if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
 		return nil, ErrEmailAlreadyUsed
@@ -69,11 +99,23 @@ func (s *userService) Register(ctx context.Context, req *userpb.RegisterRequest)
 		Email:        req.Email,
 		PasswordHash: string(hashed),
 		Name:         req.Name,
+		IsActive:     true,
 		CreatedAt:    now,
 		UpdatedAt:    now,
 	}
+	terms := models.ConsentRecord{
+		ID:            uuid.NewString(),
+		UserID:        user.ID,
+		Purpose:       models.ConsentPurposeTerms,
+		Granted:       true,
+		PolicyVersion: s.termsVersion,
+		Source:        "register",
+		IPAddress:     req.IpAddress,
+		UserAgent:     req.UserAgent,
+		RecordedAt:    now,
+	}
 
-	if err := s.repo.Create(ctx, user); err != nil {
+	if err := s.repo.CreateWithConsents(ctx, user, []models.ConsentRecord{terms}); err != nil {
 		return nil, err
 	}
 
@@ -97,6 +139,9 @@ func (s *userService) Login(ctx context.Context, req *userpb.LoginRequest) (*use
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
 		return nil, ErrInvalidCredential
 	}
+	if !user.IsActive {
+		return nil, ErrAccountInactive
+	}
 
 	token := fmt.Sprintf("dummy-token-%s", user.ID)
 	return &userpb.LoginResponse{User: toPBUser(user), Token: token}, nil
@@ -115,11 +160,221 @@ func (s *userService) GetUserByID(ctx context.Context, req *userpb.GetUserByIdRe
 	return &userpb.GetUserByIdResponse{User: toPBUser(user)}, nil
 }
 
+func (s *userService) GetUserByEmail(ctx context.Context, req *userpb.GetUserByEmailRequest) (*userpb.GetUserByIdResponse, error) {
+	email := strings.TrimSpace(strings.ToLower(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, ErrInvalidEmail
+	}
+
+	user, err := s.repo.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userpb.GetUserByIdResponse{User: toPBUser(user)}, nil
+}
+
+func (s *userService) MergeUsers(ctx context.Context, req *userpb.MergeUsersRequest) (*userpb.MergeUsersResponse, error) {
+	if _, err := uuid.Parse(req.SourceId); err != nil {
+		return nil, ErrInvalidUserID
+	}
+	if _, err := uuid.Parse(req.TargetId); err != nil {
+		return nil, ErrInvalidUserID
+	}
+	if req.SourceId == req.TargetId {
+		return nil, ErrSameUser
+	}
+
+	source, err := s.repo.GetByID(ctx, req.SourceId)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.repo.GetByID(ctx, req.TargetId)
+	if err != nil {
+		return nil, err
+	}
+	if !source.IsActive || !target.IsActive {
+		return nil, ErrAccountInactive
+	}
+
+	orderIDs, err := s.orders.ReassignOrders(ctx, source.ID, target.ID, nil, req.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("reassign orders: %w", err)
+	}
+
+	changes := []string{
+		fmt.Sprintf("move %d order(s) from %s to %s", len(orderIDs), source.ID, target.ID),
+		fmt.Sprintf("deactivate source account %s (%s)", source.ID, source.Email),
+	}
+
+	merge := &models.UserMerge{
+		ID:           uuid.NewString(),
+		SourceUserID: source.ID,
+		TargetUserID: target.ID,
+		Status:       models.MergeStatusCompleted,
+		CreatedAt:    time.Now().UTC(),
+	}
+	merge.SetOrderIDs(orderIDs)
+
+	if req.DryRun {
+		merge.ID = ""
+		return &userpb.MergeUsersResponse{DryRun: true, Merge: toPBMerge(merge), Changes: changes}, nil
+	}
+
+	if err := s.repo.ApplyMerge(ctx, merge); err != nil {
+		if _, rerr := s.orders.ReassignOrders(ctx, target.ID, source.ID, orderIDs, false); rerr != nil {
+			return nil, fmt.Errorf("apply merge: %w (order rollback failed: %v)", err, rerr)
+		}
+		return nil, fmt.Errorf("apply merge: %w", err)
+	}
+
+	return &userpb.MergeUsersResponse{Merge: toPBMerge(merge), Changes: changes}, nil
+}
+
+func (s *userService) RevertUserMerge(ctx context.Context, req *userpb.RevertUserMergeRequest) (*userpb.RevertUserMergeResponse, error) {
+	if _, err := uuid.Parse(req.MergeId); err != nil {
+		return nil, ErrInvalidMergeID
+	}
+
+	merge, err := s.repo.GetMergeByID(ctx, req.MergeId)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMergeNotFound
+		}
+		return nil, err
+	}
+	if merge.Status == models.MergeStatusReverted {
+		return nil, ErrMergeReverted
+	}
+
+	if ids := merge.OrderIDs(); len(ids) > 0 {
+		if _, err := s.orders.ReassignOrders(ctx, merge.TargetUserID, merge.SourceUserID, ids, false); err != nil {
+			return nil, fmt.Errorf("reassign orders: %w", err)
+		}
+	}
+
+	if err := s.repo.RevertMerge(ctx, merge); err != nil {
+		return nil, err
+	}
+
+	return &userpb.RevertUserMergeResponse{Merge: toPBMerge(merge)}, nil
+}
+
+func (s *userService) RecordConsent(ctx context.Context, req *userpb.RecordConsentRequest) (*userpb.RecordConsentResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, ErrInvalidUserID
+	}
+	if !req.ActorIsAdmin && req.ActorId != req.UserId {
+		return nil, ErrForbidden
+	}
+	req.Purpose = strings.TrimSpace(strings.ToLower(req.Purpose))
+	if !consentPurposes[req.Purpose] {
+		return nil, ErrInvalidPurpose
+	}
+	req.PolicyVersion = strings.TrimSpace(req.PolicyVersion)
+	if req.PolicyVersion == "" {
+		return nil, ErrInvalidPolicy
+	}
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = "api"
+	}
+
+	if _, err := s.repo.GetByID(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	record := &models.ConsentRecord{
+		ID:            uuid.NewString(),
+		UserID:        req.UserId,
+		Purpose:       req.Purpose,
+		Granted:       req.Granted,
+		PolicyVersion: req.PolicyVersion,
+		Source:        source,
+		IPAddress:     req.IpAddress,
+		UserAgent:     req.UserAgent,
+		RecordedAt:    time.Now().UTC(),
+	}
+	if err := s.repo.AppendConsent(ctx, record); err != nil {
+		return nil, err
+	}
+
+	return &userpb.RecordConsentResponse{Consent: toPBConsent(record)}, nil
+}
+
+func (s *userService) GetConsentState(ctx context.Context, req *userpb.GetConsentStateRequest) (*userpb.GetConsentStateResponse, error) {
+	if _, err := uuid.Parse(req.UserId); err != nil {
+		return nil, ErrInvalidUserID
+	}
+	if !req.ActorIsAdmin && req.ActorId != req.UserId {
+		return nil, ErrForbidden
+	}
+	if _, err := s.repo.GetByID(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	current, err := s.repo.GetCurrentConsents(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userpb.GetConsentStateResponse{
+		CurrentTermsVersion: s.termsVersion,
+		Current:             make([]*userpb.ConsentRecordData, 0, len(current)),
+	}
+	for i := range current {
+		resp.Current = append(resp.Current, toPBConsent(&current[i]))
+	}
+
+	if req.IncludeHistory {
+		history, err := s.repo.GetConsentHistory(ctx, req.UserId)
+		if err != nil {
+			return nil, err
+		}
+		resp.History = make([]*userpb.ConsentRecordData, 0, len(history))
+		for i := range history {
+			resp.History = append(resp.History, toPBConsent(&history[i]))
+		}
+	}
+
+	return resp, nil
+}
+
+func toPBConsent(record *models.ConsentRecord) *userpb.ConsentRecordData {
+	return &userpb.ConsentRecordData{
+		Id:            record.ID,
+		UserId:        record.UserID,
+		Purpose:       record.Purpose,
+		Granted:       record.Granted,
+		PolicyVersion: record.PolicyVersion,
+		Source:        record.Source,
+		IpAddress:     record.IPAddress,
+		UserAgent:     record.UserAgent,
+		RecordedAt:    record.RecordedAt.Format(time.RFC3339),
+	}
+}
+
+func toPBMerge(merge *models.UserMerge) *userpb.UserMergeData {
+	data := &userpb.UserMergeData{
+		Id:            merge.ID,
+		SourceUserId:  merge.SourceUserID,
+		TargetUserId:  merge.TargetUserID,
+		MovedOrderIds: merge.OrderIDs(),
+		Status:        merge.Status,
+		CreatedAt:     merge.CreatedAt.Format(time.RFC3339),
+	}
+	if merge.RevertedAt != nil {
+		data.RevertedAt = merge.RevertedAt.Format(time.RFC3339)
+	}
+	return data
+}
+
 func toPBUser(user *models.User) *userpb.UserData {
 	return &userpb.UserData{
 		Id:        user.ID,
 		Email:     user.Email,
 		Name:      user.Name,
+		IsActive:  user.IsActive,
 		CreatedAt: user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt: user.UpdatedAt.Format(time.RFC3339),
 	}